@@ -0,0 +1,136 @@
+package bench
+
+import (
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// How often nodes look for finished jobs to archive
+const ArchiveInterval = time.Minute
+
+// runArchiver periodically archives jobs that ended more than archive_after
+// ago. Every node runs the archiver; archiving the same job twice publishes
+// the same record and the cleanup ignores data that is already gone.
+func (b *Bench) runArchiver() {
+	ticker := time.NewTicker(ArchiveInterval)
+
+	for range ticker.C {
+		b.archiveJobs(time.Now().UTC())
+	}
+}
+
+// archiveJobs runs a single archiver pass
+func (b *Bench) archiveJobs(now time.Time) {
+	settings, err := b.nats.GetAllSettings()
+	if err != nil {
+		b.log.Errorf("archiver: unable to get settings: %s", err)
+		return
+	}
+
+	for _, s := range settings {
+		// A job cannot have ended before it was created
+		if now.Sub(s.CreatedAt) < b.params.ArchiveAfter {
+			continue
+		}
+
+		if err := b.archiveJob(s, now); err != nil {
+			b.log.Errorf("archiver: unable to archive job '%s': %s", s.ID, err)
+		}
+	}
+}
+
+// archiveJob moves a job that ended more than archive_after ago to the
+// history stream: its settings and aggregate status are archived, then its
+// results bucket and settings are deleted. Jobs without results are archived
+// with an unknown status. The job's timeline ages out on its own.
+func (b *Bench) archiveJob(settings *types.Settings, now time.Time) error {
+	archived, err := b.nats.GetArchivedRun(settings.ID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get archived run")
+	}
+
+	// Another node archived the job but has not cleaned up (yet)
+	if archived != nil {
+		return b.cleanupArchived(settings.ID)
+	}
+
+	endedAt := settings.CreatedAt
+
+	status, err := b.Status(settings.ID)
+	if err != nil {
+		// No results bucket
+		if errors.Cause(err) != nats.ErrBucketNotFound {
+			return errors.Wrap(err, "unable to get status")
+		}
+
+		status = &types.Status{
+			Status:  types.UnknownStatus,
+			Message: "job has no results",
+			JobID:   settings.ID,
+		}
+	} else {
+		if !isFinal(status.Status) {
+			return nil
+		}
+
+		if !status.EndedAt.IsZero() {
+			endedAt = status.EndedAt
+		}
+	}
+
+	if now.Sub(endedAt) < b.params.ArchiveAfter {
+		return nil
+	}
+
+	// Only aggregates are kept, as for baselines
+	status.NodeReports = nil
+	status.LatencyHistogram = nil
+
+	if err := b.nats.ArchiveRun(&types.ArchivedRun{
+		Settings:   settings,
+		Status:     status,
+		ArchivedAt: now,
+	}); err != nil {
+		return err
+	}
+
+	if err := b.cleanupArchived(settings.ID); err != nil {
+		return err
+	}
+
+	b.log.Infof("archiver: archived job '%s' (%s)", settings.ID, status.Status)
+
+	return nil
+}
+
+// cleanupArchived deletes the results bucket and settings of an archived job
+func (b *Bench) cleanupArchived(jobID string) error {
+	if err := b.nats.DeleteResults(jobID); err != nil && errors.Cause(err) != nats.ErrStreamNotFound {
+		return errors.Wrap(err, "unable to delete results")
+	}
+
+	if err := b.nats.DeleteSettings(jobID); err != nil && errors.Cause(err) != nats.ErrKeyNotFound {
+		return errors.Wrap(err, "unable to delete settings")
+	}
+
+	return nil
+}
+
+// jobSettings returns the settings of a job, falling back to its archived
+// run once the job has been archived
+func (b *Bench) jobSettings(jobID string) (*types.Settings, error) {
+	settings, err := b.nats.GetSettings(jobID)
+	if err == nil || errors.Cause(err) != nats.ErrKeyNotFound {
+		return settings, err
+	}
+
+	run, archErr := b.nats.GetArchivedRun(jobID)
+	if archErr != nil || run == nil {
+		return nil, err
+	}
+
+	return run.Settings, nil
+}
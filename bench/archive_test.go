@@ -0,0 +1,153 @@
+package bench
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestArchiveJob(t *testing.T) {
+	now := time.Date(2022, 5, 25, 12, 0, 0, 0, time.UTC)
+	settings := &types.Settings{ID: "abc", Participants: []string{"node1"}, CreatedAt: now.Add(-3 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		statuses []*types.Status
+		archived *types.ArchivedRun
+		expected types.JobStatus // status of the archived run, if archived
+		cleanup  bool
+	}{
+		{
+			name:     "completed",
+			statuses: []*types.Status{{NodeID: "node1", Status: types.CompletedStatus, EndedAt: now.Add(-2 * time.Hour)}},
+			expected: types.CompletedStatus,
+			cleanup:  true,
+		},
+		{
+			name:     "ended recently",
+			statuses: []*types.Status{{NodeID: "node1", Status: types.CompletedStatus, EndedAt: now.Add(-time.Minute)}},
+		},
+		{
+			name:     "in progress",
+			statuses: []*types.Status{{NodeID: "node1", Status: types.InProgressStatus}},
+		},
+		{
+			name:     "no results",
+			expected: types.UnknownStatus,
+			cleanup:  true,
+		},
+		{
+			name:     "already archived",
+			archived: &types.ArchivedRun{Settings: settings, Status: &types.Status{Status: types.CompletedStatus}},
+			cleanup:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+			b.params.ArchiveAfter = time.Hour
+
+			if tt.statuses != nil {
+				fake.GetStatusesReturns(tt.statuses, nil)
+			} else {
+				fake.GetStatusesReturns(nil, nats.ErrBucketNotFound)
+			}
+
+			fake.GetArchivedRunReturns(tt.archived, nil)
+			fake.GetSettingsReturns(settings, nil)
+			fake.DeleteResultsReturns(nats.ErrStreamNotFound)
+
+			if err := b.archiveJob(settings, now); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if tt.expected == "" && fake.ArchiveRunCallCount() != 0 {
+				t.Fatal("expected the job not to be archived")
+			}
+
+			if tt.expected != "" {
+				if fake.ArchiveRunCallCount() != 1 {
+					t.Fatal("expected the job to be archived")
+				}
+
+				run := fake.ArchiveRunArgsForCall(0)
+
+				if run.Settings.ID != "abc" || run.Status.Status != tt.expected || !run.ArchivedAt.Equal(now) {
+					t.Errorf("unexpected archived run %+v", run)
+				}
+			}
+
+			cleanup := fake.DeleteResultsCallCount() == 1 && fake.DeleteSettingsCallCount() == 1
+
+			if cleanup != tt.cleanup {
+				t.Errorf("expected results and settings to be deleted: %v", tt.cleanup)
+			}
+		})
+	}
+}
+
+func TestArchiveJobCompact(t *testing.T) {
+	b, fake := newTestBench(t)
+	b.params.ArchiveAfter = time.Minute
+
+	now := time.Now().UTC()
+	settings := &types.Settings{ID: "abc", Participants: []string{"node1"}, CreatedAt: now.Add(-time.Hour)}
+
+	fake.GetSettingsReturns(settings, nil)
+	fake.GetStatusesReturns([]*types.Status{{
+		NodeID:           "node1",
+		Status:           types.CompletedStatus,
+		EndedAt:          now.Add(-time.Hour),
+		NodeReport:       &types.NodeReport{Streams: []types.StreamReport{{}}},
+		LatencyHistogram: &types.LatencyHistogram{},
+	}}, nil)
+
+	if err := b.archiveJob(settings, now); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.ArchiveRunCallCount() != 1 {
+		t.Fatal("expected the job to be archived")
+	}
+
+	if s := fake.ArchiveRunArgsForCall(0).Status; s.NodeReports != nil || s.LatencyHistogram != nil {
+		t.Errorf("expected node reports and latency histogram to be dropped, got %+v", s)
+	}
+
+	// Nothing is cleaned up if the run could not be archived
+	b, fake = newTestBench(t)
+	b.params.ArchiveAfter = time.Minute
+
+	fake.GetStatusesReturns(nil, nats.ErrBucketNotFound)
+	fake.ArchiveRunReturns(errors.New("nats: timeout"))
+
+	if err := b.archiveJob(settings, now); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if fake.DeleteResultsCallCount() != 0 || fake.DeleteSettingsCallCount() != 0 {
+		t.Error("expected results and settings to be kept")
+	}
+}
+
+func TestDeleteArchived(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.RequestJobReturns([]byte(`{"node_id":"node1","job_id":"abc"}`), nil)
+	fake.GetArchivedRunReturns(&types.ArchivedRun{Settings: &types.Settings{ID: "abc"}}, nil)
+	fake.DeleteResultsReturns(nats.ErrStreamNotFound)
+
+	if _, err := b.Delete("abc", false, false, true); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.DeleteArchivedRunCallCount() != 1 || fake.DeleteArchivedRunArgsForCall(0) != "abc" {
+		t.Error("expected the archived run to be deleted")
+	}
+}
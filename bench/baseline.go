@@ -0,0 +1,169 @@
+package bench
+
+import (
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultThroughputDropPct    = 10.0
+	DefaultErrorRateIncreasePct = 1.0
+	DefaultLatencyIncreasePct   = 20.0
+)
+
+// SetBaseline makes a completed job the baseline for the given profile,
+// replacing any previous baseline. Thresholds that are not set fall back to
+// their defaults.
+func (b *Bench) SetBaseline(profile, jobID string, thresholds *types.Thresholds) (*types.Baseline, error) {
+	settings, err := b.jobSettings(jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get job settings")
+	}
+
+	if settings.Profile != "" && settings.Profile != profile {
+		return nil, errors.Errorf("job '%s' belongs to profile '%s'", jobID, settings.Profile)
+	}
+
+	status, err := b.Status(jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get job status")
+	}
+
+	if status.Status != types.CompletedStatus {
+		return nil, errors.Errorf("job '%s' is not completed (status: %s)", jobID, status.Status)
+	}
+
+	// Keep the baseline small; only aggregates are compared
+	status.NodeReports = nil
+	status.LatencyHistogram = nil
+
+	baseline := &types.Baseline{
+		Profile:    profile,
+		JobID:      jobID,
+		Thresholds: defaultThresholds(thresholds),
+		Status:     status,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if err := b.nats.SaveBaseline(baseline); err != nil {
+		return nil, errors.Wrap(err, "unable to save baseline")
+	}
+
+	return baseline, nil
+}
+
+// Compare compares a completed job against the baseline of its profile. A nil
+// comparison is returned if the job has no profile, is not completed or if
+// there is no baseline for the profile yet.
+func (b *Bench) Compare(settings *types.Settings, status *types.Status) (*types.Comparison, error) {
+	if settings == nil || status == nil || settings.Profile == "" || status.Status != types.CompletedStatus {
+		return nil, nil
+	}
+
+	baseline, err := b.nats.GetBaseline(settings.Profile)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "unable to get baseline")
+	}
+
+	return compareStatuses(baseline, status), nil
+}
+
+func compareStatuses(baseline *types.Baseline, status *types.Status) *types.Comparison {
+	thresholds := defaultThresholds(baseline.Thresholds)
+
+	comparison := &types.Comparison{
+		Profile:       baseline.Profile,
+		BaselineJobID: baseline.JobID,
+		Metrics:       make([]*types.MetricComparison, 0),
+	}
+
+	add := func(name string, base, current, threshold float64, higherIsWorse, absolute bool) {
+		m := &types.MetricComparison{
+			Name:      name,
+			Baseline:  round(base, 2),
+			Current:   round(current, 2),
+			Delta:     round(current-base, 2),
+			Threshold: threshold,
+		}
+
+		if base != 0 {
+			m.DeltaPct = round((current-base)/base*100, 2)
+		}
+
+		// Error rate thresholds are in percentage points, everything else is
+		// relative to the baseline
+		change := m.DeltaPct
+
+		if absolute {
+			change = current - base
+		}
+
+		if higherIsWorse {
+			m.Regressed = change > threshold
+		} else {
+			m.Regressed = -change > threshold
+		}
+
+		if m.Regressed {
+			comparison.Regressed = true
+		}
+
+		comparison.Metrics = append(comparison.Metrics, m)
+	}
+
+	add("total_msg_per_sec_all_nodes", baseline.Status.TotalMsgPerSecAllNodes, status.TotalMsgPerSecAllNodes,
+		*thresholds.ThroughputDropPct, false, false)
+
+	add("error_rate_pct", errorRate(baseline.Status), errorRate(status),
+		*thresholds.ErrorRateIncreasePct, true, true)
+
+	if baseline.Status.Latency != nil && status.Latency != nil {
+		latency := *thresholds.LatencyIncreasePct
+
+		add("latency_p50_ms", baseline.Status.Latency.P50Ms, status.Latency.P50Ms, latency, true, false)
+		add("latency_p90_ms", baseline.Status.Latency.P90Ms, status.Latency.P90Ms, latency, true, false)
+		add("latency_p99_ms", baseline.Status.Latency.P99Ms, status.Latency.P99Ms, latency, true, false)
+	}
+
+	return comparison
+}
+
+// errorRate returns the percentage of failed operations
+func errorRate(status *types.Status) float64 {
+	total := status.TotalProcessed + status.TotalErrors
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(status.TotalErrors) / float64(total) * 100
+}
+
+func defaultThresholds(thresholds *types.Thresholds) *types.Thresholds {
+	t := &types.Thresholds{}
+
+	if thresholds != nil {
+		*t = *thresholds
+	}
+
+	t.ThroughputDropPct = defaultThreshold(t.ThroughputDropPct, DefaultThroughputDropPct)
+	t.ErrorRateIncreasePct = defaultThreshold(t.ErrorRateIncreasePct, DefaultErrorRateIncreasePct)
+	t.LatencyIncreasePct = defaultThreshold(t.LatencyIncreasePct, DefaultLatencyIncreasePct)
+
+	return t
+}
+
+func defaultThreshold(threshold *float64, def float64) *float64 {
+	if threshold != nil {
+		return threshold
+	}
+
+	return &def
+}
@@ -0,0 +1,67 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestDefaultThresholds(t *testing.T) {
+	zero := float64(0)
+	five := float64(5)
+
+	tests := []struct {
+		name       string
+		thresholds *types.Thresholds
+		expected   [3]float64 // throughput, error rate, latency
+	}{
+		{"nil", nil, [3]float64{DefaultThroughputDropPct, DefaultErrorRateIncreasePct, DefaultLatencyIncreasePct}},
+		{"unset", &types.Thresholds{}, [3]float64{DefaultThroughputDropPct, DefaultErrorRateIncreasePct, DefaultLatencyIncreasePct}},
+		{"set", &types.Thresholds{ThroughputDropPct: &five}, [3]float64{5, DefaultErrorRateIncreasePct, DefaultLatencyIncreasePct}},
+		{"zero", &types.Thresholds{ErrorRateIncreasePct: &zero, LatencyIncreasePct: &zero},
+			[3]float64{DefaultThroughputDropPct, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := defaultThresholds(tt.thresholds)
+
+			actual := [3]float64{*th.ThroughputDropPct, *th.ErrorRateIncreasePct, *th.LatencyIncreasePct}
+
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompareStatusesZeroTolerance(t *testing.T) {
+	zero := float64(0)
+
+	baseline := &types.Baseline{
+		Profile:    "nightly",
+		JobID:      "abc",
+		Thresholds: &types.Thresholds{ThroughputDropPct: &zero},
+		Status:     &types.Status{TotalMsgPerSecAllNodes: 1000, TotalProcessed: 1000},
+	}
+
+	tests := []struct {
+		name       string
+		msgsPerSec float64
+		regressed  bool
+	}{
+		{"faster", 1001, false},
+		{"same", 1000, false},
+		{"slightly slower", 999, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := compareStatuses(baseline, &types.Status{TotalMsgPerSecAllNodes: tt.msgsPerSec, TotalProcessed: 1000})
+
+			if comparison.Regressed != tt.regressed {
+				t.Errorf("expected regressed to be %v, got %+v", tt.regressed, comparison.Metrics[0])
+			}
+		})
+	}
+}
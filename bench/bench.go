@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	mrand "math/rand"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,13 +26,39 @@ const (
 	DefaultNumMessagesPerStream = 10000
 	DefaultNumWorkersPerStream  = 1
 	DefaultSubject              = "default"
+
+	// How long a node waits for a cancelled job to write its final status
+	JobStopTimeout = 10 * time.Second
+
+	// How long Delete waits for each node to confirm; longer than
+	// JobStopTimeout so that nodes get to reply even if the job did not stop
+	CancelTimeout = JobStopTimeout + 5*time.Second
+
+	// How long Create waits for each node to accept or reject its job;
+	// nodes reply before the job runs
+	CreateTimeout = 5 * time.Second
+
+	// How long a node remembers jobs that were deleted before it received
+	// them, so that a create job that arrives late is rejected
+	DeletedJobTTL = time.Hour
 )
 
+var (
+	runes = []rune("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+)
+
+func init() {
+	mrand.Seed(time.Now().UnixNano())
+}
+
 type Bench struct {
-	nats      *natssvc.NATSService
+	nats      natssvc.INATSService
 	params    *cli.Params
 	jobs      map[string]*types.Job
 	jobsMutex *sync.RWMutex
+	deleted   map[string]time.Time // jobs deleted while not on this node; see markDeleted
+	executor  *executor
+	metrics   *metricsRegistry
 	log       *logrus.Entry
 }
 
@@ -41,9 +70,17 @@ type Worker struct {
 	Errors     []string
 	StartedAt  time.Time
 	EndedAt    time.Time
+	Latency    *types.LatencyHistogram
+
+	// Set for readers in verify mode; see verify.go
+	verifier *verifier
+
+	// Live metrics; see metrics.go
+	jobMetrics    *jobMetrics
+	streamMetrics *streamMetrics
 }
 
-func New(p *cli.Params, nsvc *natssvc.NATSService) (*Bench, error) {
+func New(p *cli.Params, nsvc natssvc.INATSService) (*Bench, error) {
 	if err := validateParams(p); err != nil {
 		return nil, errors.Wrap(err, "unable to validate params")
 	}
@@ -57,10 +94,36 @@ func New(p *cli.Params, nsvc *natssvc.NATSService) (*Bench, error) {
 		nats:      nsvc,
 		jobs:      make(map[string]*types.Job),
 		jobsMutex: &sync.RWMutex{},
+		deleted:   make(map[string]time.Time),
+		executor:  newExecutor(p.MaxConcurrentJobs, p.MaxQueuedJobs),
+		metrics:   newMetricsRegistry(),
 		log:       logrus.WithField("pkg", "bench"),
 	}, nil
 }
 
+// Start launches background processes that every njst node runs
+func (b *Bench) Start() error {
+	b.log.Debug("launching scheduler")
+
+	go b.runScheduler()
+
+	b.log.Debug("launching watchdog")
+
+	go b.runWatchdog()
+
+	b.log.Debug("launching lock sync")
+
+	go b.runLockSync()
+
+	if b.params.ArchiveAfter > 0 {
+		b.log.Debug("launching archiver")
+
+		go b.runArchiver()
+	}
+
+	return nil
+}
+
 func (b *Bench) Purge(req *types.PurgeRequest) error {
 	if req == nil {
 		return errors.New("purge request cannot be nil")
@@ -106,6 +169,12 @@ func (b *Bench) Purge(req *types.PurgeRequest) error {
 			errorCount++
 		}
 
+		// Delete timeline
+		if err := b.nats.DeleteTimeline(cfg.ID); err != nil {
+			b.log.Warningf("unable to delete timeline for job '%s': %s", cfg.ID, err)
+			errorCount++
+		}
+
 		// Delete streams
 		if err := b.nats.DeleteStreams(cfg.ID); err != nil {
 			b.log.Warningf("unable to delete streams for job '%s': %s", cfg.ID, err)
@@ -113,47 +182,234 @@ func (b *Bench) Purge(req *types.PurgeRequest) error {
 		}
 	}
 
+	if err := b.nats.PurgeHistory(); err != nil {
+		b.log.Warningf("unable to purge history: %s", err)
+		errorCount++
+	}
+
+	if lock, err := b.nats.GetLock(); err != nil || lock != nil {
+		if err == nil {
+			err = b.nats.ReleaseLock(lock)
+		}
+
+		if err != nil {
+			b.log.Warningf("unable to release cluster lock: %s", err)
+			errorCount++
+		}
+	}
+
 	b.log.Debugf("purge completed %d jobs, %d errors", len(settings), errorCount)
 
 	return nil
 }
 
-func (b *Bench) Delete(jobID string, deleteStreams, deleteSettings, deleteResults bool) error {
+// Delete cancels a job on every node and waits for the nodes to confirm
+// before deleting any of the job's data. The response lists which nodes
+// confirmed; participants that are gone or do not reply within CancelTimeout
+// are unconfirmed, but the job's data is deleted either way.
+func (b *Bench) Delete(jobID string, deleteStreams, deleteSettings, deleteResults bool) (*types.DeleteResponse, error) {
 	// Create delete jobs
 	deleteJobs, err := b.GenerateDeleteJobs(jobID)
 	if err != nil {
-		return errors.Wrap(err, "unable to create delete jobs")
+		return nil, errors.Wrap(err, "unable to create delete jobs")
 	}
 
-	// Emit delete jobs
-	if err := b.nats.EmitJobs(types.DeleteJob, deleteJobs); err != nil {
-		return errors.Wrap(err, "unable to emit delete jobs")
-	}
+	resp := b.requestCancel(jobID, deleteJobs)
 
-	// TODO: Wait for 3s to see if any errors are reported
+	// The watchdog would release it too, but only once the job is final
+	if err := b.releaseLock(jobID); err != nil {
+		b.log.Warningf("unable to release cluster lock held by job '%s': %s", jobID, err)
+	}
 
 	// Delete settings
 	if deleteSettings {
 		if err := b.nats.DeleteSettings(jobID); err != nil {
-			return errors.Wrap(err, "unable to delete settings")
+			return nil, errors.Wrap(err, "unable to delete settings")
 		}
 	}
 
 	// Delete results
 	if deleteResults {
-		if err := b.nats.DeleteResults(jobID); err != nil {
-			return errors.Wrap(err, "unable to delete results")
+		archived, err := b.nats.GetArchivedRun(jobID)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to get archived run")
+		}
+
+		// The results bucket of an archived job is already gone
+		if err := b.nats.DeleteResults(jobID); err != nil && (archived == nil || errors.Cause(err) != nats.ErrStreamNotFound) {
+			return nil, errors.Wrap(err, "unable to delete results")
+		}
+
+		if err := b.nats.DeleteTimeline(jobID); err != nil {
+			return nil, errors.Wrap(err, "unable to delete timeline")
+		}
+
+		if archived != nil {
+			if err := b.nats.DeleteArchivedRun(jobID); err != nil {
+				return nil, errors.Wrap(err, "unable to delete archived run")
+			}
 		}
 	}
 
 	// Delete streams
 	if deleteStreams {
 		if err := b.nats.DeleteStreams(jobID); err != nil {
-			return errors.Wrap(err, "unable to delete streams")
+			return nil, errors.Wrap(err, "unable to delete streams")
 		}
 	}
 
-	return nil
+	return resp, nil
+}
+
+// requestCancel sends the delete jobs to their nodes in parallel and
+// collects the replies
+func (b *Bench) requestCancel(jobID string, jobs []*types.Job) *types.DeleteResponse {
+	resp := &types.DeleteResponse{
+		ID:          jobID,
+		Confirmed:   make([]string, 0),
+		Cancelled:   make([]string, 0),
+		Unconfirmed: make([]string, 0),
+	}
+
+	replies := make([]*types.CancelReply, len(jobs))
+
+	for i := range replies {
+		replies[i] = &types.CancelReply{}
+	}
+
+	errs := b.requestJobs(types.DeleteJob, jobs, CancelTimeout, func(i int) interface{} {
+		return replies[i]
+	})
+
+	requested := make(map[string]bool)
+
+	for i, j := range jobs {
+		requested[j.NodeID] = true
+
+		switch {
+		case errs[i] != nil:
+			resp.Unconfirmed = append(resp.Unconfirmed, j.NodeID)
+			resp.Errors = append(resp.Errors, errs[i].Error())
+		case replies[i].Error != "":
+			resp.Unconfirmed = append(resp.Unconfirmed, j.NodeID)
+			resp.Errors = append(resp.Errors, fmt.Sprintf("node '%s': %s", j.NodeID, replies[i].Error))
+		default:
+			resp.Confirmed = append(resp.Confirmed, j.NodeID)
+
+			if replies[i].Running {
+				resp.Cancelled = append(resp.Cancelled, j.NodeID)
+			}
+		}
+	}
+
+	// Participants that stopped heartbeating cannot confirm (unless their
+	// share was reassigned to another node)
+	if settings, err := b.nats.GetSettings(jobID); err == nil && settings != nil {
+		for _, r := range settings.Reassignments {
+			requested[r.From] = true
+		}
+
+		for _, nodeID := range settings.Participants {
+			if !requested[nodeID] {
+				resp.Unconfirmed = append(resp.Unconfirmed, nodeID)
+				resp.Errors = append(resp.Errors, fmt.Sprintf("node '%s' is not heartbeating", nodeID))
+			}
+		}
+	}
+
+	sort.Strings(resp.Confirmed)
+	sort.Strings(resp.Cancelled)
+	sort.Strings(resp.Unconfirmed)
+
+	resp.Message = fmt.Sprintf("benchmark deleted; %d of %d node(s) confirmed", len(resp.Confirmed),
+		len(resp.Confirmed)+len(resp.Unconfirmed))
+
+	return resp
+}
+
+// requestJobs sends jobs to their nodes in parallel and unmarshals the reply
+// to the i-th job into reply(i); the returned errors are set for the jobs
+// that did not get a valid reply
+func (b *Bench) requestJobs(jobType types.JobType, jobs []*types.Job, timeout time.Duration,
+	reply func(i int) interface{}) []error {
+
+	errs := make([]error, len(jobs))
+	wg := &sync.WaitGroup{}
+
+	for i, j := range jobs {
+		wg.Add(1)
+
+		go func(i int, j *types.Job) {
+			defer wg.Done()
+
+			data, err := b.nats.RequestJob(jobType, j, timeout)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			if err := json.Unmarshal(data, reply(i)); err != nil {
+				errs[i] = errors.Wrapf(err, "unable to unmarshal reply from node '%s'", j.NodeID)
+			}
+		}(i, j)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// requestCreate sends create jobs to their nodes in parallel and collects
+// which nodes accepted them
+func (b *Bench) requestCreate(jobID string, jobs []*types.Job) *types.CreateResponse {
+	resp := &types.CreateResponse{
+		ID:       jobID,
+		Accepted: make([]string, 0),
+		Rejected: make(map[string]string),
+	}
+
+	replies := make([]*types.CreateReply, len(jobs))
+
+	for i := range replies {
+		replies[i] = &types.CreateReply{}
+	}
+
+	errs := b.requestJobs(types.CreateJob, jobs, CreateTimeout, func(i int) interface{} {
+		return replies[i]
+	})
+
+	for i, j := range jobs {
+		switch {
+		case errs[i] != nil:
+			resp.Rejected[j.NodeID] = errs[i].Error()
+		case !replies[i].Accepted:
+			resp.Rejected[j.NodeID] = replies[i].Error
+		default:
+			resp.Accepted = append(resp.Accepted, j.NodeID)
+		}
+	}
+
+	return resp
+}
+
+// rejectionError describes the rejections of a create response
+func rejectionError(resp *types.CreateResponse) error {
+	nodes := make([]string, 0, len(resp.Rejected))
+
+	for nodeID := range resp.Rejected {
+		nodes = append(nodes, nodeID)
+	}
+
+	sort.Strings(nodes)
+
+	reasons := make([]string, 0, len(nodes))
+
+	for _, nodeID := range nodes {
+		reasons = append(reasons, fmt.Sprintf("node '%s': %s", nodeID, resp.Rejected[nodeID]))
+	}
+
+	return errors.Errorf("%d of %d node(s) rejected the job: %s", len(nodes), len(nodes)+len(resp.Accepted),
+		strings.Join(reasons, "; "))
 }
 
 func (b *Bench) runReporter(doneCh chan struct{}, job *types.Job, workerMap map[string]map[int]*Worker) {
@@ -162,6 +418,8 @@ func (b *Bench) runReporter(doneCh chan struct{}, job *types.Job, workerMap map[
 		"job": job.Settings.ID,
 	})
 
+	timeline := b.newTimelineRecorder(job)
+
 MAIN:
 	for {
 		select {
@@ -174,50 +432,87 @@ MAIN:
 		case <-ticker.C:
 			aggregateStats := b.calculateStats(job.Settings, job.NodeID, workerMap, types.InProgressStatus, "; ticker")
 
-			if err := b.nats.WriteStatus(aggregateStats); err != nil {
+			if err := b.writeJobStatus(job, aggregateStats); err != nil {
 				b.log.Error("> unable to write status", err)
 				b.log.Debugf("AGGREGATE: %+v", aggregateStats)
 
 			}
+
+			timeline.record()
 		}
 	}
 
+	// Record the remainder of the last interval
+	timeline.record()
+
 	llog.Debug("reporter exiting")
 }
 
+// Status returns the aggregate status of a job; the status of an archived job
+// is read from the history stream
 func (b *Bench) Status(id string) (*types.Status, error) {
-	fullBucketName := fmt.Sprintf("%s-%s", natssvc.ResultBucketPrefix, id)
-
-	bucket, err := b.nats.GetBucket(fullBucketName)
+	statuses, err := b.nats.GetStatuses(id)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get bucket")
+		if run, archErr := b.nats.GetArchivedRun(id); archErr == nil && run != nil {
+			return run.Status, nil
+		}
+
+		return nil, err
 	}
 
-	keys, err := bucket.Keys()
+	finalStatus := aggregateStatuses(nil, statuses)
+	finalStatus.JobID = id
+
+	if !isFinal(finalStatus.Status) {
+		return finalStatus, nil
+	}
+
+	settings, err := b.nats.GetSettings(id)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to get keys")
+		// Settings may have been deleted; results are still useful
+		b.log.Debugf("unable to get settings for job '%s', skipping verdict: %s", id, err)
+		return finalStatus, nil
 	}
 
-	finalStatus := &types.Status{}
+	// Participants that have not reported yet keep the job in progress and
+	// the settings of a read job in verify mode tell which messages to expect
+	finalStatus = aggregateStatuses(settings, withPlaceholders(settings, statuses))
+	finalStatus.JobID = id
 
-	var totalPerNodeAverages = float64(0)
-	var totalNumberOfNodesReporting = 0
-	nodeReports := make([]*types.NodeReport, len(keys))
+	if !isFinal(finalStatus.Status) {
+		return finalStatus, nil
+	}
 
-	for i, key := range keys {
-		b.log.Debugf("looking up results in bucket '%s', object '%s'", fullBucketName, key)
+	verifyPlan(settings, finalStatus)
+	applyVerdict(settings, finalStatus)
 
-		entry, err := bucket.Get(key)
-		if err != nil {
-			return nil, errors.Wrap(err, "unable to get k/v entry")
-		}
+	return finalStatus, nil
+}
 
-		s := &types.Status{}
+// aggregateStatuses combines per-node statuses into a single job status.
+// The job is in an error state if any node errored, in progress if any node
+// is still running, cancelled if any node was cancelled and completed
+// otherwise. Settings are optional; without them, a read job in verify mode
+// can not tell the messages it did not receive at all.
+func aggregateStatuses(settings *types.Settings, statuses []*types.Status) *types.Status {
+	finalStatus := &types.Status{
+		Status:  types.InProgressStatus,
+		Message: "waiting for nodes to report",
+	}
 
-		if err := json.Unmarshal(entry.Value(), s); err != nil {
-			return nil, errors.Wrap(err, "unable to unmarshal status")
-		}
+	if len(statuses) == 0 {
+		return finalStatus
+	}
 
+	var totalPerNodeAverages = float64(0)
+	var totalNumberOfNodesReporting = 0
+	var numInProgress, numErrors, numCancelled, numVerifying int
+	var lost, timedOut []string
+
+	nodeReports := make([]*types.NodeReport, 0, len(statuses))
+	latency := newLatencyHistogram()
+
+	for _, s := range statuses {
 		finalStatus.JobID = s.JobID
 		finalStatus.Message = s.Message
 		finalStatus.TotalProcessed += s.TotalProcessed
@@ -225,18 +520,31 @@ func (b *Bench) Status(id string) (*types.Status, error) {
 		totalPerNodeAverages += s.AvgMsgPerSecPerNode
 		totalNumberOfNodesReporting++
 
-		finalStatus.Status = s.Status
+		switch s.Status {
+		case types.InProgressStatus:
+			numInProgress++
+		case types.ErrorStatus:
+			numErrors++
+		case types.CancelledStatus:
+			numCancelled++
+		case types.LostStatus:
+			lost = append(lost, s.NodeID)
+		case types.TimedOutStatus:
+			timedOut = append(timedOut, s.NodeID)
+		}
 
 		if len(s.Errors) != 0 {
 			finalStatus.Errors = append(finalStatus.Errors, s.Errors...)
 		}
 
+		mergeLatency(latency, s.LatencyHistogram)
+
 		if finalStatus.StartedAt.IsZero() {
 			finalStatus.StartedAt = s.StartedAt
 		}
 
 		// Want to have the earliest start time
-		if s.StartedAt.Before(finalStatus.StartedAt) {
+		if !s.StartedAt.IsZero() && s.StartedAt.Before(finalStatus.StartedAt) {
 			finalStatus.StartedAt = s.StartedAt
 		}
 
@@ -245,13 +553,45 @@ func (b *Bench) Status(id string) (*types.Status, error) {
 			finalStatus.EndedAt = s.EndedAt
 		}
 
-		nodeReports[i] = &types.NodeReport{
-			Streams: s.NodeReport.Streams,
+		if len(s.Verify) > 0 {
+			numVerifying++
+		}
+
+		if s.Plan != nil {
+			if finalStatus.Plan == nil {
+				finalStatus.Plan = &types.PlanReport{}
+			}
+
+			finalStatus.Plan.Planned += s.Plan.Planned
+			finalStatus.Plan.Processed += s.Plan.Processed
+		}
+
+		if s.NodeReport != nil {
+			nodeReports = append(nodeReports, &types.NodeReport{
+				NodeID:  s.NodeID,
+				Streams: s.NodeReport.Streams,
+			})
 		}
 	}
 
+	switch {
+	case numErrors > 0:
+		finalStatus.Status = types.ErrorStatus
+	case numInProgress > 0:
+		finalStatus.Status = types.InProgressStatus
+	case len(lost) > 0 || len(timedOut) > 0:
+		finalStatus.Status = types.FailedStatus
+		finalStatus.Message = failureReason(lost, timedOut)
+	case numCancelled > 0:
+		finalStatus.Status = types.CancelledStatus
+	default:
+		finalStatus.Status = types.CompletedStatus
+	}
+
 	// Make stats more readable -- lower decimal point, deal with unfinished job
-	if finalStatus.EndedAt.IsZero() {
+	if finalStatus.StartedAt.IsZero() {
+		finalStatus.ElapsedSeconds = 0
+	} else if finalStatus.EndedAt.IsZero() {
 		finalStatus.ElapsedSeconds = round(time.Now().UTC().Sub(finalStatus.StartedAt).Seconds(), 2)
 	} else {
 		finalStatus.ElapsedSeconds = round(finalStatus.EndedAt.Sub(finalStatus.StartedAt).Seconds(), 2)
@@ -260,9 +600,51 @@ func (b *Bench) Status(id string) (*types.Status, error) {
 	finalStatus.TotalMsgPerSecAllNodes = round(totalPerNodeAverages, 2)
 	finalStatus.AvgMsgPerSecPerNode = round(totalPerNodeAverages/float64(totalNumberOfNodesReporting), 2)
 
+	if finalStatus.Plan != nil {
+		finalStatus.Plan.Complete = isFinal(finalStatus.Status) && finalStatus.Plan.Processed == finalStatus.Plan.Planned
+	}
+
+	var expected map[string]map[string]map[uint64]uint64
+
+	if settings != nil {
+		expected = expectedSequences(settings.Read)
+	}
+
+	if numVerifying > 0 || expected != nil {
+		finalStatus.Verify = aggregateVerifyReports(statuses, expected, isFinal(finalStatus.Status))
+
+		if finalStatus.Status == types.CompletedStatus {
+			finalStatus.Errors = append(finalStatus.Errors, verifyErrors(finalStatus.Verify)...)
+		}
+	}
+
 	finalStatus.NodeReports = nodeReports
+	finalStatus.Latency = summarizeLatency(latency)
+	finalStatus.LatencyHistogram = latency
 
-	return finalStatus, nil
+	return finalStatus
+}
+
+// failureReason describes why a job with lost or timed out nodes failed
+func failureReason(lost, timedOut []string) string {
+	reasons := make([]string, 0, 2)
+
+	if len(lost) > 0 {
+		sort.Strings(lost)
+		reasons = append(reasons, fmt.Sprintf("node(s) %s lost", strings.Join(lost, ", ")))
+	}
+
+	if len(timedOut) > 0 {
+		sort.Strings(timedOut)
+		reasons = append(reasons, fmt.Sprintf("node(s) %s exceeded max_duration", strings.Join(timedOut, ", ")))
+	}
+
+	return "job failed: " + strings.Join(reasons, " and ")
+}
+
+// isFinal returns true if a job with this status will not change anymore
+func isFinal(status types.JobStatus) bool {
+	return status != types.InProgressStatus
 }
 
 func (b *Bench) createProducer(settings *types.Settings) (string, error) {
@@ -348,6 +730,27 @@ func (b *Bench) createReadJobs(settings *types.Settings) ([]*types.Job, error) {
 		return nil, errors.New("existing write ID required for read bench")
 	}
 
+	// Payloads can only be verified if the write job stamped them; settings
+	// of the write job may have been deleted, in which case we can't tell.
+	// Standalone nodes do not keep settings at all. The way the write job
+	// split its messages tells which messages to expect.
+	settings.Read.Written = nil
+
+	if settings.Read.Verify && !b.params.Standalone {
+		if ws, err := b.nats.GetSettings(settings.Read.WriteID); err == nil && ws != nil && ws.Write != nil {
+			if !ws.Write.Verify {
+				return nil, errors.Errorf("write job '%s' was not run with verify enabled", settings.Read.WriteID)
+			}
+
+			settings.Read.Written = &types.Written{
+				NumNodes:             len(ws.Participants) - len(ws.Reassignments),
+				NumMessagesPerStream: ws.Write.NumMessagesPerStream,
+				NumWorkersPerStream:  ws.Write.NumWorkersPerStream,
+				Subjects:             ws.Write.Subjects,
+			}
+		}
+	}
+
 	// Do the streams exist?
 	streams := b.nats.GetStreams("njst-" + settings.Read.WriteID + "-")
 
@@ -374,7 +777,7 @@ func (b *Bench) createReadJobs(settings *types.Settings) ([]*types.Job, error) {
 
 		// Is the stream configured for the requested subjects?
 		for _, subj := range settings.Read.Subjects {
-			if sliceContains(info.Config.Subjects, subj) {
+			if !sliceContains(info.Config.Subjects, stream+"."+subj) {
 				return nil, fmt.Errorf("stream '%s' is not configured for subject '%s'", stream, subj)
 			}
 		}
@@ -391,6 +794,12 @@ func (b *Bench) createReadJobs(settings *types.Settings) ([]*types.Job, error) {
 		numSelectedNodes = settings.Read.NumNodes
 	}
 
+	plans, err := newPlans(len(streams), settings.Read.NumMessagesPerStream, len(settings.Read.Subjects),
+		numSelectedNodes, settings.Read.NumWorkersPerStream)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to plan read jobs")
+	}
+
 	b.deleteDurableConsumers(streams)
 
 	streamInfo, err := b.createDurableConsumers(settings, streams)
@@ -398,24 +807,15 @@ func (b *Bench) createReadJobs(settings *types.Settings) ([]*types.Job, error) {
 		return nil, errors.Wrap(err, "unable to create consumer")
 	}
 
+	// Kept in the job's settings so that a lost node's share can be
+	// reassigned without creating the consumers again
+	settings.Read.Streams = streamInfo
+
 	for i := 0; i < numSelectedNodes; i++ {
 
 		jobs = append(jobs, &types.Job{
-			NodeID: nodes[i],
-			Settings: &types.Settings{
-				ID:          settings.ID,
-				NATS:        settings.NATS,
-				Description: settings.Description,
-				Read: &types.ReadSettings{
-					NumStreams:           settings.Read.NumStreams,
-					NumNodes:             numSelectedNodes,
-					NumMessagesPerStream: settings.Read.NumMessagesPerStream,
-					NumWorkersPerStream:  settings.Read.NumWorkersPerStream,
-					Streams:              streamInfo,
-					BatchSize:            settings.Read.BatchSize,
-					Subjects:             settings.Read.Subjects,
-				},
-			},
+			NodeID:    nodes[i],
+			Settings:  nodeJobSettings(settings, numSelectedNodes, plans[i]),
 			CreatedBy: b.params.NodeID,
 			CreatedAt: time.Now().UTC(),
 		})
@@ -424,6 +824,52 @@ func (b *Bench) createReadJobs(settings *types.Settings) ([]*types.Job, error) {
 	return jobs, nil
 }
 
+// nodeJobSettings returns the settings of the job emitted to a single node;
+// Streams of the read or write settings must be set
+func nodeJobSettings(settings *types.Settings, numNodes int, plan *types.Plan) *types.Settings {
+	s := &types.Settings{
+		NATS:        settings.NATS,
+		ID:          settings.ID,
+		Description: settings.Description,
+		MaxDuration: settings.MaxDuration,
+		Exclusive:   settings.Exclusive,
+		OnConflict:  settings.OnConflict,
+		CreatedAt:   settings.CreatedAt,
+	}
+
+	if settings.Read != nil {
+		s.Read = &types.ReadSettings{
+			NumStreams:           settings.Read.NumStreams,
+			NumNodes:             numNodes,
+			NumMessagesPerStream: settings.Read.NumMessagesPerStream,
+			NumWorkersPerStream:  settings.Read.NumWorkersPerStream,
+			Streams:              settings.Read.Streams,
+			BatchSize:            settings.Read.BatchSize,
+			Subjects:             settings.Read.Subjects,
+			Verify:               settings.Read.Verify,
+			Plan:                 plan,
+		}
+
+		return s
+	}
+
+	s.Write = &types.WriteSettings{
+		NumStreams:           settings.Write.NumStreams,
+		NumNodes:             numNodes,
+		NumMessagesPerStream: settings.Write.NumMessagesPerStream,
+		NumWorkersPerStream:  settings.Write.NumWorkersPerStream,
+		MsgSizeBytes:         settings.Write.MsgSizeBytes,
+		BatchSize:            settings.Write.BatchSize,
+		KeepStreams:          settings.Write.KeepStreams,
+		Verify:               settings.Write.Verify,
+		Subjects:             settings.Write.Subjects,
+		Streams:              settings.Write.Streams,
+		Plan:                 plan,
+	}
+
+	return s
+}
+
 func sliceContains(slice []string, value string) bool {
 	for _, v := range slice {
 		if v == value {
@@ -448,6 +894,21 @@ func (b *Bench) createWriteJobs(settings *types.Settings) ([]*types.Job, error)
 		return nil, errors.Errorf("unable to create write jobs: %d nodes requested but %d available", settings.Write.NumNodes, len(nodes))
 	}
 
+	// How many nodes will this test run on?
+	var numSelectedNodes int
+
+	if settings.Write.NumNodes == 0 {
+		numSelectedNodes = len(nodes)
+	} else {
+		numSelectedNodes = settings.Write.NumNodes
+	}
+
+	plans, err := newPlans(settings.Write.NumStreams, settings.Write.NumMessagesPerStream, len(settings.Write.Subjects),
+		numSelectedNodes, settings.Write.NumWorkersPerStream)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to plan write jobs")
+	}
+
 	streamPrefix := fmt.Sprintf("njst-%s", settings.ID)
 
 	storageType := nats.MemoryStorage
@@ -478,35 +939,13 @@ func (b *Bench) createWriteJobs(settings *types.Settings) ([]*types.Job, error)
 
 	jobs := make([]*types.Job, 0)
 
-	// How many nodes will this test run on?
-	var numSelectedNodes int
-
-	if settings.Write.NumNodes == 0 {
-		numSelectedNodes = len(nodes)
-	} else {
-		numSelectedNodes = settings.Write.NumNodes
-	}
-
 	settings.Write.NumNodes = numSelectedNodes
+	settings.Write.Streams = generateStreams(settings.Write.NumStreams, streamPrefix)
 
 	for i := 0; i < numSelectedNodes; i++ {
 		jobs = append(jobs, &types.Job{
-			NodeID: nodes[i],
-			Settings: &types.Settings{
-				NATS:        settings.NATS,
-				ID:          settings.ID,
-				Description: settings.Description,
-				Write: &types.WriteSettings{
-					NumStreams:           settings.Write.NumStreams,
-					NumNodes:             settings.Write.NumNodes,
-					NumMessagesPerStream: settings.Write.NumMessagesPerStream,
-					NumWorkersPerStream:  settings.Write.NumWorkersPerStream,
-					MsgSizeBytes:         settings.Write.MsgSizeBytes,
-					KeepStreams:          settings.Write.KeepStreams,
-					Subjects:             settings.Write.Subjects,
-					Streams:              generateStreams(settings.Write.NumStreams, streamPrefix),
-				},
-			},
+			NodeID:    nodes[i],
+			Settings:  nodeJobSettings(settings, numSelectedNodes, plans[i]),
 			CreatedBy: b.params.NodeID,
 			CreatedAt: time.Now().UTC(),
 		})
@@ -540,8 +979,9 @@ func (b *Bench) GenerateCreateJobs(settings *types.Settings) ([]*types.Job, erro
 		b.log.Debugf("%d write jobs created", len(jobs))
 
 		for i, j := range jobs {
-			b.log.Debugf("job #%d, nodes: %d, streams: %d, messages/stream: %d, workers/stream: %d",
-				i, j.Settings.Write.NumNodes, j.Settings.Write.NumStreams, j.Settings.Write.NumMessagesPerStream, j.Settings.Write.NumWorkersPerStream)
+			b.log.Debugf("job #%d, nodes: %d, streams: %d, messages/stream: %d, workers/stream: %d, planned messages: %d",
+				i, j.Settings.Write.NumNodes, j.Settings.Write.NumStreams, j.Settings.Write.NumMessagesPerStream, j.Settings.Write.NumWorkersPerStream,
+				j.Settings.Write.Plan.NumMessages)
 		}
 	} else {
 		return nil, errors.New("settings must have either read or write set")
@@ -554,6 +994,131 @@ func (b *Bench) GenerateCreateJobs(settings *types.Settings) ([]*types.Job, erro
 	return jobs, nil
 }
 
+// Create generates create jobs for already validated settings, emits them to
+// the participating nodes and saves the settings. If settings.ID is not set,
+// a new ID is generated. The job is only created if every node accepts its
+// share; otherwise the nodes that accepted are told to cancel, the job's data
+// is deleted and the returned response lists the nodes that rejected the job
+// (along with an error). Jobs that conflict with the cluster lock are not
+// sent to any node; the returned response holds the conflict.
+func (b *Bench) Create(settings *types.Settings) (*types.CreateResponse, error) {
+	if settings == nil {
+		return nil, errors.New("settings cannot be nil")
+	}
+
+	if settings.ID == "" {
+		settings.ID = RandString(8)
+	}
+
+	settings.CreatedAt = time.Now().UTC()
+
+	conflict, err := b.lockForCreate(settings, settings.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if conflict != nil {
+		err := conflictError(conflict)
+
+		return &types.CreateResponse{ID: settings.ID, Error: err.Error(), Conflict: conflict}, err
+	}
+
+	resp, err := b.create(settings)
+	if err != nil && settings.Exclusive {
+		b.releaseLockAfterCreate(settings.ID)
+	}
+
+	return resp, err
+}
+
+// create dispatches a job that passed the cluster lock check (see Create)
+func (b *Bench) create(settings *types.Settings) (*types.CreateResponse, error) {
+	jobs, err := b.GenerateCreateJobs(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.Participants = make([]string, 0, len(jobs))
+
+	for _, j := range jobs {
+		settings.Participants = append(settings.Participants, j.NodeID)
+	}
+
+	// Create result bucket
+	if err := b.nats.CreateResults(settings.ID); err != nil {
+		return nil, errors.Wrap(err, "unable to get or create result bucket")
+	}
+
+	resp := b.requestCreate(settings.ID, jobs)
+
+	if len(resp.Rejected) > 0 {
+		err := rejectionError(resp)
+		resp.Error = err.Error()
+
+		b.abortCreate(settings, resp)
+
+		return resp, err
+	}
+
+	// Without settings, the watchdog and status lookups cannot see the job
+	if err := b.nats.SaveSettings(settings); err != nil {
+		b.abortCreate(settings, resp)
+
+		return nil, errors.Wrap(err, "unable to save settings")
+	}
+
+	resp.Message = fmt.Sprintf("benchmark created successfully; %d node(s) accepted the job", len(resp.Accepted))
+
+	b.log.Infof("Created benchmark '%s' on %d node(s)", settings.ID, len(jobs))
+
+	return resp, nil
+}
+
+// abortCreate cancels a job that was not accepted by every node (or whose
+// settings could not be saved) on the nodes that did accept it and deletes
+// whatever was created for it. Nodes that did
+// not accept it are sent a delete job as well, without waiting for a reply: a
+// node that timed out may still receive the create job once it recovers.
+func (b *Bench) abortCreate(settings *types.Settings, resp *types.CreateResponse) {
+	accepted := make([]*types.Job, 0, len(resp.Accepted))
+	rejected := make([]*types.Job, 0, len(resp.Rejected))
+
+	for _, nodeID := range resp.Accepted {
+		accepted = append(accepted, b.deleteJob(settings.ID, nodeID))
+	}
+
+	for nodeID := range resp.Rejected {
+		rejected = append(rejected, b.deleteJob(settings.ID, nodeID))
+	}
+
+	if len(rejected) > 0 {
+		if err := b.nats.EmitJobs(types.DeleteJob, rejected); err != nil {
+			b.log.Warningf("unable to emit delete jobs for rejected job '%s': %s", settings.ID, err)
+		}
+	}
+
+	if len(accepted) > 0 {
+		if resp := b.requestCancel(settings.ID, accepted); len(resp.Unconfirmed) > 0 {
+			b.log.Warningf("unable to cancel rejected job '%s' on node(s) %s", settings.ID,
+				strings.Join(resp.Unconfirmed, ", "))
+		}
+	}
+
+	if err := b.nats.DeleteResults(settings.ID); err != nil {
+		b.log.Warningf("unable to delete results for rejected job '%s': %s", settings.ID, err)
+	}
+
+	if err := b.nats.DeleteTimeline(settings.ID); err != nil {
+		b.log.Warningf("unable to delete timeline for rejected job '%s': %s", settings.ID, err)
+	}
+
+	if settings.Write != nil {
+		if err := b.nats.DeleteStreams(settings.ID); err != nil {
+			b.log.Warningf("unable to delete streams for rejected job '%s': %s", settings.ID, err)
+		}
+	}
+}
+
 func (b *Bench) GenerateDeleteAllJobs() ([]*types.Job, error) {
 	nodes, err := b.nats.GetNodeList()
 	if err != nil {
@@ -593,19 +1158,24 @@ func (b *Bench) GenerateDeleteJobs(id string) ([]*types.Job, error) {
 	jobs := make([]*types.Job, 0)
 
 	for _, node := range nodes {
-		jobs = append(jobs, &types.Job{
-			NodeID: node,
-			Settings: &types.Settings{
-				ID: id,
-			},
-			CreatedBy: b.params.NodeID,
-			CreatedAt: time.Now().UTC(),
-		})
+		jobs = append(jobs, b.deleteJob(id, node))
 	}
 
 	return jobs, nil
 }
 
+// deleteJob returns the job that deletes job id on a node
+func (b *Bench) deleteJob(id, nodeID string) *types.Job {
+	return &types.Job{
+		NodeID: nodeID,
+		Settings: &types.Settings{
+			ID: id,
+		},
+		CreatedBy: b.params.NodeID,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
 func validateParams(p *cli.Params) error {
 	if p == nil {
 		return errors.New("params cannot be nil")
@@ -619,6 +1189,22 @@ func validateParams(p *cli.Params) error {
 		return errors.New("nats address cannot be empty")
 	}
 
+	if p.MaxConcurrentJobs < 0 {
+		return errors.New("max concurrent jobs cannot be negative")
+	}
+
+	if p.MaxQueuedJobs < 0 {
+		return errors.New("max queued jobs cannot be negative")
+	}
+
+	if p.ArchiveAfter < 0 {
+		return errors.New("archive after cannot be negative")
+	}
+
+	if p.ArchiveAfter == 0 && (p.RetentionMaxRuns > 0 || p.RetentionMaxAge > 0) {
+		return errors.New("retention limits only apply to archived jobs; archive after must be set")
+	}
+
 	return nil
 }
 
@@ -656,6 +1242,16 @@ func GenRandomBytes(size int) ([]byte, error) {
 	return data, nil
 }
 
+func RandString(n int) string {
+	b := make([]rune, n)
+
+	for i := range b {
+		b[i] = runes[mrand.Intn(len(runes))]
+	}
+
+	return string(b)
+}
+
 func round(f float64, places int) float64 {
 	pow := math.Pow(10., float64(places))
 	rounded := float64(int(f*pow)) / pow
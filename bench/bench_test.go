@@ -0,0 +1,984 @@
+package bench
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/batchcorp/njst/cli"
+	"github.com/batchcorp/njst/natssvc/natssvcfakes"
+	"github.com/batchcorp/njst/types"
+)
+
+func newTestBench(t *testing.T) (*Bench, *natssvcfakes.FakeINATSService) {
+	t.Helper()
+
+	fake := &natssvcfakes.FakeINATSService{}
+
+	b, err := New(&cli.Params{
+		NodeID:      "node1",
+		NATSAddress: []string{"localhost:4222"},
+	}, fake)
+	if err != nil {
+		t.Fatalf("unable to create bench: %s", err)
+	}
+
+	return b, fake
+}
+
+// streamInfoFor returns stream info for streams created by createWriteJobs
+// with the given subjects and number of messages
+func streamInfoFor(msgs uint64, subjects ...string) func(string) (*nats.StreamInfo, error) {
+	return func(stream string) (*nats.StreamInfo, error) {
+		streamSubjects := make([]string, 0, len(subjects))
+
+		for _, subj := range subjects {
+			streamSubjects = append(streamSubjects, stream+"."+subj)
+		}
+
+		return &nats.StreamInfo{
+			Config: nats.StreamConfig{Name: stream, Subjects: streamSubjects},
+			State:  nats.StreamState{Msgs: msgs},
+		}, nil
+	}
+}
+
+func TestNew(t *testing.T) {
+	fake := &natssvcfakes.FakeINATSService{}
+
+	tests := []struct {
+		name   string
+		params *cli.Params
+		nsvc   *natssvcfakes.FakeINATSService
+		err    string
+	}{
+		{"nil params", nil, fake, "params cannot be nil"},
+		{"no node id", &cli.Params{NATSAddress: []string{"localhost"}}, fake, "node id cannot be empty"},
+		{"no nats address", &cli.Params{NodeID: "node1"}, fake, "nats address cannot be empty"},
+		{"nil nats service", &cli.Params{NodeID: "node1", NATSAddress: []string{"localhost"}}, nil, "nats service cannot be nil"},
+		{"negative archive after", &cli.Params{NodeID: "node1", NATSAddress: []string{"localhost"}, ArchiveAfter: -time.Hour},
+			fake, "archive after cannot be negative"},
+		{"retention without archival", &cli.Params{NodeID: "node1", NATSAddress: []string{"localhost"}, RetentionMaxRuns: 10},
+			fake, "archive after must be set"},
+		{"valid", &cli.Params{NodeID: "node1", NATSAddress: []string{"localhost"}}, fake, ""},
+		{"valid with retention", &cli.Params{NodeID: "node1", NATSAddress: []string{"localhost"}, ArchiveAfter: time.Hour,
+			RetentionMaxAge: 30 * 24 * time.Hour}, fake, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+
+			// A typed nil pointer would not compare equal to a nil interface
+			if tt.nsvc == nil {
+				_, err = New(tt.params, nil)
+			} else {
+				_, err = New(tt.params, tt.nsvc)
+			}
+
+			checkErr(t, err, tt.err)
+		})
+	}
+}
+
+func TestCreateWriteJobs(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2", "node3"}, nil)
+
+	settings := &types.Settings{
+		ID:          "abc",
+		NATS:        &types.NATS{Address: "localhost:4222"},
+		MaxDuration: "10m",
+		Exclusive:   true,
+		CreatedAt:   time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+		Write: &types.WriteSettings{
+			NumStreams:           3,
+			NumNodes:             2,
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  4,
+			Subjects:             []string{"foo", "bar"},
+			NumReplicas:          3,
+			BatchSize:            50,
+			MsgSizeBytes:         512,
+			KeepStreams:          true,
+			Storage:              types.FileStorageType,
+		},
+	}
+
+	jobs, err := b.createWriteJobs(settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.AddStreamCallCount() != 3 {
+		t.Fatalf("expected 3 streams to be created, got %d", fake.AddStreamCallCount())
+	}
+
+	for i := 0; i < 3; i++ {
+		cfg := fake.AddStreamArgsForCall(i)
+		name := fmt.Sprintf("njst-abc-%d", i)
+
+		if cfg.Name != name {
+			t.Errorf("stream #%d: expected name '%s', got '%s'", i, name, cfg.Name)
+		}
+
+		if subjects := []string{name + ".foo", name + ".bar"}; !reflect.DeepEqual(cfg.Subjects, subjects) {
+			t.Errorf("stream #%d: expected subjects %v, got %v", i, subjects, cfg.Subjects)
+		}
+
+		if cfg.Storage != nats.FileStorage {
+			t.Errorf("stream #%d: expected file storage, got %s", i, cfg.Storage)
+		}
+
+		if cfg.Replicas != 3 {
+			t.Errorf("stream #%d: expected 3 replicas, got %d", i, cfg.Replicas)
+		}
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+
+	streams := []string{"njst-abc-0", "njst-abc-1", "njst-abc-2"}
+
+	// 500 messages per subject split between 8 workers; the remainder of
+	// the second subject goes to the workers after those that got the
+	// remainder of the first one
+	plans := []*types.Plan{
+		{
+			NodeIndex:            0,
+			Workers:              [][]int{{63, 62}, {63, 62}, {63, 62}, {63, 62}},
+			NumMessagesPerStream: 500,
+			NumMessages:          1500,
+		},
+		{
+			NodeIndex:            1,
+			Workers:              [][]int{{62, 63}, {62, 63}, {62, 63}, {62, 63}},
+			NumMessagesPerStream: 500,
+			NumMessages:          1500,
+		},
+	}
+
+	for i, job := range jobs {
+		if job.NodeID != fmt.Sprintf("node%d", i+1) {
+			t.Errorf("job #%d: unexpected node '%s'", i, job.NodeID)
+		}
+
+		if job.CreatedBy != "node1" {
+			t.Errorf("job #%d: unexpected created by '%s'", i, job.CreatedBy)
+		}
+
+		if job.Settings.ID != "abc" || job.Settings.NATS != settings.NATS {
+			t.Errorf("job #%d: id and nats settings should be copied", i)
+		}
+
+		if job.Settings.MaxDuration != "10m" || !job.Settings.Exclusive || !job.Settings.CreatedAt.Equal(settings.CreatedAt) {
+			t.Errorf("job #%d: max duration, exclusive and creation time should be copied", i)
+		}
+
+		ws := job.Settings.Write
+
+		expected := &types.WriteSettings{
+			NumStreams:           3,
+			NumNodes:             2,
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  4,
+			Subjects:             []string{"foo", "bar"},
+			BatchSize:            50,
+			MsgSizeBytes:         512,
+			KeepStreams:          true,
+			Streams:              streams,
+			Plan:                 plans[i],
+		}
+
+		if !reflect.DeepEqual(ws, expected) {
+			t.Errorf("job #%d: expected write settings %+v, got %+v", i, expected, ws)
+		}
+	}
+}
+
+func TestCreateWriteJobsNodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		numNodes int
+		nodes    []string
+		nodesErr error
+		numJobs  int
+		err      string
+	}{
+		{"all nodes by default", 0, []string{"node1", "node2", "node3"}, nil, 3, ""},
+		{"subset of nodes", 1, []string{"node1", "node2", "node3"}, nil, 1, ""},
+		{"exactly all nodes", 3, []string{"node1", "node2", "node3"}, nil, 3, ""},
+		{"too many nodes", 4, []string{"node1", "node2", "node3"}, nil, 0, "4 nodes requested but 3 available"},
+		{"node list error", 0, nil, errors.New("boom"), 0, "unable to get node list: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetNodeListReturns(tt.nodes, tt.nodesErr)
+
+			settings := &types.Settings{
+				ID: "abc",
+				Write: &types.WriteSettings{
+					NumStreams:           2,
+					NumNodes:             tt.numNodes,
+					NumMessagesPerStream: 100,
+					NumWorkersPerStream:  1,
+					Subjects:             []string{DefaultSubject},
+				},
+			}
+
+			jobs, err := b.createWriteJobs(settings)
+
+			if !checkErr(t, err, tt.err) {
+				if fake.AddStreamCallCount() != 0 {
+					t.Errorf("no streams should be created on error, got %d", fake.AddStreamCallCount())
+				}
+
+				return
+			}
+
+			if len(jobs) != tt.numJobs {
+				t.Fatalf("expected %d jobs, got %d", tt.numJobs, len(jobs))
+			}
+
+			// The number of selected nodes is recorded in the settings and in
+			// every job so that nodes can work out their share
+			if settings.Write.NumNodes != tt.numJobs {
+				t.Errorf("expected settings to record %d nodes, got %d", tt.numJobs, settings.Write.NumNodes)
+			}
+
+			for i, job := range jobs {
+				if job.NodeID != tt.nodes[i] {
+					t.Errorf("job #%d: expected node '%s', got '%s'", i, tt.nodes[i], job.NodeID)
+				}
+
+				if job.Settings.Write.NumNodes != tt.numJobs {
+					t.Errorf("job #%d: expected %d nodes, got %d", i, tt.numJobs, job.Settings.Write.NumNodes)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWriteJobsStreamError(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.AddStreamReturnsOnCall(1, nil, errors.New("insufficient resources"))
+
+	_, err := b.createWriteJobs(&types.Settings{
+		ID: "abc",
+		Write: &types.WriteSettings{
+			NumStreams:          3,
+			NumWorkersPerStream: 1,
+			Subjects:            []string{DefaultSubject},
+		},
+	})
+
+	checkErr(t, err, "unable to create stream 'njst-abc-1': insufficient resources")
+
+	if fake.AddStreamCallCount() != 2 {
+		t.Errorf("expected stream creation to stop after the error, got %d calls", fake.AddStreamCallCount())
+	}
+}
+
+func TestCreateReadJobs(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2"}, nil)
+	fake.GetStreamsReturns([]string{"njst-w1-0", "njst-w1-1", "njst-w1-2"})
+	fake.GetStreamInfoCalls(streamInfoFor(1000, "foo", "bar"))
+
+	settings := &types.Settings{
+		ID:   "abc",
+		NATS: &types.NATS{Address: "localhost:4222"},
+		Read: &types.ReadSettings{
+			WriteID:              "w1",
+			NumStreams:           2,
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  2,
+			Subjects:             []string{"foo", "bar"},
+			BatchSize:            10,
+		},
+	}
+
+	jobs, err := b.createReadJobs(settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.GetStreamsCallCount() != 1 {
+		t.Fatalf("expected streams to be looked up once, got %d", fake.GetStreamsCallCount())
+	}
+
+	if filter := fake.GetStreamsArgsForCall(0); !reflect.DeepEqual(filter, []string{"njst-w1-"}) {
+		t.Errorf("unexpected stream filter %v", filter)
+	}
+
+	// Only the requested number of streams is used
+	if fake.GetStreamInfoCallCount() != 2 {
+		t.Errorf("expected 2 stream info lookups, got %d", fake.GetStreamInfoCallCount())
+	}
+
+	if fake.DeleteDurableConsumersCallCount() != 2 {
+		t.Errorf("expected consumers of 2 streams to be deleted, got %d", fake.DeleteDurableConsumersCallCount())
+	}
+
+	expectedStreams := []*types.StreamInfo{
+		{StreamName: "njst-w1-0", DurableName: "njst-w1-0-foo-durable", SubjectName: "njst-w1-0.foo"},
+		{StreamName: "njst-w1-0", DurableName: "njst-w1-0-bar-durable", SubjectName: "njst-w1-0.bar"},
+		{StreamName: "njst-w1-1", DurableName: "njst-w1-1-foo-durable", SubjectName: "njst-w1-1.foo"},
+		{StreamName: "njst-w1-1", DurableName: "njst-w1-1-bar-durable", SubjectName: "njst-w1-1.bar"},
+	}
+
+	if fake.AddDurableConsumerCallCount() != len(expectedStreams) {
+		t.Fatalf("expected %d consumers, got %d", len(expectedStreams), fake.AddDurableConsumerCallCount())
+	}
+
+	for i, si := range expectedStreams {
+		stream, cfg, _ := fake.AddDurableConsumerArgsForCall(i)
+
+		if stream != si.StreamName || cfg.Durable != si.DurableName || cfg.FilterSubject != si.SubjectName {
+			t.Errorf("consumer #%d: expected %s/%s/%s, got %s/%s/%s", i, si.StreamName, si.DurableName,
+				si.SubjectName, stream, cfg.Durable, cfg.FilterSubject)
+		}
+
+		if cfg.AckPolicy != nats.AckExplicitPolicy || cfg.DeliverPolicy != nats.DeliverAllPolicy {
+			t.Errorf("consumer #%d: unexpected policies", i)
+		}
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+
+	for i, job := range jobs {
+		if job.NodeID != fmt.Sprintf("node%d", i+1) {
+			t.Errorf("job #%d: unexpected node '%s'", i, job.NodeID)
+		}
+
+		expected := &types.ReadSettings{
+			NumStreams:           2,
+			NumNodes:             2,
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  2,
+			Subjects:             []string{"foo", "bar"},
+			BatchSize:            10,
+			Streams:              expectedStreams,
+			Plan: &types.Plan{
+				NodeIndex:            i,
+				Workers:              [][]int{{125, 125}, {125, 125}},
+				NumMessagesPerStream: 500,
+				NumMessages:          1000,
+			},
+		}
+
+		if !reflect.DeepEqual(job.Settings.Read, expected) {
+			t.Errorf("job #%d: expected read settings %+v, got %+v", i, expected, job.Settings.Read)
+		}
+	}
+}
+
+func TestCreateReadJobsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		read       *types.ReadSettings
+		streams    []string
+		streamInfo func(string) (*nats.StreamInfo, error)
+		err        string
+	}{
+		{
+			name: "too many nodes",
+			read: &types.ReadSettings{WriteID: "w1", NumStreams: 1, NumNodes: 3, Subjects: []string{"foo"}},
+			err:  "3 nodes requested but 2 available",
+		},
+		{
+			name: "no write id",
+			read: &types.ReadSettings{NumStreams: 1, Subjects: []string{"foo"}},
+			err:  "existing write ID required for read bench",
+		},
+		{
+			name:    "not enough streams",
+			read:    &types.ReadSettings{WriteID: "w1", NumStreams: 2, Subjects: []string{"foo"}},
+			streams: []string{"njst-w1-0"},
+			err:     "2 streams requested but 1 available",
+		},
+		{
+			name:       "not enough messages",
+			read:       &types.ReadSettings{WriteID: "w1", NumStreams: 1, NumMessagesPerStream: 1001, Subjects: []string{"foo"}},
+			streams:    []string{"njst-w1-0"},
+			streamInfo: streamInfoFor(1000, "foo"),
+			err:        "stream 'njst-w1-0' does not contain enough messages to satisfy read request",
+		},
+		{
+			name:       "subject not configured",
+			read:       &types.ReadSettings{WriteID: "w1", NumStreams: 1, NumMessagesPerStream: 10, Subjects: []string{"foo", "baz"}},
+			streams:    []string{"njst-w1-0"},
+			streamInfo: streamInfoFor(1000, "foo", "bar"),
+			err:        "stream 'njst-w1-0' is not configured for subject 'baz'",
+		},
+		{
+			name:    "stream info error",
+			read:    &types.ReadSettings{WriteID: "w1", NumStreams: 1, Subjects: []string{"foo"}},
+			streams: []string{"njst-w1-0"},
+			streamInfo: func(string) (*nats.StreamInfo, error) {
+				return nil, errors.New("stream not found")
+			},
+			err: "unable to get stream info for 'njst-w1-0' stream: stream not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetNodeListReturns([]string{"node1", "node2"}, nil)
+			fake.GetStreamsReturns(tt.streams)
+
+			if tt.streamInfo != nil {
+				fake.GetStreamInfoCalls(tt.streamInfo)
+			}
+
+			_, err := b.createReadJobs(&types.Settings{ID: "abc", Read: tt.read})
+
+			checkErr(t, err, tt.err)
+
+			if fake.AddDurableConsumerCallCount() != 0 {
+				t.Errorf("no consumers should be created on error, got %d", fake.AddDurableConsumerCallCount())
+			}
+		})
+	}
+}
+
+func TestCreateReadJobsVerify(t *testing.T) {
+	read := func() *types.ReadSettings {
+		return &types.ReadSettings{
+			WriteID:              "w1",
+			NumStreams:           1,
+			NumMessagesPerStream: 10,
+			NumWorkersPerStream:  1,
+			BatchSize:            10,
+			Subjects:             []string{"foo"},
+			Verify:               true,
+		}
+	}
+
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.GetStreamsReturns([]string{"njst-w1-0"})
+	fake.GetStreamInfoCalls(streamInfoFor(1000, "foo"))
+	fake.GetSettingsReturns(&types.Settings{ID: "w1", Write: &types.WriteSettings{}}, nil)
+
+	_, err := b.createReadJobs(&types.Settings{ID: "abc", Read: read()})
+	checkErr(t, err, "write job 'w1' was not run with verify enabled")
+
+	// The way the write job split its messages is kept with the read job;
+	// node3 took over from node2
+	fake.GetSettingsReturns(&types.Settings{
+		ID:            "w1",
+		Participants:  []string{"node1", "node2", "node3"},
+		Reassignments: []*types.Reassignment{{From: "node2", To: "node3"}},
+		Write: &types.WriteSettings{
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  4,
+			Subjects:             []string{"foo", "bar"},
+			Verify:               true,
+		},
+	}, nil)
+
+	settings := &types.Settings{ID: "abc", Read: read()}
+
+	if _, err := b.createReadJobs(settings); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	written := &types.Written{NumNodes: 2, NumMessagesPerStream: 1000, NumWorkersPerStream: 4, Subjects: []string{"foo", "bar"}}
+
+	if !reflect.DeepEqual(settings.Read.Written, written) {
+		t.Errorf("expected %+v, got %+v", written, settings.Read.Written)
+	}
+
+	// Standalone nodes do not keep settings, so the write job cannot be
+	// looked up ("njst run" with verify)
+	b, fake = newTestBench(t)
+	b.params.Standalone = true
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.GetStreamsReturns([]string{"njst-w1-0"})
+	fake.GetStreamInfoCalls(streamInfoFor(1000, "foo"))
+
+	if _, err := b.createReadJobs(&types.Settings{ID: "abc", Read: read()}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.GetSettingsCallCount() != 0 {
+		t.Error("expected the write job's settings not to be looked up")
+	}
+}
+
+// Streams created by write jobs are configured for "<stream>.<subject>";
+// read subjects are checked against those
+func TestCreateReadJobsSubjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		err      string
+	}{
+		{"configured", []string{"foo"}, ""},
+		{"all configured", []string{"foo", "bar"}, ""},
+		{"missing", []string{"baz"}, "stream 'njst-w1-0' is not configured for subject 'baz'"},
+		{"one missing", []string{"foo", "baz"}, "stream 'njst-w1-0' is not configured for subject 'baz'"},
+		{"without stream prefix", []string{"njst-w1-0.foo"}, "is not configured for subject 'njst-w1-0.foo'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetNodeListReturns([]string{"node1"}, nil)
+			fake.GetStreamsReturns([]string{"njst-w1-0"})
+			fake.GetStreamInfoCalls(streamInfoFor(1000, "foo", "bar"))
+
+			read := &types.ReadSettings{
+				WriteID:              "w1",
+				NumStreams:           1,
+				NumMessagesPerStream: 10,
+				NumWorkersPerStream:  1,
+				BatchSize:            10,
+				Subjects:             tt.subjects,
+			}
+
+			_, err := b.createReadJobs(&types.Settings{ID: "abc", Read: read})
+
+			checkErr(t, err, tt.err)
+		})
+	}
+}
+
+func TestAggregateStatuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []types.JobStatus
+		expected types.JobStatus
+	}{
+		{"no statuses", nil, types.InProgressStatus},
+		{"all completed", []types.JobStatus{types.CompletedStatus, types.CompletedStatus}, types.CompletedStatus},
+		{"in progress", []types.JobStatus{types.CompletedStatus, types.InProgressStatus}, types.InProgressStatus},
+		{"cancelled", []types.JobStatus{types.CompletedStatus, types.CancelledStatus}, types.CancelledStatus},
+		{"in progress before cancelled", []types.JobStatus{types.CancelledStatus, types.InProgressStatus}, types.InProgressStatus},
+		{"error wins", []types.JobStatus{types.InProgressStatus, types.ErrorStatus, types.CancelledStatus}, types.ErrorStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := make([]*types.Status, 0)
+
+			for i, s := range tt.statuses {
+				statuses = append(statuses, &types.Status{NodeID: fmt.Sprintf("node%d", i), Status: s})
+			}
+
+			if s := aggregateStatuses(nil, statuses).Status; s != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	minMsgsPerSec := 2000.0
+
+	fake.GetStatusesReturns([]*types.Status{
+		{
+			JobID:               "abc",
+			NodeID:              "node1",
+			Status:              types.CompletedStatus,
+			Message:             "benchmark completed; final",
+			TotalProcessed:      1000,
+			TotalErrors:         2,
+			Errors:              []string{"error 1", "error 2"},
+			AvgMsgPerSecPerNode: 500,
+			StartedAt:           start.Add(time.Second),
+			EndedAt:             start.Add(3 * time.Second),
+			NodeReport:          &types.NodeReport{Streams: []types.StreamReport{{}}},
+		},
+		{
+			JobID:               "abc",
+			NodeID:              "node2",
+			Status:              types.CompletedStatus,
+			Message:             "benchmark completed; final",
+			TotalProcessed:      3000,
+			AvgMsgPerSecPerNode: 1000,
+			StartedAt:           start,
+			EndedAt:             start.Add(2 * time.Second),
+			NodeReport:          &types.NodeReport{Streams: []types.StreamReport{{}}},
+		},
+	}, nil)
+
+	fake.GetSettingsReturns(&types.Settings{
+		ID:     "abc",
+		Expect: &types.Expect{MinTotalMsgsPerSec: &minMsgsPerSec},
+	}, nil)
+
+	status, err := b.Status("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.GetStatusesArgsForCall(0) != "abc" {
+		t.Errorf("unexpected job id '%s'", fake.GetStatusesArgsForCall(0))
+	}
+
+	if status.JobID != "abc" || status.Status != types.CompletedStatus {
+		t.Errorf("unexpected job id or status: %s, %s", status.JobID, status.Status)
+	}
+
+	if status.TotalProcessed != 4000 || status.TotalErrors != 2 || len(status.Errors) != 2 {
+		t.Errorf("unexpected totals: processed %d, errors %d (%d messages)", status.TotalProcessed,
+			status.TotalErrors, len(status.Errors))
+	}
+
+	if !status.StartedAt.Equal(start) || !status.EndedAt.Equal(start.Add(3*time.Second)) {
+		t.Errorf("expected earliest start and latest end, got %s - %s", status.StartedAt, status.EndedAt)
+	}
+
+	if status.ElapsedSeconds != 3 {
+		t.Errorf("expected 3 elapsed seconds, got %v", status.ElapsedSeconds)
+	}
+
+	if status.TotalMsgPerSecAllNodes != 1500 || status.AvgMsgPerSecPerNode != 750 {
+		t.Errorf("unexpected rates: total %v, per node %v", status.TotalMsgPerSecAllNodes, status.AvgMsgPerSecPerNode)
+	}
+
+	if len(status.NodeReports) != 2 || status.NodeReports[0].NodeID != "node1" || status.NodeReports[1].NodeID != "node2" {
+		t.Errorf("expected a report per node, got %+v", status.NodeReports)
+	}
+
+	if status.Verdict == nil || status.Verdict.Passed || len(status.Verdict.Failures) != 1 {
+		t.Fatalf("expected a failed verdict with 1 failure, got %+v", status.Verdict)
+	}
+
+	if a := status.Verdict.Failures[0].Assertion; a != "min_total_msgs_per_sec" {
+		t.Errorf("unexpected failed assertion '%s'", a)
+	}
+}
+
+func TestStatusInProgress(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetStatusesReturns([]*types.Status{
+		{JobID: "abc", NodeID: "node1", Status: types.CompletedStatus, StartedAt: time.Now().UTC()},
+		{JobID: "abc", NodeID: "node2", Status: types.InProgressStatus, StartedAt: time.Now().UTC()},
+	}, nil)
+
+	status, err := b.Status("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if status.Status != types.InProgressStatus {
+		t.Errorf("expected in-progress status, got %s", status.Status)
+	}
+
+	// The verdict is only evaluated once the job is final
+	if fake.GetSettingsCallCount() != 0 || status.Verdict != nil {
+		t.Errorf("settings should not be looked up for a job in progress")
+	}
+}
+
+func TestStatusWaitingForParticipants(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	// node2 and node3 have not picked up the job yet
+	fake.GetStatusesReturns([]*types.Status{{JobID: "abc", NodeID: "node1", Status: types.LostStatus}}, nil)
+	fake.GetSettingsReturns(&types.Settings{ID: "abc", Participants: []string{"node1", "node2", "node3"},
+		Expect: &types.Expect{}}, nil)
+
+	status, err := b.Status("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if status.Status != types.InProgressStatus || status.Verdict != nil {
+		t.Errorf("expected in-progress status without verdict, got %s, %+v", status.Status, status.Verdict)
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetStatusesReturns(nil, errors.Wrap(nats.ErrBucketNotFound, "unable to get bucket"))
+
+	if _, err := b.Status("abc"); errors.Cause(err) != nats.ErrBucketNotFound {
+		t.Errorf("expected results error to be returned, got %v", err)
+	}
+
+	// Missing settings only skip the verdict
+	fake.GetStatusesReturns([]*types.Status{{JobID: "abc", NodeID: "node1", Status: types.CompletedStatus}}, nil)
+	fake.GetSettingsReturns(nil, nats.ErrKeyNotFound)
+
+	status, err := b.Status("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if status.Status != types.CompletedStatus || status.Verdict != nil {
+		t.Errorf("expected completed status without verdict, got %s, %+v", status.Status, status.Verdict)
+	}
+
+	// The results of an archived job are read from the history stream
+	fake.GetStatusesReturns(nil, nats.ErrBucketNotFound)
+	fake.GetArchivedRunReturns(&types.ArchivedRun{Status: &types.Status{JobID: "abc", Status: types.FailedStatus}}, nil)
+
+	status, err = b.Status("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if status.Status != types.FailedStatus {
+		t.Errorf("expected archived status, got %s", status.Status)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2", "node3", "node4"}, nil)
+	fake.GetSettingsReturns(&types.Settings{ID: "abc", Participants: []string{"node1", "node2", "node5"}}, nil)
+
+	fake.RequestJobStub = func(jobType types.JobType, job *types.Job, timeout time.Duration) ([]byte, error) {
+		if jobType != types.DeleteJob || job.Settings.ID != "abc" || timeout != CancelTimeout {
+			t.Errorf("unexpected request: %s, %+v, %s", jobType, job, timeout)
+		}
+
+		switch job.NodeID {
+		case "node1":
+			return []byte(`{"node_id":"node1","job_id":"abc","running":true}`), nil
+		case "node2":
+			return []byte(`{"node_id":"node2","job_id":"abc","running":true,"error":"job did not stop within 10s"}`), nil
+		case "node3":
+			return []byte(`{"node_id":"node3","job_id":"abc","running":false}`), nil
+		default:
+			return nil, errors.New("nats: timeout")
+		}
+	}
+
+	resp, err := b.Delete("abc", true, true, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(resp.Confirmed, []string{"node1", "node3"}) {
+		t.Errorf("unexpected confirmed nodes %v", resp.Confirmed)
+	}
+
+	if !reflect.DeepEqual(resp.Cancelled, []string{"node1"}) {
+		t.Errorf("unexpected cancelled nodes %v", resp.Cancelled)
+	}
+
+	// node5 participated in the job but is no longer heartbeating
+	if !reflect.DeepEqual(resp.Unconfirmed, []string{"node2", "node4", "node5"}) || len(resp.Errors) != 3 {
+		t.Errorf("unexpected unconfirmed nodes %v (errors: %v)", resp.Unconfirmed, resp.Errors)
+	}
+
+	if resp.Message != "benchmark deleted; 2 of 5 node(s) confirmed" {
+		t.Errorf("unexpected message '%s'", resp.Message)
+	}
+
+	// Data is deleted even if not every node confirmed
+	if fake.DeleteSettingsCallCount() != 1 || fake.DeleteResultsCallCount() != 1 ||
+		fake.DeleteTimelineCallCount() != 1 || fake.DeleteStreamsCallCount() != 1 {
+		t.Error("expected settings, results, timeline and streams to be deleted")
+	}
+}
+
+func TestDeleteErrors(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns(nil, errors.New("no heartbeats"))
+
+	_, err := b.Delete("abc", false, false, false)
+	checkErr(t, err, "unable to create delete jobs")
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.RequestJobReturns([]byte(`{"node_id":"node1","job_id":"abc"}`), nil)
+	fake.DeleteResultsReturns(errors.New("key not found"))
+
+	_, err = b.Delete("abc", false, false, true)
+	checkErr(t, err, "unable to delete results")
+
+	// Only the requested data is deleted
+	if fake.DeleteSettingsCallCount() != 0 || fake.DeleteStreamsCallCount() != 0 {
+		t.Error("settings and streams should not have been deleted")
+	}
+}
+
+func createStub(rejectedBy map[string]string) func(types.JobType, *types.Job, time.Duration) ([]byte, error) {
+	return func(jobType types.JobType, job *types.Job, timeout time.Duration) ([]byte, error) {
+		if jobType == types.DeleteJob {
+			return []byte(`{"running": true}`), nil
+		}
+
+		reason, ok := rejectedBy[job.NodeID]
+
+		switch {
+		case !ok:
+			return []byte(`{"accepted": true}`), nil
+		case reason == "":
+			return nil, errors.New("nats: timeout")
+		default:
+			return []byte(fmt.Sprintf(`{"accepted": false, "error": %q}`, reason)), nil
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2"}, nil)
+	fake.RequestJobStub = createStub(nil)
+
+	settings := &types.Settings{
+		NATS:  &types.NATS{Address: "localhost:4222"},
+		Write: &types.WriteSettings{NumStreams: 1, NumMessagesPerStream: 100, NumWorkersPerStream: 1, Subjects: []string{"foo"}},
+	}
+
+	resp, err := b.Create(settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if resp.ID != settings.ID || !reflect.DeepEqual(resp.Accepted, []string{"node1", "node2"}) || len(resp.Rejected) != 0 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	for i := 0; i < fake.RequestJobCallCount(); i++ {
+		if jobType, _, timeout := fake.RequestJobArgsForCall(i); jobType != types.CreateJob || timeout != CreateTimeout {
+			t.Errorf("unexpected request: %s, %s", jobType, timeout)
+		}
+	}
+
+	if fake.SaveSettingsCallCount() != 1 || !reflect.DeepEqual(settings.Participants, []string{"node1", "node2"}) {
+		t.Errorf("expected settings with 2 participants to be saved, got %v", settings.Participants)
+	}
+}
+
+func TestCreateRejected(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2", "node3"}, nil)
+	fake.RequestJobStub = createStub(map[string]string{"node2": "job is already running on this node", "node3": ""})
+
+	settings := &types.Settings{
+		NATS:  &types.NATS{Address: "localhost:4222"},
+		Write: &types.WriteSettings{NumStreams: 1, NumMessagesPerStream: 100, NumWorkersPerStream: 1, Subjects: []string{"foo"}},
+	}
+
+	resp, err := b.Create(settings)
+	checkErr(t, err, "2 of 3 node(s) rejected the job: node 'node2': job is already running on this node; "+
+		"node 'node3': nats: timeout")
+
+	if resp == nil || !reflect.DeepEqual(resp.Accepted, []string{"node1"}) || len(resp.Rejected) != 2 || resp.Error == "" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// node1 is told to cancel
+	var cancelled []string
+
+	for i := 0; i < fake.RequestJobCallCount(); i++ {
+		if jobType, job, _ := fake.RequestJobArgsForCall(i); jobType == types.DeleteJob {
+			cancelled = append(cancelled, job.NodeID)
+		}
+	}
+
+	if !reflect.DeepEqual(cancelled, []string{"node1"}) {
+		t.Errorf("expected node1 to be cancelled, got %v", cancelled)
+	}
+
+	// node2 and node3 are sent a delete job without waiting for a reply
+	if fake.EmitJobsCallCount() != 1 {
+		t.Fatalf("expected delete jobs to be emitted once, got %d", fake.EmitJobsCallCount())
+	}
+
+	if jobType, jobs := fake.EmitJobsArgsForCall(0); jobType != types.DeleteJob || len(jobs) != 2 {
+		t.Errorf("expected delete jobs for 2 nodes, got %s for %d", jobType, len(jobs))
+	}
+
+	if fake.SaveSettingsCallCount() != 0 {
+		t.Error("settings of a rejected job should not be saved")
+	}
+
+	if fake.DeleteResultsCallCount() != 1 || fake.DeleteTimelineCallCount() != 1 || fake.DeleteStreamsCallCount() != 1 {
+		t.Error("expected results, timeline and streams to be deleted")
+	}
+}
+
+func TestCreateSaveSettingsError(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetNodeListReturns([]string{"node1", "node2"}, nil)
+	fake.RequestJobStub = createStub(nil)
+	fake.SaveSettingsReturns(errors.New("nats: timeout"))
+
+	settings := &types.Settings{
+		NATS:  &types.NATS{Address: "localhost:4222"},
+		Write: &types.WriteSettings{NumStreams: 1, NumMessagesPerStream: 100, NumWorkersPerStream: 1, Subjects: []string{"foo"}},
+	}
+
+	_, err := b.Create(settings)
+	checkErr(t, err, "unable to save settings: nats: timeout")
+
+	// Both nodes accepted the job and are told to cancel it
+	var cancelled []string
+
+	for i := 0; i < fake.RequestJobCallCount(); i++ {
+		if jobType, job, _ := fake.RequestJobArgsForCall(i); jobType == types.DeleteJob {
+			cancelled = append(cancelled, job.NodeID)
+		}
+	}
+
+	sort.Strings(cancelled)
+
+	if !reflect.DeepEqual(cancelled, []string{"node1", "node2"}) {
+		t.Errorf("expected node1 and node2 to be cancelled, got %v", cancelled)
+	}
+
+	if fake.DeleteResultsCallCount() != 1 || fake.DeleteTimelineCallCount() != 1 || fake.DeleteStreamsCallCount() != 1 {
+		t.Error("expected results, timeline and streams to be deleted")
+	}
+}
+
+func checkErr(t *testing.T, err error, expected string) bool {
+	t.Helper()
+
+	if expected == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return true
+	}
+
+	if err == nil {
+		t.Fatalf("expected error '%s', got none", expected)
+	}
+
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected error '%s', got '%s'", expected, err)
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		err  string
+	}{
+		{"every minute", "* * * * *", ""},
+		{"lists, ranges and steps", "0,30 9-17/2 1-15 */3 mon-fri", ""},
+		{"start and step", "5/15 * * * *", ""},
+		{"names", "0 0 * jan-jun SUN", ""},
+		{"sunday as 7", "0 0 * * 7", ""},
+		{"descriptor", "@daily", ""},
+		{"descriptor uppercase", " @HOURLY ", ""},
+		{"empty", "", "expected 5 fields in cron expression, got 0"},
+		{"too few fields", "* * * *", "expected 5 fields in cron expression, got 4"},
+		{"too many fields", "* * * * * *", "expected 5 fields in cron expression, got 6"},
+		{"minute out of range", "60 * * * *", "unable to parse minute field: value '60' out of range [0-59]"},
+		{"hour out of range", "* 24 * * *", "unable to parse hour field: value '24' out of range [0-23]"},
+		{"day of month out of range", "* * 0 * *", "unable to parse day-of-month field: value '0' out of range [1-31]"},
+		{"month out of range", "* * * 13 *", "unable to parse month field: value '13' out of range [1-12]"},
+		{"day of week out of range", "* * * * 8", "unable to parse day-of-week field: value '8' out of range [0-7]"},
+		{"range end out of range", "0-60 * * * *", "value '60' out of range"},
+		{"zero step", "*/0 * * * *", "invalid step in '*/0'"},
+		{"negative step", "*/-5 * * * *", "invalid step in '*/-5'"},
+		{"non numeric step", "*/x * * * *", "invalid step in '*/x'"},
+		{"empty step", "1-5/ * * * *", "invalid step in '1-5/'"},
+		{"reversed range", "5-2 * * * *", "invalid range '5-2'"},
+		{"invalid value", "foo * * * *", "invalid value 'foo'"},
+		{"invalid name", "* * * jan-foo *", "invalid value 'foo'"},
+		{"empty list item", "1,,2 * * * *", "invalid value ''"},
+		{"never matches", "0 0 30 2 *", "cron expression never matches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			checkErr(t, err, tt.err)
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"next minute", "* * * * *", date(2022, 5, 25, 10, 7).Add(30 * time.Second), date(2022, 5, 25, 10, 8)},
+		{"exact match is skipped", "0 * * * *", date(2022, 5, 25, 10, 0), date(2022, 5, 25, 11, 0)},
+		{"step", "*/15 * * * *", date(2022, 5, 25, 10, 7), date(2022, 5, 25, 10, 15)},
+		{"start and step", "5/20 * * * *", date(2022, 5, 25, 10, 26), date(2022, 5, 25, 10, 45)},
+		{"next day", "30 9 * * *", date(2022, 5, 25, 10, 0), date(2022, 5, 26, 9, 30)},
+		{"month boundary", "30 12 1 * *", date(2022, 1, 31, 13, 0), date(2022, 2, 1, 12, 30)},
+		{"day after month boundary", "0 0 * * *", date(2022, 4, 30, 23, 30), date(2022, 5, 1, 0, 0)},
+		{"skips short months", "0 0 31 * *", date(2022, 4, 1, 0, 0), date(2022, 5, 31, 0, 0)},
+		{"year boundary", "0 0 1 1 *", date(2022, 12, 31, 23, 59), date(2023, 1, 1, 0, 0)},
+		{"day after year boundary", "15 0 * * *", date(2022, 12, 31, 0, 15), date(2023, 1, 1, 0, 15)},
+		{"next year", "0 0 1 mar *", date(2022, 3, 1, 0, 0), date(2023, 3, 1, 0, 0)},
+		{"leap day", "0 0 29 2 *", date(2022, 3, 1, 0, 0), date(2024, 2, 29, 0, 0)},
+		{"weekdays", "0 9 * * mon-fri", date(2022, 5, 27, 10, 0), date(2022, 5, 30, 9, 0)},
+		{"sunday as 7", "0 0 * * 7", date(2022, 5, 27, 10, 0), date(2022, 5, 29, 0, 0)},
+		{"day of month or week", "0 0 13 * 5", date(2022, 5, 1, 0, 0), date(2022, 5, 6, 0, 0)},
+		{"day of week across year boundary", "0 0 * jan mon", date(2022, 12, 27, 0, 0), date(2023, 1, 2, 0, 0)},
+		{"local time is converted to UTC", "0 12 * * *",
+			time.Date(2022, 5, 25, 13, 30, 0, 0, time.FixedZone("CEST", 2*60*60)), date(2022, 5, 25, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if next := c.Next(tt.from); !next.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, next)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package bench
+
+import (
+	"context"
+	"strings"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Message of the status a node writes as soon as it picks up a job
+	JobAcceptedMessage = "job accepted"
+
+	NodeEventType   = "node"
+	StatusEventType = "status"
+	EndEventType    = "end"
+
+	AcceptedNodeState   = "accepted"
+	QueuedNodeState     = "queued"
+	RunningNodeState    = "running"
+	FinishedNodeState   = "finished"
+	ErrorNodeState      = "error"
+	CancelledNodeState  = "cancelled"
+	LostNodeState       = "lost"
+	TimedOutNodeState   = "timed-out"
+	ReassignedNodeState = "reassigned"
+)
+
+// WatchStatus streams events for a job until every participating node has
+// reported a final status (or ctx is done): a "node" event every time a node
+// changes state, a "status" event with the aggregated status after every
+// update and a final "end" event. The current state of the job is replayed
+// first, so watching a finished job produces its events and ends right away.
+func (b *Bench) WatchStatus(ctx context.Context, jobID string) (<-chan *types.JobEvent, error) {
+	settings, err := b.nats.GetSettings(jobID)
+	if err != nil {
+		if run, archErr := b.nats.GetArchivedRun(jobID); archErr == nil && run != nil {
+			return archivedEvents(ctx, run), nil
+		}
+
+		return nil, errors.Wrap(err, "unable to get settings")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	statusCh, err := b.nats.WatchResults(ctx, jobID)
+	if err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "unable to watch results")
+	}
+
+	eventCh := make(chan *types.JobEvent)
+
+	go func() {
+		defer close(eventCh)
+		defer cancel()
+
+		send := func(event *types.JobEvent) bool {
+			select {
+			case eventCh <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		nodeStatuses := make(map[string]*types.Status)
+		nodeStates := make(map[string]string)
+
+		for status := range statusCh {
+			nodeStatuses[status.NodeID] = status
+
+			if state := nodeState(status); state != nodeStates[status.NodeID] {
+				nodeStates[status.NodeID] = state
+
+				if !send(&types.JobEvent{
+					Type:    NodeEventType,
+					NodeID:  status.NodeID,
+					State:   state,
+					Message: status.Message,
+				}) {
+					return
+				}
+			}
+
+			statuses := make([]*types.Status, 0, len(nodeStatuses))
+
+			for _, s := range nodeStatuses {
+				statuses = append(statuses, s)
+			}
+
+			aggregated := aggregateStatuses(settings, withPlaceholders(settings, statuses))
+			aggregated.JobID = jobID
+
+			done := allFinal(settings, nodeStatuses)
+
+			verifyPlan(settings, aggregated)
+			applyVerdict(settings, aggregated)
+
+			if !send(&types.JobEvent{Type: StatusEventType, Status: aggregated}) {
+				return
+			}
+
+			if done {
+				send(&types.JobEvent{Type: EndEventType, Message: "job ended", Status: aggregated})
+				return
+			}
+		}
+	}()
+
+	return eventCh, nil
+}
+
+// archivedEvents replays an archived job: its node states are no longer
+// known, so only its status and the end of the job are sent
+func archivedEvents(ctx context.Context, run *types.ArchivedRun) <-chan *types.JobEvent {
+	eventCh := make(chan *types.JobEvent)
+
+	go func() {
+		defer close(eventCh)
+
+		for _, event := range []*types.JobEvent{
+			{Type: StatusEventType, Status: run.Status},
+			{Type: EndEventType, Message: "job ended", Status: run.Status},
+		} {
+			select {
+			case eventCh <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return eventCh
+}
+
+// withPlaceholders adds an in-progress status for every participant that has
+// not reported yet, so that the job stays in progress until every node has
+// reported (an error is still reported right away)
+func withPlaceholders(settings *types.Settings, statuses []*types.Status) []*types.Status {
+	reported := make(map[string]bool, len(statuses))
+
+	for _, s := range statuses {
+		reported[s.NodeID] = true
+	}
+
+	for _, nodeID := range settings.Participants {
+		if !reported[nodeID] {
+			statuses = append(statuses, &types.Status{
+				NodeID:  nodeID,
+				Status:  types.InProgressStatus,
+				Message: "waiting for node to report",
+			})
+		}
+	}
+
+	return statuses
+}
+
+func nodeState(status *types.Status) string {
+	switch status.Status {
+	case types.InProgressStatus:
+		if status.Message == JobAcceptedMessage {
+			return AcceptedNodeState
+		}
+
+		if strings.HasPrefix(status.Message, JobQueuedMessage) {
+			return QueuedNodeState
+		}
+
+		return RunningNodeState
+	case types.CompletedStatus:
+		return FinishedNodeState
+	case types.CancelledStatus:
+		return CancelledNodeState
+	case types.LostStatus:
+		return LostNodeState
+	case types.TimedOutStatus:
+		return TimedOutNodeState
+	case types.ReassignedStatus:
+		return ReassignedNodeState
+	default:
+		return ErrorNodeState
+	}
+}
+
+// allFinal returns true once every participating node has reported a final
+// status
+func allFinal(settings *types.Settings, nodeStatuses map[string]*types.Status) bool {
+	if len(nodeStatuses) == 0 {
+		return false
+	}
+
+	for _, nodeID := range settings.Participants {
+		if _, ok := nodeStatuses[nodeID]; !ok {
+			return false
+		}
+	}
+
+	for _, status := range nodeStatuses {
+		if !isFinal(status.Status) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,139 @@
+package bench
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestWatchStatus(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetSettingsReturns(&types.Settings{ID: "abc", Participants: []string{"node1", "node2"}}, nil)
+
+	statusCh := make(chan *types.Status, 5)
+
+	for _, s := range []*types.Status{
+		{NodeID: "node1", Status: types.InProgressStatus, Message: JobAcceptedMessage},
+		{NodeID: "node1", Status: types.InProgressStatus, TotalProcessed: 10},
+		{NodeID: "node1", Status: types.InProgressStatus, TotalProcessed: 20},
+		{NodeID: "node1", Status: types.CompletedStatus, TotalProcessed: 50},
+		{NodeID: "node2", Status: types.CompletedStatus, TotalProcessed: 50},
+	} {
+		statusCh <- s
+	}
+
+	close(statusCh)
+
+	fake.WatchResultsReturns(statusCh, nil)
+
+	eventCh, err := b.WatchStatus(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var events []string
+
+	var last *types.JobEvent
+
+	for event := range eventCh {
+		switch event.Type {
+		case NodeEventType:
+			events = append(events, event.NodeID+" "+event.State)
+		case StatusEventType:
+			events = append(events, string(event.Status.Status))
+		default:
+			events = append(events, event.Type)
+		}
+
+		last = event
+	}
+
+	// A node event only when a node changes state; node2 has not reported
+	// until the end, so the job stays in progress
+	expected := []string{
+		"node1 accepted", "in-progress",
+		"node1 running", "in-progress",
+		"in-progress",
+		"node1 finished", "in-progress",
+		"node2 finished", "completed",
+		"end",
+	}
+
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+
+	if last.Status == nil || last.Status.JobID != "abc" || last.Status.TotalProcessed != 100 {
+		t.Errorf("expected the end event to carry the final status, got %+v", last.Status)
+	}
+}
+
+func TestWatchStatusArchived(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetSettingsReturns(nil, nats.ErrKeyNotFound)
+	fake.GetArchivedRunReturns(&types.ArchivedRun{Status: &types.Status{JobID: "abc", Status: types.CompletedStatus}}, nil)
+
+	eventCh, err := b.WatchStatus(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var kinds []string
+
+	for event := range eventCh {
+		kinds = append(kinds, event.Type)
+	}
+
+	if !reflect.DeepEqual(kinds, []string{StatusEventType, EndEventType}) {
+		t.Errorf("expected a status and an end event, got %v", kinds)
+	}
+
+	if fake.WatchResultsCallCount() != 0 {
+		t.Error("results of an archived job should not be watched")
+	}
+}
+
+func TestWithPlaceholders(t *testing.T) {
+	settings := &types.Settings{Participants: []string{"node1", "node2", "node3"}}
+
+	tests := []struct {
+		name     string
+		statuses []*types.Status
+		expected types.JobStatus
+	}{
+		{"nobody reported", nil, types.InProgressStatus},
+		{"some nodes completed", []*types.Status{
+			{NodeID: "node1", Status: types.CompletedStatus},
+			{NodeID: "node2", Status: types.CompletedStatus},
+		}, types.InProgressStatus},
+		{"every node completed", []*types.Status{
+			{NodeID: "node1", Status: types.CompletedStatus},
+			{NodeID: "node2", Status: types.CompletedStatus},
+			{NodeID: "node3", Status: types.CompletedStatus},
+		}, types.CompletedStatus},
+		{"error reported right away", []*types.Status{
+			{NodeID: "node1", Status: types.ErrorStatus},
+		}, types.ErrorStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := withPlaceholders(settings, tt.statuses)
+
+			if len(statuses) != len(settings.Participants) {
+				t.Fatalf("expected a status for each of the %d participants, got %d", len(settings.Participants),
+					len(statuses))
+			}
+
+			if status := aggregateStatuses(settings, statuses); status.Status != tt.expected {
+				t.Errorf("expected aggregated status '%s', got '%s'", tt.expected, status.Status)
+			}
+		})
+	}
+}
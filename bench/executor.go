@@ -0,0 +1,371 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	DefaultMaxConcurrentJobs = 1
+
+	// Prefix of the status message a node writes when it accepts a job that
+	// cannot start right away; followed by the reason (see queueReason)
+	JobQueuedMessage = "job queued"
+)
+
+// executor decides which of the jobs accepted by this node run. Jobs start in
+// the order they were accepted, at most maxConcurrent at a time. While an
+// exclusive job holds the cluster lock, no other job starts; the exclusive
+// job itself starts once no other job is running in the cluster (see
+// lock.go). Admission rules are enforced when a job is accepted (see admit).
+type executor struct {
+	maxConcurrent int
+	maxQueued     int // 0 for no limit
+
+	running map[string]*queuedJob
+	queue   []*queuedJob
+	mutex   *sync.Mutex
+
+	// As of the last sync with the cluster; see setCluster
+	lock        *types.ClusterLock
+	clusterJobs []string // jobs running on any node
+}
+
+type queuedJob struct {
+	id         string
+	exclusive  bool
+	acceptedAt time.Time
+	startedAt  time.Time
+	started    chan struct{}
+}
+
+func newExecutor(maxConcurrent, maxQueued int) *executor {
+	if maxConcurrent < 1 {
+		maxConcurrent = DefaultMaxConcurrentJobs
+	}
+
+	return &executor{
+		maxConcurrent: maxConcurrent,
+		maxQueued:     maxQueued,
+		running:       make(map[string]*queuedJob),
+		queue:         make([]*queuedJob, 0),
+		mutex:         &sync.Mutex{},
+	}
+}
+
+// admit queues a job (which starts right away if the node has room for it)
+// or returns why the node refuses it:
+//
+//   - jobs with on_conflict "reject" are refused while another job holds the
+//     cluster lock or while an exclusive job is queued or running
+//   - nothing is admitted while max_queued_jobs jobs are waiting
+func (e *executor) admit(jobID string, settings *types.Settings, now time.Time) string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if settings.OnConflict != types.QueueConflictPolicy {
+		if e.lock != nil && e.lock.JobID != jobID {
+			return fmt.Sprintf("cluster is locked by exclusive job '%s'", e.lock.JobID)
+		}
+
+		if id := e.exclusiveJob(); id != "" {
+			return fmt.Sprintf("node is reserved for exclusive job '%s'", id)
+		}
+	}
+
+	if e.maxQueued > 0 && len(e.queue) >= e.maxQueued {
+		return fmt.Sprintf("queue is full: %d job(s) waiting (max_queued_jobs is %d)", len(e.queue), e.maxQueued)
+	}
+
+	e.queue = append(e.queue, &queuedJob{
+		id:         jobID,
+		exclusive:  settings.Exclusive,
+		acceptedAt: now,
+		started:    make(chan struct{}),
+	})
+
+	e.schedule(now)
+
+	return ""
+}
+
+// wait blocks until an admitted job is started and returns true or until ctx
+// is done, in which case the job gives up its place and false is returned
+func (e *executor) wait(ctx context.Context, jobID string) bool {
+	e.mutex.Lock()
+	j := e.find(jobID)
+	e.mutex.Unlock()
+
+	if j == nil {
+		return false
+	}
+
+	select {
+	case <-j.started:
+		if ctx.Err() == nil {
+			return true
+		}
+	case <-ctx.Done():
+	}
+
+	e.done(jobID)
+
+	return false
+}
+
+// done removes a job and starts whatever can start in its place
+func (e *executor) done(jobID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	delete(e.running, jobID)
+
+	for i, j := range e.queue {
+		if j.id == jobID {
+			e.queue = append(e.queue[:i], e.queue[i+1:]...)
+			break
+		}
+	}
+
+	e.schedule(time.Now().UTC())
+}
+
+// setCluster updates what the executor knows about the rest of the cluster
+// and starts whatever can start now
+func (e *executor) setCluster(lock *types.ClusterLock, clusterJobs []string, now time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.lock = lock
+	e.clusterJobs = clusterJobs
+
+	e.schedule(now)
+}
+
+// schedule starts queued jobs in order for as long as there is room; a job
+// that cannot start holds up the jobs behind it, except for the job holding
+// the cluster lock, which goes first. Must be called with the mutex held.
+func (e *executor) schedule(now time.Time) {
+	for {
+		i := e.next()
+		if i < 0 {
+			return
+		}
+
+		j := e.queue[i]
+		e.queue = append(e.queue[:i], e.queue[i+1:]...)
+
+		j.startedAt = now
+		e.running[j.id] = j
+
+		close(j.started)
+	}
+}
+
+// next returns the position of the queued job to start next or -1 if none
+// can start
+func (e *executor) next() int {
+	if len(e.queue) == 0 {
+		return -1
+	}
+
+	if e.lock != nil {
+		for i, j := range e.queue {
+			if j.id == e.lock.JobID && e.canStart(j) {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	if e.canStart(e.queue[0]) {
+		return 0
+	}
+
+	return -1
+}
+
+func (e *executor) canStart(j *queuedJob) bool {
+	if e.lock != nil && e.lock.JobID != j.id {
+		return false
+	}
+
+	if j.exclusive {
+		return e.lock != nil && len(e.running) == 0 && len(e.otherClusterJobs(j.id)) == 0
+	}
+
+	return len(e.running) < e.maxConcurrent && e.runningExclusive() == ""
+}
+
+// otherClusterJobs returns the jobs other than jobID running in the cluster
+func (e *executor) otherClusterJobs(jobID string) []string {
+	jobs := make([]string, 0)
+
+	for _, id := range e.clusterJobs {
+		if id != jobID {
+			jobs = append(jobs, id)
+		}
+	}
+
+	return jobs
+}
+
+// exclusiveHead returns the ID of the job at the front of the queue if it is
+// an exclusive job or an empty string; the node claims the cluster lock for
+// it (see syncLock)
+func (e *executor) exclusiveHead() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.queue) > 0 && e.queue[0].exclusive {
+		return e.queue[0].id
+	}
+
+	return ""
+}
+
+// exclusiveJob returns the ID of the first exclusive job that is running or
+// queued or an empty string
+func (e *executor) exclusiveJob() string {
+	if id := e.runningExclusive(); id != "" {
+		return id
+	}
+
+	for _, j := range e.queue {
+		if j.exclusive {
+			return j.id
+		}
+	}
+
+	return ""
+}
+
+func (e *executor) runningExclusive() string {
+	for id, j := range e.running {
+		if j.exclusive {
+			return id
+		}
+	}
+
+	return ""
+}
+
+func (e *executor) find(jobID string) *queuedJob {
+	if j, ok := e.running[jobID]; ok {
+		return j
+	}
+
+	for _, j := range e.queue {
+		if j.id == jobID {
+			return j
+		}
+	}
+
+	return nil
+}
+
+// queueReason returns why the job at position i of the queue has not started
+// yet. Must be called with the mutex held.
+func (e *executor) queueReason(i int) string {
+	j := e.queue[i]
+
+	switch {
+	case e.lock != nil && e.lock.JobID != j.id:
+		return fmt.Sprintf("waiting for exclusive job '%s' to release the cluster lock", e.lock.JobID)
+	case e.lock != nil && len(e.running) > 0:
+		return fmt.Sprintf("exclusive job waiting for %d running job(s) to finish", len(e.running))
+	case e.lock != nil:
+		return fmt.Sprintf("exclusive job waiting for %d job(s) to finish on other nodes",
+			len(e.otherClusterJobs(j.id)))
+	case i > 0:
+		return fmt.Sprintf("waiting for %d job(s) ahead of it in the queue", i)
+	case j.exclusive:
+		return "exclusive job waiting for the cluster lock"
+	case e.runningExclusive() != "":
+		return fmt.Sprintf("waiting for exclusive job '%s' to finish", e.runningExclusive())
+	default:
+		return fmt.Sprintf("waiting for one of %d running job(s) to finish (max_concurrent_jobs is %d)",
+			len(e.running), e.maxConcurrent)
+	}
+}
+
+// queued returns the status message of a job that was just admitted or an
+// empty string if it was started right away
+func (e *executor) queued(jobID string) string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, j := range e.queue {
+		if j.id == jobID {
+			return fmt.Sprintf("%s at position %d: %s", JobQueuedMessage, i+1, e.queueReason(i))
+		}
+	}
+
+	return ""
+}
+
+func (e *executor) isQueued(jobID string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, j := range e.queue {
+		if j.id == jobID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jobs returns the running jobs (oldest first) followed by the queued jobs in
+// the order they will start
+func (e *executor) jobs(nodeID string) *types.NodeJobs {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	resp := &types.NodeJobs{
+		NodeID:            nodeID,
+		MaxConcurrentJobs: e.maxConcurrent,
+		MaxQueuedJobs:     e.maxQueued,
+		NumRunning:        len(e.running),
+		NumQueued:         len(e.queue),
+		Jobs:              make([]*types.NodeJob, 0, len(e.running)+len(e.queue)),
+	}
+
+	for _, j := range e.running {
+		resp.Jobs = append(resp.Jobs, &types.NodeJob{
+			ID:         j.id,
+			State:      types.RunningNodeJobState,
+			Exclusive:  j.exclusive,
+			AcceptedAt: j.acceptedAt,
+			StartedAt:  j.startedAt,
+		})
+	}
+
+	sort.Slice(resp.Jobs, func(i, k int) bool {
+		if !resp.Jobs[i].StartedAt.Equal(resp.Jobs[k].StartedAt) {
+			return resp.Jobs[i].StartedAt.Before(resp.Jobs[k].StartedAt)
+		}
+
+		return resp.Jobs[i].ID < resp.Jobs[k].ID
+	})
+
+	for i, j := range e.queue {
+		resp.Jobs = append(resp.Jobs, &types.NodeJob{
+			ID:         j.id,
+			State:      types.QueuedNodeJobState,
+			Position:   i + 1,
+			Reason:     e.queueReason(i),
+			Exclusive:  j.exclusive,
+			AcceptedAt: j.acceptedAt,
+		})
+	}
+
+	return resp
+}
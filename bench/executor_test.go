@@ -0,0 +1,213 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestExecutorAdmit(t *testing.T) {
+	exclusive := &types.Settings{Exclusive: true}
+	queue := &types.Settings{OnConflict: types.QueueConflictPolicy}
+	lock := &types.ClusterLock{JobID: "other"}
+
+	tests := []struct {
+		name      string
+		max       int
+		maxQueued int
+		lock      *types.ClusterLock
+		admitted  []*types.Settings
+		settings  *types.Settings
+		expected  string
+	}{
+		{"room to run", 2, 0, nil, []*types.Settings{{}}, &types.Settings{}, ""},
+		{"queued", 1, 0, nil, []*types.Settings{{}, {}}, &types.Settings{}, ""},
+		{"queue full", 1, 1, nil, []*types.Settings{{}, {}}, &types.Settings{}, "queue is full: 1 job(s) waiting"},
+		{"queue full on conflict queue", 1, 1, nil, []*types.Settings{{}, {}}, queue, "queue is full"},
+		{"exclusive pending", 2, 0, nil, []*types.Settings{exclusive}, &types.Settings{}, "reserved for exclusive job 'job0'"},
+		{"exclusive queued", 2, 0, nil, []*types.Settings{{}, exclusive}, exclusive, "reserved for exclusive job 'job1'"},
+		{"exclusive behind others", 2, 0, nil, []*types.Settings{{}, {}}, exclusive, ""},
+		{"exclusive pending on conflict queue", 2, 0, nil, []*types.Settings{exclusive}, queue, ""},
+		{"cluster locked", 2, 0, lock, []*types.Settings{}, &types.Settings{}, "cluster is locked by exclusive job 'other'"},
+		{"cluster locked on conflict queue", 2, 0, lock, []*types.Settings{}, queue, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newExecutor(tt.max, tt.maxQueued)
+			e.setCluster(tt.lock, nil, time.Now())
+
+			for i, s := range tt.admitted {
+				if reason := e.admit(fmt.Sprintf("job%d", i), s, time.Now()); reason != "" {
+					t.Fatalf("unexpected rejection: %s", reason)
+				}
+			}
+
+			reason := e.admit("new", tt.settings, time.Now())
+
+			if tt.expected == "" && reason != "" {
+				t.Fatalf("unexpected rejection: %s", reason)
+			}
+
+			if !strings.Contains(reason, tt.expected) {
+				t.Errorf("expected rejection '%s', got '%s'", tt.expected, reason)
+			}
+		})
+	}
+}
+
+func TestExecutorSchedule(t *testing.T) {
+	e := newExecutor(2, 0)
+	now := time.Now()
+
+	e.admit("a", &types.Settings{}, now)
+	e.admit("b", &types.Settings{}, now)
+	e.admit("c", &types.Settings{Exclusive: true}, now)
+
+	jobs := e.jobs("node1")
+
+	if jobs.NumRunning != 2 || jobs.NumQueued != 1 || jobs.MaxConcurrentJobs != 2 {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+
+	if j := jobs.Jobs[2]; j.ID != "c" || j.State != types.QueuedNodeJobState || j.Position != 1 ||
+		j.Reason != "exclusive job waiting for the cluster lock" {
+		t.Errorf("unexpected queued job %+v", j)
+	}
+
+	if id := e.exclusiveHead(); id != "c" {
+		t.Errorf("expected the lock to be claimed for 'c', got '%s'", id)
+	}
+
+	// The exclusive job only starts once it holds the cluster lock and no
+	// other job is running, here or on other nodes
+	e.setCluster(&types.ClusterLock{JobID: "c"}, []string{"a", "b", "x"}, now)
+
+	if msg := e.queued("c"); msg != "job queued at position 1: exclusive job waiting for 2 running job(s) to finish" {
+		t.Errorf("unexpected message '%s'", msg)
+	}
+
+	e.done("a")
+	e.done("b")
+	e.setCluster(&types.ClusterLock{JobID: "c"}, []string{"x"}, now)
+
+	if msg := e.queued("c"); msg != "job queued at position 1: exclusive job waiting for 1 job(s) to finish on other nodes" {
+		t.Errorf("unexpected message '%s'", msg)
+	}
+
+	e.setCluster(&types.ClusterLock{JobID: "c"}, []string{"c"}, now)
+
+	if e.isQueued("c") {
+		t.Fatal("expected exclusive job to start")
+	}
+
+	// Nothing else starts while the exclusive job runs, even once the lock
+	// is released
+	e.admit("d", &types.Settings{OnConflict: types.QueueConflictPolicy}, now)
+
+	if msg := e.queued("d"); msg != "job queued at position 1: waiting for exclusive job 'c' to release the cluster lock" {
+		t.Errorf("unexpected message '%s'", msg)
+	}
+
+	e.setCluster(nil, nil, now)
+
+	if msg := e.queued("d"); msg != "job queued at position 1: waiting for exclusive job 'c' to finish" {
+		t.Errorf("unexpected message '%s'", msg)
+	}
+
+	e.done("c")
+
+	if e.isQueued("d") {
+		t.Fatal("expected 'd' to start")
+	}
+
+	e.done("d")
+
+	// The job holding the lock goes ahead of the jobs queued before it
+	e = newExecutor(1, 0)
+
+	for _, id := range []string{"a", "b"} {
+		e.admit(id, &types.Settings{OnConflict: types.QueueConflictPolicy}, now)
+	}
+
+	e.admit("c", &types.Settings{Exclusive: true, OnConflict: types.QueueConflictPolicy}, now)
+	e.setCluster(&types.ClusterLock{JobID: "c"}, nil, now)
+	e.done("a")
+
+	if e.isQueued("c") || !e.isQueued("b") {
+		t.Error("expected 'c' to start before 'b'")
+	}
+
+	// Jobs start in the order they were admitted; a job that cannot start
+	// holds up the jobs behind it
+	e = newExecutor(1, 0)
+
+	for _, id := range []string{"a", "b", "c"} {
+		e.admit(id, &types.Settings{}, now)
+	}
+
+	reasons := make([]string, 0)
+
+	for _, j := range e.jobs("node1").Jobs {
+		reasons = append(reasons, j.Reason)
+	}
+
+	expected := []string{
+		"",
+		"waiting for one of 1 running job(s) to finish (max_concurrent_jobs is 1)",
+		"waiting for 1 job(s) ahead of it in the queue",
+	}
+
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("expected reasons %q, got %q", expected, reasons)
+	}
+
+	e.done("a")
+
+	if e.isQueued("b") || !e.isQueued("c") {
+		t.Error("expected 'b' to start before 'c'")
+	}
+}
+
+func TestExecutorWait(t *testing.T) {
+	e := newExecutor(1, 0)
+	now := time.Now()
+
+	e.admit("a", &types.Settings{}, now)
+	e.admit("b", &types.Settings{}, now)
+
+	if !e.wait(context.Background(), "a") {
+		t.Fatal("expected 'a' to be started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan bool)
+
+	go func() {
+		started <- e.wait(ctx, "b")
+	}()
+
+	cancel()
+
+	select {
+	case ok := <-started:
+		if ok {
+			t.Fatal("expected cancelled job not to be started")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected wait to return once cancelled")
+	}
+
+	if jobs := e.jobs("node1"); jobs.NumRunning != 1 || jobs.NumQueued != 0 {
+		t.Errorf("expected cancelled job to leave the queue, got %+v", jobs)
+	}
+
+	if e.wait(context.Background(), "unknown") {
+		t.Error("expected unknown job not to be started")
+	}
+}
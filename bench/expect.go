@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"fmt"
+
+	"github.com/batchcorp/njst/types"
+)
+
+// applyVerdict evaluates the settings' expect block against a final status
+// and sets status.Verdict. It is a no-op for jobs without an expect block or
+// jobs that are still in progress.
+func applyVerdict(settings *types.Settings, status *types.Status) {
+	if settings == nil || settings.Expect == nil || status == nil || !isFinal(status.Status) {
+		return
+	}
+
+	status.Verdict = evaluateExpect(settings.Expect, status)
+}
+
+func evaluateExpect(expect *types.Expect, status *types.Status) *types.Verdict {
+	verdict := &types.Verdict{
+		Failures: make([]*types.AssertionFailure, 0),
+	}
+
+	fail := func(assertion string, expected, actual interface{}, format string, args ...interface{}) {
+		verdict.Failures = append(verdict.Failures, &types.AssertionFailure{
+			Assertion: assertion,
+			Expected:  expected,
+			Actual:    actual,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Results of an errored or cancelled job are incomplete; never pass them
+	if status.Status != types.CompletedStatus {
+		fail("status", types.CompletedStatus, status.Status, "job did not complete (status: %s)", status.Status)
+	}
+
+	if v := expect.MinTotalMsgsPerSec; v != nil && status.TotalMsgPerSecAllNodes < *v {
+		fail("min_total_msgs_per_sec", *v, status.TotalMsgPerSecAllNodes,
+			"total msg/sec %.2f is below %v", status.TotalMsgPerSecAllNodes, *v)
+	}
+
+	if v := expect.MaxErrorRate; v != nil {
+		rate := round(errorRate(status), 4)
+
+		if rate > *v {
+			fail("max_error_rate", *v, rate, "error rate %.4f%% is above %v%%", rate, *v)
+		}
+	}
+
+	if v := expect.MaxP99LatencyMs; v != nil {
+		if status.Latency == nil {
+			fail("max_p99_latency_ms", *v, nil, "no latency was recorded")
+		} else if status.Latency.P99Ms > *v {
+			fail("max_p99_latency_ms", *v, status.Latency.P99Ms,
+				"p99 latency %.2fms is above %vms", status.Latency.P99Ms, *v)
+		}
+	}
+
+	if v := expect.MaxElapsedSeconds; v != nil && status.ElapsedSeconds > *v {
+		fail("max_elapsed_seconds", *v, status.ElapsedSeconds,
+			"elapsed %.2fs is above %vs", status.ElapsedSeconds, *v)
+	}
+
+	verdict.Passed = len(verdict.Failures) == 0
+
+	return verdict
+}
@@ -3,6 +3,7 @@ package bench
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/batchcorp/njst/natssvc"
 	"github.com/batchcorp/njst/types"
@@ -10,12 +11,17 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// CreateMsgHandler is called by natssvc when njst.$nodeID.create is written to
+// CreateMsgHandler is called by natssvc when njst.$nodeID.create is written
+// to. The node replies right away with whether it accepts the job; accepted
+// jobs wait for the node to be free and run in the background (see
+// runAcceptedJob).
 func (b *Bench) CreateMsgHandler(msg *nats.Msg) {
 	jobID := msg.Header.Get(natssvc.HeaderJobID)
 
 	if jobID == "" {
 		b.log.Errorf("CreateMsgHandler: '%s' not found in header - skipping", natssvc.HeaderJobID)
+		b.replyCreate(msg, &types.CreateReply{NodeID: b.params.NodeID, Error: "job ID not found in header"})
+
 		return
 	}
 
@@ -27,15 +33,124 @@ func (b *Bench) CreateMsgHandler(msg *nats.Msg) {
 
 	llog.Info("Received new create job")
 
-	job := b.newJob(jobID)
-	defer b.deleteJob(jobID)
+	reply := &types.CreateReply{
+		NodeID: b.params.NodeID,
+		JobID:  jobID,
+	}
+
+	received := &types.Job{}
+
+	if err := json.Unmarshal(msg.Data, received); err != nil {
+		reply.Error = fmt.Sprintf("unable to unmarshal job: %v", err)
+	} else if reply.Error = b.rejectReason(jobID, received); reply.Error == "" {
+		// Admitted jobs are queued, so admission goes last
+		reply.Error = b.executor.admit(jobID, received.Settings, time.Now().UTC())
+	}
+
+	if reply.Error != "" {
+		llog.Errorf("Rejecting job: %s", reply.Error)
+
+		// Nobody would learn about the rejection otherwise
+		if msg.Reply == "" {
+			b.ReportError(jobID, reply.Error)
+		}
+
+		b.replyCreate(msg, reply)
+
+		return
+	}
+
+	job := b.newJob(jobID, received.Settings)
+
+	job.NodeID = received.NodeID
+	job.CreatedBy = received.CreatedBy
+	job.CreatedAt = received.CreatedAt
+
+	message := JobAcceptedMessage
+
+	// Let watchers know that the job made it to this node and why it has
+	// not started if it is queued
+	if queued := b.executor.queued(jobID); queued != "" {
+		message = queued
+		llog.Info(queued)
+	}
+
+	if err := b.nats.WriteStatus(&types.Status{
+		JobID:   jobID,
+		Status:  types.InProgressStatus,
+		Message: message,
+		NodeID:  b.params.NodeID,
+	}); err != nil {
+		llog.Debugf("Unable to write accepted status: %s", err)
+	}
+
+	reply.Accepted = true
+	b.replyCreate(msg, reply)
+
+	go b.runAcceptedJob(jobID, job)
+}
 
-	if err := json.Unmarshal(msg.Data, job); err != nil {
-		b.ReportError(jobID, fmt.Sprintf("Error unmarshalling settings: %v", err))
+// rejectReason returns why this node cannot accept a job or an empty string
+// if it can
+func (b *Bench) rejectReason(jobID string, job *types.Job) string {
+	switch {
+	case job.Settings == nil:
+		return "job has no settings"
+	case job.Settings.Write == nil && job.Settings.Read == nil:
+		return "unrecognized job type - both read and write are nil"
+	}
 
+	if _, ok := b.getJob(jobID); ok {
+		return "job is already running on this node"
+	}
+
+	if b.wasDeleted(jobID) {
+		return "job was deleted"
+	}
+
+	return ""
+}
+
+func (b *Bench) replyCreate(msg *nats.Msg, reply *types.CreateReply) {
+	if msg.Reply == "" {
 		return
 	}
 
+	data, err := json.Marshal(reply)
+	if err != nil {
+		b.log.Errorf("unable to marshal create reply for job '%s': %s", reply.JobID, err)
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		b.log.Errorf("unable to reply to create job '%s': %s", reply.JobID, err)
+	}
+}
+
+// runAcceptedJob waits for the executor to start the job, runs it and writes
+// its final status. Jobs that are cancelled or exceed their max_duration
+// while queued are not started.
+func (b *Bench) runAcceptedJob(jobID string, job *types.Job) {
+	defer b.finishJob(jobID, job)
+
+	llog := b.log.WithFields(logrus.Fields{
+		"func":    "runAcceptedJob",
+		"job_id":  jobID,
+		"node_id": b.params.NodeID,
+	})
+
+	if !b.executor.wait(job.Context, jobID) {
+		llog.Warn("Job ended before it was started")
+
+		if err := b.nats.WriteStatus(notStartedStatus(jobID, b.params.NodeID, job)); err != nil {
+			llog.Debugf("Unable to write final status: %s", err)
+		}
+
+		return
+	}
+
+	defer b.executor.done(jobID)
+
 	llog.Debugf("starting job; write settings %+v; read settings %+v", job.Settings.Write, job.Settings.Read)
 
 	var status *types.Status
@@ -44,12 +159,9 @@ func (b *Bench) CreateMsgHandler(msg *nats.Msg) {
 	if job.Settings.Write != nil {
 		llog.Info("Performing write job")
 		status, err = b.runWriteBenchmark(job)
-	} else if job.Settings.Read != nil {
+	} else {
 		llog.Info("Performing read job")
 		status, err = b.runReadBenchmark(job)
-	} else {
-		b.ReportError(jobID, "unrecognized job type - both read and write are nil")
-		return
 	}
 
 	if err != nil {
@@ -57,14 +169,34 @@ func (b *Bench) CreateMsgHandler(msg *nats.Msg) {
 		return
 	}
 
-	if err := b.nats.WriteStatus(status); err != nil {
-		llog.Debugf("Unable to write final result status: %s", err)
+	if err := b.writeJobStatus(job, status); err != nil {
+		llog.Warningf("Unable to write final result status: %s", err)
 	}
 
 	llog.Info("Job complete")
 }
 
-// DeleteMsgHandler is called by natssvc when njst.$nodeID.delete is written to
+// notStartedStatus returns the final status of a job that was cancelled or
+// exceeded its max_duration before it was started
+func notStartedStatus(jobID, nodeID string, job *types.Job) *types.Status {
+	status := &types.Status{
+		JobID:   jobID,
+		Status:  finalJobStatus(job),
+		Message: "job cancelled before it was started",
+		NodeID:  nodeID,
+		EndedAt: time.Now().UTC(),
+	}
+
+	if status.Status == types.TimedOutStatus {
+		status.Message = fmt.Sprintf("job exceeded max_duration of %s before it was started", job.Settings.MaxDuration)
+	}
+
+	return status
+}
+
+// DeleteMsgHandler is called by natssvc when njst.$nodeID.delete is written
+// to. A running job is cancelled; if the delete job was sent as a request,
+// the node replies once the job's final (cancelled) status was written.
 func (b *Bench) DeleteMsgHandler(msg *nats.Msg) {
 	jobID := msg.Header.Get(natssvc.HeaderJobID)
 
@@ -79,22 +211,57 @@ func (b *Bench) DeleteMsgHandler(msg *nats.Msg) {
 		"node_id": b.params.NodeID,
 	})
 
+	reply := &types.CancelReply{
+		NodeID: b.params.NodeID,
+		JobID:  jobID,
+	}
+
 	// Is this job running?
 	job, ok := b.getJob(jobID)
 	if !ok {
 		b.log.Debugf("job '%s' not found on node '%s' - nothing to do", jobID, b.params.NodeID)
+		b.markDeleted(jobID, time.Now().UTC())
+		b.replyCancel(msg, reply)
+
 		return
 	}
 
 	llog.Info("Received new delete job")
 
-	// Cancel running reporter + worker(s)
+	reply.Running = true
+
+	// Cancel running reporter + worker(s) and wait for the final status
+	// outside of the NATS callback, which would hold up other messages
 	job.CancelFunc()
 
-	// Delete job from mem
-	b.deleteJob(jobID)
+	go func() {
+		select {
+		case <-job.Done:
+			llog.Info("Job cancelled")
+		case <-time.After(JobStopTimeout):
+			reply.Error = fmt.Sprintf("job did not stop within %s", JobStopTimeout)
+			llog.Error(reply.Error)
+		}
+
+		b.replyCancel(msg, reply)
+	}()
+}
 
-	b.log.Info("Job deleted")
+func (b *Bench) replyCancel(msg *nats.Msg, reply *types.CancelReply) {
+	// Delete jobs emitted by purge do not expect a reply
+	if msg.Reply == "" {
+		return
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		b.log.Errorf("unable to marshal cancel reply for job '%s': %s", reply.JobID, err)
+		return
+	}
+
+	if err := msg.Respond(data); err != nil {
+		b.log.Errorf("unable to reply to delete job '%s': %s", reply.JobID, err)
+	}
 }
 
 func (b *Bench) ReportError(jobID, msg string) {
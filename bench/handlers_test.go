@@ -0,0 +1,202 @@
+package bench
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/natssvc"
+	"github.com/batchcorp/njst/types"
+)
+
+func TestDeleteMsgHandler(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	job := b.newJob("abc", &types.Settings{ID: "abc"})
+
+	go func() {
+		// Stands in for runAcceptedJob: wait for the workers to exit, then
+		// write the final status and finish the job
+		<-job.Context.Done()
+		time.Sleep(50 * time.Millisecond)
+		b.finishJob("abc", job)
+	}()
+
+	msg := &nats.Msg{Header: nats.Header{natssvc.HeaderJobID: {"abc"}}}
+
+	b.DeleteMsgHandler(msg)
+
+	if job.Context.Err() != context.Canceled {
+		t.Errorf("expected job to be cancelled, got %v", job.Context.Err())
+	}
+
+	// The reply is sent once the job finishes, without holding up the
+	// handler
+	select {
+	case <-job.Done:
+		t.Error("expected handler to return before the job finished")
+	default:
+	}
+
+	<-job.Done
+
+	if _, ok := b.getJob("abc"); ok {
+		t.Error("expected job to be removed")
+	}
+
+	// Unknown jobs are a no-op
+	b.DeleteMsgHandler(&nats.Msg{Header: nats.Header{natssvc.HeaderJobID: {"xyz"}}})
+}
+
+func TestFinalJobStatus(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	job := b.newJob("abc", &types.Settings{ID: "abc"})
+
+	if status := finalJobStatus(job); status != types.CompletedStatus {
+		t.Errorf("expected completed status, got %s", status)
+	}
+
+	job.CancelFunc()
+
+	if status := finalJobStatus(job); status != types.CancelledStatus {
+		t.Errorf("expected cancelled status, got %s", status)
+	}
+
+	timed := b.newJob("def", &types.Settings{ID: "def", MaxDuration: "1ms"})
+	<-timed.Context.Done()
+
+	if status := finalJobStatus(timed); status != types.TimedOutStatus {
+		t.Errorf("expected timed out status, got %s", status)
+	}
+
+	// max_duration counts from the time the job was created
+	late := b.newJob("ghi", &types.Settings{ID: "ghi", MaxDuration: "1m", CreatedAt: time.Now().Add(-time.Hour)})
+
+	if late.Context.Err() != context.DeadlineExceeded {
+		t.Errorf("expected job created an hour ago to be past its deadline, got %v", late.Context.Err())
+	}
+
+	// Finishing a job that was replaced by a newer one keeps the newer job
+	newer := b.newJob("abc", &types.Settings{ID: "abc"})
+	b.finishJob("abc", job)
+
+	if j, ok := b.getJob("abc"); !ok || j != newer {
+		t.Error("expected newer job to be kept")
+	}
+
+	select {
+	case <-job.Done:
+	default:
+		t.Error("expected job to be done")
+	}
+}
+
+func TestCreateMsgHandlerRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"invalid JSON", `{`, "unable to unmarshal job"},
+		{"no settings", `{"node_id": "node1"}`, "job has no settings"},
+		{"no read or write", `{"settings": {"id": "abc"}}`, "both read and write are nil"},
+		{"already running", `{"settings": {"id": "abc", "write": {}}}`, "job is already running on this node"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			b.newJob("abc", &types.Settings{ID: "abc"})
+
+			b.CreateMsgHandler(&nats.Msg{Header: nats.Header{natssvc.HeaderJobID: {"abc"}}, Data: []byte(tt.data)})
+
+			// Without a reply subject, the rejection is reported as an error
+			// status instead
+			if fake.WriteStatusCallCount() != 1 {
+				t.Fatalf("expected 1 status, got %d", fake.WriteStatusCallCount())
+			}
+
+			status := fake.WriteStatusArgsForCall(0)
+
+			if status.Status != types.ErrorStatus || !strings.Contains(status.Message, tt.expected) {
+				t.Errorf("expected error status with '%s', got %+v", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestCreateMsgHandlerAfterDelete(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	header := nats.Header{natssvc.HeaderJobID: {"abc"}}
+
+	// The delete job of an aborted create arrives before the create job
+	b.DeleteMsgHandler(&nats.Msg{Header: header})
+	b.CreateMsgHandler(&nats.Msg{Header: header, Data: []byte(`{"settings": {"id": "abc", "write": {}}}`)})
+
+	if _, ok := b.getJob("abc"); ok {
+		t.Fatal("expected deleted job to be rejected")
+	}
+
+	if status := fake.WriteStatusArgsForCall(0); status.Message != "job was deleted" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Deleted jobs are forgotten after a while
+	b.markDeleted("def", time.Now().Add(DeletedJobTTL+time.Minute))
+
+	if b.wasDeleted("abc") || !b.wasDeleted("def") {
+		t.Error("expected only 'def' to be remembered")
+	}
+}
+
+func TestRunAcceptedJobNotStarted(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	// Another job is running
+	b.executor.admit("running", &types.Settings{}, time.Now())
+	b.executor.admit("abc", &types.Settings{}, time.Now())
+
+	job := b.newJob("abc", &types.Settings{ID: "abc", Write: &types.WriteSettings{}})
+
+	go b.runAcceptedJob("abc", job)
+
+	job.CancelFunc()
+
+	select {
+	case <-job.Done:
+	case <-time.After(time.Second):
+		t.Fatal("expected job to finish once cancelled")
+	}
+
+	if fake.WriteStatusCallCount() != 1 {
+		t.Fatalf("expected 1 status, got %d", fake.WriteStatusCallCount())
+	}
+
+	status := fake.WriteStatusArgsForCall(0)
+
+	if status.Status != types.CancelledStatus || status.Message != "job cancelled before it was started" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Timed out while waiting
+	timed := b.newJob("def", &types.Settings{ID: "def", MaxDuration: "1m", CreatedAt: time.Now().Add(-time.Hour)})
+	b.executor.admit("def", timed.Settings, time.Now())
+
+	b.runAcceptedJob("def", timed)
+
+	if status := fake.WriteStatusArgsForCall(1); status.Status != types.TimedOutStatus ||
+		!strings.Contains(status.Message, "before it was started") {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Jobs that did not start give up their place in the queue
+	if jobs := b.NodeJobs(); jobs.NumRunning != 1 || jobs.NumQueued != 0 {
+		t.Errorf("expected only 'running' to be left, got %+v", jobs)
+	}
+}
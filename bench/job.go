@@ -2,16 +2,39 @@ package bench
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/batchcorp/njst/types"
 )
 
-func (b *Bench) newJob(jobID string) *types.Job {
-	ctx, cancel := context.WithCancel(context.Background())
+// newJob registers a job on this node. The job's context is cancelled once
+// the settings' max_duration (if any) is exceeded; max_duration counts from
+// the time the job was created, so time spent waiting for the node counts
+// towards it.
+func (b *Bench) newJob(jobID string, settings *types.Settings) *types.Job {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if d := maxDuration(settings); d > 0 {
+		deadline := time.Now().Add(d)
+
+		if !settings.CreatedAt.IsZero() {
+			deadline = settings.CreatedAt.Add(d)
+		}
+
+		ctx, cancel = context.WithDeadline(context.Background(), deadline)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
 
 	job := &types.Job{
+		Settings:   settings,
 		Context:    ctx,
 		CancelFunc: cancel,
+		Done:       make(chan struct{}),
 	}
 
 	b.jobsMutex.Lock()
@@ -31,10 +54,95 @@ func (b *Bench) getJob(jobID string) (*types.Job, bool) {
 	return job, ok
 }
 
-// Called from nats handler
-func (b *Bench) deleteJob(id string) {
+// markDeleted remembers that a job not running on this node was deleted; a
+// node that was unresponsive while a job was created may receive the job's
+// create and delete messages in any order once it recovers
+func (b *Bench) markDeleted(jobID string, now time.Time) {
+	b.jobsMutex.Lock()
+	defer b.jobsMutex.Unlock()
+
+	for id, at := range b.deleted {
+		if now.Sub(at) > DeletedJobTTL {
+			delete(b.deleted, id)
+		}
+	}
+
+	b.deleted[jobID] = now
+}
+
+func (b *Bench) wasDeleted(jobID string) bool {
+	b.jobsMutex.RLock()
+	defer b.jobsMutex.RUnlock()
+
+	_, ok := b.deleted[jobID]
+
+	return ok
+}
+
+// finishJob removes a job from memory and lets anyone waiting on job.Done
+// know that it has finished; called once the job's final status was written
+func (b *Bench) finishJob(id string, job *types.Job) {
 	b.jobsMutex.Lock()
 	defer b.jobsMutex.Unlock()
 
-	delete(b.jobs, id)
+	// A newer job with the same ID may have replaced this one
+	if b.jobs[id] == job {
+		delete(b.jobs, id)
+	}
+
+	close(job.Done)
+}
+
+// finalJobStatus returns the status of a job whose workers have all exited.
+// A job that was cancelled (or ran out of time) at any point is cancelled (or
+// timed out), even if its workers happened to finish their share before
+// noticing.
+func finalJobStatus(job *types.Job) types.JobStatus {
+	switch job.Context.Err() {
+	case nil:
+		return types.CompletedStatus
+	case context.DeadlineExceeded:
+		return types.TimedOutStatus
+	default:
+		return types.CancelledStatus
+	}
+}
+
+// maxDuration returns the max_duration of a job or 0 if it has none; settings
+// are validated before jobs are created
+func maxDuration(settings *types.Settings) time.Duration {
+	if settings == nil || settings.MaxDuration == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(settings.MaxDuration)
+	if err != nil {
+		return 0
+	}
+
+	return d
+}
+
+// RunningJobs returns the IDs of all jobs currently running on this node;
+// jobs waiting in the node's queue are not included
+func (b *Bench) RunningJobs() []string {
+	b.jobsMutex.RLock()
+	defer b.jobsMutex.RUnlock()
+
+	ids := make([]string, 0, len(b.jobs))
+
+	for id := range b.jobs {
+		if !b.executor.isQueued(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// NodeJobs returns the jobs this node accepted, running and queued
+func (b *Bench) NodeJobs() *types.NodeJobs {
+	return b.executor.jobs(b.params.NodeID)
 }
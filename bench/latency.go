@@ -0,0 +1,108 @@
+package bench
+
+import (
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+var (
+	// Upper bounds of the latency histogram buckets
+	LatencyBucketsMs = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+)
+
+func newLatencyHistogram() *types.LatencyHistogram {
+	return &types.LatencyHistogram{
+		Buckets: make([]uint64, len(LatencyBucketsMs)+1),
+	}
+}
+
+func observeLatency(h *types.LatencyHistogram, d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+
+	i := 0
+
+	for i < len(LatencyBucketsMs) && ms > LatencyBucketsMs[i] {
+		i++
+	}
+
+	h.Buckets[i]++
+	h.Count++
+	h.SumMs += ms
+
+	if ms > h.MaxMs {
+		h.MaxMs = ms
+	}
+}
+
+// mergeLatency adds src to dst; histograms with an unexpected number of
+// buckets are ignored
+func mergeLatency(dst, src *types.LatencyHistogram) {
+	if src == nil || len(src.Buckets) != len(dst.Buckets) {
+		return
+	}
+
+	for i := range src.Buckets {
+		dst.Buckets[i] += src.Buckets[i]
+	}
+
+	dst.Count += src.Count
+	dst.SumMs += src.SumMs
+
+	if src.MaxMs > dst.MaxMs {
+		dst.MaxMs = src.MaxMs
+	}
+}
+
+func summarizeLatency(h *types.LatencyHistogram) *types.LatencySummary {
+	if h == nil || h.Count == 0 {
+		return nil
+	}
+
+	return &types.LatencySummary{
+		MeanMs: round(h.SumMs/float64(h.Count), 3),
+		P50Ms:  round(latencyPercentile(h, 0.50), 3),
+		P90Ms:  round(latencyPercentile(h, 0.90), 3),
+		P99Ms:  round(latencyPercentile(h, 0.99), 3),
+		MaxMs:  round(h.MaxMs, 3),
+	}
+}
+
+// latencyPercentile estimates the percentile by linear interpolation within
+// the bucket that contains it
+func latencyPercentile(h *types.LatencyHistogram, p float64) float64 {
+	rank := p * float64(h.Count)
+
+	var cumulative float64
+
+	for i, count := range h.Buckets {
+		if count == 0 {
+			continue
+		}
+
+		if cumulative+float64(count) < rank {
+			cumulative += float64(count)
+			continue
+		}
+
+		lower := 0.0
+
+		if i > 0 {
+			lower = LatencyBucketsMs[i-1]
+		}
+
+		upper := h.MaxMs
+
+		if i < len(LatencyBucketsMs) && LatencyBucketsMs[i] < upper {
+			upper = LatencyBucketsMs[i]
+		}
+
+		if upper < lower {
+			return upper
+		}
+
+		return lower + (upper-lower)*(rank-cumulative)/float64(count)
+	}
+
+	return h.MaxMs
+}
@@ -0,0 +1,257 @@
+package bench
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+// ListSortFields are the fields job summaries can be sorted by
+var ListSortFields = []string{"created_at", "description", "status", "throughput", "elapsed"}
+
+// List returns a page of job summaries matching the filter, including
+// archived jobs. Results are only read for the jobs on the page unless the
+// filter or sort order depends on them.
+func (b *Bench) List(filter *types.ListFilter) (*types.BenchList, error) {
+	allSettings, err := b.nats.GetAllSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get settings")
+	}
+
+	summaries := make([]*types.BenchSummary, 0)
+	live := make(map[string]bool, len(allSettings))
+
+	for _, s := range allSettings {
+		live[s.ID] = true
+
+		if matchesSettings(filter, s) {
+			summaries = append(summaries, newSummary(s))
+		}
+	}
+
+	runs, err := b.nats.GetArchivedRuns()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get archived runs")
+	}
+
+	for _, run := range runs {
+		// Archived but not cleaned up yet
+		if live[run.Settings.ID] || !matchesSettings(filter, run.Settings) {
+			continue
+		}
+
+		summary := newSummary(run.Settings)
+		summary.Archived = true
+		setSummaryStatus(summary, run.Status)
+
+		summaries = append(summaries, summary)
+	}
+
+	field, desc := sortField(filter.Sort)
+
+	if len(filter.Statuses) > 0 || field == "status" || field == "throughput" || field == "elapsed" {
+		filtered := make([]*types.BenchSummary, 0, len(summaries))
+
+		for _, s := range summaries {
+			if s.Status == "" {
+				b.fillSummary(s)
+			}
+
+			if len(filter.Statuses) == 0 || containsStatus(filter.Statuses, s.Status) {
+				filtered = append(filtered, s)
+			}
+		}
+
+		summaries = filtered
+	}
+
+	sortSummaries(summaries, field, desc)
+
+	list := &types.BenchList{
+		Total:      len(summaries),
+		Offset:     filter.Offset,
+		Limit:      filter.Limit,
+		Benchmarks: make([]*types.BenchSummary, 0),
+	}
+
+	if filter.Offset >= len(summaries) {
+		return list, nil
+	}
+
+	end := len(summaries)
+
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+
+	list.Benchmarks = summaries[filter.Offset:end]
+
+	for _, s := range list.Benchmarks {
+		if s.Status == "" {
+			b.fillSummary(s)
+		}
+	}
+
+	return list, nil
+}
+
+// matchesSettings applies the parts of the filter that only need the job's
+// settings
+func matchesSettings(filter *types.ListFilter, settings *types.Settings) bool {
+	if filter.Type != "" && jobType(settings) != filter.Type {
+		return false
+	}
+
+	if filter.Description != "" &&
+		!strings.Contains(strings.ToLower(settings.Description), strings.ToLower(filter.Description)) {
+		return false
+	}
+
+	if filter.Profile != "" && settings.Profile != filter.Profile {
+		return false
+	}
+
+	if filter.Owner != "" && settings.Owner != filter.Owner {
+		return false
+	}
+
+	for k, v := range filter.Tags {
+		if tag, ok := settings.Tags[k]; !ok || (v != "" && tag != v) {
+			return false
+		}
+	}
+
+	if !filter.Since.IsZero() && settings.CreatedAt.Before(filter.Since) {
+		return false
+	}
+
+	if !filter.Until.IsZero() && !settings.CreatedAt.Before(filter.Until) {
+		return false
+	}
+
+	return true
+}
+
+func newSummary(settings *types.Settings) *types.BenchSummary {
+	return &types.BenchSummary{
+		ID:          settings.ID,
+		Description: settings.Description,
+		Type:        jobType(settings),
+		Profile:     settings.Profile,
+		Owner:       settings.Owner,
+		Tags:        settings.Tags,
+		ScheduleID:  settings.ScheduleID,
+		CreatedAt:   settings.CreatedAt,
+		NumStreams:  numStreams(settings),
+		NumNodes:    len(settings.Participants),
+	}
+}
+
+// fillSummary sets the fields of a summary that come from the job's status
+func (b *Bench) fillSummary(summary *types.BenchSummary) {
+	status, err := b.Status(summary.ID)
+	if err != nil {
+		b.log.Debugf("unable to get status of job '%s': %s", summary.ID, err)
+		summary.Status = types.UnknownStatus
+
+		return
+	}
+
+	setSummaryStatus(summary, status)
+}
+
+func setSummaryStatus(summary *types.BenchSummary, status *types.Status) {
+	summary.Status = status.Status
+	summary.ElapsedSeconds = status.ElapsedSeconds
+	summary.TotalMsgPerSecAllNodes = status.TotalMsgPerSecAllNodes
+
+	if status.Verdict != nil {
+		passed := status.Verdict.Passed
+		summary.Passed = &passed
+	}
+}
+
+// sortField splits a sort order into the field and whether it is descending;
+// jobs are listed newest first by default
+func sortField(order string) (string, bool) {
+	if order == "" {
+		return "created_at", true
+	}
+
+	if strings.HasPrefix(order, "-") {
+		return order[1:], true
+	}
+
+	return order, false
+}
+
+// sortSummaries sorts by the given field (see ListSortFields); ties are
+// broken by ID so that pages are stable
+func sortSummaries(summaries []*types.BenchSummary, field string, desc bool) {
+	less := func(a, b *types.BenchSummary) bool {
+		switch field {
+		case "description":
+			if a.Description != b.Description {
+				return a.Description < b.Description
+			}
+		case "status":
+			if a.Status != b.Status {
+				return a.Status < b.Status
+			}
+		case "throughput":
+			if a.TotalMsgPerSecAllNodes != b.TotalMsgPerSecAllNodes {
+				return a.TotalMsgPerSecAllNodes < b.TotalMsgPerSecAllNodes
+			}
+		case "elapsed":
+			if a.ElapsedSeconds != b.ElapsedSeconds {
+				return a.ElapsedSeconds < b.ElapsedSeconds
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+
+		return a.ID < b.ID
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if desc {
+			return less(summaries[j], summaries[i])
+		}
+
+		return less(summaries[i], summaries[j])
+	})
+}
+
+func jobType(settings *types.Settings) string {
+	if settings.Write != nil {
+		return "write"
+	}
+
+	return "read"
+}
+
+func numStreams(settings *types.Settings) int {
+	if settings.Write != nil {
+		return settings.Write.NumStreams
+	}
+
+	if settings.Read != nil {
+		return settings.Read.NumStreams
+	}
+
+	return 0
+}
+
+func containsStatus(statuses []types.JobStatus, status types.JobStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,219 @@
+package bench
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestList(t *testing.T) {
+	created := time.Date(2022, 5, 25, 12, 0, 0, 0, time.UTC)
+
+	allSettings := []*types.Settings{
+		{ID: "a", Description: "nightly run", Write: &types.WriteSettings{NumStreams: 2}, Participants: []string{"node1"},
+			Owner: "jane", Tags: map[string]string{"eval": "nats-2.10", "env": "staging"}, CreatedAt: created},
+		{ID: "b", Description: "ad hoc", Read: &types.ReadSettings{NumStreams: 1}, Participants: []string{"node1"},
+			Owner: "joe", Tags: map[string]string{"eval": "nats-2.9"}, CreatedAt: created.Add(time.Hour)},
+		{ID: "c", Description: "Nightly, big", Write: &types.WriteSettings{}, Participants: []string{"node1"},
+			Profile: "big", CreatedAt: created.Add(2 * time.Hour)},
+		{ID: "d", Description: "results deleted", Write: &types.WriteSettings{}, Participants: []string{"node1"},
+			CreatedAt: created.Add(3 * time.Hour)},
+	}
+
+	statuses := map[string]*types.Status{
+		"a": {NodeID: "node1", Status: types.CompletedStatus, AvgMsgPerSecPerNode: 100},
+		"b": {NodeID: "node1", Status: types.InProgressStatus},
+		"c": {NodeID: "node1", Status: types.CompletedStatus, AvgMsgPerSecPerNode: 300},
+	}
+
+	tests := []struct {
+		name     string
+		filter   *types.ListFilter
+		expected []string
+		total    int
+		lookups  int // jobs whose results are read
+	}{
+		{"newest first", &types.ListFilter{}, []string{"d", "c", "b", "a"}, 4, 4},
+		{"page", &types.ListFilter{Offset: 1, Limit: 2}, []string{"c", "b"}, 4, 2},
+		{"past the end", &types.ListFilter{Offset: 4, Limit: 2}, []string{}, 4, 0},
+		{"oldest first", &types.ListFilter{Sort: "created_at", Limit: 1}, []string{"a"}, 4, 1},
+		{"type", &types.ListFilter{Type: "read"}, []string{"b"}, 1, 1},
+		{"description", &types.ListFilter{Description: "NIGHTLY"}, []string{"c", "a"}, 2, 2},
+		{"profile", &types.ListFilter{Profile: "big"}, []string{"c"}, 1, 1},
+		{"owner", &types.ListFilter{Owner: "joe"}, []string{"b"}, 1, 1},
+		{"tag", &types.ListFilter{Tags: map[string]string{"eval": "nats-2.10"}}, []string{"a"}, 1, 1},
+		{"tag key", &types.ListFilter{Tags: map[string]string{"eval": ""}}, []string{"b", "a"}, 2, 2},
+		{"tags", &types.ListFilter{Tags: map[string]string{"eval": "", "env": "prod"}}, []string{}, 0, 0},
+		{"time range", &types.ListFilter{Since: created.Add(time.Hour), Until: created.Add(3 * time.Hour)},
+			[]string{"c", "b"}, 2, 2},
+		{"status", &types.ListFilter{Statuses: []types.JobStatus{types.CompletedStatus}, Limit: 1}, []string{"c"}, 2, 4},
+		{"unknown status", &types.ListFilter{Statuses: []types.JobStatus{types.UnknownStatus}}, []string{"d"}, 1, 4},
+		{"throughput", &types.ListFilter{Sort: "-throughput", Limit: 2}, []string{"c", "a"}, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetAllSettingsReturns(allSettings, nil)
+
+			fake.GetSettingsStub = func(id string) (*types.Settings, error) {
+				for _, s := range allSettings {
+					if s.ID == id {
+						return s, nil
+					}
+				}
+
+				return nil, nats.ErrKeyNotFound
+			}
+
+			fake.GetStatusesStub = func(id string) ([]*types.Status, error) {
+				if s, ok := statuses[id]; ok {
+					return []*types.Status{s}, nil
+				}
+
+				return nil, nats.ErrBucketNotFound
+			}
+
+			list, err := b.List(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			ids := make([]string, 0)
+
+			for _, s := range list.Benchmarks {
+				ids = append(ids, s.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+
+			if list.Total != tt.total {
+				t.Errorf("expected total %d, got %d", tt.total, list.Total)
+			}
+
+			if n := fake.GetStatusesCallCount(); n != tt.lookups {
+				t.Errorf("expected results of %d job(s) to be read, got %d", tt.lookups, n)
+			}
+		})
+	}
+}
+
+func TestListSummary(t *testing.T) {
+	b, fake := newTestBench(t)
+	minMsgsPerSec := float64(500)
+
+	settings := &types.Settings{
+		ID:           "a",
+		Description:  "nightly",
+		Write:        &types.WriteSettings{NumStreams: 2},
+		Profile:      "p1",
+		Owner:        "jane",
+		Tags:         map[string]string{"eval": "nats-2.10"},
+		Participants: []string{"node1", "node2"},
+		Expect:       &types.Expect{MinTotalMsgsPerSec: &minMsgsPerSec},
+		CreatedAt:    time.Date(2022, 5, 25, 12, 0, 0, 0, time.UTC),
+	}
+
+	fake.GetAllSettingsReturns([]*types.Settings{settings}, nil)
+	fake.GetSettingsReturns(settings, nil)
+	fake.GetStatusesReturns([]*types.Status{
+		{NodeID: "node1", Status: types.CompletedStatus, AvgMsgPerSecPerNode: 100, ElapsedSeconds: 10},
+		{NodeID: "node2", Status: types.CompletedStatus, AvgMsgPerSecPerNode: 100, ElapsedSeconds: 12},
+	}, nil)
+
+	list, err := b.List(&types.ListFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(list.Benchmarks) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(list.Benchmarks))
+	}
+
+	s := list.Benchmarks[0]
+
+	if s.ID != "a" || s.Type != "write" || s.Profile != "p1" || s.Owner != "jane" || s.Tags["eval"] != "nats-2.10" ||
+		s.NumStreams != 2 || s.NumNodes != 2 ||
+		!s.CreatedAt.Equal(settings.CreatedAt) || s.Status != types.CompletedStatus {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	if s.TotalMsgPerSecAllNodes != 200 {
+		t.Errorf("expected 200 msgs/sec, got %f", s.TotalMsgPerSecAllNodes)
+	}
+
+	if s.Passed == nil || *s.Passed {
+		t.Error("expected the job to have failed its assertions")
+	}
+}
+
+func TestListArchived(t *testing.T) {
+	b, fake := newTestBench(t)
+	created := time.Date(2022, 5, 25, 12, 0, 0, 0, time.UTC)
+
+	live := &types.Settings{ID: "b", Write: &types.WriteSettings{}, Participants: []string{"node1"}, CreatedAt: created.Add(time.Hour)}
+
+	fake.GetAllSettingsReturns([]*types.Settings{live}, nil)
+	fake.GetSettingsReturns(live, nil)
+	fake.GetStatusesReturns([]*types.Status{{NodeID: "node1", Status: types.InProgressStatus}}, nil)
+	fake.GetArchivedRunsReturns([]*types.ArchivedRun{
+		{
+			Settings: &types.Settings{ID: "a", Owner: "jane", Write: &types.WriteSettings{}, CreatedAt: created},
+			Status:   &types.Status{Status: types.CompletedStatus, TotalMsgPerSecAllNodes: 100},
+		},
+		{
+			Settings: &types.Settings{ID: "c", Owner: "joe", Read: &types.ReadSettings{}, CreatedAt: created.Add(2 * time.Hour)},
+			Status:   &types.Status{Status: types.FailedStatus},
+		},
+		// Archived, but its settings were not deleted yet
+		{
+			Settings: live,
+			Status:   &types.Status{Status: types.CompletedStatus},
+		},
+	}, nil)
+
+	list, err := b.List(&types.ListFilter{Sort: "-throughput"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ids := make([]string, 0)
+
+	for _, s := range list.Benchmarks {
+		ids = append(ids, s.ID)
+	}
+
+	if !reflect.DeepEqual(ids, []string{"a", "c", "b"}) {
+		t.Fatalf("expected [a c b], got %v", ids)
+	}
+
+	if s := list.Benchmarks[0]; !s.Archived || s.Status != types.CompletedStatus || s.TotalMsgPerSecAllNodes != 100 {
+		t.Errorf("unexpected archived summary %+v", s)
+	}
+
+	if s := list.Benchmarks[2]; s.Archived || s.Status != types.InProgressStatus {
+		t.Errorf("unexpected live summary %+v", s)
+	}
+
+	// Only the live job's results are read
+	if n := fake.GetStatusesCallCount(); n != 1 {
+		t.Errorf("expected results of 1 job to be read, got %d", n)
+	}
+
+	// Filters apply to archived jobs too
+	list, err = b.List(&types.ListFilter{Owner: "joe"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if list.Total != 1 || list.Benchmarks[0].ID != "c" {
+		t.Errorf("expected only 'c', got %+v", list.Benchmarks)
+	}
+}
@@ -0,0 +1,264 @@
+package bench
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// How often nodes look up the cluster lock and claim it for the exclusive
+	// job at the front of their queue
+	LockSyncInterval = time.Second
+
+	// The node creating an exclusive job acquires the lock before the job's
+	// settings are saved; a lock whose job has no settings is only released
+	// once it is older than this
+	LockGracePeriod = time.Minute
+)
+
+// runLockSync periodically lets the executor know which job holds the cluster
+// lock and which jobs are running in the cluster
+func (b *Bench) runLockSync() {
+	ticker := time.NewTicker(LockSyncInterval)
+
+	for range ticker.C {
+		b.syncLock(time.Now().UTC())
+	}
+}
+
+// syncLock claims the cluster lock for the exclusive job at the front of this
+// node's queue if no job holds it and updates the executor. The jobs running
+// in the cluster are only looked up if the job holding the lock is waiting
+// on this node.
+func (b *Bench) syncLock(now time.Time) {
+	lock, err := b.nats.GetLock()
+	if err != nil {
+		b.log.Errorf("unable to get cluster lock: %s", err)
+		return
+	}
+
+	if lock == nil {
+		if jobID := b.executor.exclusiveHead(); jobID != "" {
+			lock, err = b.acquireLock(jobID, now)
+			if err != nil {
+				b.log.Errorf("unable to acquire cluster lock for job '%s': %s", jobID, err)
+				return
+			}
+		}
+	}
+
+	var jobs []string
+
+	if lock != nil && b.executor.isQueued(lock.JobID) {
+		nodes, err := b.nats.GetNodes()
+		if err != nil {
+			b.log.Errorf("unable to get nodes: %s", err)
+			return
+		}
+
+		jobs = clusterJobs(nodes)
+	}
+
+	b.executor.setCluster(lock, jobs, now)
+}
+
+// acquireLock acquires the cluster lock for a job; if another node got there
+// first, the lock it acquired is returned instead
+func (b *Bench) acquireLock(jobID string, now time.Time) (*types.ClusterLock, error) {
+	lock := &types.ClusterLock{
+		JobID:      jobID,
+		NodeID:     b.params.NodeID,
+		AcquiredAt: now,
+	}
+
+	if err := b.nats.AcquireLock(lock); err != nil {
+		held, getErr := b.nats.GetLock()
+		if getErr != nil || held == nil {
+			return nil, err
+		}
+
+		return held, nil
+	}
+
+	b.log.Infof("Acquired cluster lock for exclusive job '%s'", jobID)
+
+	return lock, nil
+}
+
+// releaseLock releases the cluster lock if it is held by the job
+func (b *Bench) releaseLock(jobID string) error {
+	lock, err := b.nats.GetLock()
+	if err != nil {
+		return err
+	}
+
+	if lock == nil || lock.JobID != jobID {
+		return nil
+	}
+
+	if err := b.nats.ReleaseLock(lock); err != nil {
+		return err
+	}
+
+	b.log.Infof("Released cluster lock held by job '%s'", jobID)
+
+	return nil
+}
+
+// checkLock releases the cluster lock once every participant of the job
+// holding it reported a final status or if the job no longer exists; run by
+// the watchdog
+func (b *Bench) checkLock(now time.Time) error {
+	lock, err := b.nats.GetLock()
+	if err != nil {
+		return errors.Wrap(err, "unable to get cluster lock")
+	}
+
+	if lock == nil {
+		return nil
+	}
+
+	settings, err := b.nats.GetSettings(lock.JobID)
+	if err != nil {
+		if errors.Cause(err) != nats.ErrKeyNotFound {
+			return errors.Wrap(err, "unable to get settings")
+		}
+
+		if now.Sub(lock.AcquiredAt) < LockGracePeriod {
+			return nil
+		}
+
+		b.log.Warningf("watchdog: job '%s' holding the cluster lock does not exist", lock.JobID)
+
+		return b.releaseStaleLock(lock)
+	}
+
+	statuses, err := b.nats.GetStatuses(lock.JobID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get statuses")
+	}
+
+	final := make(map[string]bool, len(statuses))
+
+	for _, s := range statuses {
+		final[s.NodeID] = isFinal(s.Status)
+	}
+
+	for _, nodeID := range settings.Participants {
+		if !final[nodeID] {
+			return nil
+		}
+	}
+
+	b.log.Infof("watchdog: job '%s' is final; releasing the cluster lock", lock.JobID)
+
+	return b.releaseStaleLock(lock)
+}
+
+// releaseStaleLock releases a lock read by the watchdog; every node runs the
+// watchdog, so another node releasing it first is not an error
+func (b *Bench) releaseStaleLock(lock *types.ClusterLock) error {
+	err := b.nats.ReleaseLock(lock)
+	if err == nil {
+		return nil
+	}
+
+	current, getErr := b.nats.GetLock()
+	if getErr == nil && (current == nil || current.Revision != lock.Revision) {
+		return nil
+	}
+
+	return err
+}
+
+// lockForCreate checks a job that is about to be created against the cluster
+// lock and returns the conflict that keeps it from being created, if any.
+// Exclusive jobs acquire the lock here; with on_conflict "reject" they also
+// conflict with any job that is running. Jobs with on_conflict "queue" never
+// conflict: their nodes hold them back until the lock is free (and claim it
+// for exclusive jobs).
+func (b *Bench) lockForCreate(settings *types.Settings, now time.Time) (*types.Conflict, error) {
+	queue := settings.OnConflict == types.QueueConflictPolicy
+
+	lock, err := b.nats.GetLock()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get cluster lock")
+	}
+
+	if !settings.Exclusive {
+		if lock != nil && !queue {
+			return &types.Conflict{Lock: lock}, nil
+		}
+
+		return nil, nil
+	}
+
+	if lock == nil {
+		lock, err = b.acquireLock(settings.ID, now)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to acquire cluster lock")
+		}
+	}
+
+	if queue {
+		return nil, nil
+	}
+
+	if lock.JobID != settings.ID {
+		return &types.Conflict{Lock: lock}, nil
+	}
+
+	nodes, err := b.nats.GetNodes()
+	if err != nil {
+		b.releaseLockAfterCreate(settings.ID)
+		return nil, errors.Wrap(err, "unable to get nodes")
+	}
+
+	if jobs := clusterJobs(nodes); len(jobs) > 0 {
+		b.releaseLockAfterCreate(settings.ID)
+		return &types.Conflict{Jobs: jobs}, nil
+	}
+
+	return nil, nil
+}
+
+// releaseLockAfterCreate releases the lock of an exclusive job that could
+// not be created
+func (b *Bench) releaseLockAfterCreate(jobID string) {
+	if err := b.releaseLock(jobID); err != nil {
+		b.log.Warningf("unable to release cluster lock held by job '%s': %s", jobID, err)
+	}
+}
+
+// conflictError describes why a job conflicts with the cluster lock
+func conflictError(conflict *types.Conflict) error {
+	if conflict.Lock != nil {
+		return errors.Errorf("cluster is locked by exclusive job '%s' (since %s); set on_conflict to %q to wait for it",
+			conflict.Lock.JobID, conflict.Lock.AcquiredAt.Format(time.RFC3339), types.QueueConflictPolicy)
+	}
+
+	return errors.Errorf("cluster is busy: %d job(s) running (%s); set on_conflict to %q to wait for them",
+		len(conflict.Jobs), strings.Join(conflict.Jobs, ", "), types.QueueConflictPolicy)
+}
+
+// clusterJobs returns the IDs of the jobs running on any node
+func clusterJobs(nodes []*types.NodeInfo) []string {
+	jobs := make([]string, 0)
+
+	for _, n := range nodes {
+		for _, id := range n.Jobs {
+			if !sliceContains(jobs, id) {
+				jobs = append(jobs, id)
+			}
+		}
+	}
+
+	sort.Strings(jobs)
+
+	return jobs
+}
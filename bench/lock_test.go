@@ -0,0 +1,262 @@
+package bench
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestLockForCreate(t *testing.T) {
+	other := &types.ClusterLock{JobID: "other"}
+	busy := []*types.NodeInfo{{ID: "node1", Jobs: []string{"x"}}, {ID: "node2", Jobs: []string{"x", "y"}}}
+
+	tests := []struct {
+		name       string
+		exclusive  bool
+		onConflict types.ConflictPolicy
+		lock       *types.ClusterLock
+		nodes      []*types.NodeInfo
+		conflict   *types.Conflict
+		acquired   bool
+		released   bool
+	}{
+		{name: "unlocked"},
+		{name: "locked", lock: other, conflict: &types.Conflict{Lock: other}},
+		{name: "locked, queue", lock: other, onConflict: types.QueueConflictPolicy},
+		{name: "exclusive", exclusive: true, nodes: []*types.NodeInfo{{ID: "node1"}}, acquired: true},
+		{name: "exclusive, busy", exclusive: true, nodes: busy, acquired: true, released: true,
+			conflict: &types.Conflict{Jobs: []string{"x", "y"}}},
+		{name: "exclusive, busy, queue", exclusive: true, onConflict: types.QueueConflictPolicy, nodes: busy,
+			acquired: true},
+		{name: "exclusive, locked", exclusive: true, lock: other, conflict: &types.Conflict{Lock: other}},
+		{name: "exclusive, locked, queue", exclusive: true, onConflict: types.QueueConflictPolicy, lock: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			var held *types.ClusterLock
+
+			if tt.lock != nil {
+				held = tt.lock
+			}
+
+			fake.GetLockStub = func() (*types.ClusterLock, error) {
+				return held, nil
+			}
+
+			fake.AcquireLockStub = func(lock *types.ClusterLock) error {
+				held = lock
+				return nil
+			}
+
+			fake.ReleaseLockStub = func(*types.ClusterLock) error {
+				held = nil
+				return nil
+			}
+
+			fake.GetNodesReturns(tt.nodes, nil)
+
+			settings := &types.Settings{ID: "abc", Exclusive: tt.exclusive, OnConflict: tt.onConflict}
+
+			conflict, err := b.lockForCreate(settings, time.Now())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(conflict, tt.conflict) {
+				t.Errorf("expected conflict %+v, got %+v", tt.conflict, conflict)
+			}
+
+			if acquired := fake.AcquireLockCallCount() == 1; acquired != tt.acquired {
+				t.Errorf("expected lock to be acquired: %v", tt.acquired)
+			}
+
+			if released := fake.ReleaseLockCallCount() == 1; released != tt.released {
+				t.Errorf("expected lock to be released: %v", tt.released)
+			}
+		})
+	}
+}
+
+func TestCreateConflict(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetLockReturns(&types.ClusterLock{JobID: "other", AcquiredAt: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)}, nil)
+
+	settings := &types.Settings{
+		NATS:  &types.NATS{Address: "localhost:4222"},
+		Write: &types.WriteSettings{NumStreams: 1, NumMessagesPerStream: 100, NumWorkersPerStream: 1, Subjects: []string{"foo"}},
+	}
+
+	resp, err := b.Create(settings)
+	checkErr(t, err, "cluster is locked by exclusive job 'other' (since 2022-01-01T12:00:00Z)")
+
+	if resp == nil || resp.Conflict == nil || resp.Conflict.Lock.JobID != "other" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	if fake.AddStreamCallCount() != 0 || fake.RequestJobCallCount() != 0 {
+		t.Error("a conflicting job should not be dispatched")
+	}
+
+	// An exclusive job that fails to be created releases the lock
+	b, fake = newTestBench(t)
+
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1"}}, nil)
+	fake.GetNodeListReturns(nil, errors.New("no nodes"))
+
+	settings.Exclusive = true
+	settings.ID = ""
+
+	fake.GetLockStub = func() (*types.ClusterLock, error) {
+		if fake.AcquireLockCallCount() == 0 {
+			return nil, nil
+		}
+
+		return fake.AcquireLockArgsForCall(0), nil
+	}
+
+	if _, err := b.Create(settings); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if fake.ReleaseLockCallCount() != 1 || fake.ReleaseLockArgsForCall(0).JobID != settings.ID {
+		t.Error("expected the lock to be released")
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name     string
+		lock     *types.ClusterLock
+		settings *types.Settings
+		statuses []*types.Status
+		released bool
+	}{
+		{name: "no lock"},
+		{
+			name: "job being created",
+			lock: &types.ClusterLock{JobID: "abc", AcquiredAt: now.Add(-time.Second)},
+		},
+		{
+			name:     "job does not exist",
+			lock:     &types.ClusterLock{JobID: "abc", AcquiredAt: now.Add(-LockGracePeriod)},
+			released: true,
+		},
+		{
+			name:     "job running",
+			lock:     &types.ClusterLock{JobID: "abc", AcquiredAt: now.Add(-time.Hour)},
+			settings: &types.Settings{ID: "abc", Participants: []string{"node1", "node2"}},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.CompletedStatus},
+				{NodeID: "node2", Status: types.InProgressStatus},
+			},
+		},
+		{
+			name:     "job final",
+			lock:     &types.ClusterLock{JobID: "abc", AcquiredAt: now.Add(-time.Hour)},
+			settings: &types.Settings{ID: "abc", Participants: []string{"node1", "node2"}},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.CompletedStatus},
+				{NodeID: "node2", Status: types.CancelledStatus},
+			},
+			released: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetLockReturns(tt.lock, nil)
+			fake.GetStatusesReturns(tt.statuses, nil)
+
+			if tt.settings != nil {
+				fake.GetSettingsReturns(tt.settings, nil)
+			} else {
+				fake.GetSettingsReturns(nil, nats.ErrKeyNotFound)
+			}
+
+			if err := b.checkLock(now); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if released := fake.ReleaseLockCallCount() == 1; released != tt.released {
+				t.Errorf("expected lock to be released: %v", tt.released)
+			}
+		})
+	}
+
+	// Every node runs the watchdog; losing the race to release the lock is
+	// not an error
+	b, fake := newTestBench(t)
+
+	fake.GetLockReturnsOnCall(0, &types.ClusterLock{JobID: "abc", AcquiredAt: now.Add(-time.Hour), Revision: 3}, nil)
+	fake.GetLockReturnsOnCall(1, nil, nil)
+	fake.GetSettingsReturns(nil, nats.ErrKeyNotFound)
+	fake.ReleaseLockReturns(errors.New("nats: wrong last sequence: 4"))
+
+	if err := b.checkLock(now); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestSyncLock(t *testing.T) {
+	b, fake := newTestBench(t)
+	now := time.Now()
+
+	b.executor.admit("running", &types.Settings{}, now)
+	b.executor.admit("abc", &types.Settings{Exclusive: true}, now)
+
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1", Jobs: []string{"running"}}, {ID: "node2"}}, nil)
+
+	// The node claims the lock for the exclusive job at the front of its
+	// queue, which then waits for the running job to finish
+	b.syncLock(now)
+
+	if fake.AcquireLockCallCount() != 1 || fake.AcquireLockArgsForCall(0).JobID != "abc" {
+		t.Fatal("expected the lock to be acquired for 'abc'")
+	}
+
+	if fake.GetNodesCallCount() != 1 {
+		t.Error("expected the jobs running in the cluster to be looked up")
+	}
+
+	b.executor.done("running")
+
+	if !b.executor.isQueued("abc") {
+		t.Fatal("expected 'abc' to wait for the next sync")
+	}
+
+	fake.GetLockReturns(fake.AcquireLockArgsForCall(0), nil)
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1"}, {ID: "node2"}}, nil)
+
+	b.syncLock(now)
+
+	if b.executor.isQueued("abc") {
+		t.Error("expected 'abc' to start")
+	}
+
+	// Another node got the lock first
+	b, fake = newTestBench(t)
+
+	b.executor.admit("def", &types.Settings{Exclusive: true}, now)
+
+	fake.AcquireLockReturns(errors.New("wrong last sequence"))
+	fake.GetLockReturnsOnCall(1, &types.ClusterLock{JobID: "abc"}, nil)
+
+	b.syncLock(now)
+
+	if msg := b.executor.queued("def"); msg != "job queued at position 1: waiting for exclusive job 'abc' to release the cluster lock" {
+		t.Errorf("unexpected message '%s'", msg)
+	}
+}
@@ -0,0 +1,234 @@
+package bench
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	// How long metrics of a finished job are still exposed
+	MetricsRetention = 10 * time.Minute
+
+	PublishErrorCategory    = "publish"
+	AckErrorCategory        = "ack"
+	TimeoutErrorCategory    = "timeout"
+	ConnectionErrorCategory = "connection"
+	SubscribeErrorCategory  = "subscribe"
+	FetchErrorCategory      = "fetch"
+)
+
+var (
+	// Error categories that can occur for each job type
+	errorCategories = map[string][]string{
+		"write": {PublishErrorCategory, AckErrorCategory, TimeoutErrorCategory, ConnectionErrorCategory},
+		"read":  {SubscribeErrorCategory, FetchErrorCategory, TimeoutErrorCategory, ConnectionErrorCategory},
+	}
+)
+
+// metricsRegistry holds live metrics for all jobs that ran on this node.
+// Counters are updated by workers as they go (unlike Worker counters which
+// are only read by the reporter once a second).
+type metricsRegistry struct {
+	jobs  map[string]*jobMetrics
+	mutex *sync.RWMutex
+}
+
+type jobMetrics struct {
+	workers int64 // first for 64-bit alignment of atomic ops on 32-bit platforms
+
+	jobID       string
+	description string
+	jobType     string
+	streams     map[string]*streamMetrics // read-only after creation
+	endedAt     time.Time
+
+	latency      *types.LatencyHistogram
+	latencyMutex *sync.Mutex
+}
+
+type jobCounters struct {
+	messages uint64
+	bytes    uint64
+	errors   uint64
+	latency  *types.LatencyHistogram
+}
+
+type streamMetrics struct {
+	messages uint64
+	bytes    uint64
+	errors   map[string]*uint64 // read-only after creation
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		jobs:  make(map[string]*jobMetrics),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// startJob registers (or resets) the metrics for a job that is about to start
+func (m *metricsRegistry) startJob(settings *types.Settings, jobType string, streams []string) *jobMetrics {
+	jm := &jobMetrics{
+		jobID:        settings.ID,
+		description:  settings.Description,
+		jobType:      jobType,
+		streams:      make(map[string]*streamMetrics),
+		latency:      newLatencyHistogram(),
+		latencyMutex: &sync.Mutex{},
+	}
+
+	for _, stream := range streams {
+		sm := &streamMetrics{
+			errors: make(map[string]*uint64),
+		}
+
+		for _, category := range errorCategories[jobType] {
+			sm.errors[category] = new(uint64)
+		}
+
+		jm.streams[stream] = sm
+	}
+
+	m.mutex.Lock()
+	m.jobs[settings.ID] = jm
+	m.mutex.Unlock()
+
+	return jm
+}
+
+func (m *metricsRegistry) getJob(jobID string) *jobMetrics {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.jobs[jobID]
+}
+
+func (m *metricsRegistry) finishJob(jm *jobMetrics) {
+	m.mutex.Lock()
+	jm.endedAt = time.Now()
+	m.mutex.Unlock()
+}
+
+// snapshot returns the current metrics of all jobs; metrics of jobs that
+// finished more than MetricsRetention ago are dropped
+func (m *metricsRegistry) snapshot() []*types.JobMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	jobs := make([]*types.JobMetrics, 0, len(m.jobs))
+
+	for id, jm := range m.jobs {
+		if !jm.endedAt.IsZero() && time.Since(jm.endedAt) > MetricsRetention {
+			delete(m.jobs, id)
+			continue
+		}
+
+		jobs = append(jobs, jm.snapshot())
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].JobID < jobs[j].JobID
+	})
+
+	return jobs
+}
+
+func (jm *jobMetrics) snapshot() *types.JobMetrics {
+	s := &types.JobMetrics{
+		JobID:       jm.jobID,
+		Description: jm.description,
+		Type:        jm.jobType,
+		Running:     jm.endedAt.IsZero(),
+		Workers:     int(atomic.LoadInt64(&jm.workers)),
+		Streams:     make([]*types.StreamMetrics, 0, len(jm.streams)),
+	}
+
+	for name, sm := range jm.streams {
+		ss := &types.StreamMetrics{
+			Stream:   name,
+			Messages: atomic.LoadUint64(&sm.messages),
+			Bytes:    atomic.LoadUint64(&sm.bytes),
+			Errors:   make(map[string]uint64),
+		}
+
+		for category, n := range sm.errors {
+			ss.Errors[category] = atomic.LoadUint64(n)
+		}
+
+		s.Streams = append(s.Streams, ss)
+	}
+
+	sort.Slice(s.Streams, func(i, j int) bool {
+		return s.Streams[i].Stream < s.Streams[j].Stream
+	})
+
+	jm.latencyMutex.Lock()
+	s.Latency = newLatencyHistogram()
+	mergeLatency(s.Latency, jm.latency)
+	jm.latencyMutex.Unlock()
+
+	return s
+}
+
+// counters returns the job's totals across all streams; unlike snapshot() it
+// is safe to call while the job is finishing
+func (jm *jobMetrics) counters() *jobCounters {
+	c := &jobCounters{
+		latency: newLatencyHistogram(),
+	}
+
+	for _, sm := range jm.streams {
+		c.messages += atomic.LoadUint64(&sm.messages)
+		c.bytes += atomic.LoadUint64(&sm.bytes)
+
+		for _, n := range sm.errors {
+			c.errors += atomic.LoadUint64(n)
+		}
+	}
+
+	jm.latencyMutex.Lock()
+	mergeLatency(c.latency, jm.latency)
+	jm.latencyMutex.Unlock()
+
+	return c
+}
+
+func (jm *jobMetrics) workerStarted() {
+	atomic.AddInt64(&jm.workers, 1)
+}
+
+func (jm *jobMetrics) workerDone() {
+	atomic.AddInt64(&jm.workers, -1)
+}
+
+func (jm *jobMetrics) observeLatency(d time.Duration) {
+	jm.latencyMutex.Lock()
+	observeLatency(jm.latency, d)
+	jm.latencyMutex.Unlock()
+}
+
+func (sm *streamMetrics) addMessages(n, bytes int) {
+	atomic.AddUint64(&sm.messages, uint64(n))
+	atomic.AddUint64(&sm.bytes, uint64(bytes))
+}
+
+func (sm *streamMetrics) addError(category string) {
+	if n, ok := sm.errors[category]; ok {
+		atomic.AddUint64(n, 1)
+	}
+}
+
+// Metrics returns a snapshot of this node's live job metrics
+func (b *Bench) Metrics() *types.NodeMetrics {
+	return &types.NodeMetrics{
+		NodeID:              b.params.NodeID,
+		RunningJobs:         len(b.RunningJobs()),
+		QueuedJobs:          b.NodeJobs().NumQueued,
+		NATSConnectionState: b.nats.ConnectionState(),
+		Jobs:                b.metrics.snapshot(),
+	}
+}
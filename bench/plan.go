@@ -0,0 +1,160 @@
+package bench
+
+import (
+	"fmt"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+// newPlans splits the messages of a job between nodes, workers and subjects.
+// Each stream is split the same way: first between subjects, then each
+// subject's share between all workers of all nodes. Shares differ by at most
+// one message and always add up to numMessagesPerStream; the workers that get
+// an extra message rotate between subjects so that no node or worker is
+// always the one that gets the remainder.
+func newPlans(numStreams, numMessagesPerStream, numSubjects, numNodes, numWorkers int) ([]*types.Plan, error) {
+	if numStreams < 1 || numSubjects < 1 || numNodes < 1 || numWorkers < 1 {
+		return nil, errors.Errorf("unable to plan job with %d stream(s), %d subject(s), %d node(s) and %d worker(s)",
+			numStreams, numSubjects, numNodes, numWorkers)
+	}
+
+	if numMessagesPerStream < 0 {
+		return nil, errors.New("number of messages per stream cannot be negative")
+	}
+
+	plans := make([]*types.Plan, numNodes)
+
+	for i := range plans {
+		plans[i] = &types.Plan{
+			NodeIndex: i,
+			Workers:   make([][]int, numWorkers),
+		}
+
+		for w := range plans[i].Workers {
+			plans[i].Workers[w] = make([]int, numSubjects)
+		}
+	}
+
+	numSlots := numNodes * numWorkers
+	offset := 0
+
+	for s, subjectShare := range splitEvenly(numMessagesPerStream, numSubjects, 0) {
+		for slot, share := range splitEvenly(subjectShare, numSlots, offset) {
+			plan := plans[slot/numWorkers]
+
+			plan.Workers[slot%numWorkers][s] = share
+			plan.NumMessagesPerStream += share
+		}
+
+		offset += subjectShare % numSlots
+	}
+
+	for _, plan := range plans {
+		plan.NumMessages = plan.NumMessagesPerStream * numStreams
+	}
+
+	return plans, nil
+}
+
+// splitEvenly splits total into n shares that differ by at most one. The
+// remainder goes to the shares starting at index offset (wrapping around).
+func splitEvenly(total, n, offset int) []int {
+	shares := make([]int, n)
+
+	for i := range shares {
+		shares[i] = total / n
+	}
+
+	for i := 0; i < total%n; i++ {
+		shares[(offset+i)%n]++
+	}
+
+	return shares
+}
+
+// plannedMessages returns the number of messages a worker processes on a
+// subject of a stream
+func plannedMessages(plan *types.Plan, stream string, worker, subject int) int {
+	if plan == nil {
+		return 0
+	}
+
+	workers := plan.Workers
+
+	if w, ok := plan.Streams[stream]; ok {
+		workers = w
+	}
+
+	if worker >= len(workers) || subject >= len(workers[worker]) {
+		return 0
+	}
+
+	return workers[worker][subject]
+}
+
+// plannedOffset returns the number of messages of a worker's share on a
+// subject of a stream that were written before the share was reassigned
+func plannedOffset(plan *types.Plan, stream string, worker, subject int) int {
+	if plan == nil {
+		return 0
+	}
+
+	workers, ok := plan.Offsets[stream]
+	if !ok || worker >= len(workers) || subject >= len(workers[worker]) {
+		return 0
+	}
+
+	return workers[worker][subject]
+}
+
+// planReport compares the number of messages processed by a node with its
+// plan
+func planReport(settings *types.Settings, processed int, jobStatus types.JobStatus) *types.PlanReport {
+	var plan *types.Plan
+
+	switch {
+	case settings.Write != nil:
+		plan = settings.Write.Plan
+	case settings.Read != nil:
+		plan = settings.Read.Plan
+	}
+
+	if plan == nil {
+		return nil
+	}
+
+	return &types.PlanReport{
+		Planned:   plan.NumMessages,
+		Processed: processed,
+		Complete:  isFinal(jobStatus) && processed == plan.NumMessages,
+	}
+}
+
+// verifyPlan checks the processed messages of a job against the total
+// number of planned messages; nodes that did not report count as missing
+// messages. Completed jobs that fell short get an error describing it.
+func verifyPlan(settings *types.Settings, status *types.Status) {
+	if settings == nil || status == nil || status.Plan == nil {
+		return
+	}
+
+	var planned int
+
+	switch {
+	case settings.Write != nil:
+		planned = settings.Write.NumStreams * settings.Write.NumMessagesPerStream
+	case settings.Read != nil:
+		planned = settings.Read.NumStreams * settings.Read.NumMessagesPerStream
+	default:
+		return
+	}
+
+	status.Plan.Planned = planned
+	status.Plan.Complete = isFinal(status.Status) && status.Plan.Processed == planned
+
+	if status.Status == types.CompletedStatus && !status.Plan.Complete {
+		status.Errors = append(status.Errors, fmt.Sprintf("processed %d of %d planned messages",
+			status.Plan.Processed, planned))
+	}
+}
@@ -0,0 +1,190 @@
+package bench
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestNewPlans(t *testing.T) {
+	for _, numMessages := range []int{0, 1, 7, 100, 999, 1000, 10000, 10007} {
+		for numSubjects := 1; numSubjects <= 4; numSubjects++ {
+			for numNodes := 1; numNodes <= 5; numNodes++ {
+				for numWorkers := 1; numWorkers <= 4; numWorkers++ {
+					plans, err := newPlans(3, numMessages, numSubjects, numNodes, numWorkers)
+					if err != nil {
+						t.Fatalf("unexpected error: %s", err)
+					}
+
+					checkPlans(t, plans, numMessages, numSubjects, numNodes, numWorkers)
+				}
+			}
+		}
+	}
+}
+
+func checkPlans(t *testing.T, plans []*types.Plan, numMessages, numSubjects, numNodes, numWorkers int) {
+	t.Helper()
+
+	if len(plans) != numNodes {
+		t.Fatalf("expected %d plans, got %d", numNodes, len(plans))
+	}
+
+	var total int
+
+	subjectTotals := make([]int, numSubjects)
+	min, max := make([]int, numSubjects), make([]int, numSubjects)
+
+	for s := range min {
+		min[s] = numMessages
+	}
+
+	for i, plan := range plans {
+		if plan.NodeIndex != i {
+			t.Errorf("plan #%d: unexpected node index %d", i, plan.NodeIndex)
+		}
+
+		if len(plan.Workers) != numWorkers {
+			t.Fatalf("plan #%d: expected %d workers, got %d", i, numWorkers, len(plan.Workers))
+		}
+
+		var nodeTotal int
+
+		for _, counts := range plan.Workers {
+			if len(counts) != numSubjects {
+				t.Fatalf("plan #%d: expected %d subjects, got %d", i, numSubjects, len(counts))
+			}
+
+			for s, n := range counts {
+				nodeTotal += n
+				subjectTotals[s] += n
+
+				if n < min[s] {
+					min[s] = n
+				}
+
+				if n > max[s] {
+					max[s] = n
+				}
+			}
+		}
+
+		if plan.NumMessagesPerStream != nodeTotal || plan.NumMessages != nodeTotal*3 {
+			t.Errorf("plan #%d: expected %d messages per stream, %d total; got %d, %d", i, nodeTotal,
+				nodeTotal*3, plan.NumMessagesPerStream, plan.NumMessages)
+		}
+
+		total += nodeTotal
+	}
+
+	if total != numMessages {
+		t.Errorf("%d messages, %d subjects, %d nodes, %d workers: planned %d messages per stream",
+			numMessages, numSubjects, numNodes, numWorkers, total)
+	}
+
+	for s := range subjectTotals {
+		if subjectTotals[s] != numMessages/numSubjects && subjectTotals[s] != numMessages/numSubjects+1 {
+			t.Errorf("subject #%d: uneven share %d of %d messages", s, subjectTotals[s], numMessages)
+		}
+
+		if max[s]-min[s] > 1 {
+			t.Errorf("subject #%d: worker shares differ by %d", s, max[s]-min[s])
+		}
+	}
+}
+
+func TestNewPlansRemainder(t *testing.T) {
+	// 10 messages over 3 subjects (4, 3, 3) and 2 nodes with 2 workers each:
+	// the workers that get an extra message rotate between subjects
+	plans, err := newPlans(1, 10, 3, 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := [][][]int{
+		{{1, 1, 1}, {1, 1, 1}},
+		{{1, 1, 0}, {1, 0, 1}},
+	}
+
+	for i, plan := range plans {
+		if !reflect.DeepEqual(plan.Workers, expected[i]) {
+			t.Errorf("plan #%d: expected %v, got %v", i, expected[i], plan.Workers)
+		}
+	}
+}
+
+func TestNewPlansErrors(t *testing.T) {
+	tests := []struct {
+		name                                                      string
+		numStreams, numMessages, numSubjects, numNodes, numWorker int
+		err                                                       string
+	}{
+		{"no streams", 0, 10, 1, 1, 1, "unable to plan job with 0 stream(s)"},
+		{"no subjects", 1, 10, 0, 1, 1, "0 subject(s)"},
+		{"no nodes", 1, 10, 1, 0, 1, "0 node(s)"},
+		{"no workers", 1, 10, 1, 1, 0, "0 worker(s)"},
+		{"negative messages", 1, -1, 1, 1, 1, "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newPlans(tt.numStreams, tt.numMessages, tt.numSubjects, tt.numNodes, tt.numWorker)
+			checkErr(t, err, tt.err)
+		})
+	}
+}
+
+func TestVerifyPlan(t *testing.T) {
+	settings := &types.Settings{
+		Write: &types.WriteSettings{NumStreams: 2, NumMessagesPerStream: 1000},
+	}
+
+	tests := []struct {
+		name      string
+		status    types.JobStatus
+		nodes     []*types.PlanReport
+		complete  bool
+		shortfall bool
+	}{
+		{"complete", types.CompletedStatus, []*types.PlanReport{{Planned: 1000, Processed: 1000}, {Planned: 1000, Processed: 1000}}, true, false},
+		{"messages missing", types.CompletedStatus, []*types.PlanReport{{Planned: 1000, Processed: 999}, {Planned: 1000, Processed: 1000}}, false, true},
+		{"node missing", types.CompletedStatus, []*types.PlanReport{{Planned: 1000, Processed: 1000}}, false, true},
+		{"in progress", types.InProgressStatus, []*types.PlanReport{{Planned: 1000, Processed: 1000}, {Planned: 1000, Processed: 1000}}, false, false},
+		{"cancelled", types.CancelledStatus, []*types.PlanReport{{Planned: 1000, Processed: 10}, {Planned: 1000, Processed: 10}}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := make([]*types.Status, 0)
+
+			for _, report := range tt.nodes {
+				statuses = append(statuses, &types.Status{Status: tt.status, TotalProcessed: report.Processed, Plan: report})
+			}
+
+			status := aggregateStatuses(nil, statuses)
+			verifyPlan(settings, status)
+
+			if status.Plan.Planned != 2000 {
+				t.Errorf("expected 2000 planned messages, got %d", status.Plan.Planned)
+			}
+
+			if status.Plan.Complete != tt.complete {
+				t.Errorf("expected complete to be %v", tt.complete)
+			}
+
+			var shortfall bool
+
+			for _, e := range status.Errors {
+				if strings.Contains(e, "planned messages") {
+					shortfall = true
+				}
+			}
+
+			if shortfall != tt.shortfall {
+				t.Errorf("expected shortfall error to be %v, got errors %v", tt.shortfall, status.Errors)
+			}
+		})
+	}
+}
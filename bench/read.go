@@ -29,6 +29,16 @@ func (b *Bench) runReadBenchmark(job *types.Job) (*types.Status, error) {
 		return nil, errors.New("no streams to read from")
 	}
 
+	if job.Settings.Read.Plan == nil {
+		return nil, errors.New("job has no message plan")
+	}
+
+	for _, streamInfo := range job.Settings.Read.Streams {
+		if subjectIndex(job.Settings.Read.Subjects, streamInfo) < 0 {
+			return nil, errors.Errorf("subject '%s' is not part of the job", streamInfo.SubjectName)
+		}
+	}
+
 	workerMap := make(map[string]map[int]*Worker, 0)
 	var (
 		workerID int
@@ -45,20 +55,40 @@ func (b *Bench) runReadBenchmark(job *types.Job) (*types.Status, error) {
 		defer nc.Drain()
 	}
 
+	streams := make([]string, 0, len(job.Settings.Read.Streams))
+
+	for _, streamInfo := range job.Settings.Read.Streams {
+		streams = append(streams, streamInfo.StreamName)
+	}
+
+	jm := b.metrics.startJob(job.Settings, "read", streams)
+	defer b.metrics.finishJob(jm)
+
 	for _, streamInfo := range job.Settings.Read.Streams {
+		subject := subjectIndex(job.Settings.Read.Subjects, streamInfo)
+
 		for i := 0; i < job.Settings.Read.NumWorkersPerStream; i++ {
 			if workerMap[streamInfo.StreamName] == nil {
 				workerMap[streamInfo.StreamName] = make(map[int]*Worker, 0)
 			}
 
 			workerMap[streamInfo.StreamName][workerID] = &Worker{
-				WorkerID: workerID,
-				Errors:   make([]string, 0),
+				WorkerID:      workerID,
+				Errors:        make([]string, 0),
+				Latency:       newLatencyHistogram(),
+				jobMetrics:    jm,
+				streamMetrics: jm.streams[streamInfo.StreamName],
+			}
+
+			if job.Settings.Read.Verify {
+				workerMap[streamInfo.StreamName][workerID].verifier = newVerifier()
 			}
 
 			wg.Add(1)
 
-			go b.runReaderWorker(job, nc, workerID, streamInfo, workerMap[streamInfo.StreamName][workerID], wg)
+			numMessages := plannedMessages(job.Settings.Read.Plan, streamInfo.StreamName, i, subject)
+
+			go b.runReaderWorker(job, nc, workerID, streamInfo, numMessages, workerMap[streamInfo.StreamName][workerID], wg)
 
 			workerID++
 		}
@@ -73,20 +103,8 @@ func (b *Bench) runReadBenchmark(job *types.Job) (*types.Status, error) {
 	// Stop reporter & monitor
 	close(doneCh)
 
-	var status types.JobStatus
-
-	// Was this job cancelled? (in select to avoid blocking if not cancelled)
-	select {
-	case _, ok := <-job.Context.Done():
-		if !ok {
-			status = types.CancelledStatus
-		}
-	default:
-		status = types.CompletedStatus
-	}
-
 	// Calculate the final status
-	return b.calculateStats(job.Settings, job.NodeID, workerMap, status, "; final"), nil
+	return b.calculateStats(job.Settings, job.NodeID, workerMap, finalJobStatus(job), "; final"), nil
 }
 
 func (b *Bench) calculateNumRead(workerMap map[string]map[int]*Worker) map[string]int {
@@ -101,13 +119,29 @@ func (b *Bench) calculateNumRead(workerMap map[string]map[int]*Worker) map[strin
 	return numRead
 }
 
-func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, streamInfo *types.StreamInfo, worker *Worker, wg *sync.WaitGroup) {
+// subjectIndex returns the index of the consumer's subject in subjects or -1
+// if it is not one of them
+func subjectIndex(subjects []string, streamInfo *types.StreamInfo) int {
+	for i, subj := range subjects {
+		if streamInfo.StreamName+"."+subj == streamInfo.SubjectName {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// runReaderWorker reads targetNumberOfReads messages from the consumer
+func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, streamInfo *types.StreamInfo, targetNumberOfReads int, worker *Worker, wg *sync.WaitGroup) {
 	var myNC = nc
 
 	defer func() {
 		wg.Done()
 	}()
 
+	worker.jobMetrics.workerStarted()
+	defer worker.jobMetrics.workerDone()
+
 	llog := b.log.WithFields(logrus.Fields{
 		"worker_id": workerID,
 		"stream":    streamInfo.StreamName,
@@ -121,6 +155,7 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 		myNC, err = b.nats.NewConn(job.Settings.NATS)
 		if err != nil {
 			b.log.Log(logrus.ErrorLevel, "can't get connection for individual worker: ", err)
+			worker.streamMetrics.addError(ConnectionErrorCategory)
 			return
 		}
 
@@ -140,6 +175,7 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 	if err != nil {
 		llog.Errorf("unable to subscribe to stream '%s': %v", streamInfo.SubjectName, err)
 		worker.Errors = append(worker.Errors, err.Error())
+		worker.streamMetrics.addError(SubscribeErrorCategory)
 		worker.NumErrors++
 
 		return
@@ -151,8 +187,6 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 		}
 	}()
 
-	targetNumberOfReads := (job.Settings.Read.NumMessagesPerStream / (job.Settings.Read.NumWorkersPerStream * job.Settings.Read.NumNodes)) / len(job.Settings.Read.Subjects)
-
 	worker.StartedAt = time.Now().UTC()
 
 	for worker.NumRead < targetNumberOfReads {
@@ -166,9 +200,11 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 			}
 		}()
 
+		fetchStartedAt := time.Now()
+
 		msgs, err := sub.Fetch(batchSize, nats.Context(job.Context))
 		if err != nil {
-			if strings.Contains(err.Error(), "context canceled") {
+			if job.Context.Err() != nil {
 				llog.Debug("worker asked to exit")
 
 				break
@@ -188,6 +224,12 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 
 			llog.Errorf("unable to fetch message(s) (pending: %s): %s", pendingStr, err)
 
+			if err == nats.ErrTimeout {
+				worker.streamMetrics.addError(TimeoutErrorCategory)
+			} else {
+				worker.streamMetrics.addError(FetchErrorCategory)
+			}
+
 			worker.NumErrors++
 
 			if worker.NumErrors > MaxErrorsPerWorker {
@@ -201,8 +243,27 @@ func (b *Bench) runReaderWorker(job *types.Job, nc *nats.Conn, workerID int, str
 			continue
 		}
 
+		fetchLatency := time.Since(fetchStartedAt)
+
+		observeLatency(worker.Latency, fetchLatency)
+		worker.jobMetrics.observeLatency(fetchLatency)
+
 		worker.NumRead += len(msgs)
 
+		numBytes := 0
+
+		subject := strings.TrimPrefix(streamInfo.SubjectName, streamInfo.StreamName+".")
+
+		for _, msg := range msgs {
+			numBytes += len(msg.Data)
+
+			if worker.verifier != nil {
+				worker.verifier.observe(subject, msg.Data)
+			}
+		}
+
+		worker.streamMetrics.addMessages(len(msgs), numBytes)
+
 		for _, msg := range msgs {
 			// Do not pass ctx to Ack - it will cause the ack to be sync (and slow)
 			if err := msg.Ack(); err != nil {
@@ -0,0 +1,375 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	// Message of the status the watchdog writes for a lost node of a job with
+	// reassign_on_failure set while no node is available to take over
+	ReassignWaitingMessage = "node stopped heartbeating; waiting for a node that is not participating in the job to take over its share"
+
+	// Times a node tries to write its status while other nodes write it too
+	StatusUpdateAttempts = 3
+)
+
+// reassign hands the remaining share of a lost node to the least loaded node
+// that is not participating in the job. The updated settings are written
+// first, which fails if another node's watchdog got there first; the other
+// node is then asked to take on the remaining share and the lost node is
+// marked reassigned. Returns the ID of the node that took over or an empty
+// string if no node is available.
+func (b *Bench) reassign(settings *types.Settings, nodeID string, last *types.Status, now time.Time) (string, error) {
+	if len(jobStreams(settings)) == 0 {
+		return "", errors.New("job has no streams")
+	}
+
+	plan, err := nodePlan(settings, nodeID)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to determine plan")
+	}
+
+	nodes, err := b.nats.GetNodes()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to get nodes")
+	}
+
+	to := pickNode(nodes, settings.Participants)
+	if to == "" {
+		return "", nil
+	}
+
+	numNodes := len(settings.Participants) - len(settings.Reassignments)
+	remaining := remainingPlan(settings, nodeID, plan, last)
+
+	settings.Participants = append(settings.Participants, to)
+	settings.Reassignments = append(settings.Reassignments, &types.Reassignment{
+		From: nodeID,
+		To:   to,
+		At:   now,
+		Plan: remaining,
+	})
+
+	if err := b.nats.UpdateSettings(settings); err != nil {
+		return "", errors.Wrap(err, "unable to claim reassignment")
+	}
+
+	// Keeps the job in progress until the other node reports
+	if err := b.nats.WriteStatus(&types.Status{
+		JobID:   settings.ID,
+		NodeID:  to,
+		Status:  types.InProgressStatus,
+		Message: fmt.Sprintf("taking over the remaining share of node '%s'", nodeID),
+	}); err != nil {
+		return "", errors.Wrapf(err, "unable to write status for node '%s'", to)
+	}
+
+	job := &types.Job{
+		NodeID:    to,
+		Settings:  nodeJobSettings(settings, numNodes, remaining),
+		CreatedBy: b.params.NodeID,
+		CreatedAt: now,
+	}
+
+	reply := &types.CreateReply{}
+
+	errs := b.requestJobs(types.CreateJob, []*types.Job{job}, CreateTimeout, func(int) interface{} {
+		return reply
+	})
+
+	if errs[0] != nil || !reply.Accepted {
+		reason := reply.Error
+
+		if errs[0] != nil {
+			reason = errs[0].Error()
+		}
+
+		// The node is a participant now; the job would wait for it forever
+		if err := b.nats.WriteStatus(&types.Status{
+			JobID:   settings.ID,
+			NodeID:  to,
+			Status:  types.ErrorStatus,
+			Message: fmt.Sprintf("node did not take over the remaining share of node '%s': %s", nodeID, reason),
+			EndedAt: now,
+		}); err != nil {
+			b.log.Errorf("unable to write status for node '%s': %s", to, err)
+		}
+
+		return "", errors.Errorf("node '%s' did not accept the job: %s", to, reason)
+	}
+
+	reason := fmt.Sprintf("node '%s' stopped heartbeating before the job finished; its remaining %d message(s) "+
+		"were reassigned to node '%s'", nodeID, remaining.NumMessages, to)
+
+	status := watchdogStatus(settings.ID, nodeID, last, types.ReassignedStatus, reason, now)
+
+	// The rest of the node's share is planned for the node that took over
+	if status.Plan != nil {
+		status.Plan = &types.PlanReport{
+			Planned:   status.Plan.Processed,
+			Processed: status.Plan.Processed,
+			Complete:  true,
+		}
+	}
+
+	if err := b.nats.WriteStatus(status); err != nil {
+		return "", errors.Wrapf(err, "unable to write status for node '%s'", nodeID)
+	}
+
+	return to, nil
+}
+
+// writeJobStatus writes the status of a job running on this node. Another
+// node's watchdog may have taken this node to be lost (ex: it missed a few
+// heartbeats) and written a final status for it; its share may be running on
+// another node then, so the job is cancelled and the final status is kept.
+// Statuses are written against the revision of the node's last status, so
+// that a final status written in between is never overwritten.
+func (b *Bench) writeJobStatus(job *types.Job, status *types.Status) error {
+	if b.params.Standalone {
+		return b.nats.WriteStatus(status)
+	}
+
+	var err error
+
+	for i := 0; i < StatusUpdateAttempts; i++ {
+		last, getErr := b.nats.GetStatus(status.JobID, status.NodeID)
+
+		switch {
+		case getErr == nil && isFinal(last.Status):
+			job.CancelFunc()
+
+			return errors.Errorf("status of node '%s' was set to '%s' by another node: %s", status.NodeID,
+				last.Status, last.Message)
+		case getErr == nil:
+			status.Revision = last.Revision
+		case errors.Cause(getErr) == nats.ErrKeyNotFound:
+			status.Revision = 0
+		default:
+			return errors.Wrap(getErr, "unable to get last status")
+		}
+
+		// Fails if the status was written since it was read
+		if err = b.nats.UpdateStatus(status); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// pickNode returns the node with the fewest running jobs among the nodes that
+// are not participating in the job (ties go to the lowest ID) or an empty
+// string if there is none
+func pickNode(nodes []*types.NodeInfo, participants []string) string {
+	candidates := make([]*types.NodeInfo, 0, len(nodes))
+
+	for _, n := range nodes {
+		if !sliceContains(participants, n.ID) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Jobs) != len(candidates[j].Jobs) {
+			return len(candidates[i].Jobs) < len(candidates[j].Jobs)
+		}
+
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	return candidates[0].ID
+}
+
+// nodePlan returns the plan of a participant: the share it was given when it
+// took over from a lost node or its share of the job as planned when the job
+// was created. Nodes that took over are appended to the participants, so
+// the first participants are the ones the job was created with.
+func nodePlan(settings *types.Settings, nodeID string) (*types.Plan, error) {
+	for _, r := range settings.Reassignments {
+		if r.To == nodeID {
+			return r.Plan, nil
+		}
+	}
+
+	numNodes := len(settings.Participants) - len(settings.Reassignments)
+
+	index := -1
+
+	for i := 0; i < numNodes; i++ {
+		if settings.Participants[i] == nodeID {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return nil, errors.Errorf("node '%s' is not participating in the job", nodeID)
+	}
+
+	var (
+		plans []*types.Plan
+		err   error
+	)
+
+	switch {
+	case settings.Write != nil:
+		plans, err = newPlans(settings.Write.NumStreams, settings.Write.NumMessagesPerStream,
+			len(settings.Write.Subjects), numNodes, settings.Write.NumWorkersPerStream)
+	case settings.Read != nil:
+		plans, err = newPlans(settings.Read.NumStreams, settings.Read.NumMessagesPerStream,
+			len(settings.Read.Subjects), numNodes, settings.Read.NumWorkersPerStream)
+	default:
+		return nil, errors.New("settings must have either read or write set")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plans[index], nil
+}
+
+// remainingPlan returns the part of a node's plan that it did not process
+// according to its last status; messages processed after the last status
+// are processed again by the node that takes over. Writer workers go
+// through their subjects in order, each reader worker reads a single subject.
+func remainingPlan(settings *types.Settings, nodeID string, plan *types.Plan, last *types.Status) *types.Plan {
+	processed := processedPerWorker(nodeID, last)
+
+	remaining := &types.Plan{
+		NodeIndex: plan.NodeIndex,
+		Streams:   make(map[string][][]int),
+	}
+
+	switch {
+	case settings.Write != nil:
+		remaining.Offsets = make(map[string][][]int)
+
+		for _, stream := range settings.Write.Streams {
+			workers := make([][]int, settings.Write.NumWorkersPerStream)
+			offsets := make([][]int, settings.Write.NumWorkersPerStream)
+
+			for w := range workers {
+				workers[w] = make([]int, len(settings.Write.Subjects))
+				offsets[w] = make([]int, len(settings.Write.Subjects))
+				done := processed[stream][w]
+
+				for s := range workers[w] {
+					planned := plannedMessages(plan, stream, w, s)
+					offset := plannedOffset(plan, stream, w, s)
+					n := min(done, planned)
+
+					workers[w][s] = planned - n
+					offsets[w][s] = offset + n
+					remaining.NumMessages += planned - n
+					done -= n
+				}
+			}
+
+			remaining.Streams[stream] = workers
+			remaining.Offsets[stream] = offsets
+		}
+	case settings.Read != nil:
+		// Reader workers are numbered across all streams and subjects, in
+		// the order of Read.Streams
+		numWorkers := settings.Read.NumWorkersPerStream
+
+		for i, info := range settings.Read.Streams {
+			subject := subjectIndex(settings.Read.Subjects, info)
+
+			if subject < 0 {
+				continue
+			}
+
+			workers, ok := remaining.Streams[info.StreamName]
+			if !ok {
+				workers = make([][]int, numWorkers)
+
+				for w := range workers {
+					workers[w] = make([]int, len(settings.Read.Subjects))
+				}
+
+				remaining.Streams[info.StreamName] = workers
+			}
+
+			for w := range workers {
+				planned := plannedMessages(plan, info.StreamName, w, subject)
+				n := min(processed[info.StreamName][i*numWorkers+w], planned)
+
+				workers[w][subject] = planned - n
+				remaining.NumMessages += planned - n
+			}
+		}
+	}
+
+	return remaining
+}
+
+// processedPerWorker returns the number of messages processed by each worker
+// of each stream according to a node's status. Worker reports are named
+// "$nodeID-$stream-$workerID"; see calculateStats.
+func processedPerWorker(nodeID string, status *types.Status) map[string]map[int]int {
+	processed := make(map[string]map[int]int)
+
+	if status == nil || status.NodeReport == nil {
+		return processed
+	}
+
+	for _, stream := range status.NodeReport.Streams {
+		for _, w := range stream.Workers {
+			name := strings.TrimPrefix(w.WorkerID, nodeID+"-")
+
+			i := strings.LastIndex(name, "-")
+			if i < 0 {
+				continue
+			}
+
+			workerID, err := strconv.Atoi(name[i+1:])
+			if err != nil {
+				continue
+			}
+
+			if _, ok := processed[name[:i]]; !ok {
+				processed[name[:i]] = make(map[int]int)
+			}
+
+			processed[name[:i]][workerID] += w.Processed
+		}
+	}
+
+	return processed
+}
+
+// jobStreams returns the names of the streams a job writes to or reads from
+func jobStreams(settings *types.Settings) []string {
+	switch {
+	case settings.Write != nil:
+		return settings.Write.Streams
+	case settings.Read != nil:
+		streams := make([]string, 0, len(settings.Read.Streams))
+
+		for _, info := range settings.Read.Streams {
+			if !sliceContains(streams, info.StreamName) {
+				streams = append(streams, info.StreamName)
+			}
+		}
+
+		return streams
+	}
+
+	return nil
+}
@@ -0,0 +1,460 @@
+package bench
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func reassignWriteSettings() *types.Settings {
+	return &types.Settings{
+		ID:                "abc",
+		ReassignOnFailure: true,
+		Participants:      []string{"node1", "node2"},
+		Write: &types.WriteSettings{
+			NumStreams:           2,
+			NumMessagesPerStream: 1000,
+			NumWorkersPerStream:  2,
+			Subjects:             []string{"foo", "bar"},
+			Streams:              []string{"njst-abc-0", "njst-abc-1"},
+		},
+	}
+}
+
+// workerReport returns a node report in which every worker of every stream
+// processed the given number of messages
+func workerReport(nodeID string, streams []string, numWorkers, processed int) *types.NodeReport {
+	report := &types.NodeReport{}
+
+	for _, stream := range streams {
+		sr := types.StreamReport{}
+
+		for w := 0; w < numWorkers; w++ {
+			sr.Workers = append(sr.Workers, types.WorkerReport{
+				WorkerID:  nodeID + "-" + stream + "-" + strconv.Itoa(w),
+				Processed: processed,
+			})
+		}
+
+		report.Streams = append(report.Streams, sr)
+	}
+
+	return report
+}
+
+func TestPickNode(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodes        []*types.NodeInfo
+		participants []string
+		expected     string
+	}{
+		{"no nodes", nil, []string{"node1"}, ""},
+		{"only participants", []*types.NodeInfo{{ID: "node1"}, {ID: "node2"}}, []string{"node1", "node2"}, ""},
+		{"idle node", []*types.NodeInfo{
+			{ID: "node1"}, {ID: "node3", Jobs: []string{"x"}}, {ID: "node4"},
+		}, []string{"node1", "node2"}, "node4"},
+		{"least loaded", []*types.NodeInfo{
+			{ID: "node3", Jobs: []string{"x", "y"}}, {ID: "node4", Jobs: []string{"z"}},
+		}, []string{"node1"}, "node4"},
+		{"ties go to the lowest ID", []*types.NodeInfo{{ID: "node5"}, {ID: "node3"}, {ID: "node4"}},
+			[]string{"node1"}, "node3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if to := pickNode(tt.nodes, tt.participants); to != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, to)
+			}
+		})
+	}
+}
+
+func TestNodePlan(t *testing.T) {
+	settings := reassignWriteSettings()
+
+	plans, err := newPlans(2, 1000, 2, 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	plan, err := nodePlan(settings, "node2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(plan, plans[1]) {
+		t.Errorf("expected %+v, got %+v", plans[1], plan)
+	}
+
+	// Nodes that took over have the plan they were given; the original
+	// plans are still split between the original number of nodes
+	reassigned := &types.Plan{NumMessages: 42}
+
+	settings.Participants = append(settings.Participants, "node3")
+	settings.Reassignments = []*types.Reassignment{{From: "node2", To: "node3", Plan: reassigned}}
+
+	if plan, err := nodePlan(settings, "node3"); err != nil || plan != reassigned {
+		t.Errorf("expected reassigned plan, got %+v (%v)", plan, err)
+	}
+
+	if plan, err := nodePlan(settings, "node1"); err != nil || !reflect.DeepEqual(plan, plans[0]) {
+		t.Errorf("expected %+v, got %+v (%v)", plans[0], plan, err)
+	}
+
+	if _, err := nodePlan(settings, "node4"); err == nil {
+		t.Error("expected an error for a node that is not participating")
+	}
+}
+
+func TestRemainingPlanWrite(t *testing.T) {
+	settings := reassignWriteSettings()
+
+	// 2 workers per stream; each worker writes 125 messages to foo and 125
+	// to bar
+	plans, _ := newPlans(2, 1000, 2, 2, 2)
+
+	last := &types.Status{
+		NodeID: "node2",
+		NodeReport: &types.NodeReport{Streams: []types.StreamReport{
+			{Workers: []types.WorkerReport{
+				{WorkerID: "node2-njst-abc-0-0", Processed: 100},
+				{WorkerID: "node2-njst-abc-0-1", Processed: 200},
+			}},
+			{Workers: []types.WorkerReport{
+				{WorkerID: "node2-njst-abc-1-0", Processed: 250},
+			}},
+		}},
+	}
+
+	remaining := remainingPlan(settings, "node2", plans[1], last)
+
+	expected := map[string][][]int{
+		"njst-abc-0": {{25, 125}, {0, 50}},
+		"njst-abc-1": {{0, 0}, {125, 125}},
+	}
+
+	if !reflect.DeepEqual(remaining.Streams, expected) {
+		t.Errorf("expected %v, got %v", expected, remaining.Streams)
+	}
+
+	// Producers of the node that takes over continue where the lost node's
+	// producers were
+	offsets := map[string][][]int{
+		"njst-abc-0": {{100, 0}, {125, 75}},
+		"njst-abc-1": {{125, 125}, {0, 0}},
+	}
+
+	if !reflect.DeepEqual(remaining.Offsets, offsets) {
+		t.Errorf("expected offsets %v, got %v", offsets, remaining.Offsets)
+	}
+
+	if remaining.NumMessages != 1000-550 || remaining.NodeIndex != 1 {
+		t.Errorf("expected 450 remaining messages for node index 1, got %d for %d", remaining.NumMessages,
+			remaining.NodeIndex)
+	}
+
+	// A node that never reported did not process anything
+	if remaining := remainingPlan(settings, "node2", plans[1], nil); remaining.NumMessages != plans[1].NumMessages {
+		t.Errorf("expected %d remaining messages, got %d", plans[1].NumMessages, remaining.NumMessages)
+	}
+
+	// The remaining share of a node that took over is split the same way
+	again := remainingPlan(settings, "node3", remaining, &types.Status{
+		NodeReport: workerReport("node3", settings.Write.Streams, 2, 25),
+	})
+
+	expected = map[string][][]int{
+		"njst-abc-0": {{0, 125}, {0, 25}},
+		"njst-abc-1": {{0, 0}, {100, 125}},
+	}
+
+	if !reflect.DeepEqual(again.Streams, expected) || again.NumMessages != 450-75 {
+		t.Errorf("expected %v (375 messages), got %v (%d messages)", expected, again.Streams, again.NumMessages)
+	}
+
+	offsets = map[string][][]int{
+		"njst-abc-0": {{125, 0}, {125, 100}},
+		"njst-abc-1": {{125, 125}, {25, 0}},
+	}
+
+	if !reflect.DeepEqual(again.Offsets, offsets) {
+		t.Errorf("expected offsets %v, got %v", offsets, again.Offsets)
+	}
+}
+
+func TestRemainingPlanRead(t *testing.T) {
+	settings := &types.Settings{
+		ID:           "def",
+		Participants: []string{"node1"},
+		Read: &types.ReadSettings{
+			NumStreams:           1,
+			NumMessagesPerStream: 100,
+			NumWorkersPerStream:  2,
+			Subjects:             []string{"foo", "bar"},
+			Streams: []*types.StreamInfo{
+				{StreamName: "njst-w-0", SubjectName: "njst-w-0.foo"},
+				{StreamName: "njst-w-0", SubjectName: "njst-w-0.bar"},
+			},
+		},
+	}
+
+	plans, _ := newPlans(1, 100, 2, 1, 2)
+
+	// Workers 0 and 1 read foo, 2 and 3 read bar
+	last := &types.Status{NodeReport: &types.NodeReport{Streams: []types.StreamReport{{Workers: []types.WorkerReport{
+		{WorkerID: "node1-njst-w-0-0", Processed: 25},
+		{WorkerID: "node1-njst-w-0-1", Processed: 10},
+		{WorkerID: "node1-njst-w-0-3", Processed: 20},
+	}}}}}
+
+	remaining := remainingPlan(settings, "node1", plans[0], last)
+
+	expected := map[string][][]int{"njst-w-0": {{0, 25}, {15, 5}}}
+
+	if !reflect.DeepEqual(remaining.Streams, expected) || remaining.NumMessages != 45 {
+		t.Errorf("expected %v (45 messages), got %v (%d messages)", expected, remaining.Streams, remaining.NumMessages)
+	}
+}
+
+func TestReassign(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	settings := reassignWriteSettings()
+
+	plans, _ := newPlans(2, 1000, 2, 2, 2)
+
+	last := &types.Status{
+		JobID:          "abc",
+		NodeID:         "node2",
+		Status:         types.InProgressStatus,
+		TotalProcessed: 400,
+		NodeReport:     workerReport("node2", settings.Write.Streams, 2, 100),
+		Plan:           &types.PlanReport{Planned: 500, Processed: 400},
+	}
+
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1", Jobs: []string{"abc"}}, {ID: "node3"}}, nil)
+	fake.RequestJobReturns([]byte(`{"accepted": true}`), nil)
+
+	to, err := b.reassign(settings, "node2", last, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if to != "node3" {
+		t.Fatalf("expected node3 to take over, got '%s'", to)
+	}
+
+	if fake.UpdateSettingsCallCount() != 1 {
+		t.Fatal("expected settings to be updated")
+	}
+
+	updated := fake.UpdateSettingsArgsForCall(0)
+
+	if !reflect.DeepEqual(updated.Participants, []string{"node1", "node2", "node3"}) || len(updated.Reassignments) != 1 {
+		t.Fatalf("unexpected participants %v or reassignments %+v", updated.Participants, updated.Reassignments)
+	}
+
+	r := updated.Reassignments[0]
+
+	if r.From != "node2" || r.To != "node3" || !r.At.Equal(now) || r.Plan.NumMessages != plans[1].NumMessages-400 {
+		t.Errorf("unexpected reassignment %+v", r)
+	}
+
+	if fake.RequestJobCallCount() != 1 {
+		t.Fatal("expected a create job to be sent")
+	}
+
+	jobType, job, _ := fake.RequestJobArgsForCall(0)
+
+	if jobType != types.CreateJob || job.NodeID != "node3" {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	js := job.Settings
+
+	if js.ID != "abc" || js.Write == nil || js.Write.Plan != r.Plan || js.Write.NumNodes != 2 ||
+		!reflect.DeepEqual(js.Write.Streams, settings.Write.Streams) {
+		t.Errorf("unexpected job settings %+v", js.Write)
+	}
+
+	// The node that took over is in progress before the lost node is
+	// marked reassigned
+	if fake.WriteStatusCallCount() != 2 {
+		t.Fatalf("expected 2 statuses, got %d", fake.WriteStatusCallCount())
+	}
+
+	if s := fake.WriteStatusArgsForCall(0); s.NodeID != "node3" || s.Status != types.InProgressStatus {
+		t.Errorf("unexpected status %+v", s)
+	}
+
+	s := fake.WriteStatusArgsForCall(1)
+
+	if s.NodeID != "node2" || s.Status != types.ReassignedStatus || s.TotalProcessed != 400 ||
+		!strings.Contains(s.Message, "reassigned to node 'node3'") {
+		t.Errorf("unexpected status %+v", s)
+	}
+
+	if s.Plan == nil || s.Plan.Planned != 400 || !s.Plan.Complete || last.Plan.Planned != 500 {
+		t.Errorf("unexpected plan %+v", s.Plan)
+	}
+}
+
+func TestReassignErrors(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	// Another node's watchdog got there first
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node3"}}, nil)
+	fake.UpdateSettingsReturns(errors.New("wrong last sequence"))
+
+	if _, err := b.reassign(reassignWriteSettings(), "node2", nil, time.Now()); err == nil {
+		t.Error("expected an error")
+	}
+
+	if fake.RequestJobCallCount() != 0 || fake.WriteStatusCallCount() != 0 {
+		t.Error("nothing should be sent or written without claiming the reassignment")
+	}
+
+	// The node that was picked rejects the job
+	fake.UpdateSettingsReturns(nil)
+	fake.RequestJobReturns([]byte(`{"accepted": false, "error": "job is already running on this node"}`), nil)
+
+	if _, err := b.reassign(reassignWriteSettings(), "node2", nil, time.Now()); err == nil {
+		t.Error("expected an error")
+	}
+
+	// Placeholder and error status of node3; node2 is not reassigned
+	if n := fake.WriteStatusCallCount(); n != 2 || fake.WriteStatusArgsForCall(1).Status != types.ErrorStatus {
+		t.Errorf("expected an error status for node3, got %d status(es)", n)
+	}
+
+	// No node available to take over
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1"}}, nil)
+
+	to, err := b.reassign(reassignWriteSettings(), "node2", nil, time.Now())
+	if err != nil || to != "" {
+		t.Errorf("expected no node and no error, got '%s' (%v)", to, err)
+	}
+
+	if fake.RequestJobCallCount() != 1 {
+		t.Error("no job should be sent without a node to take over")
+	}
+}
+
+func TestCheckJobReassign(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetStatusesReturns([]*types.Status{
+		{NodeID: "node1", Status: types.InProgressStatus},
+		{NodeID: "node2", Status: types.InProgressStatus},
+	}, nil)
+
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1"}}, nil)
+
+	alive := map[string]bool{"node1": true}
+
+	done, err := b.checkJob(reassignWriteSettings(), alive, time.Now().UTC())
+	if err != nil || done {
+		t.Fatalf("expected job to not be done, got %v (%v)", done, err)
+	}
+
+	// Without a node to take over, the lost node keeps running until one
+	// joins
+	if fake.WriteStatusCallCount() != 1 {
+		t.Fatalf("expected 1 status, got %d", fake.WriteStatusCallCount())
+	}
+
+	status := fake.WriteStatusArgsForCall(0)
+
+	if status.NodeID != "node2" || status.Status != types.InProgressStatus || status.Message != ReassignWaitingMessage {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// The waiting status is only written once
+	fake.GetStatusesReturns([]*types.Status{{NodeID: "node1", Status: types.InProgressStatus}, status}, nil)
+
+	if _, err := b.checkJob(reassignWriteSettings(), alive, time.Now().UTC()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.WriteStatusCallCount() != 1 {
+		t.Errorf("expected no new status, got %d", fake.WriteStatusCallCount())
+	}
+
+	// Once a node joins, it takes over
+	fake.GetNodesReturns([]*types.NodeInfo{{ID: "node1"}, {ID: "node3"}}, nil)
+	fake.RequestJobReturns([]byte(`{"accepted": true}`), nil)
+
+	if _, err := b.checkJob(reassignWriteSettings(), alive, time.Now().UTC()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.RequestJobCallCount() != 1 || fake.WriteStatusArgsForCall(2).Status != types.ReassignedStatus {
+		t.Error("expected the share of node2 to be reassigned")
+	}
+}
+
+func TestWriteJobStatus(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	cancelled := false
+
+	job := &types.Job{
+		NodeID:     "node1",
+		CancelFunc: func() { cancelled = true },
+	}
+
+	status := &types.Status{JobID: "abc", NodeID: "node1", Status: types.InProgressStatus}
+
+	// First status of the node
+	fake.GetStatusReturns(nil, nats.ErrKeyNotFound)
+
+	if err := b.writeJobStatus(job, status); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.UpdateStatusCallCount() != 1 || fake.UpdateStatusArgsForCall(0).Revision != 0 {
+		t.Error("expected the status to be created")
+	}
+
+	// Written against the revision of the last status; retried when the
+	// status was written in between
+	fake.GetStatusReturns(&types.Status{Status: types.InProgressStatus, Revision: 7}, nil)
+	fake.UpdateStatusReturnsOnCall(1, errors.New("wrong last sequence"))
+
+	if err := b.writeJobStatus(job, status); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.UpdateStatusCallCount() != 3 || fake.UpdateStatusArgsForCall(2).Revision != 7 {
+		t.Errorf("expected the status to be updated at revision 7, got %d update(s)", fake.UpdateStatusCallCount())
+	}
+
+	if cancelled {
+		t.Error("job should not be cancelled")
+	}
+
+	// Another node's watchdog took the node to be lost and reassigned its
+	// share; the job stops and the reassigned status is kept
+	fake.GetStatusReturns(&types.Status{Status: types.ReassignedStatus, Revision: 9}, nil)
+
+	if err := b.writeJobStatus(job, status); err == nil {
+		t.Error("expected an error")
+	}
+
+	if !cancelled {
+		t.Error("expected the job to be cancelled")
+	}
+
+	if fake.UpdateStatusCallCount() != 3 || fake.WriteStatusCallCount() != 0 {
+		t.Error("the reassigned status should not be overwritten")
+	}
+}
@@ -0,0 +1,492 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// How often to check job results while waiting for a job to finish
+	WaitPollInterval = time.Second
+
+	// How often a running scenario or sweep checks whether it was deleted
+	ScenarioCheckInterval = 2 * time.Second
+
+	// How long a scenario waits for a step's job on top of its max_duration;
+	// longer than MaxDurationGracePeriod so that the watchdog normally marks
+	// the job's nodes timed out first
+	StepGracePeriod = time.Minute
+)
+
+var (
+	stepRefRegex = regexp.MustCompile(`\$\{steps\.([a-zA-Z0-9_-]+)\.id\}`)
+)
+
+// CreateScenario saves the scenario and starts running it in the background
+// on this node. Steps are expected to be validated.
+func (b *Bench) CreateScenario(scenario *types.Scenario) error {
+	if scenario == nil {
+		return errors.New("scenario cannot be nil")
+	}
+
+	scenario.ID = RandString(8)
+	scenario.Status = types.InProgressStatus
+	scenario.CreatedBy = b.params.NodeID
+	scenario.CreatedAt = time.Now().UTC()
+	scenario.Results = make([]*types.StepResult, 0)
+
+	if err := b.nats.SaveScenario(scenario); err != nil {
+		return errors.Wrap(err, "unable to save scenario")
+	}
+
+	go b.runScenario(scenario)
+
+	return nil
+}
+
+// StepRefs returns the names of the steps referenced via ${steps.<name>.id}
+// in the given settings
+func StepRefs(settings *types.Settings) ([]string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal settings")
+	}
+
+	refs := make([]string, 0)
+
+	for _, match := range stepRefRegex.FindAllStringSubmatch(string(data), -1) {
+		refs = append(refs, match[1])
+	}
+
+	return refs, nil
+}
+
+func (b *Bench) runScenario(scenario *types.Scenario) {
+	llog := b.log.WithFields(logrus.Fields{
+		"func":        "runScenario",
+		"scenario_id": scenario.ID,
+	})
+
+	// Validated when the scenario is created
+	timeout, _ := time.ParseDuration(scenario.Timeout)
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	defer cancel()
+
+	go b.cancelOnDelete(ctx, cancel, func() error {
+		_, err := b.nats.GetScenario(scenario.ID)
+		return err
+	})
+
+	// Step name -> job IDs created by the step
+	stepJobs := make(map[string][]string)
+
+	llog.Infof("Running scenario with %d step(s)", len(scenario.Steps))
+
+	for _, step := range scenario.Steps {
+		result := &types.StepResult{
+			Name:      step.Name,
+			Type:      step.Type,
+			Status:    types.InProgressStatus,
+			StartedAt: time.Now().UTC(),
+		}
+
+		scenario.Results = append(scenario.Results, result)
+
+		if err := b.updateScenario(scenario); err != nil {
+			llog.Errorf("unable to update scenario: %s", err)
+		}
+
+		llog.Debugf("running step '%s' (%s)", step.Name, step.Type)
+
+		err := b.runStep(ctx, step, result, stepJobs)
+
+		result.EndedAt = time.Now().UTC()
+
+		if err == nil {
+			result.Status = types.CompletedStatus
+			continue
+		}
+
+		result.Error = err.Error()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			result.Status = types.FailedStatus
+			scenario.Status = types.FailedStatus
+			scenario.Message = fmt.Sprintf("scenario timed out after %s in step '%s'", scenario.Timeout, step.Name)
+
+			break
+		}
+
+		if ctx.Err() != nil {
+			// Scenario was deleted; there is nothing left to update
+			llog.Info("Scenario cancelled")
+			return
+		}
+
+		result.Status = types.ErrorStatus
+		scenario.Status = types.ErrorStatus
+		scenario.Message = fmt.Sprintf("step '%s' failed: %s", step.Name, err)
+
+		break
+	}
+
+	if scenario.Status == types.InProgressStatus {
+		scenario.Status = types.CompletedStatus
+		scenario.Message = "scenario completed"
+	}
+
+	scenario.EndedAt = time.Now().UTC()
+
+	if err := b.updateScenario(scenario); err != nil {
+		llog.Errorf("unable to update scenario: %s", err)
+	}
+
+	llog.Infof("Scenario finished with status '%s'", scenario.Status)
+}
+
+// checkScenarios fails scenarios that are in progress but whose node is no
+// longer heartbeating; scenarios only run on the node that created them.
+// Run by the watchdog. The jobs of the step that was running are left to
+// finish on their own.
+func (b *Bench) checkScenarios(now time.Time) error {
+	scenarios, err := b.nats.GetAllScenarios()
+	if err != nil {
+		return errors.Wrap(err, "unable to get scenarios")
+	}
+
+	var alive map[string]bool
+
+	for _, scenario := range scenarios {
+		if scenario.Status != types.InProgressStatus {
+			continue
+		}
+
+		// Only look up the node list if there is anything to check
+		if alive == nil {
+			nodes, err := b.nats.GetNodeList()
+			if err != nil {
+				return errors.Wrap(err, "unable to get node list")
+			}
+
+			alive = make(map[string]bool, len(nodes))
+
+			for _, nodeID := range nodes {
+				alive[nodeID] = true
+			}
+		}
+
+		if alive[scenario.CreatedBy] {
+			continue
+		}
+
+		reason := fmt.Sprintf("node '%s' running the scenario is gone", scenario.CreatedBy)
+
+		b.log.Warningf("watchdog: %s; failing scenario '%s'", reason, scenario.ID)
+
+		for _, result := range scenario.Results {
+			if result.Status == types.InProgressStatus {
+				result.Status = types.FailedStatus
+				result.Error = reason
+				result.EndedAt = now
+			}
+		}
+
+		scenario.Status = types.FailedStatus
+		scenario.Message = reason
+		scenario.EndedAt = now
+
+		if err := b.updateScenario(scenario); err != nil {
+			return errors.Wrapf(err, "unable to update scenario '%s'", scenario.ID)
+		}
+	}
+
+	return nil
+}
+
+func (b *Bench) updateScenario(scenario *types.Scenario) error {
+	// Do not resurrect a deleted scenario
+	if _, err := b.nats.GetScenario(scenario.ID); err != nil {
+		return err
+	}
+
+	return b.nats.SaveScenario(scenario)
+}
+
+// cancelOnDelete cancels ctx once get() reports that the underlying KV entry
+// (scenario, sweep) no longer exists
+func (b *Bench) cancelOnDelete(ctx context.Context, cancel context.CancelFunc, get func() error) {
+	ticker := time.NewTicker(ScenarioCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := get(); err != nil && errors.Cause(err) == nats.ErrKeyNotFound {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (b *Bench) runStep(ctx context.Context, step *types.ScenarioStep, result *types.StepResult, stepJobs map[string][]string) error {
+	switch step.Type {
+	case types.WriteStep, types.ReadStep:
+		settings, err := resolveStepRefs(step.Settings, stepJobs)
+		if err != nil {
+			return err
+		}
+
+		status, err := b.runJob(ctx, settings, result)
+		stepJobs[step.Name] = result.JobIDs
+
+		if status != nil {
+			result.Results = append(result.Results, status)
+		}
+
+		if err != nil {
+			return err
+		}
+	case types.MixedStep:
+		settings, err := resolveStepRefs(step.Settings, stepJobs)
+		if err != nil {
+			return err
+		}
+
+		// Writer and reader run as two separate jobs at the same time
+		writeSettings, err := copySettings(settings)
+		if err != nil {
+			return err
+		}
+
+		writeSettings.Read = nil
+
+		readSettings, err := copySettings(settings)
+		if err != nil {
+			return err
+		}
+
+		readSettings.Write = nil
+
+		if err := b.createStepJob(writeSettings, result); err != nil {
+			return err
+		}
+
+		if err := b.createStepJob(readSettings, result); err != nil {
+			b.cancelJobs(writeSettings.ID)
+			return err
+		}
+
+		// The step ID of a mixed step is the ID of its write job
+		stepJobs[step.Name] = result.JobIDs
+
+		for _, s := range []*types.Settings{writeSettings, readSettings} {
+			status, err := b.waitForJob(ctx, s)
+			if err != nil {
+				b.cancelJobs(writeSettings.ID, readSettings.ID)
+				return err
+			}
+
+			result.Results = append(result.Results, status)
+
+			if status.Status != types.CompletedStatus {
+				return errors.Errorf("job '%s' finished with status '%s'", s.ID, status.Status)
+			}
+		}
+	case types.WaitStep:
+		duration, err := time.ParseDuration(step.Duration)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse duration")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(duration):
+		}
+	case types.CleanupStep:
+		targets := step.Cleanup.Steps
+
+		if len(targets) == 0 {
+			for name := range stepJobs {
+				targets = append(targets, name)
+			}
+		}
+
+		for _, name := range targets {
+			for _, jobID := range stepJobs[name] {
+				resp, err := b.Delete(jobID, step.Cleanup.Streams, step.Cleanup.Settings, step.Cleanup.Results)
+				if err != nil {
+					return errors.Wrapf(err, "unable to clean up job '%s' from step '%s'", jobID, name)
+				}
+
+				if len(resp.Unconfirmed) > 0 {
+					b.log.Warningf("cleanup of job '%s' not confirmed by node(s) %v", jobID, resp.Unconfirmed)
+				}
+
+				result.JobIDs = append(result.JobIDs, jobID)
+			}
+		}
+	default:
+		return errors.Errorf("unknown step type '%s'", step.Type)
+	}
+
+	return nil
+}
+
+// runJob creates a job for the settings and waits for it to finish
+func (b *Bench) runJob(ctx context.Context, settings *types.Settings, result *types.StepResult) (*types.Status, error) {
+	if err := b.createStepJob(settings, result); err != nil {
+		return nil, err
+	}
+
+	status, err := b.waitForJob(ctx, settings)
+	if err != nil {
+		b.cancelJobs(settings.ID)
+		return nil, err
+	}
+
+	if status.Status != types.CompletedStatus {
+		return status, errors.Errorf("job '%s' finished with status '%s'", settings.ID, status.Status)
+	}
+
+	return status, nil
+}
+
+func (b *Bench) createStepJob(settings *types.Settings, result *types.StepResult) error {
+	if _, err := b.Create(settings); err != nil {
+		return errors.Wrap(err, "unable to create job")
+	}
+
+	result.JobIDs = append(result.JobIDs, settings.ID)
+
+	return nil
+}
+
+// cancelJobs stops the given jobs without deleting any of their data
+func (b *Bench) cancelJobs(ids ...string) {
+	for _, id := range ids {
+		resp, err := b.Delete(id, false, false, false)
+		if err != nil {
+			b.log.Errorf("unable to cancel job '%s': %s", id, err)
+			continue
+		}
+
+		if len(resp.Unconfirmed) > 0 {
+			b.log.Warningf("cancellation of job '%s' not confirmed by node(s) %v", id, resp.Unconfirmed)
+		}
+	}
+}
+
+// waitForJob blocks until every participating node has reported a final
+// status for the job and returns the aggregated status. Jobs with a
+// max_duration are waited for until StepGracePeriod after it.
+func (b *Bench) waitForJob(ctx context.Context, settings *types.Settings) (*types.Status, error) {
+	ticker := time.NewTicker(WaitPollInterval)
+	defer ticker.Stop()
+
+	var deadline time.Time
+
+	if d := maxDuration(settings); d > 0 {
+		deadline = settings.CreatedAt.Add(d + StepGracePeriod)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case now := <-ticker.C:
+			if !deadline.IsZero() && now.After(deadline) {
+				return nil, errors.Errorf("job '%s' did not finish within max_duration of %s", settings.ID, settings.MaxDuration)
+			}
+		}
+
+		statuses, err := b.nats.GetStatuses(settings.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get status for job '%s'", settings.ID)
+		}
+
+		if len(statuses) < len(settings.Participants) {
+			continue
+		}
+
+		done := true
+
+		for _, s := range statuses {
+			if !isFinal(s.Status) {
+				done = false
+				break
+			}
+		}
+
+		if !done {
+			continue
+		}
+
+		status := aggregateStatuses(settings, statuses)
+		status.JobID = settings.ID
+
+		applyVerdict(settings, status)
+
+		return status, nil
+	}
+}
+
+// resolveStepRefs returns a copy of settings with all ${steps.<name>.id}
+// references replaced with the job ID of the referenced step
+func resolveStepRefs(settings *types.Settings, stepJobs map[string][]string) (*types.Settings, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal settings")
+	}
+
+	var resolveErr error
+
+	resolved := stepRefRegex.ReplaceAllStringFunc(string(data), func(ref string) string {
+		name := stepRefRegex.FindStringSubmatch(ref)[1]
+
+		ids, ok := stepJobs[name]
+		if !ok || len(ids) == 0 {
+			resolveErr = errors.Errorf("step '%s' has no job ID", name)
+			return ref
+		}
+
+		return ids[0]
+	})
+
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	cp := &types.Settings{}
+
+	if err := json.Unmarshal([]byte(resolved), cp); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal resolved settings")
+	}
+
+	// Every run of a step is a new job
+	cp.ID = ""
+
+	return cp, nil
+}
@@ -0,0 +1,135 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestWaitForJobStuck(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetStatusesReturns([]*types.Status{{NodeID: "node1", Status: types.InProgressStatus}}, nil)
+
+	// The job never reaches a final status; the wait ends once the job is
+	// past its max_duration
+	settings := &types.Settings{
+		ID:           "abc",
+		MaxDuration:  "1m",
+		Participants: []string{"node1"},
+		CreatedAt:    time.Now().UTC().Add(-time.Hour),
+	}
+
+	_, err := b.waitForJob(context.Background(), settings)
+	checkErr(t, err, "job 'abc' did not finish within max_duration of 1m")
+
+	// Without a max_duration the wait is bounded by the scenario's timeout
+	settings.MaxDuration = ""
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := b.waitForJob(ctx, settings); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRunScenarioTimeout(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	scenario := &types.Scenario{
+		ID:      "abc",
+		Status:  types.InProgressStatus,
+		Timeout: "50ms",
+		Steps: []*types.ScenarioStep{
+			{Name: "stuck", Type: types.WaitStep, Duration: "1h"},
+			{Name: "never", Type: types.WaitStep, Duration: "1s"},
+		},
+	}
+
+	fake.GetScenarioReturns(scenario, nil)
+
+	done := make(chan struct{})
+
+	go func() {
+		b.runScenario(scenario)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the scenario to time out")
+	}
+
+	if scenario.Status != types.FailedStatus || scenario.Message != "scenario timed out after 50ms in step 'stuck'" {
+		t.Errorf("unexpected status '%s' (%s)", scenario.Status, scenario.Message)
+	}
+
+	if len(scenario.Results) != 1 || scenario.Results[0].Status != types.FailedStatus {
+		t.Errorf("expected only the stuck step to have failed, got %+v", scenario.Results)
+	}
+
+	if n := fake.SaveScenarioCallCount(); n == 0 || fake.SaveScenarioArgsForCall(n-1).Status != types.FailedStatus {
+		t.Error("expected the failed scenario to be saved")
+	}
+}
+
+func TestCheckScenarios(t *testing.T) {
+	b, fake := newTestBench(t)
+	now := time.Now().UTC()
+
+	orphaned := &types.Scenario{
+		ID:        "a",
+		Status:    types.InProgressStatus,
+		CreatedBy: "node2",
+		Results: []*types.StepResult{
+			{Name: "seed", Status: types.CompletedStatus},
+			{Name: "read", Status: types.InProgressStatus},
+		},
+	}
+
+	fake.GetAllScenariosReturns([]*types.Scenario{
+		orphaned,
+		{ID: "b", Status: types.InProgressStatus, CreatedBy: "node1"},
+		{ID: "c", Status: types.CompletedStatus, CreatedBy: "node2"},
+	}, nil)
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.GetScenarioReturns(orphaned, nil)
+
+	if err := b.checkScenarios(now); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.SaveScenarioCallCount() != 1 {
+		t.Fatalf("expected only the orphaned scenario to be saved, got %d", fake.SaveScenarioCallCount())
+	}
+
+	saved := fake.SaveScenarioArgsForCall(0)
+
+	if saved.ID != "a" || saved.Status != types.FailedStatus || !saved.EndedAt.Equal(now) ||
+		saved.Message != "node 'node2' running the scenario is gone" {
+		t.Errorf("unexpected scenario %+v", saved)
+	}
+
+	if saved.Results[0].Status != types.CompletedStatus || saved.Results[1].Status != types.FailedStatus {
+		t.Errorf("expected only the running step to fail, got %s and %s", saved.Results[0].Status, saved.Results[1].Status)
+	}
+
+	// Nothing to check; the node list is not looked up
+	b, fake = newTestBench(t)
+
+	fake.GetAllScenariosReturns([]*types.Scenario{{ID: "c", Status: types.CompletedStatus}}, nil)
+	fake.GetNodeListReturns(nil, errors.New("no heartbeats"))
+
+	if err := b.checkScenarios(now); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if fake.GetNodeListCallCount() != 0 {
+		t.Error("expected the node list not to be looked up")
+	}
+}
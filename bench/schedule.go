@@ -0,0 +1,166 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SchedulerInterval = 5 * time.Second
+
+	// How many times to retry recording a schedule run if another node
+	// updated the schedule in the meantime
+	MaxScheduleUpdateAttempts = 5
+)
+
+// CreateSchedule saves a new schedule. Settings are expected to be validated.
+func (b *Bench) CreateSchedule(schedule *types.Schedule) error {
+	if schedule == nil || schedule.Settings == nil {
+		return errors.New("schedule and schedule settings cannot be nil")
+	}
+
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse cron expression")
+	}
+
+	now := time.Now().UTC()
+
+	schedule.ID = RandString(8)
+	schedule.Settings.ID = ""
+	schedule.CreatedAt = now
+	schedule.NextRunAt = cron.Next(now)
+	schedule.JobIDs = make([]string, 0)
+
+	if err := b.nats.SaveSchedule(schedule); err != nil {
+		return errors.Wrap(err, "unable to save schedule")
+	}
+
+	return nil
+}
+
+// runScheduler periodically checks all schedules and fires the ones that are
+// due. Every node runs the scheduler; a node only fires a schedule if it is
+// able to advance the schedule's next_run_at via a KV revision-checked update,
+// so exactly one node fires each run.
+func (b *Bench) runScheduler() {
+	ticker := time.NewTicker(SchedulerInterval)
+
+	for range ticker.C {
+		schedules, err := b.nats.GetAllSchedules()
+		if err != nil {
+			b.log.Errorf("scheduler: unable to get schedules: %s", err)
+			continue
+		}
+
+		now := time.Now().UTC()
+
+		for _, schedule := range schedules {
+			if schedule.NextRunAt.After(now) {
+				continue
+			}
+
+			b.fireSchedule(schedule, now)
+		}
+	}
+}
+
+func (b *Bench) fireSchedule(schedule *types.Schedule, now time.Time) {
+	llog := b.log.WithFields(logrus.Fields{
+		"func":        "fireSchedule",
+		"schedule_id": schedule.ID,
+	})
+
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		llog.Errorf("unable to parse cron expression '%s': %s", schedule.Cron, err)
+		return
+	}
+
+	// Claim this run
+	schedule.NextRunAt = cron.Next(now)
+	schedule.LastRunAt = now
+	schedule.LastRunBy = b.params.NodeID
+
+	if err := b.nats.UpdateSchedule(schedule); err != nil {
+		llog.Debugf("schedule run claimed by another node: %s", err)
+		return
+	}
+
+	llog.Info("Firing scheduled benchmark")
+
+	var jobID string
+
+	settings, err := copySettings(schedule.Settings)
+	if err == nil {
+		settings.ScheduleID = schedule.ID
+
+		if settings.Description == "" {
+			settings.Description = fmt.Sprintf("scheduled by '%s'", schedule.ID)
+		}
+
+		_, err = b.Create(settings)
+		jobID = settings.ID
+	}
+
+	if err != nil {
+		llog.Errorf("unable to create scheduled benchmark: %s", err)
+	}
+
+	if err := b.recordScheduleRun(schedule.ID, jobID, err); err != nil {
+		llog.Errorf("unable to record schedule run: %s", err)
+	}
+}
+
+// recordScheduleRun appends the job ID (or records the error) for the latest
+// schedule run, retrying if the schedule was modified concurrently.
+func (b *Bench) recordScheduleRun(scheduleID, jobID string, runErr error) error {
+	var err error
+
+	for i := 0; i < MaxScheduleUpdateAttempts; i++ {
+		var schedule *types.Schedule
+
+		schedule, err = b.nats.GetSchedule(scheduleID)
+		if err != nil {
+			return errors.Wrap(err, "unable to get schedule")
+		}
+
+		schedule.LastError = ""
+
+		if runErr != nil {
+			schedule.LastError = runErr.Error()
+		}
+
+		if jobID != "" && runErr == nil {
+			schedule.JobIDs = append(schedule.JobIDs, jobID)
+		}
+
+		if err = b.nats.UpdateSchedule(schedule); err == nil {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(err, "unable to update schedule after %d attempts", MaxScheduleUpdateAttempts)
+}
+
+// copySettings deep copies settings; job generation mutates the settings it
+// is given, so a schedule's settings must never be passed in directly.
+func copySettings(settings *types.Settings) (*types.Settings, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal settings")
+	}
+
+	cp := &types.Settings{}
+
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal settings")
+	}
+
+	return cp, nil
+}
@@ -0,0 +1,86 @@
+package bench
+
+import (
+	"context"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+// RunStandalone runs validated settings on this node only and returns the
+// final status once the job is done; used by "njst run". Cancelling ctx
+// cancels the job (the returned status will be "cancelled").
+//
+// Streams of write jobs are deleted afterwards unless keep_streams is set;
+// keep them to run a read job against them later on.
+func (b *Bench) RunStandalone(ctx context.Context, settings *types.Settings) (*types.Status, error) {
+	if !b.params.Standalone {
+		return nil, errors.New("node is not running in standalone mode")
+	}
+
+	if settings == nil {
+		return nil, errors.New("settings cannot be nil")
+	}
+
+	if settings.ID == "" {
+		settings.ID = RandString(8)
+	}
+
+	jobs, err := b.GenerateCreateJobs(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write jobs are generated along with their streams
+	if settings.Write != nil && !settings.Write.KeepStreams {
+		defer func() {
+			if err := b.nats.DeleteStreams(settings.ID); err != nil {
+				b.log.Errorf("unable to delete streams for job '%s': %s", settings.ID, err)
+			}
+		}()
+	}
+
+	if len(jobs) != 1 {
+		return nil, errors.Errorf("expected a single job in standalone mode, got %d", len(jobs))
+	}
+
+	settings.Participants = []string{b.params.NodeID}
+
+	job := b.newJob(settings.ID, jobs[0].Settings)
+	defer b.finishJob(settings.ID, job)
+
+	job.NodeID = jobs[0].NodeID
+	job.CreatedBy = b.params.NodeID
+	job.CreatedAt = jobs[0].CreatedAt
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			job.CancelFunc()
+		case <-job.Context.Done():
+		}
+	}()
+
+	// Stops the goroutine above once the job is done
+	defer job.CancelFunc()
+
+	var status *types.Status
+
+	if job.Settings.Write != nil {
+		status, err = b.runWriteBenchmark(job)
+	} else {
+		status, err = b.runReadBenchmark(job)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "error running benchmark")
+	}
+
+	finalStatus := aggregateStatuses(settings, []*types.Status{status})
+	finalStatus.JobID = settings.ID
+
+	verifyPlan(settings, finalStatus)
+	applyVerdict(settings, finalStatus)
+
+	return finalStatus, nil
+}
@@ -0,0 +1,104 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func standaloneSettings(keepStreams bool) *types.Settings {
+	return &types.Settings{
+		NATS: &types.NATS{Address: "localhost:4222"},
+		Write: &types.WriteSettings{
+			NumNodes:             1,
+			NumStreams:           2,
+			NumMessagesPerStream: 10,
+			NumWorkersPerStream:  1,
+			Subjects:             []string{"foo"},
+			KeepStreams:          keepStreams,
+		},
+	}
+}
+
+func TestRunStandalone(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepStreams bool
+		deleted     bool
+	}{
+		{"streams are deleted", false, true},
+		{"streams are kept", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+			b.params.Standalone = true
+
+			fake.GetNodeListReturns([]string{"node1"}, nil)
+
+			// Workers give up right away
+			fake.NewConnReturns(nil, errors.New("nats: no servers available for connection"))
+
+			settings := standaloneSettings(tt.keepStreams)
+
+			status, err := b.RunStandalone(context.Background(), settings)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if settings.ID == "" || status.JobID != settings.ID {
+				t.Errorf("expected the status of job '%s', got '%s'", settings.ID, status.JobID)
+			}
+
+			if len(settings.Participants) != 1 || settings.Participants[0] != "node1" {
+				t.Errorf("expected node1 to be the only participant, got %v", settings.Participants)
+			}
+
+			if fake.AddStreamCallCount() != 2 {
+				t.Errorf("expected 2 streams to be created, got %d", fake.AddStreamCallCount())
+			}
+
+			if deleted := fake.DeleteStreamsCallCount() == 1; deleted != tt.deleted {
+				t.Errorf("expected streams to be deleted: %v", tt.deleted)
+			}
+
+			if _, ok := b.getJob(settings.ID); ok {
+				t.Error("expected the job to be finished")
+			}
+
+			// Nothing is coordinated with other nodes
+			if fake.RequestJobCallCount() != 0 || fake.SaveSettingsCallCount() != 0 || fake.GetStatusCallCount() != 0 {
+				t.Error("expected no jobs to be sent and no settings or statuses to be read or saved")
+			}
+		})
+	}
+}
+
+func TestRunStandaloneErrors(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	_, err := b.RunStandalone(context.Background(), standaloneSettings(false))
+	checkErr(t, err, "node is not running in standalone mode")
+
+	b.params.Standalone = true
+
+	_, err = b.RunStandalone(context.Background(), nil)
+	checkErr(t, err, "settings cannot be nil")
+
+	// A job that would be split across nodes
+	fake.GetNodeListReturns([]string{"node1", "node2"}, nil)
+
+	settings := standaloneSettings(false)
+	settings.Write.NumNodes = 2
+
+	_, err = b.RunStandalone(context.Background(), settings)
+	checkErr(t, err, "expected a single job in standalone mode, got 2")
+
+	// Its streams were created along with the jobs
+	if fake.DeleteStreamsCallCount() != 1 {
+		t.Error("expected the streams of the rejected job to be deleted")
+	}
+}
@@ -0,0 +1,319 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MaxSweepCombinations = 256
+)
+
+// CreateSweep saves the sweep and starts running the given combinations (as
+// generated by SweepCombinations and then validated) in the background on
+// this node.
+func (b *Bench) CreateSweep(sweep *types.Sweep, combinations []*types.Settings, params []map[string]interface{}) error {
+	if sweep == nil || sweep.Base == nil {
+		return errors.New("sweep and sweep base cannot be nil")
+	}
+
+	if len(combinations) == 0 || len(combinations) != len(params) {
+		return errors.New("combinations and params must be non-empty and of equal length")
+	}
+
+	sweep.ID = RandString(8)
+	sweep.Status = types.InProgressStatus
+	sweep.CreatedBy = b.params.NodeID
+	sweep.CreatedAt = time.Now().UTC()
+	sweep.Results = make([]*types.SweepResult, 0)
+
+	if err := b.nats.SaveSweep(sweep); err != nil {
+		return errors.Wrap(err, "unable to save sweep")
+	}
+
+	go b.runSweep(sweep, combinations, params)
+
+	return nil
+}
+
+// SweepCombinations returns the settings (and the axis values used for them)
+// for every combination of axis values. Axes are iterated in name order, the
+// last axis changing fastest.
+func SweepCombinations(sweep *types.Sweep) ([]*types.Settings, []map[string]interface{}, error) {
+	names := make([]string, 0, len(sweep.Axes))
+	total := 1
+
+	for name, values := range sweep.Axes {
+		if len(values) == 0 {
+			return nil, nil, errors.Errorf("axis '%s' has no values", name)
+		}
+
+		names = append(names, name)
+		total *= len(values)
+
+		if total > MaxSweepCombinations {
+			return nil, nil, errors.Errorf("sweep exceeds %d combinations", MaxSweepCombinations)
+		}
+	}
+
+	sort.Strings(names)
+
+	base, err := json.Marshal(sweep.Base)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to marshal base settings")
+	}
+
+	combinations := make([]*types.Settings, 0, total)
+	params := make([]map[string]interface{}, 0, total)
+
+	for i := 0; i < total; i++ {
+		settingsMap := make(map[string]interface{})
+
+		if err := json.Unmarshal(base, &settingsMap); err != nil {
+			return nil, nil, errors.Wrap(err, "unable to unmarshal base settings")
+		}
+
+		combination := make(map[string]interface{})
+
+		// Mixed radix; last axis changes fastest
+		idx := i
+
+		for j := len(names) - 1; j >= 0; j-- {
+			values := sweep.Axes[names[j]]
+			value := values[idx%len(values)]
+			idx /= len(values)
+
+			if err := applySweepParam(settingsMap, names[j], value); err != nil {
+				return nil, nil, err
+			}
+
+			combination[names[j]] = value
+		}
+
+		data, err := json.Marshal(settingsMap)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "unable to marshal combination")
+		}
+
+		settings := &types.Settings{}
+
+		if err := json.Unmarshal(data, settings); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid value in combination %s", formatSweepParams(combination))
+		}
+
+		settings.ID = ""
+
+		combinations = append(combinations, settings)
+		params = append(params, combination)
+	}
+
+	return combinations, params, nil
+}
+
+func applySweepParam(settingsMap map[string]interface{}, name string, value interface{}) error {
+	section, field := "", name
+
+	if i := strings.Index(name, "."); i != -1 {
+		section, field = name[:i], name[i+1:]
+	} else if field == "address" || field == "shared_connection" {
+		section = "nats"
+	} else if settingsMap["write"] != nil {
+		section = "write"
+	} else {
+		section = "read"
+	}
+
+	sectionMap, ok := settingsMap[section].(map[string]interface{})
+	if !ok {
+		return errors.Errorf("axis '%s': base job has no '%s' settings", name, section)
+	}
+
+	if _, ok := sectionMap[field]; !ok {
+		return errors.Errorf("axis '%s': unknown setting '%s' in '%s' settings", name, field, section)
+	}
+
+	sectionMap[field] = value
+
+	return nil
+}
+
+func formatSweepParams(params map[string]interface{}) string {
+	names := make([]string, 0, len(params))
+
+	for name := range params {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, params[name]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (b *Bench) runSweep(sweep *types.Sweep, combinations []*types.Settings, params []map[string]interface{}) {
+	llog := b.log.WithFields(logrus.Fields{
+		"func":     "runSweep",
+		"sweep_id": sweep.ID,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go b.cancelOnDelete(ctx, cancel, func() error {
+		_, err := b.nats.GetSweep(sweep.ID)
+		return err
+	})
+
+	llog.Infof("Running sweep with %d combination(s)", len(combinations))
+
+	for i, settings := range combinations {
+		result := &types.SweepResult{
+			Params: params[i],
+			Status: types.InProgressStatus,
+		}
+
+		sweep.Results = append(sweep.Results, result)
+
+		if err := b.updateSweep(sweep); err != nil {
+			llog.Errorf("unable to update sweep: %s", err)
+		}
+
+		settings.Description = strings.TrimSpace(fmt.Sprintf("%s [%s]", sweep.Base.Description, formatSweepParams(params[i])))
+
+		status, err := b.runSweepJob(ctx, settings)
+
+		result.JobID = settings.ID
+
+		if status != nil {
+			result.Status = status.Status
+			result.ElapsedSeconds = status.ElapsedSeconds
+			result.TotalProcessed = status.TotalProcessed
+			result.TotalErrors = status.TotalErrors
+			result.TotalMsgPerSecAllNodes = status.TotalMsgPerSecAllNodes
+			result.AvgMsgPerSecPerNode = status.AvgMsgPerSecPerNode
+			result.Latency = status.Latency
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				llog.Info("Sweep cancelled")
+				return
+			}
+
+			// A failed combination is still a data point; keep going
+			result.Status = types.ErrorStatus
+			result.Error = err.Error()
+		}
+	}
+
+	sweep.Status = types.CompletedStatus
+	sweep.Message = "sweep completed"
+	sweep.EndedAt = time.Now().UTC()
+
+	if err := b.updateSweep(sweep); err != nil {
+		llog.Errorf("unable to update sweep: %s", err)
+	}
+
+	llog.Info("Sweep finished")
+}
+
+// runSweepJob runs a single combination and removes the streams it wrote to
+// (unless keep_streams is set) so that they do not pile up during a sweep
+func (b *Bench) runSweepJob(ctx context.Context, settings *types.Settings) (*types.Status, error) {
+	if _, err := b.Create(settings); err != nil {
+		return nil, errors.Wrap(err, "unable to create job")
+	}
+
+	status, err := b.waitForJob(ctx, settings)
+	if err != nil {
+		b.cancelJobs(settings.ID)
+	}
+
+	if settings.Write != nil && !settings.Write.KeepStreams {
+		if err := b.nats.DeleteStreams(settings.ID); err != nil {
+			b.log.Errorf("unable to delete streams for sweep job '%s': %s", settings.ID, err)
+		}
+	}
+
+	return status, err
+}
+
+// checkSweeps fails sweeps that are in progress but whose node is no longer
+// heartbeating; sweeps only run on the node that created them. Run by the
+// watchdog. The job of the combination that was running is left to finish
+// on its own.
+func (b *Bench) checkSweeps(now time.Time) error {
+	sweeps, err := b.nats.GetAllSweeps()
+	if err != nil {
+		return errors.Wrap(err, "unable to get sweeps")
+	}
+
+	var alive map[string]bool
+
+	for _, sweep := range sweeps {
+		if sweep.Status != types.InProgressStatus {
+			continue
+		}
+
+		// Only look up the node list if there is anything to check
+		if alive == nil {
+			nodes, err := b.nats.GetNodeList()
+			if err != nil {
+				return errors.Wrap(err, "unable to get node list")
+			}
+
+			alive = make(map[string]bool, len(nodes))
+
+			for _, nodeID := range nodes {
+				alive[nodeID] = true
+			}
+		}
+
+		if alive[sweep.CreatedBy] {
+			continue
+		}
+
+		reason := fmt.Sprintf("node '%s' running the sweep is gone", sweep.CreatedBy)
+
+		b.log.Warningf("watchdog: %s; failing sweep '%s'", reason, sweep.ID)
+
+		for _, result := range sweep.Results {
+			if result.Status == types.InProgressStatus {
+				result.Status = types.FailedStatus
+				result.Error = reason
+			}
+		}
+
+		sweep.Status = types.FailedStatus
+		sweep.Message = reason
+		sweep.EndedAt = now
+
+		if err := b.updateSweep(sweep); err != nil {
+			return errors.Wrapf(err, "unable to update sweep '%s'", sweep.ID)
+		}
+	}
+
+	return nil
+}
+
+func (b *Bench) updateSweep(sweep *types.Sweep) error {
+	// Do not resurrect a deleted sweep
+	if _, err := b.nats.GetSweep(sweep.ID); err != nil {
+		return err
+	}
+
+	return b.nats.SaveSweep(sweep)
+}
@@ -0,0 +1,178 @@
+package bench
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestSweepCombinations(t *testing.T) {
+	sweep := &types.Sweep{
+		Base: &types.Settings{
+			NATS:  &types.NATS{Address: "localhost:4222"},
+			Write: &types.WriteSettings{NumStreams: 1, NumMessagesPerStream: 100, MsgSizeBytes: 1024},
+		},
+		Axes: map[string][]interface{}{
+			"num_workers_per_stream": {1, 2},
+			"msg_size_bytes":         {128, 256, 512},
+		},
+	}
+
+	combinations, params, err := SweepCombinations(sweep)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(combinations) != 6 || len(params) != 6 {
+		t.Fatalf("expected 6 combinations, got %d", len(combinations))
+	}
+
+	// Axes in name order, the last axis changing fastest
+	var got [][2]int
+
+	for _, c := range combinations {
+		got = append(got, [2]int{c.Write.MsgSizeBytes, c.Write.NumWorkersPerStream})
+	}
+
+	expected := [][2]int{{128, 1}, {128, 2}, {256, 1}, {256, 2}, {512, 1}, {512, 2}}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if p := formatSweepParams(params[1]); p != "msg_size_bytes=128 num_workers_per_stream=2" {
+		t.Errorf("unexpected params '%s'", p)
+	}
+
+	// The base job is left as is
+	if sweep.Base.Write.MsgSizeBytes != 1024 || combinations[0].Write.NumMessagesPerStream != 100 ||
+		combinations[0].NATS.Address != "localhost:4222" {
+		t.Error("expected every combination to start from the base job")
+	}
+}
+
+func TestSweepCombinationsErrors(t *testing.T) {
+	tooMany := make([]interface{}, 17)
+
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
+	tests := []struct {
+		name     string
+		axes     map[string][]interface{}
+		expected string
+	}{
+		{"no values", map[string][]interface{}{"msg_size_bytes": {}}, "axis 'msg_size_bytes' has no values"},
+		{"unknown field", map[string][]interface{}{"msg_size": {1}}, "axis 'msg_size': unknown setting 'msg_size' in 'write' settings"},
+		{"missing section", map[string][]interface{}{"read.num_streams": {1}}, "axis 'read.num_streams': base job has no 'read' settings"},
+		{"invalid value", map[string][]interface{}{"msg_size_bytes": {"big"}}, "invalid value in combination msg_size_bytes=big"},
+		{"too many combinations", map[string][]interface{}{"msg_size_bytes": tooMany, "num_streams": tooMany},
+			"sweep exceeds 256 combinations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sweep := &types.Sweep{
+				Base: &types.Settings{Write: &types.WriteSettings{NumStreams: 1}},
+				Axes: tt.axes,
+			}
+
+			if _, _, err := SweepCombinations(sweep); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing '%s', got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestApplySweepParam(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		param    string
+		section  string
+		field    string
+	}{
+		{"bare write setting", map[string]interface{}{"write": map[string]interface{}{"msg_size_bytes": 1}},
+			"msg_size_bytes", "write", "msg_size_bytes"},
+		{"bare read setting", map[string]interface{}{"read": map[string]interface{}{"batch_size": 1}},
+			"batch_size", "read", "batch_size"},
+		{"bare nats setting", map[string]interface{}{"nats": map[string]interface{}{"shared_connection": false},
+			"write": map[string]interface{}{}}, "shared_connection", "nats", "shared_connection"},
+		{"qualified setting", map[string]interface{}{"read": map[string]interface{}{"num_streams": 1},
+			"write": map[string]interface{}{"num_streams": 1}}, "read.num_streams", "read", "num_streams"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := applySweepParam(tt.settings, tt.param, 42); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if v := tt.settings[tt.section].(map[string]interface{})[tt.field]; v != 42 {
+				t.Errorf("expected %s.%s to be set, got %v", tt.section, tt.field, v)
+			}
+		})
+	}
+}
+
+func TestCheckSweeps(t *testing.T) {
+	b, fake := newTestBench(t)
+	now := time.Now().UTC()
+
+	orphaned := &types.Sweep{
+		ID:        "a",
+		Status:    types.InProgressStatus,
+		CreatedBy: "node2",
+		Results: []*types.SweepResult{
+			{JobID: "j1", Status: types.CompletedStatus},
+			{JobID: "j2", Status: types.InProgressStatus},
+		},
+	}
+
+	fake.GetAllSweepsReturns([]*types.Sweep{
+		orphaned,
+		{ID: "b", Status: types.InProgressStatus, CreatedBy: "node1"},
+		{ID: "c", Status: types.CompletedStatus, CreatedBy: "node2"},
+	}, nil)
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+	fake.GetSweepReturns(orphaned, nil)
+
+	if err := b.checkSweeps(now); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.SaveSweepCallCount() != 1 {
+		t.Fatalf("expected only the orphaned sweep to be saved, got %d", fake.SaveSweepCallCount())
+	}
+
+	saved := fake.SaveSweepArgsForCall(0)
+
+	if saved.ID != "a" || saved.Status != types.FailedStatus || !saved.EndedAt.Equal(now) ||
+		saved.Message != "node 'node2' running the sweep is gone" {
+		t.Errorf("unexpected sweep %+v", saved)
+	}
+
+	if saved.Results[0].Status != types.CompletedStatus || saved.Results[1].Status != types.FailedStatus {
+		t.Errorf("expected only the running combination to fail, got %s and %s", saved.Results[0].Status,
+			saved.Results[1].Status)
+	}
+
+	// Nothing to check; the node list is not looked up
+	b, fake = newTestBench(t)
+
+	fake.GetAllSweepsReturns([]*types.Sweep{{ID: "c", Status: types.CompletedStatus}}, nil)
+	fake.GetNodeListReturns(nil, errors.New("no heartbeats"))
+
+	if err := b.checkSweeps(now); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if fake.GetNodeListCallCount() != 0 {
+		t.Error("expected the node list not to be looked up")
+	}
+}
@@ -0,0 +1,162 @@
+package bench
+
+import (
+	"sort"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+// timelineRecorder writes a timeline sample with the job's activity since
+// the previous sample every time record() is called
+type timelineRecorder struct {
+	b        *Bench
+	job      *types.Job
+	metrics  *jobMetrics
+	last     *jobCounters
+	lastTime time.Time
+}
+
+func (b *Bench) newTimelineRecorder(job *types.Job) *timelineRecorder {
+	r := &timelineRecorder{
+		b:        b,
+		job:      job,
+		metrics:  b.metrics.getJob(job.Settings.ID),
+		lastTime: time.Now(),
+	}
+
+	if r.metrics != nil {
+		r.last = r.metrics.counters()
+	}
+
+	return r
+}
+
+func (r *timelineRecorder) record() {
+	if r.metrics == nil {
+		return
+	}
+
+	now := time.Now()
+	current := r.metrics.counters()
+	interval := now.Sub(r.lastTime).Seconds()
+
+	if interval <= 0 {
+		return
+	}
+
+	latency := subtractLatency(current.latency, r.last.latency)
+
+	sample := &types.TimelineSample{
+		JobID:            r.job.Settings.ID,
+		NodeID:           r.job.NodeID,
+		Timestamp:        now.UTC(),
+		IntervalSeconds:  round(interval, 3),
+		Messages:         current.messages - r.last.messages,
+		Bytes:            current.bytes - r.last.bytes,
+		Errors:           current.errors - r.last.errors,
+		Latency:          summarizeLatency(latency),
+		LatencyHistogram: latency,
+	}
+
+	sample.MsgPerSec = round(float64(sample.Messages)/interval, 2)
+	sample.BytesPerSec = round(float64(sample.Bytes)/interval, 2)
+
+	r.last = current
+	r.lastTime = now
+
+	if err := r.b.nats.WriteTimelineSample(sample); err != nil {
+		r.b.log.Errorf("unable to write timeline sample for job '%s': %s", sample.JobID, err)
+	}
+}
+
+// subtractLatency returns the observations in cur that are not in prev. The
+// max of the difference is exact if it is a new overall max, otherwise it is
+// estimated as the upper bound of the highest non-empty bucket.
+func subtractLatency(cur, prev *types.LatencyHistogram) *types.LatencyHistogram {
+	h := newLatencyHistogram()
+
+	for i := range h.Buckets {
+		h.Buckets[i] = cur.Buckets[i] - prev.Buckets[i]
+	}
+
+	h.Count = cur.Count - prev.Count
+	h.SumMs = cur.SumMs - prev.SumMs
+
+	if cur.MaxMs > prev.MaxMs {
+		h.MaxMs = cur.MaxMs
+		return h
+	}
+
+	for i := len(h.Buckets) - 1; i >= 0; i-- {
+		if h.Buckets[i] == 0 {
+			continue
+		}
+
+		h.MaxMs = cur.MaxMs
+
+		if i < len(LatencyBucketsMs) && LatencyBucketsMs[i] < h.MaxMs {
+			h.MaxMs = LatencyBucketsMs[i]
+		}
+
+		break
+	}
+
+	return h
+}
+
+// Timeline returns the timeline samples of all nodes for a job, along with
+// per-second totals across all nodes
+func (b *Bench) Timeline(jobID string) (*types.Timeline, error) {
+	samples, err := b.nats.GetTimelineSamples(jobID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get timeline samples")
+	}
+
+	timeline := &types.Timeline{
+		JobID:  jobID,
+		Totals: make([]*types.TimelineSample, 0),
+		Nodes:  make(map[string][]*types.TimelineSample),
+	}
+
+	totals := make(map[time.Time]*types.TimelineSample)
+
+	for _, sample := range samples {
+		timeline.Nodes[sample.NodeID] = append(timeline.Nodes[sample.NodeID], sample)
+
+		// Node reporters are not aligned; bucket samples by the second they ended in
+		second := sample.Timestamp.Truncate(time.Second)
+
+		total, ok := totals[second]
+		if !ok {
+			total = &types.TimelineSample{
+				JobID:            jobID,
+				Timestamp:        second,
+				IntervalSeconds:  ReporterFrequency.Seconds(),
+				LatencyHistogram: newLatencyHistogram(),
+			}
+
+			totals[second] = total
+			timeline.Totals = append(timeline.Totals, total)
+		}
+
+		total.Messages += sample.Messages
+		total.Bytes += sample.Bytes
+		total.Errors += sample.Errors
+
+		mergeLatency(total.LatencyHistogram, sample.LatencyHistogram)
+	}
+
+	sort.Slice(timeline.Totals, func(i, j int) bool {
+		return timeline.Totals[i].Timestamp.Before(timeline.Totals[j].Timestamp)
+	})
+
+	for _, total := range timeline.Totals {
+		total.MsgPerSec = round(float64(total.Messages)/total.IntervalSeconds, 2)
+		total.BytesPerSec = round(float64(total.Bytes)/total.IntervalSeconds, 2)
+		total.Latency = summarizeLatency(total.LatencyHistogram)
+	}
+
+	return timeline, nil
+}
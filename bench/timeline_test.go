@@ -0,0 +1,149 @@
+package bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestTimelineRecorder(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	settings := &types.Settings{ID: "abc"}
+	job := &types.Job{NodeID: "node1", Settings: settings}
+
+	// Jobs without metrics are not sampled
+	b.newTimelineRecorder(job).record()
+
+	if fake.WriteTimelineSampleCallCount() != 0 {
+		t.Fatal("expected no sample without metrics")
+	}
+
+	jm := b.metrics.startJob(settings, "write", []string{"njst-abc-0"})
+
+	// Activity before the recorder starts is not part of the first sample
+	jm.streams["njst-abc-0"].addMessages(100, 10000)
+
+	r := b.newTimelineRecorder(job)
+
+	jm.streams["njst-abc-0"].addMessages(10, 1000)
+	jm.streams["njst-abc-0"].addError(PublishErrorCategory)
+	jm.observeLatency(3 * time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+	r.record()
+
+	jm.streams["njst-abc-0"].addMessages(5, 500)
+
+	time.Sleep(10 * time.Millisecond)
+	r.record()
+
+	if fake.WriteTimelineSampleCallCount() != 2 {
+		t.Fatalf("expected 2 samples, got %d", fake.WriteTimelineSampleCallCount())
+	}
+
+	first := fake.WriteTimelineSampleArgsForCall(0)
+
+	if first.JobID != "abc" || first.NodeID != "node1" || first.Messages != 10 || first.Bytes != 1000 || first.Errors != 1 {
+		t.Errorf("unexpected first sample %+v", first)
+	}
+
+	if first.IntervalSeconds <= 0 || first.MsgPerSec <= 0 || first.BytesPerSec <= first.MsgPerSec {
+		t.Errorf("unexpected rate %v over %vs", first.MsgPerSec, first.IntervalSeconds)
+	}
+
+	if first.LatencyHistogram.Count != 1 || first.Latency == nil {
+		t.Errorf("expected 1 latency observation, got %+v", first.LatencyHistogram)
+	}
+
+	// Each sample only holds the activity since the previous one
+	second := fake.WriteTimelineSampleArgsForCall(1)
+
+	if second.Messages != 5 || second.Bytes != 500 || second.Errors != 0 || second.LatencyHistogram.Count != 0 ||
+		second.Latency != nil {
+		t.Errorf("unexpected second sample %+v", second)
+	}
+}
+
+func TestSubtractLatency(t *testing.T) {
+	tests := []struct {
+		name  string
+		added []time.Duration
+		count uint64
+		maxMs float64
+	}{
+		{"new max is exact", []time.Duration{3 * time.Millisecond, 80 * time.Millisecond}, 2, 80},
+		{"max is estimated from the highest bucket", []time.Duration{3 * time.Millisecond}, 1, 5},
+		{"max is capped by the overall max", []time.Duration{40 * time.Millisecond}, 1, 50},
+		{"no observations", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := newLatencyHistogram()
+			cur := newLatencyHistogram()
+
+			for _, h := range []*types.LatencyHistogram{prev, cur} {
+				observeLatency(h, time.Millisecond)
+				observeLatency(h, 50*time.Millisecond)
+			}
+
+			for _, d := range tt.added {
+				observeLatency(cur, d)
+			}
+
+			h := subtractLatency(cur, prev)
+
+			if h.Count != tt.count || h.MaxMs != tt.maxMs {
+				t.Errorf("expected %d observation(s) with max %vms, got %d with max %vms", tt.count, tt.maxMs,
+					h.Count, h.MaxMs)
+			}
+		})
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	latency := newLatencyHistogram()
+	observeLatency(latency, 2*time.Millisecond)
+
+	// Node reporters are not aligned
+	fake.GetTimelineSamplesReturns([]*types.TimelineSample{
+		{NodeID: "node1", Timestamp: start.Add(1100 * time.Millisecond), Messages: 100, Bytes: 1000, LatencyHistogram: latency},
+		{NodeID: "node2", Timestamp: start.Add(1900 * time.Millisecond), Messages: 50, Bytes: 500, Errors: 1},
+		{NodeID: "node1", Timestamp: start.Add(200 * time.Millisecond), Messages: 10, Bytes: 100},
+	}, nil)
+
+	timeline, err := b.Timeline("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(timeline.Nodes["node1"]) != 2 || len(timeline.Nodes["node2"]) != 1 {
+		t.Errorf("unexpected node samples %+v", timeline.Nodes)
+	}
+
+	// Totals per second, in order
+	if len(timeline.Totals) != 2 {
+		t.Fatalf("expected 2 totals, got %d", len(timeline.Totals))
+	}
+
+	if total := timeline.Totals[0]; !total.Timestamp.Equal(start) || total.Messages != 10 {
+		t.Errorf("unexpected first total %+v", total)
+	}
+
+	total := timeline.Totals[1]
+
+	if !total.Timestamp.Equal(start.Add(time.Second)) || total.Messages != 150 || total.Bytes != 1500 ||
+		total.Errors != 1 || total.MsgPerSec != 150 || total.BytesPerSec != 1500 {
+		t.Errorf("unexpected second total %+v", total)
+	}
+
+	if total.LatencyHistogram.Count != 1 || total.Latency == nil {
+		t.Errorf("expected the latency of node1 in the total, got %+v", total.LatencyHistogram)
+	}
+}
@@ -0,0 +1,692 @@
+package bench
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	// Size of the header writers prepend to the payload in verify mode:
+	// producer ID (8 bytes), sequence (8 bytes) and a CRC32 of the rest of
+	// the payload (4 bytes)
+	VerifyHeaderSize = 20
+
+	// Sequence ranges a node's status holds at most. Ranges break up when
+	// workers share a consumer; beyond this, only the number of messages
+	// received from a producer is reported.
+	MaxVerifyRanges = 10000
+)
+
+// producer stamps payloads of a single writer worker on a single subject with
+// a sequence starting at 1
+type producer struct {
+	id  uint64
+	seq uint64
+}
+
+// newProducer returns the producer of a writer worker on the s-th subject of
+// a stream. Its ID is the worker's place in the plan, so that read jobs know
+// which producers to expect; a node that took over a lost node's share
+// continues the sequences of the lost node's producers.
+func newProducer(plan *types.Plan, stream string, worker, subject int) *producer {
+	if plan == nil {
+		return &producer{id: producerID(0, worker, subject)}
+	}
+
+	return &producer{
+		id:  producerID(plan.NodeIndex, worker, subject),
+		seq: uint64(plannedOffset(plan, stream, worker, subject)),
+	}
+}
+
+// producerID identifies the producer of a worker of the node with the given
+// index on the s-th subject of a stream
+func producerID(node, worker, subject int) uint64 {
+	return uint64(node)<<32 | uint64(worker&0xffff)<<16 | uint64(subject&0xffff)
+}
+
+// next returns a copy of data with the next sequence number and a checksum
+func (p *producer) next(data []byte) []byte {
+	p.seq++
+
+	payload := make([]byte, len(data))
+	copy(payload, data)
+
+	binary.BigEndian.PutUint64(payload[0:8], p.id)
+	binary.BigEndian.PutUint64(payload[8:16], p.seq)
+	binary.BigEndian.PutUint32(payload[16:20], payloadChecksum(payload))
+
+	return payload
+}
+
+// parsePayload returns the producer ID and sequence of a payload; ok is false
+// if the payload is too short or its checksum does not match
+func parsePayload(payload []byte) (id, seq uint64, ok bool) {
+	if len(payload) < VerifyHeaderSize {
+		return 0, 0, false
+	}
+
+	if binary.BigEndian.Uint32(payload[16:20]) != payloadChecksum(payload) {
+		return 0, 0, false
+	}
+
+	return binary.BigEndian.Uint64(payload[0:8]), binary.BigEndian.Uint64(payload[8:16]), true
+}
+
+// payloadChecksum returns the checksum of everything but the checksum itself
+func payloadChecksum(payload []byte) uint32 {
+	crc := crc32.ChecksumIEEE(payload[0:16])
+	return crc32.Update(crc, crc32.IEEETable, payload[VerifyHeaderSize:])
+}
+
+// verifier keeps track of the messages a reader worker received. Messages
+// of a producer are stored in sequence order and a pull consumer delivers
+// them in stream order, so a worker must see increasing sequences.
+type verifier struct {
+	received   map[string]map[uint64]*seqSet // subject -> producer -> sequences
+	last       map[string]map[uint64]uint64  // subject -> producer -> last sequence
+	messages   int
+	duplicates int
+	reordered  int
+	corrupted  int
+	mutex      *sync.Mutex
+}
+
+func newVerifier() *verifier {
+	return &verifier{
+		received: make(map[string]map[uint64]*seqSet),
+		last:     make(map[string]map[uint64]uint64),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+func (v *verifier) observe(subject string, payload []byte) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.messages++
+
+	id, seq, ok := parsePayload(payload)
+	if !ok {
+		v.corrupted++
+		return
+	}
+
+	if _, ok := v.received[subject]; !ok {
+		v.received[subject] = make(map[uint64]*seqSet)
+		v.last[subject] = make(map[uint64]uint64)
+	}
+
+	set, ok := v.received[subject][id]
+	if !ok {
+		set = &seqSet{}
+		v.received[subject][id] = set
+	}
+
+	if !set.add(seq) {
+		v.duplicates++
+		return
+	}
+
+	if seq < v.last[subject][id] {
+		v.reordered++
+	} else {
+		v.last[subject][id] = seq
+	}
+}
+
+// verifyReports summarizes the verifiers of a node's workers per stream.
+// Sequences are only included if includeSequences is set (for final
+// statuses) as they are only needed to find gaps and duplicates across nodes;
+// at most MaxVerifyRanges ranges are included.
+func verifyReports(workerMap map[string]map[int]*Worker, includeSequences bool) []*types.VerifyReport {
+	reports := make([]*types.VerifyReport, 0)
+	budget := MaxVerifyRanges
+
+	streams := make([]string, 0, len(workerMap))
+
+	for stream := range workerMap {
+		streams = append(streams, stream)
+	}
+
+	sort.Strings(streams)
+
+	for _, stream := range streams {
+		workers := workerMap[stream]
+		report := &types.VerifyReport{Stream: stream}
+		received := make(map[string]map[uint64]*seqSet)
+		verifying := false
+
+		for _, worker := range workers {
+			v := worker.verifier
+
+			if v == nil {
+				continue
+			}
+
+			verifying = true
+
+			v.mutex.Lock()
+
+			report.Messages += v.messages
+			report.Duplicates += v.duplicates + mergeSequences(received, v.received)
+			report.Reordered += v.reordered
+			report.Corrupted += v.corrupted
+
+			v.mutex.Unlock()
+		}
+
+		if !verifying {
+			continue
+		}
+
+		report.Producers = numProducers(received)
+
+		if includeSequences {
+			report.Sequences = encodeSequences(received, &budget)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// aggregateVerifyReports combines the per stream reports of all nodes.
+// Messages received by more than one node are duplicates; gaps are only
+// counted if final is set, because until then the missing messages may
+// still be read by another node. Producers in expected (see
+// expectedSequences) are missing messages up to their last sequence, even
+// if none of their messages were received.
+func aggregateVerifyReports(statuses []*types.Status, expected map[string]map[string]map[uint64]uint64, final bool) []*types.VerifyReport {
+	streams := make(map[string]*types.VerifyReport)
+	received := make(map[string]*streamSequences)
+
+	for stream := range expected {
+		streams[stream] = &types.VerifyReport{Stream: stream}
+		received[stream] = newStreamSequences()
+	}
+
+	for _, s := range statuses {
+		for _, r := range s.Verify {
+			report, ok := streams[r.Stream]
+			if !ok {
+				report = &types.VerifyReport{Stream: r.Stream}
+				streams[r.Stream] = report
+				received[r.Stream] = newStreamSequences()
+			}
+
+			report.Messages += r.Messages
+			report.Duplicates += r.Duplicates + received[r.Stream].merge(r.Sequences)
+			report.Reordered += r.Reordered
+			report.Corrupted += r.Corrupted
+		}
+	}
+
+	reports := make([]*types.VerifyReport, 0, len(streams))
+
+	for stream, report := range streams {
+		seqs := received[stream]
+
+		report.Producers = seqs.numProducers()
+		report.Estimated = seqs.estimated()
+
+		if final {
+			missing, duplicates := seqs.lost(expected[stream])
+
+			report.Gaps = missing
+			report.Duplicates += duplicates
+		}
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Stream < reports[j].Stream
+	})
+
+	return reports
+}
+
+// verifyErrors describes the problems found in every stream
+func verifyErrors(reports []*types.VerifyReport) []string {
+	errs := make([]string, 0)
+
+	for _, r := range reports {
+		if r.Gaps == 0 && r.Duplicates == 0 && r.Reordered == 0 && r.Corrupted == 0 {
+			continue
+		}
+
+		errs = append(errs, fmt.Sprintf("stream '%s' failed verification: %d missing, %d duplicate, "+
+			"%d reordered and %d corrupted message(s)", r.Stream, r.Gaps, r.Duplicates, r.Reordered, r.Corrupted))
+	}
+
+	return errs
+}
+
+// mergeSequences adds the sequences in src to dst and returns the number of
+// sequences that were already in dst
+func mergeSequences(dst, src map[string]map[uint64]*seqSet) int {
+	var overlap int
+
+	for subject, producers := range src {
+		if _, ok := dst[subject]; !ok {
+			dst[subject] = make(map[uint64]*seqSet)
+		}
+
+		for id, set := range producers {
+			if _, ok := dst[subject][id]; !ok {
+				dst[subject][id] = &seqSet{}
+			}
+
+			overlap += dst[subject][id].merge(set)
+		}
+	}
+
+	return overlap
+}
+
+// streamSequences are the sequences received on a stream by all nodes.
+// Sequences of producers that some node only reported the number of
+// messages of are counted instead.
+type streamSequences struct {
+	exact   map[string]map[uint64]*seqSet   // subject -> producer -> sequences
+	counted map[string]map[uint64]*seqCount // subject -> producer -> count
+}
+
+type seqCount struct {
+	count, last uint64
+}
+
+func newStreamSequences() *streamSequences {
+	return &streamSequences{
+		exact:   make(map[string]map[uint64]*seqSet),
+		counted: make(map[string]map[uint64]*seqCount),
+	}
+}
+
+// merge adds the sequences of a node's report and returns the number of
+// sequences that were already received
+func (s *streamSequences) merge(sequences map[string]map[string]*types.SeqSummary) int {
+	var overlap int
+
+	for subject, producers := range sequences {
+		if _, ok := s.exact[subject]; !ok {
+			s.exact[subject] = make(map[uint64]*seqSet)
+			s.counted[subject] = make(map[uint64]*seqCount)
+		}
+
+		for hexID, summary := range producers {
+			id, err := strconv.ParseUint(hexID, 16, 64)
+			if err != nil || summary == nil {
+				continue
+			}
+
+			set, isExact := s.exact[subject][id]
+			c, isCounted := s.counted[subject][id]
+
+			if !isCounted && len(summary.Ranges) == 0 {
+				c = &seqCount{}
+
+				if isExact {
+					c.count, c.last = set.size(), set.last()
+					delete(s.exact[subject], id)
+				}
+
+				s.counted[subject][id] = c
+				isCounted = true
+			}
+
+			if isCounted {
+				c.count += summary.Count
+
+				if summary.Last > c.last {
+					c.last = summary.Last
+				}
+
+				continue
+			}
+
+			if !isExact {
+				set = &seqSet{}
+				s.exact[subject][id] = set
+			}
+
+			overlap += set.merge(decodeRanges(summary.Ranges))
+		}
+	}
+
+	return overlap
+}
+
+func (s *streamSequences) numProducers() int {
+	n := numProducers(s.exact)
+
+	for _, producers := range s.counted {
+		n += len(producers)
+	}
+
+	return n
+}
+
+// estimated returns true if some producers are counted
+func (s *streamSequences) estimated() bool {
+	for _, producers := range s.counted {
+		if len(producers) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lost returns the number of messages missing from the sequences and the
+// number of duplicates among the counted ones; expected holds the last
+// sequence of every producer per subject, if known. Messages of a counted
+// producer beyond its last sequence are taken to be duplicates.
+func (s *streamSequences) lost(expected map[string]map[uint64]uint64) (missing, duplicates int) {
+	for subject, producers := range s.exact {
+		for id, set := range producers {
+			missing += set.missing(expected[subject][id])
+		}
+	}
+
+	for subject, producers := range s.counted {
+		for id, c := range producers {
+			last := c.last
+
+			if e := expected[subject][id]; e > last {
+				last = e
+			}
+
+			if c.count < last {
+				missing += int(last - c.count)
+			} else {
+				duplicates += int(c.count - last)
+			}
+		}
+	}
+
+	for subject, producers := range expected {
+		for id, last := range producers {
+			_, isExact := s.exact[subject][id]
+			_, isCounted := s.counted[subject][id]
+
+			if !isExact && !isCounted {
+				missing += int(last)
+			}
+		}
+	}
+
+	return missing, duplicates
+}
+
+// expectedSequences returns the last sequence of every producer of the write
+// job, per stream and subject, for the subjects a read job reads in full.
+// Returns nil if the write job is not known.
+func expectedSequences(read *types.ReadSettings) map[string]map[string]map[uint64]uint64 {
+	if read == nil || read.Written == nil || len(read.Subjects) == 0 {
+		return nil
+	}
+
+	written := read.Written
+
+	plans, err := newPlans(1, written.NumMessagesPerStream, len(written.Subjects), written.NumNodes,
+		written.NumWorkersPerStream)
+	if err != nil {
+		return nil
+	}
+
+	writeShares := splitEvenly(written.NumMessagesPerStream, len(written.Subjects), 0)
+	readShares := splitEvenly(read.NumMessagesPerStream, len(read.Subjects), 0)
+
+	expected := make(map[string]map[string]map[uint64]uint64)
+
+	for _, info := range read.Streams {
+		r := subjectIndex(read.Subjects, info)
+		if r < 0 {
+			continue
+		}
+
+		s := -1
+
+		for i, subj := range written.Subjects {
+			if subj == read.Subjects[r] {
+				s = i
+			}
+		}
+
+		// The read job stops before the end of the subject; messages after
+		// that are not missing
+		if s < 0 || readShares[r] < writeShares[s] {
+			continue
+		}
+
+		producers := make(map[uint64]uint64)
+
+		for _, plan := range plans {
+			for w, subjects := range plan.Workers {
+				if n := subjects[s]; n > 0 {
+					producers[producerID(plan.NodeIndex, w, s)] = uint64(n)
+				}
+			}
+		}
+
+		if _, ok := expected[info.StreamName]; !ok {
+			expected[info.StreamName] = make(map[string]map[uint64]uint64)
+		}
+
+		expected[info.StreamName][read.Subjects[r]] = producers
+	}
+
+	return expected
+}
+
+func numProducers(received map[string]map[uint64]*seqSet) int {
+	var n int
+
+	for _, producers := range received {
+		n += len(producers)
+	}
+
+	return n
+}
+
+// encodeSequences converts sequences to their JSON representation: producer
+// IDs in hex and ranges as flat [first, last, first, last, ..] lists. Ranges
+// are only included as long as budget allows.
+func encodeSequences(received map[string]map[uint64]*seqSet, budget *int) map[string]map[string]*types.SeqSummary {
+	encoded := make(map[string]map[string]*types.SeqSummary, len(received))
+
+	subjects := make([]string, 0, len(received))
+
+	for subject := range received {
+		subjects = append(subjects, subject)
+	}
+
+	sort.Strings(subjects)
+
+	for _, subject := range subjects {
+		producers := received[subject]
+		encoded[subject] = make(map[string]*types.SeqSummary, len(producers))
+
+		ids := make([]uint64, 0, len(producers))
+
+		for id := range producers {
+			ids = append(ids, id)
+		}
+
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+
+		for _, id := range ids {
+			set := producers[id]
+
+			summary := &types.SeqSummary{
+				First: set.first(),
+				Last:  set.last(),
+				Count: set.size(),
+			}
+
+			if len(set.ranges) <= *budget {
+				summary.Ranges = make([]uint64, 0, 2*len(set.ranges))
+
+				for _, r := range set.ranges {
+					summary.Ranges = append(summary.Ranges, r.first, r.last)
+				}
+
+				*budget -= len(set.ranges)
+			}
+
+			encoded[subject][strconv.FormatUint(id, 16)] = summary
+		}
+	}
+
+	return encoded
+}
+
+func decodeRanges(ranges []uint64) *seqSet {
+	set := &seqSet{}
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		set.ranges = append(set.ranges, seqRange{first: ranges[i], last: ranges[i+1]})
+	}
+
+	return set
+}
+
+type seqRange struct {
+	first, last uint64
+}
+
+// seqSet is a set of sequences stored as sorted, non-adjacent ranges
+type seqSet struct {
+	ranges []seqRange
+}
+
+// add adds seq to the set and returns false if it was already in it
+func (s *seqSet) add(seq uint64) bool {
+	return s.addRange(seqRange{first: seq, last: seq}) == 0
+}
+
+// merge adds all sequences of other to the set and returns the number of
+// sequences that were already in it
+func (s *seqSet) merge(other *seqSet) int {
+	var overlap int
+
+	for _, r := range other.ranges {
+		overlap += s.addRange(r)
+	}
+
+	return overlap
+}
+
+// addRange adds r to the set and returns the number of sequences of r that
+// were already in it
+func (s *seqSet) addRange(r seqRange) int {
+	n := len(s.ranges)
+
+	// Fast path: sequences mostly arrive in order
+	if n == 0 || r.first > s.ranges[n-1].last+1 {
+		s.ranges = append(s.ranges, r)
+		return 0
+	}
+
+	if r.first == s.ranges[n-1].last+1 {
+		s.ranges[n-1].last = r.last
+		return 0
+	}
+
+	// First range that ends at or after r.first-1, i.e. the first range r
+	// can touch
+	i := sort.Search(n, func(i int) bool {
+		return s.ranges[i].last+1 >= r.first
+	})
+
+	merged := r
+	overlap := 0
+	j := i
+
+	for ; j < n && s.ranges[j].first <= r.last+1; j++ {
+		overlap += overlapSize(s.ranges[j], r)
+
+		if s.ranges[j].first < merged.first {
+			merged.first = s.ranges[j].first
+		}
+
+		if s.ranges[j].last > merged.last {
+			merged.last = s.ranges[j].last
+		}
+	}
+
+	ranges := make([]seqRange, 0, n-(j-i)+1)
+	ranges = append(ranges, s.ranges[:i]...)
+	ranges = append(ranges, merged)
+	ranges = append(ranges, s.ranges[j:]...)
+
+	s.ranges = ranges
+
+	return overlap
+}
+
+func (s *seqSet) first() uint64 {
+	if len(s.ranges) == 0 {
+		return 0
+	}
+
+	return s.ranges[0].first
+}
+
+func (s *seqSet) last() uint64 {
+	if len(s.ranges) == 0 {
+		return 0
+	}
+
+	return s.ranges[len(s.ranges)-1].last
+}
+
+// size returns the number of sequences in the set
+func (s *seqSet) size() uint64 {
+	var n uint64
+
+	for _, r := range s.ranges {
+		n += r.last - r.first + 1
+	}
+
+	return n
+}
+
+// missing returns the number of sequences missing between 1 and last, or the
+// highest sequence in the set if that is higher
+func (s *seqSet) missing(last uint64) int {
+	if s.last() > last {
+		last = s.last()
+	}
+
+	return int(last - s.size())
+}
+
+func overlapSize(a, b seqRange) int {
+	first, last := a.first, a.last
+
+	if b.first > first {
+		first = b.first
+	}
+
+	if b.last < last {
+		last = b.last
+	}
+
+	if first > last {
+		return 0
+	}
+
+	return int(last - first + 1)
+}
@@ -0,0 +1,423 @@
+package bench
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestProducerPayloads(t *testing.T) {
+	data := make([]byte, 64)
+
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	p := newProducer(nil, "njst-w1-0", 0, 0)
+
+	for i := uint64(1); i <= 3; i++ {
+		payload := p.next(data)
+
+		if len(payload) != len(data) {
+			t.Fatalf("expected payload of %d bytes, got %d", len(data), len(payload))
+		}
+
+		id, seq, ok := parsePayload(payload)
+		if !ok || id != p.id || seq != i {
+			t.Errorf("expected producer %x, sequence %d; got %x, %d (ok: %v)", p.id, i, id, seq, ok)
+		}
+	}
+
+	if data[0] != 0 || data[19] != 19 {
+		t.Error("producer must not modify the shared data")
+	}
+
+	payload := p.next(data)
+	payload[len(payload)-1] ^= 0xff
+
+	if _, _, ok := parsePayload(payload); ok {
+		t.Error("expected corrupted payload to fail the checksum")
+	}
+
+	if _, _, ok := parsePayload(payload[:VerifyHeaderSize-1]); ok {
+		t.Error("expected short payload to fail")
+	}
+
+	// A node that took over the share of node index 2 continues the
+	// sequences of its producers
+	plan := &types.Plan{NodeIndex: 2, Offsets: map[string][][]int{"njst-w1-0": {{0, 0}, {0, 5}}}}
+
+	p = newProducer(plan, "njst-w1-0", 1, 1)
+
+	if id, seq, _ := parsePayload(p.next(data)); id != producerID(2, 1, 1) || seq != 6 {
+		t.Errorf("expected producer %x, sequence 6; got %x, %d", producerID(2, 1, 1), id, seq)
+	}
+
+	if producerID(0, 1, 0) == producerID(0, 0, 1) || producerID(1, 0, 0) == producerID(0, 0, 0) {
+		t.Error("expected producers of different workers and subjects to differ")
+	}
+}
+
+func TestSeqSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		seqs       []uint64
+		last       uint64
+		ranges     []seqRange
+		dups       int
+		numMissing int
+	}{
+		{"in order", []uint64{1, 2, 3, 4}, 0, []seqRange{{1, 4}}, 0, 0},
+		{"gap", []uint64{1, 2, 5, 6}, 0, []seqRange{{1, 2}, {5, 6}}, 0, 2},
+		{"missing first", []uint64{3, 4}, 0, []seqRange{{3, 4}}, 0, 2},
+		{"out of order", []uint64{1, 4, 3, 2}, 0, []seqRange{{1, 4}}, 0, 0},
+		{"fill gap between ranges", []uint64{1, 3, 5, 2, 4}, 0, []seqRange{{1, 5}}, 0, 0},
+		{"duplicates", []uint64{1, 2, 2, 3, 1}, 0, []seqRange{{1, 3}}, 2, 0},
+		{"insert before first", []uint64{5, 6, 1}, 0, []seqRange{{1, 1}, {5, 6}}, 0, 3},
+		{"missing last", []uint64{1, 2, 3}, 5, []seqRange{{1, 3}}, 0, 2},
+		{"gap and missing last", []uint64{1, 3}, 4, []seqRange{{1, 1}, {3, 3}}, 0, 2},
+		{"beyond last", []uint64{1, 2, 3, 4}, 2, []seqRange{{1, 4}}, 0, 0},
+		{"none received", nil, 3, nil, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := &seqSet{}
+			dups := 0
+
+			for _, seq := range tt.seqs {
+				if !set.add(seq) {
+					dups++
+				}
+			}
+
+			if !reflect.DeepEqual(set.ranges, tt.ranges) {
+				t.Errorf("expected ranges %v, got %v", tt.ranges, set.ranges)
+			}
+
+			if dups != tt.dups {
+				t.Errorf("expected %d duplicates, got %d", tt.dups, dups)
+			}
+
+			if n := set.missing(tt.last); n != tt.numMissing {
+				t.Errorf("expected %d missing, got %d", tt.numMissing, n)
+			}
+		})
+	}
+}
+
+func TestSeqSetMerge(t *testing.T) {
+	set := &seqSet{ranges: []seqRange{{1, 10}, {21, 30}, {41, 50}}}
+
+	overlap := set.merge(&seqSet{ranges: []seqRange{{5, 25}, {31, 35}, {60, 60}}})
+
+	// 5-10 and 21-25
+	if overlap != 11 {
+		t.Errorf("expected 11 overlapping sequences, got %d", overlap)
+	}
+
+	expected := []seqRange{{1, 35}, {41, 50}, {60, 60}}
+
+	if !reflect.DeepEqual(set.ranges, expected) {
+		t.Errorf("expected %v, got %v", expected, set.ranges)
+	}
+
+	if n := set.missing(0); n != 14 {
+		t.Errorf("expected 14 missing, got %d", n)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	data := make([]byte, 32)
+	p1, p2 := newProducer(nil, "njst-w1-0", 0, 0), newProducer(nil, "njst-w1-0", 1, 0)
+
+	payloads := make([][]byte, 0)
+
+	for i := 0; i < 5; i++ {
+		payloads = append(payloads, p1.next(data))
+	}
+
+	v := newVerifier()
+
+	// 1, 2, 4, 3 (reordered), 3 (duplicate), 5 from p1
+	for _, i := range []int{0, 1, 3, 2, 2, 4} {
+		v.observe("foo", payloads[i])
+	}
+
+	// The same producer on another subject is tracked separately
+	v.observe("bar", payloads[0])
+
+	v.observe("foo", p2.next(data))
+
+	corrupted := p2.next(data)
+	corrupted[VerifyHeaderSize] ^= 0xff
+
+	v.observe("foo", corrupted)
+
+	if v.messages != 9 || v.duplicates != 1 || v.reordered != 1 || v.corrupted != 1 {
+		t.Errorf("unexpected counts: %d messages, %d duplicates, %d reordered, %d corrupted", v.messages,
+			v.duplicates, v.reordered, v.corrupted)
+	}
+
+	if len(v.received["foo"]) != 2 || len(v.received["bar"]) != 1 {
+		t.Errorf("expected 2 producers on foo and 1 on bar, got %d and %d", len(v.received["foo"]),
+			len(v.received["bar"]))
+	}
+}
+
+func TestAggregateVerifyReports(t *testing.T) {
+	data := make([]byte, 32)
+	p := newProducer(nil, "njst-w1-0", 0, 0)
+
+	payloads := make([][]byte, 0)
+
+	for i := 0; i < 10; i++ {
+		payloads = append(payloads, p.next(data))
+	}
+
+	// Two workers on node1 read 1-3 and 4-5, node2 reads 5-6 (5 is a
+	// duplicate across nodes) and 9-10; 7 and 8 are missing
+	node1 := map[string]map[int]*Worker{"njst-w1-0": {0: {verifier: newVerifier()}, 1: {verifier: newVerifier()}}}
+	node2 := map[string]map[int]*Worker{"njst-w1-0": {0: {verifier: newVerifier()}}}
+
+	for i := 0; i < 3; i++ {
+		node1["njst-w1-0"][0].verifier.observe("foo", payloads[i])
+	}
+
+	for i := 3; i < 5; i++ {
+		node1["njst-w1-0"][1].verifier.observe("foo", payloads[i])
+	}
+
+	for _, i := range []int{4, 5, 8, 9} {
+		node2["njst-w1-0"][0].verifier.observe("foo", payloads[i])
+	}
+
+	statuses := []*types.Status{
+		{Status: types.CompletedStatus, Verify: verifyReports(node1, true)},
+		{Status: types.CompletedStatus, Verify: verifyReports(node2, true)},
+	}
+
+	if n := len(statuses[0].Verify); n != 1 || statuses[0].Verify[0].Sequences == nil {
+		t.Fatalf("expected a report with sequences for 1 stream, got %d", n)
+	}
+
+	status := aggregateStatuses(nil, statuses)
+
+	expected := []*types.VerifyReport{
+		{Stream: "njst-w1-0", Messages: 9, Producers: 1, Gaps: 2, Duplicates: 1},
+	}
+
+	if !reflect.DeepEqual(status.Verify, expected) {
+		t.Errorf("expected %+v, got %+v", expected[0], status.Verify[0])
+	}
+
+	if len(status.Errors) != 1 {
+		t.Errorf("expected a verification error, got %v", status.Errors)
+	}
+
+	// Gaps are only counted once the job is final
+	statuses[1].Status = types.InProgressStatus
+	statuses[1].Verify = verifyReports(node2, false)
+
+	status = aggregateStatuses(nil, statuses)
+
+	if status.Verify[0].Gaps != 0 || len(status.Errors) != 0 {
+		t.Errorf("expected no gaps or errors while in progress, got %+v, %v", status.Verify[0], status.Errors)
+	}
+}
+
+func TestExpectedSequences(t *testing.T) {
+	streams := []*types.StreamInfo{
+		{StreamName: "njst-w1-0", SubjectName: "njst-w1-0.foo"},
+		{StreamName: "njst-w1-0", SubjectName: "njst-w1-0.bar"},
+	}
+
+	written := &types.Written{NumNodes: 2, NumMessagesPerStream: 11, NumWorkersPerStream: 2, Subjects: []string{"foo", "bar"}}
+
+	// foo gets 6 messages (2, 2, 1 and 1 per worker), bar gets 5 (1, 1, 2
+	// and 1; the extra messages rotate between subjects)
+	foo := map[uint64]uint64{
+		producerID(0, 0, 0): 2, producerID(0, 1, 0): 2, producerID(1, 0, 0): 1, producerID(1, 1, 0): 1,
+	}
+	bar := map[uint64]uint64{
+		producerID(0, 0, 1): 1, producerID(0, 1, 1): 1, producerID(1, 0, 1): 2, producerID(1, 1, 1): 1,
+	}
+
+	tests := []struct {
+		name     string
+		read     *types.ReadSettings
+		expected map[string]map[string]map[uint64]uint64
+	}{
+		{"unknown write job", &types.ReadSettings{NumMessagesPerStream: 11, Subjects: []string{"foo", "bar"}, Streams: streams}, nil},
+		{"everything", &types.ReadSettings{NumMessagesPerStream: 11, Subjects: []string{"foo", "bar"}, Streams: streams,
+			Written: written}, map[string]map[string]map[uint64]uint64{"njst-w1-0": {"foo": foo, "bar": bar}}},
+		{"one subject", &types.ReadSettings{NumMessagesPerStream: 6, Subjects: []string{"foo"}, Streams: streams[:1],
+			Written: written}, map[string]map[string]map[uint64]uint64{"njst-w1-0": {"foo": foo}}},
+		{"part of a subject", &types.ReadSettings{NumMessagesPerStream: 10, Subjects: []string{"foo", "bar"}, Streams: streams,
+			Written: written}, map[string]map[string]map[uint64]uint64{"njst-w1-0": {"bar": bar}}},
+		{"nothing in full", &types.ReadSettings{NumMessagesPerStream: 5, Subjects: []string{"foo"}, Streams: streams[:1],
+			Written: written}, map[string]map[string]map[uint64]uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if expected := expectedSequences(tt.read); !reflect.DeepEqual(expected, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, expected)
+			}
+		})
+	}
+}
+
+// Messages lost at the end of a producer's sequence and producers that are
+// missing entirely can only be told from the write job's plan
+func TestAggregateVerifyReportsLost(t *testing.T) {
+	data := make([]byte, 32)
+
+	// The write job had 2 workers on 1 node, each writing 3 messages to foo
+	settings := &types.Settings{
+		Read: &types.ReadSettings{
+			NumMessagesPerStream: 6,
+			Subjects:             []string{"foo"},
+			Streams:              []*types.StreamInfo{{StreamName: "njst-w1-0", SubjectName: "njst-w1-0.foo"}},
+			Written:              &types.Written{NumNodes: 1, NumMessagesPerStream: 6, NumWorkersPerStream: 2, Subjects: []string{"foo"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		received map[int]int // worker -> messages received
+		gaps     int
+	}{
+		{"nothing lost", map[int]int{0: 3, 1: 3}, 0},
+		{"lost at the end", map[int]int{0: 3, 1: 1}, 2},
+		{"producer missing", map[int]int{0: 3}, 3},
+		{"everything lost", map[int]int{}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workerMap := map[string]map[int]*Worker{"njst-w1-0": {0: {verifier: newVerifier()}}}
+
+			for w, n := range tt.received {
+				p := newProducer(&types.Plan{}, "njst-w1-0", w, 0)
+
+				for i := 0; i < n; i++ {
+					workerMap["njst-w1-0"][0].verifier.observe("foo", p.next(data))
+				}
+			}
+
+			statuses := []*types.Status{{Status: types.CompletedStatus, Verify: verifyReports(workerMap, true)}}
+
+			status := aggregateStatuses(settings, statuses)
+
+			if len(status.Verify) != 1 || status.Verify[0].Gaps != tt.gaps {
+				t.Fatalf("expected %d gaps, got %+v", tt.gaps, status.Verify)
+			}
+
+			if (tt.gaps > 0) != (len(status.Errors) > 0) {
+				t.Errorf("unexpected errors %v", status.Errors)
+			}
+
+			// Without the write job, only gaps before the highest sequence
+			// received can be told
+			if status := aggregateStatuses(nil, statuses); status.Verify[0].Gaps != 0 {
+				t.Errorf("expected no gaps without settings, got %d", status.Verify[0].Gaps)
+			}
+		})
+	}
+}
+
+// Ranges break up when workers share a consumer; statuses must still fit in
+// the results bucket
+func TestVerifyReportsMaxRanges(t *testing.T) {
+	v := newVerifier()
+
+	// Every other sequence of 2 producers
+	v.received["foo"] = map[uint64]*seqSet{}
+
+	for id := uint64(1); id <= 2; id++ {
+		set := &seqSet{}
+
+		for seq := uint64(1); seq <= 2*MaxVerifyRanges; seq += 2 {
+			set.add(seq)
+		}
+
+		v.received["foo"][id] = set
+	}
+
+	reports := verifyReports(map[string]map[int]*Worker{"njst-w1-0": {0: {verifier: v}}}, true)
+
+	first, second := reports[0].Sequences["foo"]["1"], reports[0].Sequences["foo"]["2"]
+
+	if len(first.Ranges) != 2*MaxVerifyRanges || second.Ranges != nil {
+		t.Fatalf("expected only the first producer's ranges, got %d and %d", len(first.Ranges)/2, len(second.Ranges)/2)
+	}
+
+	expected := &types.SeqSummary{First: 1, Last: 2*MaxVerifyRanges - 1, Count: MaxVerifyRanges}
+
+	if second.First != expected.First || second.Last != expected.Last || second.Count != expected.Count {
+		t.Errorf("expected %+v, got %+v", expected, second)
+	}
+
+	data, err := json.Marshal(&types.Status{Verify: reports})
+	if err != nil {
+		t.Fatalf("unable to marshal status: %s", err)
+	}
+
+	if len(data) > 1024*1024 {
+		t.Errorf("expected status to fit in a 1MB message, got %d bytes", len(data))
+	}
+}
+
+// Producers that a node only reported the number of messages of are
+// counted; their gaps and duplicates across nodes are estimated
+func TestAggregateVerifyReportsEstimated(t *testing.T) {
+	exact := func(ranges ...uint64) *types.SeqSummary {
+		set := decodeRanges(ranges)
+		return &types.SeqSummary{First: set.first(), Last: set.last(), Count: set.size(), Ranges: ranges}
+	}
+
+	report := func(producers map[string]*types.SeqSummary) []*types.VerifyReport {
+		return []*types.VerifyReport{{Stream: "njst-w1-0", Sequences: map[string]map[string]*types.SeqSummary{"foo": producers}}}
+	}
+
+	expected := map[string]map[string]map[uint64]uint64{"njst-w1-0": {"foo": {1: 10, 2: 10}}}
+
+	tests := []struct {
+		name       string
+		node1      map[string]*types.SeqSummary
+		node2      map[string]*types.SeqSummary
+		gaps       int
+		duplicates int
+		estimated  bool
+	}{
+		{"exact", map[string]*types.SeqSummary{"1": exact(1, 5), "2": exact(1, 10)},
+			map[string]*types.SeqSummary{"1": exact(5, 8)}, 2, 1, false},
+		{"counted on one node", map[string]*types.SeqSummary{"1": {First: 1, Last: 5, Count: 5}, "2": exact(1, 10)},
+			map[string]*types.SeqSummary{"1": exact(6, 8)}, 2, 0, true},
+		{"counted on both nodes", map[string]*types.SeqSummary{"1": {First: 1, Last: 5, Count: 5}, "2": exact(1, 10)},
+			map[string]*types.SeqSummary{"1": {First: 5, Last: 8, Count: 4}}, 1, 0, true},
+		{"counted beyond last", map[string]*types.SeqSummary{"1": {First: 1, Last: 10, Count: 10}, "2": exact(1, 10)},
+			map[string]*types.SeqSummary{"1": {First: 1, Last: 2, Count: 2}}, 0, 2, true},
+		{"counted producer missing at the end", map[string]*types.SeqSummary{"1": {First: 1, Last: 4, Count: 4},
+			"2": exact(1, 10)}, map[string]*types.SeqSummary{}, 6, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := []*types.Status{
+				{Status: types.CompletedStatus, Verify: report(tt.node1)},
+				{Status: types.CompletedStatus, Verify: report(tt.node2)},
+			}
+
+			r := aggregateVerifyReports(statuses, expected, true)[0]
+
+			if r.Gaps != tt.gaps || r.Duplicates != tt.duplicates || r.Estimated != tt.estimated || r.Producers != 2 {
+				t.Errorf("expected %d gaps, %d duplicates (estimated: %v), got %+v", tt.gaps, tt.duplicates,
+					tt.estimated, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	WatchdogInterval = 5 * time.Second
+
+	// Nodes enforce max_duration themselves; the watchdog gives them this
+	// long on top of it to write their final status
+	MaxDurationGracePeriod = 30 * time.Second
+)
+
+// runWatchdog periodically checks unfinished jobs for participants that
+// stopped heartbeating (lost) or did not finish within the job's max_duration
+// (timed out) and writes a final status on their behalf, so that every job
+// ends up in a final state. The share of a lost node of a job with
+// reassign_on_failure set is handed to another node instead. The cluster lock
+// is released once the job holding it is final and scenarios and sweeps
+// whose node is gone are failed. Every node runs the watchdog; the statuses it writes are
+// the same no matter which node writes them.
+func (b *Bench) runWatchdog() {
+	ticker := time.NewTicker(WatchdogInterval)
+
+	// Jobs that are known to be final are not checked again
+	finished := make(map[string]bool)
+
+	for range ticker.C {
+		b.checkJobs(finished, time.Now().UTC())
+	}
+}
+
+// checkJobs runs a single watchdog pass; jobs found to be final are added to
+// finished and jobs that were deleted are removed from it
+func (b *Bench) checkJobs(finished map[string]bool, now time.Time) {
+	if err := b.checkLock(now); err != nil {
+		b.log.Errorf("watchdog: unable to check cluster lock: %s", err)
+	}
+
+	if err := b.checkScenarios(now); err != nil {
+		b.log.Errorf("watchdog: unable to check scenarios: %s", err)
+	}
+
+	if err := b.checkSweeps(now); err != nil {
+		b.log.Errorf("watchdog: unable to check sweeps: %s", err)
+	}
+
+	settings, err := b.nats.GetAllSettings()
+	if err != nil {
+		b.log.Errorf("watchdog: unable to get settings: %s", err)
+		return
+	}
+
+	// Forget deleted jobs so that finished does not grow forever
+	ids := make(map[string]bool, len(settings))
+
+	for _, s := range settings {
+		ids[s.ID] = true
+	}
+
+	for id := range finished {
+		if !ids[id] {
+			delete(finished, id)
+		}
+	}
+
+	var alive map[string]bool
+
+	for _, s := range settings {
+		if finished[s.ID] || len(s.Participants) == 0 {
+			continue
+		}
+
+		// Only look up the node list if there is anything to check
+		if alive == nil {
+			nodes, err := b.nats.GetNodeList()
+			if err != nil {
+				b.log.Errorf("watchdog: unable to get node list: %s", err)
+				return
+			}
+
+			alive = make(map[string]bool, len(nodes))
+
+			for _, nodeID := range nodes {
+				alive[nodeID] = true
+			}
+		}
+
+		done, err := b.checkJob(s, alive, now)
+		if err != nil {
+			b.log.Errorf("watchdog: unable to check job '%s': %s", s.ID, err)
+			continue
+		}
+
+		if done {
+			finished[s.ID] = true
+		}
+	}
+}
+
+// checkJob writes a final status for every participant of the job that is
+// lost or timed out and returns true if every participant has a final status
+func (b *Bench) checkJob(settings *types.Settings, alive map[string]bool, now time.Time) (bool, error) {
+	statuses, err := b.nats.GetStatuses(settings.ID)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to get statuses")
+	}
+
+	nodeStatuses := make(map[string]*types.Status, len(statuses))
+
+	for _, s := range statuses {
+		nodeStatuses[s.NodeID] = s
+	}
+
+	var overdue bool
+
+	if d := maxDuration(settings); d > 0 && !settings.CreatedAt.IsZero() {
+		overdue = now.After(settings.CreatedAt.Add(d + MaxDurationGracePeriod))
+	}
+
+	done := true
+
+	for _, nodeID := range settings.Participants {
+		last := nodeStatuses[nodeID]
+
+		if last != nil && isFinal(last.Status) {
+			continue
+		}
+
+		var (
+			status types.JobStatus
+			reason string
+		)
+
+		switch {
+		case !alive[nodeID] && settings.ReassignOnFailure && !overdue:
+			// The node that takes over is a participant now, so the job
+			// is not done either way
+			done = false
+
+			if err := b.reassignOrWait(settings, nodeID, last, now); err != nil {
+				b.log.Warningf("watchdog: job '%s': unable to reassign the share of node '%s': %s",
+					settings.ID, nodeID, err)
+			}
+
+			continue
+		case !alive[nodeID]:
+			status = types.LostStatus
+			reason = fmt.Sprintf("node '%s' stopped heartbeating before the job finished", nodeID)
+		case overdue:
+			status = types.TimedOutStatus
+			reason = fmt.Sprintf("node '%s' did not finish within max_duration of %s", nodeID, settings.MaxDuration)
+		default:
+			done = false
+			continue
+		}
+
+		if err := b.nats.WriteStatus(watchdogStatus(settings.ID, nodeID, last, status, reason, now)); err != nil {
+			return false, errors.Wrapf(err, "unable to write status for node '%s'", nodeID)
+		}
+
+		b.log.Warningf("watchdog: job '%s': %s", settings.ID, reason)
+	}
+
+	return done, nil
+}
+
+// reassignOrWait reassigns the remaining share of a lost node; if no node is
+// available to take over, the lost node's status says so and the job waits
+// for a node to join (or for max_duration to be exceeded)
+func (b *Bench) reassignOrWait(settings *types.Settings, nodeID string, last *types.Status, now time.Time) error {
+	to, err := b.reassign(settings, nodeID, last, now)
+	if err != nil {
+		return err
+	}
+
+	if to != "" {
+		b.log.Warningf("watchdog: job '%s': node '%s' stopped heartbeating; its share was reassigned to node '%s'",
+			settings.ID, nodeID, to)
+
+		return nil
+	}
+
+	if last != nil && last.Message == ReassignWaitingMessage {
+		return nil
+	}
+
+	b.log.Warningf("watchdog: job '%s': node '%s' stopped heartbeating; no node available to take over",
+		settings.ID, nodeID)
+
+	status := &types.Status{}
+
+	if last != nil {
+		copied := *last
+		status = &copied
+	}
+
+	status.JobID = settings.ID
+	status.NodeID = nodeID
+	status.Status = types.InProgressStatus
+	status.Message = ReassignWaitingMessage
+
+	return b.nats.WriteStatus(status)
+}
+
+// watchdogStatus returns the final status for a node that did not report one;
+// the stats of the node's last report (if any) are kept
+func watchdogStatus(jobID, nodeID string, last *types.Status, status types.JobStatus, reason string, now time.Time) *types.Status {
+	s := &types.Status{}
+
+	if last != nil {
+		copied := *last
+		s = &copied
+	}
+
+	s.JobID = jobID
+	s.NodeID = nodeID
+	s.Status = status
+	s.Message = reason
+	s.EndedAt = now
+	s.Errors = append(append([]string{}, s.Errors...), reason)
+
+	return s
+}
@@ -0,0 +1,222 @@
+package bench
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestCheckJob(t *testing.T) {
+	createdAt := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		maxDuration string
+		now         time.Time
+		alive       []string
+		statuses    []*types.Status
+		written     map[string]types.JobStatus
+		done        bool
+	}{
+		{
+			name:  "all running",
+			now:   createdAt.Add(time.Hour),
+			alive: []string{"node1", "node2"},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.InProgressStatus},
+				{NodeID: "node2", Status: types.InProgressStatus},
+			},
+			written: map[string]types.JobStatus{},
+		},
+		{
+			name:  "all final",
+			now:   createdAt.Add(time.Hour),
+			alive: []string{},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.CompletedStatus},
+				{NodeID: "node2", Status: types.CancelledStatus},
+			},
+			written: map[string]types.JobStatus{},
+			done:    true,
+		},
+		{
+			name:  "node lost while running",
+			now:   createdAt.Add(time.Hour),
+			alive: []string{"node1"},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.CompletedStatus},
+				{NodeID: "node2", Status: types.InProgressStatus, TotalProcessed: 500},
+			},
+			written: map[string]types.JobStatus{"node2": types.LostStatus},
+			done:    true,
+		},
+		{
+			name:     "node lost before reporting",
+			now:      createdAt.Add(time.Minute),
+			alive:    []string{"node1"},
+			statuses: []*types.Status{{NodeID: "node1", Status: types.InProgressStatus}},
+			written:  map[string]types.JobStatus{"node2": types.LostStatus},
+		},
+		{
+			name:        "within max duration and grace period",
+			maxDuration: "10m",
+			now:         createdAt.Add(10*time.Minute + MaxDurationGracePeriod),
+			alive:       []string{"node1", "node2"},
+			statuses:    []*types.Status{{NodeID: "node1", Status: types.InProgressStatus}},
+			written:     map[string]types.JobStatus{},
+		},
+		{
+			name:        "max duration exceeded",
+			maxDuration: "10m",
+			now:         createdAt.Add(10*time.Minute + MaxDurationGracePeriod + time.Second),
+			alive:       []string{"node1", "node2"},
+			statuses: []*types.Status{
+				{NodeID: "node1", Status: types.TimedOutStatus},
+				{NodeID: "node2", Status: types.InProgressStatus},
+			},
+			written: map[string]types.JobStatus{"node2": types.TimedOutStatus},
+			done:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, fake := newTestBench(t)
+
+			fake.GetStatusesReturns(tt.statuses, nil)
+
+			settings := &types.Settings{
+				ID:           "abc",
+				MaxDuration:  tt.maxDuration,
+				CreatedAt:    createdAt,
+				Participants: []string{"node1", "node2"},
+			}
+
+			alive := make(map[string]bool)
+
+			for _, nodeID := range tt.alive {
+				alive[nodeID] = true
+			}
+
+			done, err := b.checkJob(settings, alive, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if done != tt.done {
+				t.Errorf("expected done to be %v", tt.done)
+			}
+
+			written := make(map[string]types.JobStatus)
+
+			for i := 0; i < fake.WriteStatusCallCount(); i++ {
+				status := fake.WriteStatusArgsForCall(i)
+
+				if status.JobID != "abc" || len(status.Errors) != 1 || !status.EndedAt.Equal(tt.now) {
+					t.Errorf("unexpected status %+v", status)
+				}
+
+				written[status.NodeID] = status.Status
+			}
+
+			if !reflect.DeepEqual(written, tt.written) {
+				t.Errorf("expected %v to be written, got %v", tt.written, written)
+			}
+		})
+	}
+}
+
+func TestCheckJobs(t *testing.T) {
+	b, fake := newTestBench(t)
+
+	fake.GetAllSettingsReturns([]*types.Settings{
+		{ID: "abc", Participants: []string{"node1"}},
+		{ID: "def", Participants: []string{"node1"}},
+		{ID: "old"},
+	}, nil)
+
+	fake.GetNodeListReturns([]string{"node1"}, nil)
+
+	fake.GetStatusesStub = func(jobID string) ([]*types.Status, error) {
+		if jobID == "abc" {
+			return []*types.Status{{NodeID: "node1", Status: types.CompletedStatus}}, nil
+		}
+
+		return []*types.Status{{NodeID: "node1", Status: types.InProgressStatus}}, nil
+	}
+
+	finished := make(map[string]bool)
+
+	b.checkJobs(finished, time.Now().UTC())
+
+	if !reflect.DeepEqual(finished, map[string]bool{"abc": true}) {
+		t.Errorf("expected only 'abc' to be finished, got %v", finished)
+	}
+
+	// Finished jobs (and jobs without participants) are not checked again
+	b.checkJobs(finished, time.Now().UTC())
+
+	if fake.GetStatusesCallCount() != 3 || fake.GetNodeListCallCount() != 2 {
+		t.Errorf("unexpected number of lookups: %d statuses, %d node lists", fake.GetStatusesCallCount(),
+			fake.GetNodeListCallCount())
+	}
+
+	// Deleted jobs are forgotten
+	fake.GetAllSettingsReturns([]*types.Settings{{ID: "def", Participants: []string{"node1"}}}, nil)
+
+	b.checkJobs(finished, time.Now().UTC())
+
+	if len(finished) != 0 {
+		t.Errorf("expected 'abc' to be forgotten, got %v", finished)
+	}
+}
+
+func TestWatchdogStatus(t *testing.T) {
+	now := time.Now().UTC()
+	last := &types.Status{NodeID: "node2", Status: types.InProgressStatus, TotalProcessed: 500,
+		Errors: []string{"timeout"}}
+
+	status := watchdogStatus("abc", "node2", last, types.LostStatus, "node 'node2' lost", now)
+
+	if status.Status != types.LostStatus || status.TotalProcessed != 500 || status.Message != "node 'node2' lost" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	if !reflect.DeepEqual(status.Errors, []string{"timeout", "node 'node2' lost"}) {
+		t.Errorf("unexpected errors %v", status.Errors)
+	}
+
+	if last.Status != types.InProgressStatus || len(last.Errors) != 1 {
+		t.Error("last status must not be modified")
+	}
+}
+
+func TestAggregateStatusesFailed(t *testing.T) {
+	statuses := []*types.Status{
+		{NodeID: "node1", Status: types.CompletedStatus},
+		{NodeID: "node3", Status: types.LostStatus},
+		{NodeID: "node2", Status: types.LostStatus},
+		{NodeID: "node4", Status: types.TimedOutStatus},
+	}
+
+	status := aggregateStatuses(nil, statuses)
+
+	if status.Status != types.FailedStatus {
+		t.Errorf("expected failed status, got %s", status.Status)
+	}
+
+	expected := "job failed: node(s) node2, node3 lost and node(s) node4 exceeded max_duration"
+
+	if status.Message != expected {
+		t.Errorf("expected message '%s', got '%s'", expected, status.Message)
+	}
+
+	// Nodes that are still running keep the job in progress
+	statuses = append(statuses, &types.Status{NodeID: "node5", Status: types.InProgressStatus})
+
+	if status := aggregateStatuses(nil, statuses); status.Status != types.InProgressStatus {
+		t.Errorf("expected in-progress status, got %s", status.Status)
+	}
+}
@@ -20,6 +20,13 @@ func (b *Bench) runWriteBenchmark(job *types.Job) (*types.Status, error) {
 		return nil, errors.New("job or job settings cannot be nil")
 	}
 
+	// Each worker writes its planned share to each subject of its stream
+	plan := job.Settings.Write.Plan
+
+	if plan == nil {
+		return nil, errors.New("job has no message plan")
+	}
+
 	workerMap := make(map[string]map[int]*Worker, 0)
 
 	// Generate the data
@@ -30,18 +37,13 @@ func (b *Bench) runWriteBenchmark(job *types.Job) (*types.Status, error) {
 
 	doneCh := make(chan struct{}, 1)
 
+	jm := b.metrics.startJob(job.Settings, "write", job.Settings.Write.Streams)
+	defer b.metrics.finishJob(jm)
+
 	go b.runReporter(doneCh, job, workerMap)
 
 	wg := &sync.WaitGroup{}
 
-	// If there are multiple subjects, each worker writes a portion of NumMessages
-	// to each subject
-	numMessagesPerWorker := job.Settings.Write.NumMessagesPerStream / (job.Settings.Write.NumNodes * job.Settings.Write.NumWorkersPerStream)
-	numMessagesPerLastWorker := numMessagesPerWorker + (job.Settings.Write.NumMessagesPerStream % job.Settings.Write.NumWorkersPerStream)
-
-	numMessagesPerWorkerPerSubject := numMessagesPerWorker / len(job.Settings.Write.Subjects)
-	numMessagesPerLastWorkerPerSubject := numMessagesPerWorkerPerSubject + (numMessagesPerLastWorker % len(job.Settings.Write.Subjects))
-
 	var nc *nats.Conn
 
 	if job.Settings.NATS.SharedConnection {
@@ -53,7 +55,7 @@ func (b *Bench) runWriteBenchmark(job *types.Job) (*types.Status, error) {
 		defer nc.Drain()
 	}
 
-	// Launch workers; last one gets remainder
+	// Launch workers
 	for _, stream := range job.Settings.Write.Streams {
 		for i := 0; i < job.Settings.Write.NumWorkersPerStream; i++ {
 			if _, ok := workerMap[stream]; !ok {
@@ -61,18 +63,22 @@ func (b *Bench) runWriteBenchmark(job *types.Job) (*types.Status, error) {
 			}
 
 			workerMap[stream][i] = &Worker{
-				WorkerID: i,
-				Errors:   make([]string, 0),
+				WorkerID:      i,
+				Errors:        make([]string, 0),
+				Latency:       newLatencyHistogram(),
+				jobMetrics:    jm,
+				streamMetrics: jm.streams[stream],
 			}
 
-			wg.Add(1)
+			numMessages := make([]int, len(job.Settings.Write.Subjects))
 
-			// Last worker gets remaining messages
-			if i == job.Settings.Write.NumWorkersPerStream-1 {
-				go b.runWriterWorker(nc, job, i, stream, data, numMessagesPerLastWorkerPerSubject, workerMap[stream][i], wg)
-			} else {
-				go b.runWriterWorker(nc, job, i, stream, data, numMessagesPerWorkerPerSubject, workerMap[stream][i], wg)
+			for s := range numMessages {
+				numMessages[s] = plannedMessages(plan, stream, i, s)
 			}
+
+			wg.Add(1)
+
+			go b.runWriterWorker(nc, job, i, stream, data, numMessages, workerMap[stream][i], wg)
 		}
 	}
 
@@ -82,20 +88,8 @@ func (b *Bench) runWriteBenchmark(job *types.Job) (*types.Status, error) {
 	// Stop the reporter
 	doneCh <- struct{}{}
 
-	var status types.JobStatus
-
-	// Was this job cancelled? (in select to avoid blocking if not cancelled)
-	select {
-	case _, ok := <-job.Context.Done():
-		if !ok {
-			status = types.CancelledStatus
-		}
-	default:
-		status = types.CompletedStatus
-	}
-
 	// Calculate the final status
-	return b.calculateStats(job.Settings, job.NodeID, workerMap, status, "; final"), nil
+	return b.calculateStats(job.Settings, job.NodeID, workerMap, finalJobStatus(job), "; final"), nil
 }
 
 func min(a, b int) int {
@@ -105,7 +99,9 @@ func min(a, b int) int {
 	return b
 }
 
-func (b *Bench) runWriterWorker(nc *nats.Conn, job *types.Job, workerID int, stream string, data []byte, numMessages int, worker *Worker, wg *sync.WaitGroup) {
+// runWriterWorker writes numMessages[i] messages to the i-th subject of the
+// stream
+func (b *Bench) runWriterWorker(nc *nats.Conn, job *types.Job, workerID int, stream string, data []byte, numMessagesPerSubject []int, worker *Worker, wg *sync.WaitGroup) {
 	var batchSize = job.Settings.Write.BatchSize
 
 	if batchSize == 0 {
@@ -117,10 +113,14 @@ func (b *Bench) runWriterWorker(nc *nats.Conn, job *types.Job, workerID int, str
 
 	defer wg.Done()
 
+	worker.jobMetrics.workerStarted()
+	defer worker.jobMetrics.workerDone()
+
 	if !job.Settings.NATS.SharedConnection {
 		myNC, err = b.nats.NewConn(job.Settings.NATS)
 		if err != nil {
 			b.log.Log(logrus.ErrorLevel, "can't get connection for connection per worker: ", err)
+			worker.streamMetrics.addError(ConnectionErrorCategory)
 			return
 		}
 
@@ -138,10 +138,16 @@ func (b *Bench) runWriterWorker(nc *nats.Conn, job *types.Job, workerID int, str
 		return
 	}
 
+	var numMessagesTotal int
+
+	for _, n := range numMessagesPerSubject {
+		numMessagesTotal += n
+	}
+
 	llog := b.log.WithFields(logrus.Fields{
 		"worker_id":   workerID,
 		"stream":      stream,
-		"numMessages": numMessages,
+		"numMessages": numMessagesTotal,
 	})
 
 	llog.Debug("worker starting")
@@ -150,20 +156,36 @@ func (b *Bench) runWriterWorker(nc *nats.Conn, job *types.Job, workerID int, str
 	worker.StartedAt = time.Now().UTC()
 
 MAIN:
-	for _, subj := range job.Settings.Write.Subjects {
+	for s, subj := range job.Settings.Write.Subjects {
+		numMessages := numMessagesPerSubject[s]
+
+		var p *producer
+
+		if job.Settings.Write.Verify {
+			p = newProducer(job.Settings.Write.Plan, stream, workerID, s)
+		}
+
 		for i := 0; i < numMessages; i += batchSize {
 			futures := make([]nats.PubAckFuture, min(batchSize, numMessages-i))
+			batchStartedAt := time.Now()
 
 			for j := 0; j < batchSize && i+j < numMessages; j++ {
 				fullSubj := fmt.Sprintf("%s.%s", stream, subj)
 
-				futures[j], err = js.PublishAsync(fullSubj, data)
+				payload := data
+
+				if p != nil {
+					payload = p.next(data)
+				}
+
+				futures[j], err = js.PublishAsync(fullSubj, payload)
 				if err != nil {
 					llog.Errorf("unable to JS async publish message: %s", err)
+					worker.streamMetrics.addError(PublishErrorCategory)
 					worker.NumErrors++
 					worker.Errors = append(worker.Errors, err.Error())
 
-					if worker.NumErrors > numMessages {
+					if worker.NumErrors > numMessagesTotal {
 						llog.Error("worker exiting prematurely due to too many errors")
 						break MAIN
 					}
@@ -175,19 +197,26 @@ MAIN:
 			select {
 			case <-job.Context.Done():
 				llog.Debug("worker exiting due to context done")
-				return
+				break MAIN
 			case <-js.PublishAsyncComplete():
+				batchLatency := time.Since(batchStartedAt)
+
+				observeLatency(worker.Latency, batchLatency)
+				worker.jobMetrics.observeLatency(batchLatency)
+
 				for future := range futures {
 					select {
 					case <-futures[future].Ok():
 						worker.NumWritten++
+						worker.streamMetrics.addMessages(1, len(data))
 					case e := <-futures[future].Err():
 						llog.Errorf("PubAsyncFuture for message %v in batch not OK: %v", future, e)
 
+						worker.streamMetrics.addError(AckErrorCategory)
 						worker.NumErrors++
 						worker.Errors = append(worker.Errors, e.Error())
 
-						if worker.NumErrors > numMessages {
+						if worker.NumErrors > numMessagesTotal {
 							llog.Error("worker exiting prematurely due to too many errors")
 							break MAIN
 						}
@@ -196,11 +225,12 @@ MAIN:
 			case <-time.After(10 * time.Second):
 				llog.Error("PublishAsyncComplete timed out after 10s")
 
+				worker.streamMetrics.addError(TimeoutErrorCategory)
 				worker.NumErrors++
 				worker.Errors = append(worker.Errors, fmt.Sprintf(
 					"PublishAsyncComplete timed out after 10s (pending: %d)", js.PublishAsyncPending()))
 
-				if worker.NumErrors > numMessages {
+				if worker.NumErrors > numMessagesTotal {
 					llog.Error("worker exiting prematurely due to too many errors")
 					break MAIN
 				}
@@ -226,6 +256,7 @@ func (b *Bench) calculateStats(settings *types.Settings, nodeId string, workerMa
 	)
 
 	errs := make([]string, 0)
+	latency := newLatencyHistogram()
 
 	message := "benchmark is in progress"
 
@@ -234,6 +265,8 @@ func (b *Bench) calculateStats(settings *types.Settings, nodeId string, workerMa
 		message = "benchmark completed"
 	case types.CancelledStatus:
 		message = "benchmark cancelled"
+	case types.TimedOutStatus:
+		message = fmt.Sprintf("benchmark exceeded max_duration of %s", settings.MaxDuration)
 	case types.InProgressStatus:
 		message = "benchmark is in progress"
 	}
@@ -271,10 +304,17 @@ func (b *Bench) calculateStats(settings *types.Settings, nodeId string, workerMa
 
 			report.Processed = workerNumProcessed
 			numProcessedTotal += workerNumProcessed
-			report.AvgMsgPerSec = round(float64(workerNumProcessed)/workerElapsed.Seconds(), 2)
+
+			// A worker that has just started has no meaningful rate yet
+			if workerElapsed > 0 {
+				report.AvgMsgPerSec = round(float64(workerNumProcessed)/workerElapsed.Seconds(), 2)
+			}
 
 			totalPerWorkGroupAverages += report.AvgMsgPerSec
 
+			report.Latency = summarizeLatency(worker.Latency)
+			mergeLatency(latency, worker.Latency)
+
 			report.Errors = worker.NumErrors
 			numErrorsTotal += worker.NumErrors
 			if len(worker.Errors) > 0 {
@@ -305,7 +345,11 @@ func (b *Bench) calculateStats(settings *types.Settings, nodeId string, workerMa
 		errs = errs[:100]
 	}
 
-	avgMsgPerSec := totalPerWorkGroupAverages / float64(len(workerMap))
+	var avgMsgPerSec float64
+
+	if len(workerMap) > 0 {
+		avgMsgPerSec = totalPerWorkGroupAverages / float64(len(workerMap))
+	}
 
 	return &types.Status{
 		NodeID:              b.params.NodeID,
@@ -319,6 +363,10 @@ func (b *Bench) calculateStats(settings *types.Settings, nodeId string, workerMa
 		TotalErrors:         numErrorsTotal,
 		StartedAt:           minStartedAt,
 		EndedAt:             maxEndedAt,
+		Latency:             summarizeLatency(latency),
+		LatencyHistogram:    latency,
+		Plan:                planReport(settings, numProcessedTotal, jobStatus),
+		Verify:              verifyReports(workerMap, isFinal(jobStatus)),
 		NodeReport: &types.NodeReport{
 			Streams: streamReports,
 		},
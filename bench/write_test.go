@@ -0,0 +1,184 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestCalculateStats(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	settings := &types.Settings{
+		ID:    "abc",
+		Write: &types.WriteSettings{NumStreams: 2, NumWorkersPerStream: 2, Plan: &types.Plan{NumMessages: 6000}},
+	}
+
+	workerMap := map[string]map[int]*Worker{
+		"njst-abc-0": {
+			0: {WorkerID: 0, NumWritten: 1000, StartedAt: start, EndedAt: start.Add(2 * time.Second)},
+			1: {WorkerID: 1, NumWritten: 1000, NumErrors: 1, Errors: []string{"timeout"}, StartedAt: start,
+				EndedAt: start.Add(4 * time.Second)},
+		},
+		"njst-abc-1": {
+			0: {WorkerID: 0, NumWritten: 3000, StartedAt: start.Add(time.Second), EndedAt: start.Add(4 * time.Second)},
+			1: {WorkerID: 1, NumWritten: 500, StartedAt: start.Add(time.Second), EndedAt: start.Add(5 * time.Second)},
+		},
+	}
+
+	status := b.calculateStats(settings, "node1", workerMap, types.CompletedStatus, "; final")
+
+	if status.NodeID != "node1" || status.JobID != "abc" || status.Status != types.CompletedStatus {
+		t.Errorf("unexpected node, job or status: %s, %s, %s", status.NodeID, status.JobID, status.Status)
+	}
+
+	if status.Message != "benchmark completed; final" {
+		t.Errorf("unexpected message '%s'", status.Message)
+	}
+
+	if status.TotalProcessed != 5500 || status.TotalErrors != 1 || len(status.Errors) != 1 {
+		t.Errorf("unexpected totals: processed %d, errors %d (%d messages)", status.TotalProcessed,
+			status.TotalErrors, len(status.Errors))
+	}
+
+	if !status.StartedAt.Equal(start) || !status.EndedAt.Equal(start.Add(5*time.Second)) {
+		t.Errorf("expected earliest start and latest end, got %s - %s", status.StartedAt, status.EndedAt)
+	}
+
+	// Sum of worker elapsed times
+	if status.ElapsedSeconds != 13 {
+		t.Errorf("expected 13 elapsed seconds, got %v", status.ElapsedSeconds)
+	}
+
+	// (500 + 250 + 1000 + 125) msg/sec, divided by the number of streams
+	if status.AvgMsgPerSecPerNode != 937.5 {
+		t.Errorf("expected 937.5 msg/sec, got %v", status.AvgMsgPerSecPerNode)
+	}
+
+	if p := status.Plan; p == nil || p.Planned != 6000 || p.Processed != 5500 || p.Complete {
+		t.Errorf("expected an incomplete plan report, got %+v", p)
+	}
+
+	if status.NodeReport == nil || len(status.NodeReport.Streams) != 2 {
+		t.Fatalf("expected a report for 2 streams, got %+v", status.NodeReport)
+	}
+
+	rates := make(map[string]float64)
+
+	for _, stream := range status.NodeReport.Streams {
+		if len(stream.Workers) != 2 {
+			t.Fatalf("expected 2 workers per stream, got %d", len(stream.Workers))
+		}
+
+		for _, w := range stream.Workers {
+			rates[w.WorkerID] = w.AvgMsgPerSec
+		}
+	}
+
+	expectedRates := map[string]float64{
+		"node1-njst-abc-0-0": 500,
+		"node1-njst-abc-0-1": 250,
+		"node1-njst-abc-1-0": 1000,
+		"node1-njst-abc-1-1": 125,
+	}
+
+	for id, rate := range expectedRates {
+		if rates[id] != rate {
+			t.Errorf("worker %s: expected %v msg/sec, got %v", id, rate, rates[id])
+		}
+	}
+}
+
+func TestCalculateStatsRead(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	start := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	settings := &types.Settings{ID: "abc", Read: &types.ReadSettings{NumStreams: 1}}
+
+	workerMap := map[string]map[int]*Worker{
+		"njst-w1-0": {
+			0: {WorkerID: 0, NumRead: 200, NumWritten: 999, StartedAt: start, EndedAt: start.Add(time.Second)},
+		},
+	}
+
+	status := b.calculateStats(settings, "node1", workerMap, types.InProgressStatus, "; ticker")
+
+	if status.TotalProcessed != 200 {
+		t.Errorf("read jobs should count messages read, got %d", status.TotalProcessed)
+	}
+
+	if status.Message != "benchmark is in progress; ticker" {
+		t.Errorf("unexpected message '%s'", status.Message)
+	}
+}
+
+func TestCalculateStatsErrorCap(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	start := time.Now().UTC()
+	errs := make([]string, 0)
+
+	for i := 0; i < 150; i++ {
+		errs = append(errs, fmt.Sprintf("error %d", i))
+	}
+
+	workerMap := map[string]map[int]*Worker{
+		"njst-abc-0": {
+			0: {WorkerID: 0, NumErrors: 150, Errors: errs, StartedAt: start, EndedAt: start.Add(time.Second)},
+		},
+	}
+
+	status := b.calculateStats(&types.Settings{ID: "abc", Write: &types.WriteSettings{}}, "node1", workerMap,
+		types.CancelledStatus, "")
+
+	if len(status.Errors) != 100 || status.TotalErrors != 150 {
+		t.Errorf("expected 100 of 150 errors to be kept, got %d of %d", len(status.Errors), status.TotalErrors)
+	}
+
+	if !strings.HasPrefix(status.Message, "benchmark cancelled") {
+		t.Errorf("unexpected message '%s'", status.Message)
+	}
+}
+
+// Statuses are written to the results bucket as JSON, which cannot encode
+// NaN or Inf
+func TestCalculateStatsNoRate(t *testing.T) {
+	b, _ := newTestBench(t)
+
+	now := time.Now().UTC()
+	settings := &types.Settings{ID: "abc", Write: &types.WriteSettings{}}
+
+	tests := []struct {
+		name      string
+		workerMap map[string]map[int]*Worker
+	}{
+		{"no workers", map[string]map[int]*Worker{}},
+		{"no elapsed time", map[string]map[int]*Worker{
+			"njst-abc-0": {0: {WorkerID: 0, StartedAt: now, EndedAt: now}},
+		}},
+		{"no elapsed time with messages", map[string]map[int]*Worker{
+			"njst-abc-0": {0: {WorkerID: 0, NumWritten: 10, StartedAt: now, EndedAt: now}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := b.calculateStats(settings, "node1", tt.workerMap, types.InProgressStatus, "")
+
+			if status.AvgMsgPerSecPerNode != 0 {
+				t.Errorf("expected no rate, got %v", status.AvgMsgPerSecPerNode)
+			}
+
+			if _, err := json.Marshal(status); err != nil {
+				t.Errorf("unable to marshal status: %s", err)
+			}
+		})
+	}
+}
@@ -1,15 +1,62 @@
 package cli
 
+import "time"
+
 type Params struct {
-	NodeID            string   `json:"nodeID"`
-	Debug             bool     `json:"debug"`
-	NATSAddress       []string `json:"nats_address"`
-	HTTPAddress       string   `json:"http_address"`
-	NATSSubject       string   `json:"nats_subject"`
-	NATSUseTLS        bool     `json:"nats_use_tls"`
-	NATSTLSCaCert     string   `json:"nats_tls_ca_cert"`
-	NATSTLSClientCert string   `json:"nats_tls_client_cert"`
-	NATSTLSClientKey  string   `json:"nats_tls_client_key"`
-	NATSTLSSkipVerify bool     `json:"nats_tls_skip_verify"`
-	EnablePprof       bool     `json:"enable_pprof"`
+	NodeID            string            `json:"nodeID"`
+	Debug             bool              `json:"debug"`
+	NATSAddress       []string          `json:"nats_address"`
+	HTTPAddress       string            `json:"http_address"`
+	NATSSubject       string            `json:"nats_subject"`
+	NATSUseTLS        bool              `json:"nats_use_tls"`
+	NATSTLSCaCert     string            `json:"nats_tls_ca_cert"`
+	NATSTLSClientCert string            `json:"nats_tls_client_cert"`
+	NATSTLSClientKey  string            `json:"nats_tls_client_key"`
+	NATSTLSSkipVerify bool              `json:"nats_tls_skip_verify"`
+	EnablePprof       bool              `json:"enable_pprof"`
+	NodeLabels        map[string]string `json:"node_labels"`
+	MaxConcurrentJobs int               `json:"max_concurrent_jobs"`
+	MaxQueuedJobs     int               `json:"max_queued_jobs"`
+
+	// Finished jobs are archived into the history stream this long after
+	// they end; 0 disables archival. The retention limits apply to the
+	// history stream (0 for no limit).
+	ArchiveAfter     time.Duration `json:"archive_after"`
+	RetentionMaxRuns int           `json:"retention_max_runs"`
+	RetentionMaxAge  time.Duration `json:"retention_max_age"`
+
+	// Set by main
+	Version string `json:"version"`
+
+	// Set by "njst run"; the node does not use any internal buckets, streams
+	// or subscriptions and is the only node in its "cluster"
+	Standalone bool `json:"standalone"`
+}
+
+// ClientParams are used by the client subcommands (everything but "server")
+type ClientParams struct {
+	Address string
+	Timeout time.Duration
+
+	SpecFile       string
+	JobID          string
+	NodeID         string
+	Watch          bool
+	Wait           bool
+	DeleteStreams  bool
+	DeleteSettings bool
+	DeleteResults  bool
+
+	// bench list filters; see GET /bench
+	ListStatus  string
+	ListType    string
+	ListQuery   string
+	ListProfile string
+	ListOwner   string
+	ListTags    []string
+	ListSince   string
+	ListUntil   string
+	ListSort    string
+	ListLimit   int
+	ListOffset  int
 }
@@ -0,0 +1,265 @@
+// Package client is a small client for the njst HTTP API; it is used by the
+// njst CLI subcommands and can talk to any node in the cluster.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultAddress = "http://localhost:5000"
+	DefaultTimeout = 30 * time.Second
+)
+
+type Client struct {
+	address string
+	http    *http.Client
+}
+
+func New(address string, timeout time.Duration) (*Client, error) {
+	if address == "" {
+		return nil, errors.New("address cannot be empty")
+	}
+
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	if _, err := url.Parse(address); err != nil {
+		return nil, errors.Wrap(err, "invalid address")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		address: strings.TrimRight(address, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// CreateBenchmark creates a job from a raw settings spec (as accepted by
+// POST /bench) and returns the job ID
+func (c *Client) CreateBenchmark(spec []byte) (string, error) {
+	resp := &types.CreateResponse{}
+
+	if err := c.do(http.MethodPost, "/bench", spec, resp); err != nil {
+		return "", err
+	}
+
+	if resp.ID == "" {
+		return "", errors.New("response does not contain a job id")
+	}
+
+	return resp.ID, nil
+}
+
+// GetBenchmark returns the status (including node reports) and settings of a job
+func (c *Client) GetBenchmark(id string) (*types.StatusResponse, error) {
+	resp := &types.StatusResponse{}
+
+	if err := c.do(http.MethodGet, "/bench/"+url.PathEscape(id)+"?full", nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ListBenchmarks returns a page of job summaries; query holds the filters
+// accepted by GET /bench
+func (c *Client) ListBenchmarks(query url.Values) (*types.BenchList, error) {
+	path := "/bench"
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	list := &types.BenchList{}
+
+	if err := c.do(http.MethodGet, path, nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// DeleteBenchmark cancels a job and deletes the selected data; the response
+// lists which nodes confirmed the cancellation
+func (c *Client) DeleteBenchmark(id string, streams, settings, results bool) (*types.DeleteResponse, error) {
+	query := url.Values{}
+
+	if streams {
+		query.Set("streams", "")
+	}
+
+	if settings {
+		query.Set("settings", "")
+	}
+
+	if results {
+		query.Set("results", "")
+	}
+
+	path := "/bench/" + url.PathEscape(id)
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp := &types.DeleteResponse{}
+
+	if err := c.do(http.MethodDelete, path, nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Purge deletes all streams, k/v stores and results created by njst
+func (c *Client) Purge() error {
+	data, err := json.Marshal(&types.PurgeRequest{All: true})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal purge request")
+	}
+
+	return c.do(http.MethodPost, "/bench/purge", data, nil)
+}
+
+func (c *Client) GetCluster() (*types.ClusterResponse, error) {
+	resp := &types.ClusterResponse{}
+
+	if err := c.do(http.MethodGet, "/cluster", nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetNodeJobs returns the jobs running and queued on a node
+func (c *Client) GetNodeJobs(nodeID string) (*types.NodeJobs, error) {
+	resp := &types.NodeJobs{}
+
+	if err := c.do(http.MethodGet, "/cluster/"+url.PathEscape(nodeID)+"/jobs", nil, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// WatchBenchmark calls fn for every event of GET /bench/:id/events until the
+// job ends, ctx is done or fn returns an error
+func (c *Client) WatchBenchmark(ctx context.Context, id string, fn func(event *types.JobEvent) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.address+"/bench/"+url.PathEscape(id)+"/events", nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to create request")
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream lasts as long as the job; don't use the client timeout
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to watch job")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Only "data" lines are of interest; the event type is part of the data
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		event := &types.JobEvent{}
+
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), event); err != nil {
+			return errors.Wrap(err, "unable to unmarshal event")
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, "unable to read events")
+	}
+
+	return nil
+}
+
+func (c *Client) do(method, path string, body []byte, v interface{}) error {
+	var reader io.Reader
+
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, c.address+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "unable to create request")
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "unable to %s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "unable to decode response")
+	}
+
+	return nil
+}
+
+// responseError turns a non-200 response into an error, using the API's
+// {"error": ".."} body if there is one
+func responseError(resp *http.Response) error {
+	data, _ := ioutil.ReadAll(resp.Body)
+
+	// Some error responses carry more than the error (ex: POST /bench)
+	apiErr := struct {
+		Error string `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Error != "" {
+		return fmt.Errorf("%s (HTTP %d)", apiErr.Error, resp.StatusCode)
+	}
+
+	return fmt.Errorf("unexpected response: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
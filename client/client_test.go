@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"localhost:5000", "http://localhost:5000"},
+		{"https://njst.example.com/", "https://njst.example.com"},
+		{"http://10.0.0.1:5000", "http://10.0.0.1:5000"},
+	}
+
+	for _, tt := range tests {
+		c, err := New(tt.address, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if c.address != tt.expected || c.http.Timeout != DefaultTimeout {
+			t.Errorf("expected %s with the default timeout, got %s (%s)", tt.expected, c.address, c.http.Timeout)
+		}
+	}
+
+	if _, err := New("", 0); err == nil {
+		t.Error("expected an error for an empty address")
+	}
+}
+
+func TestDo(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+
+		switch r.URL.Path {
+		case "/bench":
+			fmt.Fprint(rw, `{"id": "abc"}`)
+		case "/bench/abc":
+			fmt.Fprint(rw, `{"id": "abc", "confirmed": ["node1"]}`)
+		case "/bench/missing":
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(rw, `{"error": "key not found"}`)
+		default:
+			rw.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(rw, "bad gateway\n")
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if id, err := c.CreateBenchmark([]byte(`{}`)); err != nil || id != "abc" {
+		t.Errorf("expected job 'abc', got '%s' (%v)", id, err)
+	}
+
+	if resp, err := c.DeleteBenchmark("abc", true, false, true); err != nil || len(resp.Confirmed) != 1 {
+		t.Errorf("unexpected delete response %+v (%v)", resp, err)
+	}
+
+	// The API's error message is used when there is one
+	if _, err := c.GetBenchmark("missing"); err == nil || err.Error() != "key not found (HTTP 404)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := c.GetCluster(); err == nil || err.Error() != "unexpected response: HTTP 502: bad gateway" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"POST /bench",
+		"DELETE /bench/abc?results=&streams=",
+		"GET /bench/missing?full",
+		"GET /cluster",
+	}
+
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestWatchBenchmark(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bench/abc/events" {
+			rw.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(rw, `{"error": "key not found"}`)
+
+			return
+		}
+
+		fmt.Fprint(rw, "event: node\ndata: {\"type\": \"node\", \"node_id\": \"node1\", \"state\": \"running\"}\n\n")
+		fmt.Fprint(rw, ": keep-alive\n\n")
+		fmt.Fprint(rw, "event: end\ndata: {\"type\": \"end\", \"message\": \"job ended\"}\n\n")
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var events []*types.JobEvent
+
+	err = c.WatchBenchmark(context.Background(), "abc", func(event *types.JobEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(events) != 2 || events[0].NodeID != "node1" || events[0].State != "running" || events[1].Type != "end" {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	// fn stops the watch
+	stop := fmt.Errorf("stop")
+
+	if err := c.WatchBenchmark(context.Background(), "abc", func(*types.JobEvent) error { return stop }); err != stop {
+		t.Errorf("expected fn's error, got %v", err)
+	}
+
+	if err := c.WatchBenchmark(context.Background(), "xyz", nil); err == nil || err.Error() != "key not found (HTTP 404)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/batchcorp/njst/cli"
+	"github.com/batchcorp/njst/client"
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	// Max number of job errors printed by "bench status"
+	MaxPrintedErrors = 10
+)
+
+// runClient runs one of the client subcommands against a node's HTTP API
+func runClient(command string, p *cli.ClientParams) error {
+	c, err := client.New(p.Address, p.Timeout)
+	if err != nil {
+		return errors.Wrap(err, "unable to create client")
+	}
+
+	switch command {
+	case "bench create":
+		return benchCreate(c, p)
+	case "bench status":
+		return benchStatus(c, p)
+	case "bench list":
+		return benchList(c, p)
+	case "bench delete":
+		return benchDelete(c, p)
+	case "bench purge":
+		return benchPurge(c)
+	case "cluster":
+		if p.NodeID != "" {
+			return nodeJobs(c, p.NodeID)
+		}
+
+		return clusterInfo(c)
+	default:
+		return fmt.Errorf("unknown command '%s'", command)
+	}
+}
+
+func benchCreate(c *client.Client, p *cli.ClientParams) error {
+	spec, err := readSpec(p.SpecFile)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.CreateBenchmark(spec)
+	if err != nil {
+		return errors.Wrap(err, "unable to create benchmark")
+	}
+
+	fmt.Printf("Created job %s\n", id)
+
+	if !p.Wait {
+		return nil
+	}
+
+	p.JobID = id
+	p.Watch = true
+
+	return benchStatus(c, p)
+}
+
+func benchStatus(c *client.Client, p *cli.ClientParams) error {
+	if p.Watch || p.Wait {
+		if err := watchBenchmark(c, p.JobID, p.Watch); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.GetBenchmark(p.JobID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get benchmark")
+	}
+
+	printStatus(os.Stdout, resp)
+
+	if p.Wait {
+		return jobFailure(resp.Status)
+	}
+
+	return nil
+}
+
+// watchBenchmark blocks until the job ends; progress is printed if verbose
+// is set
+func watchBenchmark(c *client.Client, id string, verbose bool) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	ended := false
+
+	err := c.WatchBenchmark(ctx, id, func(event *types.JobEvent) error {
+		if !verbose && event.Type != "end" {
+			return nil
+		}
+
+		switch event.Type {
+		case "node":
+			fmt.Printf("%s  node %s: %s (%s)\n", timestamp(), event.NodeID, event.State, event.Message)
+		case "status":
+			s := event.Status
+			fmt.Printf("%s  %s: processed=%d errors=%d msg/sec=%.2f elapsed=%.2fs\n", timestamp(),
+				s.Status, s.TotalProcessed, s.TotalErrors, s.TotalMsgPerSecAllNodes, s.ElapsedSeconds)
+		case "end":
+			ended = true
+
+			if verbose {
+				fmt.Printf("%s  job ended: %s\n\n", timestamp(), event.Status.Status)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "unable to watch benchmark")
+	}
+
+	if !ended {
+		return errors.New("stopped watching before the job ended")
+	}
+
+	return nil
+}
+
+// jobFailure returns an error if the job did not complete or did not pass its
+// expect block
+func jobFailure(status *types.Status) error {
+	if status.Status != types.CompletedStatus {
+		return fmt.Errorf("job %s did not complete (status: %s)", status.JobID, status.Status)
+	}
+
+	if status.Verdict != nil && !status.Verdict.Passed {
+		return fmt.Errorf("job %s failed %d assertion(s)", status.JobID, len(status.Verdict.Failures))
+	}
+
+	for _, r := range status.Verify {
+		if r.Gaps > 0 || r.Duplicates > 0 || r.Reordered > 0 || r.Corrupted > 0 {
+			return fmt.Errorf("job %s failed verification of stream %s", status.JobID, r.Stream)
+		}
+	}
+
+	return nil
+}
+
+func benchList(c *client.Client, p *cli.ClientParams) error {
+	query := url.Values{}
+
+	for name, value := range map[string]string{
+		"status":  p.ListStatus,
+		"type":    p.ListType,
+		"q":       p.ListQuery,
+		"profile": p.ListProfile,
+		"owner":   p.ListOwner,
+		"since":   p.ListSince,
+		"until":   p.ListUntil,
+		"sort":    p.ListSort,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	for _, tag := range p.ListTags {
+		query.Add("tag", tag)
+	}
+
+	if p.ListLimit > 0 {
+		query.Set("limit", strconv.Itoa(p.ListLimit))
+	}
+
+	if p.ListOffset > 0 {
+		query.Set("offset", strconv.Itoa(p.ListOffset))
+	}
+
+	list, err := c.ListBenchmarks(query)
+	if err != nil {
+		return errors.Wrap(err, "unable to list benchmarks")
+	}
+
+	tw := newTabWriter(os.Stdout)
+	fmt.Fprintln(tw, "ID\tCREATED\tDESCRIPTION\tOWNER\tTAGS\tTYPE\tSTREAMS\tNODES\tPROFILE\tSTATUS\tMSGS/SEC")
+
+	for _, s := range list.Benchmarks {
+		status := string(s.Status)
+
+		if s.Passed != nil && !*s.Passed {
+			status += " (failed assertions)"
+		}
+
+		if s.Archived {
+			status += " (archived)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t%.0f\n", s.ID,
+			s.CreatedAt.Local().Format(time.RFC3339), s.Description, s.Owner, formatPairs(s.Tags), s.Type,
+			s.NumStreams, s.NumNodes, s.Profile, status, s.TotalMsgPerSecAllNodes)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(list.Benchmarks) < list.Total {
+		fmt.Printf("\nShowing %d-%d of %d job(s); use --offset and --limit to see more\n", list.Offset+1,
+			list.Offset+len(list.Benchmarks), list.Total)
+	}
+
+	return nil
+}
+
+func benchDelete(c *client.Client, p *cli.ClientParams) error {
+	resp, err := c.DeleteBenchmark(p.JobID, p.DeleteStreams, p.DeleteSettings, p.DeleteResults)
+	if err != nil {
+		return errors.Wrap(err, "unable to delete benchmark")
+	}
+
+	fmt.Printf("Deleted job %s\n", p.JobID)
+	fmt.Printf("Confirmed by %d node(s), cancelled on %d\n", len(resp.Confirmed), len(resp.Cancelled))
+
+	if len(resp.Unconfirmed) > 0 {
+		for _, e := range resp.Errors {
+			fmt.Printf("  %s\n", e)
+		}
+
+		return errors.Errorf("%d node(s) did not confirm: %s", len(resp.Unconfirmed),
+			strings.Join(resp.Unconfirmed, ", "))
+	}
+
+	return nil
+}
+
+func benchPurge(c *client.Client) error {
+	if err := c.Purge(); err != nil {
+		return errors.Wrap(err, "unable to purge")
+	}
+
+	fmt.Println("Purged all njst streams, k/v stores and results")
+
+	return nil
+}
+
+func clusterInfo(c *client.Client) error {
+	cluster, err := c.GetCluster()
+	if err != nil {
+		return errors.Wrap(err, "unable to get cluster")
+	}
+
+	sort.Slice(cluster.Nodes, func(i, j int) bool {
+		return cluster.Nodes[i].ID < cluster.Nodes[j].ID
+	})
+
+	fmt.Printf("Nodes: %d (%d idle)\n", cluster.Count, cluster.NumIdle)
+
+	if cluster.Lock != nil {
+		fmt.Printf("Lock: held by exclusive job %s since %s\n", cluster.Lock.JobID,
+			cluster.Lock.AcquiredAt.Local().Format(time.RFC3339))
+	}
+
+	fmt.Println()
+
+	tw := newTabWriter(os.Stdout)
+	fmt.Fprintln(tw, "ID\tVERSION\tHOSTNAME\tLABELS\tCPUS\tGOROUTINES\tMEM ALLOC (MB)\tJOBS\tQUEUED\tLAST SEEN")
+
+	for _, n := range cluster.Nodes {
+		labels := make([]string, 0, len(n.Labels))
+
+		for k, v := range n.Labels {
+			labels = append(labels, k+"="+v)
+		}
+
+		sort.Strings(labels)
+
+		jobs := "-"
+
+		if len(n.Jobs) > 0 {
+			jobs = strings.Join(n.Jobs, ",")
+		}
+
+		var queued int
+
+		if n.Queue != nil {
+			queued = n.Queue.NumQueued
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%.1f\t%s\t%d\t%s\n", n.ID, n.Version, n.Hostname,
+			strings.Join(labels, ","), n.NumCPU, n.NumGoroutines, float64(n.MemAllocBytes)/1024/1024, jobs,
+			queued, n.LastSeen.Local().Format(time.RFC3339))
+	}
+
+	return tw.Flush()
+}
+
+func nodeJobs(c *client.Client, nodeID string) error {
+	resp, err := c.GetNodeJobs(nodeID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get node jobs")
+	}
+
+	maxQueued := "no limit"
+
+	if resp.MaxQueuedJobs > 0 {
+		maxQueued = fmt.Sprintf("%d", resp.MaxQueuedJobs)
+	}
+
+	fmt.Printf("Node: %s\nRunning: %d (max %d)\nQueued: %d (max %s)\n\n", nodeID, resp.NumRunning,
+		resp.MaxConcurrentJobs, resp.NumQueued, maxQueued)
+
+	if len(resp.Jobs) == 0 {
+		return nil
+	}
+
+	tw := newTabWriter(os.Stdout)
+	fmt.Fprintln(tw, "ID\tSTATE\tPOSITION\tEXCLUSIVE\tACCEPTED\tSTARTED\tREASON")
+
+	for _, j := range resp.Jobs {
+		position, started, reason := "-", "-", "-"
+
+		if j.Position > 0 {
+			position = fmt.Sprintf("%d", j.Position)
+		}
+
+		if !j.StartedAt.IsZero() {
+			started = j.StartedAt.Local().Format(time.RFC3339)
+		}
+
+		if j.Reason != "" {
+			reason = j.Reason
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\t%s\t%s\t%s\n", j.ID, j.State, position, j.Exclusive,
+			j.AcceptedAt.Local().Format(time.RFC3339), started, reason)
+	}
+
+	return tw.Flush()
+}
+
+func printStatus(w io.Writer, resp *types.StatusResponse) {
+	s := resp.Status
+
+	tw := newTabWriter(w)
+
+	fmt.Fprintf(tw, "ID:\t%s\n", s.JobID)
+
+	if resp.Settings != nil {
+		fmt.Fprintf(tw, "Description:\t%s\n", resp.Settings.Description)
+		fmt.Fprintf(tw, "Type:\t%s\n", jobType(resp.Settings))
+
+		if resp.Settings.Owner != "" {
+			fmt.Fprintf(tw, "Owner:\t%s\n", resp.Settings.Owner)
+		}
+
+		if len(resp.Settings.Tags) > 0 {
+			fmt.Fprintf(tw, "Tags:\t%s\n", formatPairs(resp.Settings.Tags))
+		}
+
+		if len(resp.Settings.Annotations) > 0 {
+			fmt.Fprintf(tw, "Annotations:\t%s\n", formatPairs(resp.Settings.Annotations))
+		}
+	}
+
+	fmt.Fprintf(tw, "Status:\t%s\n", s.Status)
+
+	if resp.ArchivedAt != nil {
+		fmt.Fprintf(tw, "Archived:\t%s\n", resp.ArchivedAt.Local().Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(tw, "Message:\t%s\n", s.Message)
+	fmt.Fprintf(tw, "Processed:\t%d\n", s.TotalProcessed)
+
+	if s.Plan != nil {
+		fmt.Fprintf(tw, "Planned:\t%d\n", s.Plan.Planned)
+	}
+
+	fmt.Fprintf(tw, "Errors:\t%d\n", s.TotalErrors)
+	fmt.Fprintf(tw, "Elapsed:\t%.2fs\n", s.ElapsedSeconds)
+	fmt.Fprintf(tw, "Total msg/sec:\t%.2f\n", s.TotalMsgPerSecAllNodes)
+	fmt.Fprintf(tw, "Avg msg/sec per node:\t%.2f\n", s.AvgMsgPerSecPerNode)
+
+	if s.Latency != nil {
+		fmt.Fprintf(tw, "Latency (ms):\tp50 %.2f  p90 %.2f  p99 %.2f  max %.2f\n",
+			s.Latency.P50Ms, s.Latency.P90Ms, s.Latency.P99Ms, s.Latency.MaxMs)
+	}
+
+	if s.Verdict != nil {
+		verdict := "passed"
+
+		if !s.Verdict.Passed {
+			verdict = "failed"
+		}
+
+		fmt.Fprintf(tw, "Verdict:\t%s\n", verdict)
+
+		for _, f := range s.Verdict.Failures {
+			fmt.Fprintf(tw, "\t- %s\n", f.Message)
+		}
+	}
+
+	if resp.Comparison != nil {
+		regressed := "no"
+
+		if resp.Comparison.Regressed {
+			regressed = "yes"
+		}
+
+		fmt.Fprintf(tw, "Regressed:\t%s (baseline %s of profile %s)\n", regressed,
+			resp.Comparison.BaselineJobID, resp.Comparison.Profile)
+	}
+
+	tw.Flush()
+
+	if len(s.Errors) > 0 {
+		fmt.Fprintln(w, "\nJob errors:")
+
+		for i, e := range s.Errors {
+			if i == MaxPrintedErrors {
+				fmt.Fprintf(w, "  .. and %d more\n", len(s.Errors)-MaxPrintedErrors)
+				break
+			}
+
+			fmt.Fprintf(w, "  %s\n", e)
+		}
+	}
+
+	if len(s.Verify) > 0 {
+		fmt.Fprintln(w)
+
+		tw = newTabWriter(w)
+		fmt.Fprintln(tw, "STREAM\tMESSAGES\tPRODUCERS\tGAPS\tDUPLICATES\tREORDERED\tCORRUPTED")
+
+		for _, r := range s.Verify {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", r.Stream, r.Messages, r.Producers, r.Gaps,
+				r.Duplicates, r.Reordered, r.Corrupted)
+		}
+
+		tw.Flush()
+	}
+
+	if len(s.NodeReports) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+
+	tw = newTabWriter(w)
+	fmt.Fprintln(tw, "NODE\tWORKERS\tPROCESSED\tERRORS\tELAPSED (S)\tMSG/SEC")
+
+	reports := append([]*types.NodeReport{}, s.NodeReports...)
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].NodeID < reports[j].NodeID
+	})
+
+	for _, report := range reports {
+		var workers, processed, errs int
+		var elapsed, msgPerSec float64
+
+		for _, stream := range report.Streams {
+			for _, worker := range stream.Workers {
+				workers++
+				processed += worker.Processed
+				errs += worker.Errors
+				msgPerSec += worker.AvgMsgPerSec
+
+				if worker.ElapsedSeconds > elapsed {
+					elapsed = worker.ElapsedSeconds
+				}
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.2f\t%.2f\n", report.NodeID, workers, processed, errs, elapsed, msgPerSec)
+	}
+
+	tw.Flush()
+}
+
+// readSpec reads a job spec from a file or from stdin if path is "-"
+func readSpec(path string) ([]byte, error) {
+	var (
+		spec []byte
+		err  error
+	)
+
+	if path == "-" {
+		spec, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		spec, err = ioutil.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read spec")
+	}
+
+	return spec, nil
+}
+
+func jobType(settings *types.Settings) string {
+	switch {
+	case settings.Write != nil:
+		return "write"
+	case settings.Read != nil:
+		return "read"
+	default:
+		return "-"
+	}
+}
+
+// formatPairs formats tags or annotations as "k1=v1,k2=v2", sorted by key
+func formatPairs(pairs map[string]string) string {
+	formatted := make([]string, 0, len(pairs))
+
+	for k, v := range pairs {
+		formatted = append(formatted, k+"="+v)
+	}
+
+	sort.Strings(formatted)
+
+	return strings.Join(formatted, ",")
+}
+
+func newTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+func timestamp() string {
+	return time.Now().Format("15:04:05")
+}
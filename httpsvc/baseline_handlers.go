@@ -0,0 +1,130 @@
+package httpsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+func (h *HTTPService) createBaselineHandler(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.log.Errorf("could not read request body: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("could not read request body: %s", err), rw)
+		return
+	}
+	defer r.Body.Close()
+
+	req := &types.Baseline{}
+
+	if err := json.Unmarshal(body, req); err != nil {
+		h.log.Errorf("unable to unmarshal baseline: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to unmarshal baseline: %s", err), rw)
+		return
+	}
+
+	if err := validateBaseline(req); err != nil {
+		h.log.Errorf("unable to validate baseline: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to validate baseline: %s", err), rw)
+		return
+	}
+
+	baseline, err := h.bench.SetBaseline(req.Profile, req.JobID, req.Thresholds)
+	if err != nil {
+		h.log.Errorf("unable to set baseline: %s", err)
+
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, fmt.Sprintf("unable to set baseline: %s", err), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to set baseline: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, baseline, rw)
+}
+
+func (h *HTTPService) getAllBaselinesHandler(rw http.ResponseWriter, r *http.Request) {
+	baselines, err := h.nats.GetAllBaselines()
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get baselines: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, baselines, rw)
+}
+
+func (h *HTTPService) getBaselineHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	profile := ps.ByName("profile")
+
+	if profile == "" {
+		writeErrorJSON(http.StatusBadRequest, "profile is required", rw)
+		return
+	}
+
+	baseline, err := h.nats.GetBaseline(profile)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get baseline: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, baseline, rw)
+}
+
+func (h *HTTPService) deleteBaselineHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	profile := ps.ByName("profile")
+
+	if profile == "" {
+		writeErrorJSON(http.StatusBadRequest, "profile is required", rw)
+		return
+	}
+
+	if err := h.nats.DeleteBaseline(profile); err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to delete baseline: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"message": "baseline deleted",
+	}, rw)
+}
+
+func validateBaseline(baseline *types.Baseline) error {
+	if baseline == nil {
+		return errors.New("baseline cannot be nil")
+	}
+
+	if baseline.Profile == "" {
+		return errors.New("profile cannot be empty")
+	}
+
+	if !profileRegex.MatchString(baseline.Profile) {
+		return errors.New("profile may only contain letters, digits, '_', '-' and '.'")
+	}
+
+	if baseline.JobID == "" {
+		return errors.New("job_id cannot be empty")
+	}
+
+	if t := baseline.Thresholds; t != nil {
+		for _, v := range []*float64{t.ThroughputDropPct, t.ErrorRateIncreasePct, t.LatencyIncreasePct} {
+			if v != nil && *v < 0 {
+				return errors.New("thresholds cannot be negative")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package httpsvc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestValidateBaseline(t *testing.T) {
+	negative := float64(-1)
+	zero := float64(0)
+
+	tests := []struct {
+		name     string
+		baseline *types.Baseline
+		err      string
+	}{
+		{"nil baseline", nil, "baseline cannot be nil"},
+		{"no profile", &types.Baseline{JobID: "abc"}, "profile cannot be empty"},
+		{"invalid profile", &types.Baseline{Profile: "a/b", JobID: "abc"}, "profile may only contain"},
+		{"no job id", &types.Baseline{Profile: "nightly"}, "job_id cannot be empty"},
+		{"negative threshold", &types.Baseline{Profile: "nightly", JobID: "abc",
+			Thresholds: &types.Thresholds{LatencyIncreasePct: &negative}}, "thresholds cannot be negative"},
+		{"zero threshold", &types.Baseline{Profile: "nightly", JobID: "abc",
+			Thresholds: &types.Thresholds{ThroughputDropPct: &zero}}, ""},
+		{"valid", &types.Baseline{Profile: "nightly", JobID: "abc"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBaseline(tt.baseline)
+
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error '%s', got '%v'", tt.err, err)
+			}
+		})
+	}
+}
@@ -5,15 +5,39 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/batchcorp/njst/bench"
-	"github.com/batchcorp/njst/natssvc"
 	"github.com/batchcorp/njst/types"
 	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 )
 
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 1000
+)
+
+var (
+	// Profiles are used as KV keys
+	profileRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+	// Statuses jobs can be listed by
+	listStatuses = map[types.JobStatus]bool{
+		types.InProgressStatus: true,
+		types.CompletedStatus:  true,
+		types.ErrorStatus:      true,
+		types.CancelledStatus:  true,
+		types.FailedStatus:     true,
+		types.UnknownStatus:    true,
+	}
+)
+
 func (h *HTTPService) getBenchmarkHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	id := ps.ByName("id")
 
@@ -22,42 +46,246 @@ func (h *HTTPService) getBenchmarkHandler(rw http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	status, err := h.bench.Status(id)
+	resp, statusCode, err := h.getStatusResponse(id)
 	if err != nil {
-		if strings.Contains(err.Error(), "unable to get") {
+		writeErrorJSON(statusCode, err.Error(), rw)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+
+	if format == "" || format == "json" {
+		// Clear node reports and the raw latency histogram unless "full" is specified
+		if _, ok := r.URL.Query()["full"]; !ok {
+			resp.Status.NodeReports = nil
+			resp.Status.LatencyHistogram = nil
+		}
+
+		writeJSON(http.StatusOK, resp, rw)
+		return
+	}
+
+	contentType, data, err := renderExport(format, []*types.StatusResponse{resp})
+	if err != nil {
+		writeErrorJSON(http.StatusBadRequest, err.Error(), rw)
+		return
+	}
+
+	writeRaw(http.StatusOK, contentType, data, rw)
+}
+
+func (h *HTTPService) getTimelineHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	if _, err := h.nats.GetSettings(id); err != nil {
+		if errors.Cause(err) != nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get settings: %s", err), rw)
+			return
+		}
+
+		// The timeline of an archived job is kept until it ages out
+		if run, archErr := h.nats.GetArchivedRun(id); archErr != nil || run == nil {
 			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
 			return
 		}
+	}
 
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get status: %s", err), rw)
+	timeline, err := h.bench.Timeline(id)
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get timeline: %s", err), rw)
 		return
 	}
 
-	// Clear node reports unless "full" is specified
+	// Clear raw latency histograms unless "full" is specified
 	if _, ok := r.URL.Query()["full"]; !ok {
-		status.NodeReports = nil
+		for _, sample := range timeline.Totals {
+			sample.LatencyHistogram = nil
+		}
+
+		for _, samples := range timeline.Nodes {
+			for _, sample := range samples {
+				sample.LatencyHistogram = nil
+			}
+		}
+	}
+
+	writeJSON(http.StatusOK, timeline, rw)
+}
+
+// getStatusResponse returns the full (including node reports) status,
+// settings and baseline comparison for a job along with the HTTP status code
+// to use if an error occurred. Archived jobs are read from the history stream.
+func (h *HTTPService) getStatusResponse(id string) (*types.StatusResponse, int, error) {
+	status, err := h.bench.Status(id)
+	if err != nil {
+		// No results bucket
+		if errors.Cause(err) == nats.ErrBucketNotFound {
+			return nil, http.StatusNotFound, err
+		}
+
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "unable to get status")
 	}
 
+	var archivedAt *time.Time
+
 	settings, err := h.nats.GetSettings(id)
 	if err != nil {
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get settings: %s", err), rw)
-		return
+		if errors.Cause(err) != nats.ErrKeyNotFound {
+			return nil, http.StatusInternalServerError, errors.Wrap(err, "unable to get settings")
+		}
+
+		run, archErr := h.nats.GetArchivedRun(id)
+		if archErr != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(archErr, "unable to get archived run")
+		}
+
+		if run == nil {
+			return nil, http.StatusNotFound, err
+		}
+
+		settings = run.Settings
+		status = run.Status
+		archivedAt = &run.ArchivedAt
 	}
 
-	writeJSON(http.StatusOK, &types.StatusResponse{
-		Status:   status,
-		Settings: settings,
-	}, rw)
+	comparison, err := h.bench.Compare(settings, status)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "unable to compare against baseline")
+	}
+
+	return &types.StatusResponse{
+		Status:     status,
+		Settings:   settings,
+		Comparison: comparison,
+		ArchivedAt: archivedAt,
+	}, http.StatusOK, nil
 }
 
+// getAllBenchmarksHandler lists job summaries; see parseListFilter for the
+// supported query params
 func (h *HTTPService) getAllBenchmarksHandler(rw http.ResponseWriter, r *http.Request) {
-	allSettings, err := h.nats.GetAllSettings()
+	filter, err := parseListFilter(r.URL.Query(), time.Now().UTC())
+	if err != nil {
+		writeErrorJSON(http.StatusBadRequest, err.Error(), rw)
+		return
+	}
+
+	list, err := h.bench.List(filter)
 	if err != nil {
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get settings: %s", err), rw)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to list benchmarks: %s", err), rw)
 		return
 	}
 
-	writeJSON(http.StatusOK, allSettings, rw)
+	writeJSON(http.StatusOK, list, rw)
+}
+
+// parseListFilter parses the query params of GET /bench: status (comma
+// separated), type, q (description substring), profile, owner, tag (key=value
+// or key, repeatable), since and until (see parseListTime), sort ([-]field),
+// limit and offset
+func parseListFilter(query url.Values, now time.Time) (*types.ListFilter, error) {
+	filter := &types.ListFilter{
+		Type:        query.Get("type"),
+		Description: query.Get("q"),
+		Profile:     query.Get("profile"),
+		Owner:       query.Get("owner"),
+		Sort:        query.Get("sort"),
+		Limit:       DefaultListLimit,
+	}
+
+	for _, param := range query["status"] {
+		for _, status := range strings.Split(param, ",") {
+			if status = strings.TrimSpace(status); status == "" {
+				continue
+			}
+
+			if !listStatuses[types.JobStatus(status)] {
+				return nil, errors.Errorf("unrecognized status '%s'", status)
+			}
+
+			filter.Statuses = append(filter.Statuses, types.JobStatus(status))
+		}
+	}
+
+	for _, tag := range query["tag"] {
+		parts := strings.SplitN(tag, "=", 2)
+
+		if parts[0] == "" {
+			return nil, errors.Errorf("tag '%s' must be either key=value or key", tag)
+		}
+
+		if filter.Tags == nil {
+			filter.Tags = make(map[string]string)
+		}
+
+		filter.Tags[parts[0]] = ""
+
+		if len(parts) == 2 {
+			filter.Tags[parts[0]] = parts[1]
+		}
+	}
+
+	if filter.Type != "" && filter.Type != "write" && filter.Type != "read" {
+		return nil, errors.New("type must be either 'write' or 'read'")
+	}
+
+	var err error
+
+	if filter.Since, err = parseListTime(query.Get("since"), now); err != nil {
+		return nil, errors.Wrap(err, "unable to parse since")
+	}
+
+	if filter.Until, err = parseListTime(query.Get("until"), now); err != nil {
+		return nil, errors.Wrap(err, "unable to parse until")
+	}
+
+	if filter.Sort != "" && !validSort(filter.Sort) {
+		return nil, errors.Errorf("sort must be one of %s (prefix with '-' for descending order)",
+			strings.Join(bench.ListSortFields, ", "))
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 || filter.Limit > MaxListLimit {
+			return nil, errors.Errorf("limit must be between 1 and %d", MaxListLimit)
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return nil, errors.New("offset cannot be negative")
+		}
+	}
+
+	return filter, nil
+}
+
+func validSort(sort string) bool {
+	for _, field := range bench.ListSortFields {
+		if strings.TrimPrefix(sort, "-") == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseListTime parses either an RFC3339 time or a duration before now (ex:
+// "24h")
+func parseListTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, v)
 }
 
 func (h *HTTPService) purgeAllHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -116,14 +344,13 @@ func (h *HTTPService) deleteBenchmarkHandler(rw http.ResponseWriter, r *http.Req
 		deleteResults = true
 	}
 
-	if err := h.bench.Delete(id, deleteStreams, deleteSettings, deleteResults); err != nil {
+	resp, err := h.bench.Delete(id, deleteStreams, deleteSettings, deleteResults)
+	if err != nil {
 		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to delete benchmark: %s", err), rw)
 		return
 	}
 
-	writeJSON(http.StatusOK, map[string]string{
-		"message": "emitted benchmark deletion job",
-	}, rw)
+	writeJSON(http.StatusOK, resp, rw)
 }
 
 func (h *HTTPService) createBenchmarkHandler(rw http.ResponseWriter, r *http.Request) {
@@ -143,54 +370,42 @@ func (h *HTTPService) createBenchmarkHandler(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := validateSettings(settings); err != nil {
+	if err := ValidateSettings(settings); err != nil {
 		h.log.Errorf("unable to validate settings: %s", err)
 		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to validate settings: %s", err), rw)
 		return
 	}
 
-	settings.ID = RandString(8)
-
-	jobs, err := h.bench.GenerateCreateJobs(settings)
+	resp, err := h.bench.Create(settings)
 	if err != nil {
-		h.log.Errorf("unable to create jobs: %s", err)
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to create jobs: %s", err), rw)
-		return
-	}
+		h.log.Errorf("unable to create benchmark: %s", err)
 
-	// Create result bucket
-	bucket, err := h.nats.GetOrCreateBucket(natssvc.ResultBucketPrefix, settings.ID)
-	if err != nil {
-		h.log.Errorf("unable to get or create result bucket: %s", err)
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get or create result bucket: %s", err), rw)
-		return
-	}
+		// Another exclusive job holds the cluster lock
+		if resp != nil && resp.Conflict != nil {
+			resp.Error = fmt.Sprintf("unable to create benchmark: %s", err)
+			writeJSON(http.StatusConflict, resp, rw)
 
-	bucketName := fmt.Sprintf("%s-%s", natssvc.ResultBucketPrefix, settings.ID)
+			return
+		}
 
-	h.nats.CacheBucket(bucketName, bucket)
+		// Nodes rejected the job
+		if resp != nil {
+			resp.Error = fmt.Sprintf("unable to create benchmark: %s", err)
+			writeJSON(http.StatusServiceUnavailable, resp, rw)
 
-	if err := h.nats.EmitJobs(types.CreateJob, jobs); err != nil {
-		h.log.Errorf("unable to emit jobs: %s", err)
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to emit create benchmark: %s", err), rw)
-		return
-	}
+			return
+		}
 
-	if err := h.nats.SaveSettings(settings); err != nil {
-		h.log.Errorf("unable to save settings: %s", err)
-		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to save settings: %s", err), rw)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to create benchmark: %s", err), rw)
 		return
 	}
 
-	h.log.Infof("Emitted '%d' create benchmark job(s)", len(jobs))
-
-	writeJSON(http.StatusOK, map[string]string{
-		"id":      settings.ID,
-		"message": fmt.Sprintf("benchmark created successfully; created %d jobs", len(jobs)),
-	}, rw)
+	writeJSON(http.StatusOK, resp, rw)
 }
 
-func validateSettings(settings *types.Settings) error {
+// ValidateSettings validates job settings and fills in defaults; it is also
+// used by "njst run" for standalone jobs
+func ValidateSettings(settings *types.Settings) error {
 	if settings == nil {
 		return errors.New("settings cannot be nil")
 	}
@@ -203,6 +418,46 @@ func validateSettings(settings *types.Settings) error {
 		return errors.New("read or write settings must be set")
 	}
 
+	if settings.Profile != "" && !profileRegex.MatchString(settings.Profile) {
+		return errors.New("profile may only contain letters, digits, '_', '-' and '.'")
+	}
+
+	// Tag keys end up in tag=key=value query params
+	for k := range settings.Tags {
+		if !profileRegex.MatchString(k) {
+			return errors.Errorf("tag '%s': keys may only contain letters, digits, '_', '-' and '.'", k)
+		}
+	}
+
+	for k := range settings.Annotations {
+		if strings.TrimSpace(k) == "" {
+			return errors.New("annotation keys cannot be empty")
+		}
+	}
+
+	switch settings.OnConflict {
+	case "", types.RejectConflictPolicy, types.QueueConflictPolicy:
+	default:
+		return errors.Errorf("on_conflict must be either %q or %q", types.RejectConflictPolicy, types.QueueConflictPolicy)
+	}
+
+	if settings.MaxDuration != "" {
+		d, err := time.ParseDuration(settings.MaxDuration)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse max_duration")
+		}
+
+		if d <= 0 {
+			return errors.New("max_duration must be positive")
+		}
+	}
+
+	if settings.Expect != nil {
+		if err := validateExpect(settings.Expect); err != nil {
+			return errors.Wrap(err, "invalid expect settings")
+		}
+	}
+
 	if settings.Read != nil {
 		if err := validateReadSettings(settings.Read); err != nil {
 			return err
@@ -218,6 +473,27 @@ func validateSettings(settings *types.Settings) error {
 	return nil
 }
 
+func validateExpect(expect *types.Expect) error {
+	limits := map[string]*float64{
+		"min_total_msgs_per_sec": expect.MinTotalMsgsPerSec,
+		"max_error_rate":         expect.MaxErrorRate,
+		"max_p99_latency_ms":     expect.MaxP99LatencyMs,
+		"max_elapsed_seconds":    expect.MaxElapsedSeconds,
+	}
+
+	for name, v := range limits {
+		if v != nil && *v < 0 {
+			return errors.Errorf("%s cannot be negative", name)
+		}
+	}
+
+	if expect.MaxErrorRate != nil && *expect.MaxErrorRate > 100 {
+		return errors.New("max_error_rate is a percentage and cannot be above 100")
+	}
+
+	return nil
+}
+
 func validateReadSettings(rs *types.ReadSettings) error {
 	if rs == nil {
 		return errors.New("read settings cannot be nil")
@@ -235,14 +511,14 @@ func validateReadSettings(rs *types.ReadSettings) error {
 		rs.BatchSize = bench.DefaultBatchSize
 	}
 
-	if rs.BatchSize > rs.NumMessagesPerStream {
-		return errors.New("batch size cannot be greater than num messages per stream")
-	}
-
 	if rs.NumMessagesPerStream < 1 {
 		rs.NumMessagesPerStream = bench.DefaultNumMessagesPerStream
 	}
 
+	if rs.BatchSize > rs.NumMessagesPerStream {
+		return errors.New("batch size cannot be greater than num messages per stream")
+	}
+
 	if len(rs.Subjects) == 0 {
 		rs.Subjects = []string{bench.DefaultSubject}
 	}
@@ -267,6 +543,10 @@ func validateWriteSettings(ws *types.WriteSettings) error {
 		ws.MsgSizeBytes = bench.DefaultMsgSizeBytes
 	}
 
+	if ws.Verify && ws.MsgSizeBytes < bench.VerifyHeaderSize {
+		return errors.Errorf("msg_size_bytes must be at least %d bytes with verify enabled", bench.VerifyHeaderSize)
+	}
+
 	if ws.NumMessagesPerStream == 0 {
 		ws.NumMessagesPerStream = bench.DefaultNumMessagesPerStream
 	}
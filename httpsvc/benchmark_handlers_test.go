@@ -0,0 +1,219 @@
+package httpsvc
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestValidateSettings(t *testing.T) {
+	nats := &types.NATS{Address: "localhost:4222"}
+
+	tests := []struct {
+		name     string
+		settings *types.Settings
+		err      string
+	}{
+		{"nil settings", nil, "settings cannot be nil"},
+		{"nil nats settings", &types.Settings{Write: &types.WriteSettings{}}, "nats settings cannot be nil"},
+		{"no read or write settings", &types.Settings{NATS: nats}, "read or write settings must be set"},
+		{"invalid profile", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, Profile: "a b"},
+			"profile may only contain"},
+		{"valid profile", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, Profile: "nightly_r3-v1.2"}, ""},
+		{"tags and annotations", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, Owner: "jane",
+			Tags:        map[string]string{"eval": "nats-2.10", "env": ""},
+			Annotations: map[string]string{"ticket": "OPS-1234", "nats version": "2.10.1"}}, ""},
+		{"invalid tag key", &types.Settings{NATS: nats, Write: &types.WriteSettings{},
+			Tags: map[string]string{"a=b": "c"}}, "tag 'a=b': keys may only contain"},
+		{"empty annotation key", &types.Settings{NATS: nats, Write: &types.WriteSettings{},
+			Annotations: map[string]string{" ": "x"}}, "annotation keys cannot be empty"},
+		{"negative expect", &types.Settings{NATS: nats, Write: &types.WriteSettings{},
+			Expect: &types.Expect{MaxP99LatencyMs: float(-1)}}, "max_p99_latency_ms cannot be negative"},
+		{"error rate above 100", &types.Settings{NATS: nats, Write: &types.WriteSettings{},
+			Expect: &types.Expect{MaxErrorRate: float(100.1)}}, "max_error_rate is a percentage"},
+		{"zero limits", &types.Settings{NATS: nats, Write: &types.WriteSettings{},
+			Expect: &types.Expect{MaxErrorRate: float(0), MinTotalMsgsPerSec: float(0)}}, ""},
+		{"max duration", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, MaxDuration: "90s"}, ""},
+		{"invalid max duration", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, MaxDuration: "soon"},
+			"unable to parse max_duration"},
+		{"negative max duration", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, MaxDuration: "-1m"},
+			"max_duration must be positive"},
+		{"on conflict queue", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, Exclusive: true,
+			OnConflict: types.QueueConflictPolicy}, ""},
+		{"unknown on conflict", &types.Settings{NATS: nats, Write: &types.WriteSettings{}, OnConflict: "wait"},
+			"on_conflict must be"},
+		{"verify with small messages", &types.Settings{NATS: nats, Write: &types.WriteSettings{MsgSizeBytes: 19, Verify: true}},
+			"msg_size_bytes must be at least 20 bytes"},
+		{"verify", &types.Settings{NATS: nats, Write: &types.WriteSettings{MsgSizeBytes: 20, Verify: true}}, ""},
+		{"unknown storage", &types.Settings{NATS: nats, Write: &types.WriteSettings{Storage: "tape"}},
+			"unrecognized storage type"},
+		{"read batch size above num messages", &types.Settings{NATS: nats,
+			Read: &types.ReadSettings{NumMessagesPerStream: 10, BatchSize: 11}},
+			"batch size cannot be greater than num messages per stream"},
+		{"read batch size equal to num messages", &types.Settings{NATS: nats,
+			Read: &types.ReadSettings{NumMessagesPerStream: 10, BatchSize: 10}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSettings(tt.settings)
+
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error '%s', got '%v'", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateWriteSettingsDefaults(t *testing.T) {
+	ws := &types.WriteSettings{}
+
+	if err := validateWriteSettings(ws); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := &types.WriteSettings{
+		NumStreams:           bench.DefaultNumStreams,
+		NumMessagesPerStream: bench.DefaultNumMessagesPerStream,
+		NumWorkersPerStream:  bench.DefaultNumWorkersPerStream,
+		Subjects:             []string{bench.DefaultSubject},
+		BatchSize:            bench.DefaultBatchSize,
+		MsgSizeBytes:         bench.DefaultMsgSizeBytes,
+		Storage:              types.MemoryStreamType,
+	}
+
+	if !reflect.DeepEqual(ws, expected) {
+		t.Errorf("expected %+v, got %+v", expected, ws)
+	}
+
+	// Explicit values are kept
+	ws = &types.WriteSettings{
+		NumStreams:           4,
+		NumMessagesPerStream: 50,
+		NumWorkersPerStream:  2,
+		Subjects:             []string{"foo"},
+		BatchSize:            5,
+		MsgSizeBytes:         64,
+		Storage:              types.FileStorageType,
+	}
+
+	kept := *ws
+
+	if err := validateWriteSettings(ws); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(*ws, kept) {
+		t.Errorf("expected %+v, got %+v", kept, *ws)
+	}
+}
+
+func TestValidateReadSettingsDefaults(t *testing.T) {
+	rs := &types.ReadSettings{WriteID: "w1"}
+
+	if err := validateReadSettings(rs); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := &types.ReadSettings{
+		WriteID:              "w1",
+		NumStreams:           bench.DefaultNumStreams,
+		NumMessagesPerStream: bench.DefaultNumMessagesPerStream,
+		NumWorkersPerStream:  bench.DefaultNumWorkersPerStream,
+		Subjects:             []string{bench.DefaultSubject},
+		BatchSize:            bench.DefaultBatchSize,
+	}
+
+	if !reflect.DeepEqual(rs, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rs)
+	}
+}
+
+func TestParseListFilter(t *testing.T) {
+	now := time.Date(2022, 5, 25, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected *types.ListFilter
+		err      string
+	}{
+		{"defaults", "", &types.ListFilter{Limit: DefaultListLimit}, ""},
+		{"filters", "status=completed,failed&type=write&q=nightly&profile=p1&sort=-throughput&limit=10&offset=20",
+			&types.ListFilter{
+				Statuses:    []types.JobStatus{types.CompletedStatus, types.FailedStatus},
+				Type:        "write",
+				Description: "nightly",
+				Profile:     "p1",
+				Sort:        "-throughput",
+				Limit:       10,
+				Offset:      20,
+			}, ""},
+		{"owner and tags", "owner=jane&tag=eval=nats-2.10&tag=env&tag=url=a=b", &types.ListFilter{
+			Owner: "jane",
+			Tags:  map[string]string{"eval": "nats-2.10", "env": "", "url": "a=b"},
+			Limit: DefaultListLimit,
+		}, ""},
+		{"tag without key", "tag==x", nil, "tag '=x' must be either key=value or key"},
+		{"repeated status", "status=error&status=unknown", &types.ListFilter{
+			Statuses: []types.JobStatus{types.ErrorStatus, types.UnknownStatus},
+			Limit:    DefaultListLimit,
+		}, ""},
+		{"time range", "since=24h&until=2022-05-25T06:00:00Z", &types.ListFilter{
+			Since: now.Add(-24 * time.Hour),
+			Until: time.Date(2022, 5, 25, 6, 0, 0, 0, time.UTC),
+			Limit: DefaultListLimit,
+		}, ""},
+		{"unknown status", "status=lost", nil, "unrecognized status 'lost'"},
+		{"unknown type", "type=both", nil, "type must be either"},
+		{"invalid since", "since=yesterday", nil, "unable to parse since"},
+		{"unknown sort", "sort=-id", nil, "sort must be one of"},
+		{"limit too large", "limit=100000", nil, "limit must be between 1 and 1000"},
+		{"zero limit", "limit=0", nil, "limit must be between"},
+		{"negative offset", "offset=-1", nil, "offset cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unable to parse query: %s", err)
+			}
+
+			filter, err := parseListFilter(query, now)
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error '%s', got '%v'", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(filter, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, filter)
+			}
+		})
+	}
+}
@@ -3,24 +3,94 @@ package httpsvc
 import (
 	"fmt"
 	"net/http"
-)
 
-type GetClusterResponse struct {
-	Nodes []string `json:"nodes"`
-	Count int      `json:"count"`
-}
+	"github.com/batchcorp/njst/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+)
 
 func (h *HTTPService) getClusterHandler(rw http.ResponseWriter, r *http.Request) {
-	nodes, err := h.nats.GetNodeList()
+	nodes, err := h.nats.GetNodes()
 	if err != nil {
 		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get cluster nodes: %v", err), rw)
 		return
 	}
 
-	resp := &GetClusterResponse{
-		Nodes: nodes,
-		Count: len(nodes),
+	lock, err := h.nats.GetLock()
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get cluster lock: %v", err), rw)
+		return
+	}
+
+	resp := &types.ClusterResponse{
+		Nodes:    nodes,
+		Count:    len(nodes),
+		Versions: make(map[string]int),
+		Lock:     lock,
+	}
+
+	for _, node := range nodes {
+		resp.Versions[node.Version]++
+
+		if len(node.Jobs) == 0 {
+			resp.NumIdle++
+		}
 	}
 
 	writeJSON(http.StatusOK, resp, rw)
 }
+
+func (h *HTTPService) getClusterNodeHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("node")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "node is required", rw)
+		return
+	}
+
+	node, err := h.nats.GetNode(id)
+	if err != nil {
+		if err == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, fmt.Sprintf("node '%s' not found", id), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get node: %v", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, node, rw)
+}
+
+// getClusterNodeJobsHandler lists the jobs a node is running and the jobs
+// waiting in its queue (with the reason they have not started), as of the
+// node's last heartbeat
+func (h *HTTPService) getClusterNodeJobsHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("node")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "node is required", rw)
+		return
+	}
+
+	node, err := h.nats.GetNode(id)
+	if err != nil {
+		if err == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, fmt.Sprintf("node '%s' not found", id), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get node: %v", err), rw)
+		return
+	}
+
+	// Nodes running an older version do not report their queue
+	if node.Queue == nil {
+		node.Queue = &types.NodeJobs{
+			NodeID: node.ID,
+			Jobs:   make([]*types.NodeJob, 0),
+		}
+	}
+
+	writeJSON(http.StatusOK, node.Queue, rw)
+}
@@ -0,0 +1,68 @@
+package httpsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// getEventsHandler streams job events as Server-Sent Events until the job
+// ends or the client disconnects
+func (h *HTTPService) getEventsHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeErrorJSON(http.StatusInternalServerError, "streaming is not supported", rw)
+		return
+	}
+
+	eventCh, err := h.bench.WatchStatus(r.Context(), id)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to watch job: %s", err), rw)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_, full := r.URL.Query()["full"]
+
+	for event := range eventCh {
+		// Clear node reports and raw latency histograms unless "full" is specified
+		if event.Status != nil && !full {
+			event.Status.NodeReports = nil
+			event.Status.LatencyHistogram = nil
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			h.log.Errorf("unable to marshal event for job '%s': %s", id, err)
+			continue
+		}
+
+		if _, err := fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			h.log.Debugf("unable to write event for job '%s': %s", id, err)
+			return
+		}
+
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,499 @@
+package httpsvc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	CSVFormat      = "csv"
+	MarkdownFormat = "markdown"
+	JUnitFormat    = "junit"
+	JSONLFormat    = "jsonl"
+)
+
+var (
+	exportColumns = []string{
+		"job_id", "description", "record", "worker_id", "status", "processed", "errors",
+		"elapsed_seconds", "msg_per_sec", "avg_msg_per_sec_per_node",
+		"p50_ms", "p90_ms", "p99_ms", "max_ms", "verdict", "regressed",
+		"owner", "tags", "annotations",
+	}
+
+	markdownJobColumns = []string{
+		"job_id", "description", "owner", "tags", "status", "processed", "errors", "elapsed_seconds", "msg_per_sec",
+		"avg_msg_per_sec_per_node", "p50_ms", "p90_ms", "p99_ms", "max_ms", "verdict", "regressed",
+	}
+
+	markdownWorkerColumns = []string{
+		"job_id", "worker_id", "processed", "errors", "elapsed_seconds", "msg_per_sec",
+		"p50_ms", "p90_ms", "p99_ms", "max_ms",
+	}
+)
+
+// exportRecord is a single line of a JSON Lines export; "job" records carry
+// the aggregate status, "worker" records carry a single worker report
+type exportRecord struct {
+	Type       string              `json:"type"`
+	JobID      string              `json:"job_id"`
+	Status     *types.Status       `json:"status,omitempty"`
+	Settings   *types.Settings     `json:"settings,omitempty"`
+	Comparison *types.Comparison   `json:"comparison,omitempty"`
+	Worker     *types.WorkerReport `json:"worker,omitempty"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	Name       string            `xml:"name,attr"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Properties []*junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []*junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// renderExport renders one or more job results in the given format and
+// returns the content type to use for the response
+func renderExport(format string, responses []*types.StatusResponse) (string, []byte, error) {
+	switch format {
+	case CSVFormat:
+		data, err := renderCSV(responses)
+		return "text/csv; charset=utf-8", data, err
+	case MarkdownFormat, "md":
+		return "text/markdown; charset=utf-8", renderMarkdown(responses), nil
+	case JUnitFormat:
+		data, err := renderJUnit(responses)
+		return "application/xml; charset=utf-8", data, err
+	case JSONLFormat:
+		data, err := renderJSONL(responses)
+		return "application/x-ndjson", data, err
+	default:
+		return "", nil, errors.Errorf("unknown format '%s'; must be one of json, csv, markdown, junit or jsonl", format)
+	}
+}
+
+// exportRows returns the aggregate row followed by one row per worker for
+// every job; columns match exportColumns
+func exportRows(responses []*types.StatusResponse) [][]string {
+	rows := make([][]string, 0)
+
+	for _, resp := range responses {
+		status := resp.Status
+
+		var description, owner, tags, annotations string
+
+		if resp.Settings != nil {
+			description = resp.Settings.Description
+			owner = resp.Settings.Owner
+			tags = formatPairs(resp.Settings.Tags)
+			annotations = formatPairs(resp.Settings.Annotations)
+		}
+
+		verdict := ""
+
+		if status.Verdict != nil {
+			verdict = "failed"
+
+			if status.Verdict.Passed {
+				verdict = "passed"
+			}
+		}
+
+		regressed := ""
+
+		if resp.Comparison != nil {
+			regressed = strconv.FormatBool(resp.Comparison.Regressed)
+		}
+
+		row := []string{
+			status.JobID, description, "job", "", string(status.Status),
+			strconv.Itoa(status.TotalProcessed), strconv.Itoa(status.TotalErrors),
+			formatFloat(status.ElapsedSeconds), formatFloat(status.TotalMsgPerSecAllNodes),
+			formatFloat(status.AvgMsgPerSecPerNode),
+		}
+
+		row = append(row, latencyColumns(status.Latency)...)
+		row = append(row, verdict, regressed, owner, tags, annotations)
+		rows = append(rows, row)
+
+		for _, worker := range workerReports(status) {
+			row := []string{
+				status.JobID, description, "worker", worker.WorkerID, "",
+				strconv.Itoa(worker.Processed), strconv.Itoa(worker.Errors),
+				formatFloat(worker.ElapsedSeconds), formatFloat(worker.AvgMsgPerSec), "",
+			}
+
+			row = append(row, latencyColumns(worker.Latency)...)
+			row = append(row, "", "", owner, tags, annotations)
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func renderCSV(responses []*types.StatusResponse) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	if err := w.Write(exportColumns); err != nil {
+		return nil, errors.Wrap(err, "unable to write csv header")
+	}
+
+	if err := w.WriteAll(exportRows(responses)); err != nil {
+		return nil, errors.Wrap(err, "unable to write csv rows")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderMarkdown(responses []*types.StatusResponse) []byte {
+	sb := &strings.Builder{}
+
+	jobRows := make([][]string, 0)
+	workerRows := make([][]string, 0)
+
+	for _, row := range exportRows(responses) {
+		if row[2] == "job" {
+			jobRows = append(jobRows, pickColumns(row, markdownJobColumns))
+		} else {
+			workerRows = append(workerRows, pickColumns(row, markdownWorkerColumns))
+		}
+	}
+
+	sb.WriteString("## Jobs\n\n")
+	sb.WriteString(markdownTable(markdownJobColumns, jobRows))
+
+	for _, resp := range responses {
+		if resp.Status.Verdict == nil || resp.Status.Verdict.Passed {
+			continue
+		}
+
+		fmt.Fprintf(sb, "\n### Failed assertions for %s\n\n", resp.Status.JobID)
+
+		for _, failure := range resp.Status.Verdict.Failures {
+			fmt.Fprintf(sb, "* `%s`: %s\n", failure.Assertion, failure.Message)
+		}
+	}
+
+	if len(workerRows) > 0 {
+		sb.WriteString("\n## Workers\n\n")
+		sb.WriteString(markdownTable(markdownWorkerColumns, workerRows))
+	}
+
+	return []byte(sb.String())
+}
+
+// pickColumns returns the given columns (by exportColumns name) of a row
+func pickColumns(row []string, names []string) []string {
+	picked := make([]string, 0, len(names))
+
+	for _, name := range names {
+		for i, col := range exportColumns {
+			if col == name {
+				picked = append(picked, row[i])
+				break
+			}
+		}
+	}
+
+	return picked
+}
+
+func renderJUnit(responses []*types.StatusResponse) ([]byte, error) {
+	suites := &junitTestSuites{
+		Name:       "njst",
+		TestSuites: make([]*junitTestSuite, 0, len(responses)),
+	}
+
+	for _, resp := range responses {
+		suite := junitSuite(resp)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal junit xml")
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// junitSuite turns a job into a test suite: one test case for the job status,
+// one per expect assertion and one per baseline comparison metric
+func junitSuite(resp *types.StatusResponse) *junitTestSuite {
+	status := resp.Status
+	className := "njst." + status.JobID
+	elapsed := formatFloat(status.ElapsedSeconds)
+
+	suite := &junitTestSuite{
+		Name:       className,
+		Time:       elapsed,
+		Properties: make([]*junitProperty, 0),
+		TestCases:  make([]*junitTestCase, 0),
+	}
+
+	if !status.StartedAt.IsZero() {
+		suite.Timestamp = status.StartedAt.Format("2006-01-02T15:04:05")
+	}
+
+	if resp.Settings != nil {
+		if resp.Settings.Description != "" {
+			suite.Properties = append(suite.Properties, &junitProperty{Name: "description", Value: resp.Settings.Description})
+		}
+
+		if resp.Settings.Profile != "" {
+			suite.Properties = append(suite.Properties, &junitProperty{Name: "profile", Value: resp.Settings.Profile})
+		}
+
+		if resp.Settings.Owner != "" {
+			suite.Properties = append(suite.Properties, &junitProperty{Name: "owner", Value: resp.Settings.Owner})
+		}
+
+		for _, k := range sortedKeys(resp.Settings.Tags) {
+			suite.Properties = append(suite.Properties, &junitProperty{Name: "tag." + k, Value: resp.Settings.Tags[k]})
+		}
+
+		for _, k := range sortedKeys(resp.Settings.Annotations) {
+			suite.Properties = append(suite.Properties,
+				&junitProperty{Name: "annotation." + k, Value: resp.Settings.Annotations[k]})
+		}
+	}
+
+	add := func(name, failureType, message string) {
+		tc := &junitTestCase{
+			ClassName: className,
+			Name:      name,
+			Time:      elapsed,
+		}
+
+		if message != "" {
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    failureType,
+				Text:    message,
+			}
+
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	statusMessage := ""
+
+	if status.Status != types.CompletedStatus {
+		statusMessage = fmt.Sprintf("job status is '%s': %s", status.Status, status.Message)
+	}
+
+	add("status", "status", statusMessage)
+
+	if resp.Settings != nil && resp.Settings.Expect != nil {
+		failures := make(map[string]string)
+
+		if status.Verdict != nil {
+			for _, f := range status.Verdict.Failures {
+				failures[f.Assertion] = f.Message
+			}
+		}
+
+		for _, assertion := range expectAssertions(resp.Settings.Expect) {
+			add("expect."+assertion, "assertion", failures[assertion])
+		}
+	}
+
+	if resp.Comparison != nil {
+		for _, m := range resp.Comparison.Metrics {
+			message := ""
+
+			if m.Regressed {
+				message = fmt.Sprintf("%s regressed against baseline '%s': %v -> %v (%+.2f%%, threshold %v)",
+					m.Name, resp.Comparison.BaselineJobID, m.Baseline, m.Current, m.DeltaPct, m.Threshold)
+			}
+
+			add("baseline."+m.Name, "regression", message)
+		}
+	}
+
+	return suite
+}
+
+func renderJSONL(responses []*types.StatusResponse) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+
+	for _, resp := range responses {
+		// Workers get their own records
+		status := *resp.Status
+		status.NodeReports = nil
+		status.LatencyHistogram = nil
+
+		records := []*exportRecord{{
+			Type:       "job",
+			JobID:      status.JobID,
+			Status:     &status,
+			Settings:   resp.Settings,
+			Comparison: resp.Comparison,
+		}}
+
+		for _, worker := range workerReports(resp.Status) {
+			records = append(records, &exportRecord{
+				Type:   "worker",
+				JobID:  status.JobID,
+				Worker: worker,
+			})
+		}
+
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return nil, errors.Wrapf(err, "unable to encode record for job '%s'", status.JobID)
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// expectAssertions returns the names of all assertions that are set
+func expectAssertions(expect *types.Expect) []string {
+	assertions := make([]string, 0)
+
+	if expect.MinTotalMsgsPerSec != nil {
+		assertions = append(assertions, "min_total_msgs_per_sec")
+	}
+
+	if expect.MaxErrorRate != nil {
+		assertions = append(assertions, "max_error_rate")
+	}
+
+	if expect.MaxP99LatencyMs != nil {
+		assertions = append(assertions, "max_p99_latency_ms")
+	}
+
+	if expect.MaxElapsedSeconds != nil {
+		assertions = append(assertions, "max_elapsed_seconds")
+	}
+
+	return assertions
+}
+
+// workerReports returns all worker reports of all nodes, sorted by worker ID
+func workerReports(status *types.Status) []*types.WorkerReport {
+	workers := make([]*types.WorkerReport, 0)
+
+	for _, nodeReport := range status.NodeReports {
+		if nodeReport == nil {
+			continue
+		}
+
+		for _, streamReport := range nodeReport.Streams {
+			for i := range streamReport.Workers {
+				workers = append(workers, &streamReport.Workers[i])
+			}
+		}
+	}
+
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].WorkerID < workers[j].WorkerID
+	})
+
+	return workers
+}
+
+func latencyColumns(latency *types.LatencySummary) []string {
+	if latency == nil {
+		return []string{"", "", "", ""}
+	}
+
+	return []string{
+		formatFloat(latency.P50Ms),
+		formatFloat(latency.P90Ms),
+		formatFloat(latency.P99Ms),
+		formatFloat(latency.MaxMs),
+	}
+}
+
+func markdownTable(headers []string, rows [][]string) string {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(sb, "|%s\n", strings.Repeat(" --- |", len(headers)))
+
+	for _, row := range rows {
+		escaped := make([]string, len(row))
+
+		for i, col := range row {
+			escaped[i] = strings.Replace(col, "|", "\\|", -1)
+		}
+
+		fmt.Fprintf(sb, "| %s |\n", strings.Join(escaped, " | "))
+	}
+
+	return sb.String()
+}
+
+// formatPairs formats tags or annotations as "k1=v1;k2=v2", sorted by key
+func formatPairs(pairs map[string]string) string {
+	formatted := make([]string, 0, len(pairs))
+
+	for _, k := range sortedKeys(pairs) {
+		formatted = append(formatted, k+"="+pairs[k])
+	}
+
+	return strings.Join(formatted, ";")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,64 @@
+package httpsvc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/batchcorp/njst/types"
+)
+
+const (
+	MaxExportJobs = 100
+)
+
+// exportHandler exports the results of multiple jobs at once; job IDs are
+// passed via ?ids=a,b,c (or repeated ?ids= params)
+func (h *HTTPService) exportHandler(rw http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0)
+
+	for _, param := range r.URL.Query()["ids"] {
+		for _, id := range strings.Split(param, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		writeErrorJSON(http.StatusBadRequest, "ids is required", rw)
+		return
+	}
+
+	if len(ids) > MaxExportJobs {
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("cannot export more than %d jobs at once", MaxExportJobs), rw)
+		return
+	}
+
+	responses := make([]*types.StatusResponse, 0, len(ids))
+
+	for _, id := range ids {
+		resp, statusCode, err := h.getStatusResponse(id)
+		if err != nil {
+			writeErrorJSON(statusCode, fmt.Sprintf("unable to export job '%s': %s", id, err), rw)
+			return
+		}
+
+		responses = append(responses, resp)
+	}
+
+	format := r.URL.Query().Get("format")
+
+	if format == "" || format == "json" {
+		writeJSON(http.StatusOK, responses, rw)
+		return
+	}
+
+	contentType, data, err := renderExport(format, responses)
+	if err != nil {
+		writeErrorJSON(http.StatusBadRequest, err.Error(), rw)
+		return
+	}
+
+	writeRaw(http.StatusOK, contentType, data, rw)
+}
@@ -0,0 +1,47 @@
+package httpsvc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/batchcorp/njst/types"
+)
+
+func TestRenderExportTags(t *testing.T) {
+	responses := []*types.StatusResponse{{
+		Status: &types.Status{JobID: "abc", Status: types.CompletedStatus},
+		Settings: &types.Settings{
+			Description: "upgrade eval",
+			Owner:       "jane",
+			Tags:        map[string]string{"eval": "nats-2.10", "env": "staging"},
+			Annotations: map[string]string{"ticket": "OPS-1234"},
+		},
+	}}
+
+	tests := []struct {
+		format   string
+		expected []string
+	}{
+		{CSVFormat, []string{",owner,tags,annotations\n", ",jane,env=staging;eval=nats-2.10,ticket=OPS-1234\n"}},
+		{MarkdownFormat, []string{"| job_id | description | owner | tags |", "| abc | upgrade eval | jane | env=staging;eval=nats-2.10 |"}},
+		{JUnitFormat, []string{`<property name="owner" value="jane">`, `<property name="tag.eval" value="nats-2.10">`,
+			`<property name="annotation.ticket" value="OPS-1234">`}},
+		{JSONLFormat, []string{`"owner":"jane"`, `"tags":{"env":"staging","eval":"nats-2.10"}`,
+			`"annotations":{"ticket":"OPS-1234"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			_, data, err := renderExport(tt.format, responses)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, e := range tt.expected {
+				if !strings.Contains(string(data), e) {
+					t.Errorf("expected export to contain '%s', got:\n%s", e, data)
+				}
+			}
+		})
+	}
+}
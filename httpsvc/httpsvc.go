@@ -2,7 +2,6 @@ package httpsvc
 
 import (
 	"encoding/json"
-	"math/rand"
 	"net/http"
 	"net/http/pprof"
 	"time"
@@ -19,20 +18,12 @@ import (
 type HTTPService struct {
 	params  *cli.Params
 	log     *logrus.Entry
-	nats    *natssvc.NATSService
+	nats    natssvc.IStore
 	bench   *bench.Bench
 	version string
 }
 
-var (
-	runes = []rune("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-)
-
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
-func New(params *cli.Params, n *natssvc.NATSService, b *bench.Bench, version string) (*HTTPService, error) {
+func New(params *cli.Params, n natssvc.IStore, b *bench.Bench, version string) (*HTTPService, error) {
 	if err := validateParams(params); err != nil {
 		return nil, err
 	}
@@ -72,14 +63,44 @@ func (h *HTTPService) Start() error {
 
 	router.HandlerFunc("GET", "/health-check", h.healthCheckHandler)
 	router.HandlerFunc("GET", "/version", h.versionHandler)
+	router.HandlerFunc("GET", "/metrics", h.metricsHandler)
+
+	router.HandlerFunc("GET", "/", h.rootHandler)
+	router.Handler("GET", "/ui/*filepath", uiHandler())
 
 	router.HandlerFunc("GET", "/bench", h.getAllBenchmarksHandler)
 	router.Handle("GET", "/bench/:id", h.getBenchmarkHandler)
+	router.Handle("GET", "/bench/:id/timeline", h.getTimelineHandler)
+	router.Handle("GET", "/bench/:id/events", h.getEventsHandler)
 	router.Handle("POST", "/bench/purge", h.purgeAllHandler)
 	router.Handle("DELETE", "/bench/:id", h.deleteBenchmarkHandler)
 	router.HandlerFunc("POST", "/bench", h.createBenchmarkHandler)
 
+	router.HandlerFunc("GET", "/schedules", h.getAllSchedulesHandler)
+	router.Handle("GET", "/schedules/:id", h.getScheduleHandler)
+	router.Handle("DELETE", "/schedules/:id", h.deleteScheduleHandler)
+	router.HandlerFunc("POST", "/schedules", h.createScheduleHandler)
+
+	router.HandlerFunc("GET", "/scenarios", h.getAllScenariosHandler)
+	router.Handle("GET", "/scenarios/:id", h.getScenarioHandler)
+	router.Handle("DELETE", "/scenarios/:id", h.deleteScenarioHandler)
+	router.HandlerFunc("POST", "/scenarios", h.createScenarioHandler)
+
+	router.HandlerFunc("GET", "/sweeps", h.getAllSweepsHandler)
+	router.Handle("GET", "/sweeps/:id", h.getSweepHandler)
+	router.Handle("DELETE", "/sweeps/:id", h.deleteSweepHandler)
+	router.HandlerFunc("POST", "/sweeps", h.createSweepHandler)
+
+	router.HandlerFunc("GET", "/baselines", h.getAllBaselinesHandler)
+	router.Handle("GET", "/baselines/:profile", h.getBaselineHandler)
+	router.Handle("DELETE", "/baselines/:profile", h.deleteBaselineHandler)
+	router.HandlerFunc("POST", "/baselines", h.createBaselineHandler)
+
+	router.HandlerFunc("GET", "/export", h.exportHandler)
+
 	router.HandlerFunc("GET", "/cluster", h.getClusterHandler)
+	router.Handle("GET", "/cluster/:node", h.getClusterNodeHandler)
+	router.Handle("GET", "/cluster/:node/jobs", h.getClusterNodeJobsHandler)
 
 	server := &http.Server{Addr: h.params.HTTPAddress, Handler: router}
 
@@ -120,6 +141,16 @@ func writeJSON(statusCode int, data interface{}, w http.ResponseWriter) {
 	}
 }
 
+func writeRaw(statusCode int, contentType string, data []byte, w http.ResponseWriter) {
+	w.Header().Add("Content-type", contentType)
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(data); err != nil {
+		logrus.Errorf("Unable to write response data: %s", err)
+		return
+	}
+}
+
 func writeErrorJSON(statusCode int, msg string, w http.ResponseWriter) {
 	writeJSON(statusCode, map[string]string{"error": msg}, w)
 }
@@ -131,13 +162,3 @@ func validateParams(params *cli.Params) error {
 
 	return nil
 }
-
-func RandString(n int) string {
-	b := make([]rune, n)
-
-	for i := range b {
-		b[i] = runes[rand.Intn(len(runes))]
-	}
-
-	return string(b)
-}
@@ -0,0 +1,158 @@
+package httpsvc
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+)
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// metricsHandler exposes this node's live job metrics in the Prometheus text
+// exposition format
+func (h *HTTPService) metricsHandler(rw http.ResponseWriter, r *http.Request) {
+	writeRaw(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(renderMetrics(h.bench.Metrics(), h.version)), rw)
+}
+
+func renderMetrics(m *types.NodeMetrics, version string) string {
+	sb := &strings.Builder{}
+
+	nodeLabels := labels("node_id", m.NodeID)
+
+	family(sb, "njst_node_info", "gauge", "Static information about the njst node")
+	sample(sb, "njst_node_info", labels("node_id", m.NodeID, "version", version), 1)
+
+	family(sb, "njst_running_jobs", "gauge", "Number of jobs currently running on the node")
+	sample(sb, "njst_running_jobs", nodeLabels, float64(m.RunningJobs))
+
+	family(sb, "njst_queued_jobs", "gauge", "Number of jobs waiting in the node's queue")
+	sample(sb, "njst_queued_jobs", nodeLabels, float64(m.QueuedJobs))
+
+	connected := 0.0
+
+	if m.NATSConnectionState == "CONNECTED" {
+		connected = 1
+	}
+
+	family(sb, "njst_nats_connected", "gauge", "Whether the node's NATS connection is connected (1) or not (0)")
+	sample(sb, "njst_nats_connected", nodeLabels, connected)
+
+	family(sb, "njst_nats_connection_state", "gauge", "State of the node's NATS connection")
+	sample(sb, "njst_nats_connection_state", labels("node_id", m.NodeID, "state", m.NATSConnectionState), 1)
+
+	if len(m.Jobs) == 0 {
+		return sb.String()
+	}
+
+	jobLabels := func(job *types.JobMetrics, extra ...string) string {
+		return labels(append([]string{
+			"job_id", job.JobID,
+			"node_id", m.NodeID,
+			"description", job.Description,
+			"type", job.Type,
+		}, extra...)...)
+	}
+
+	family(sb, "njst_job_running", "gauge", "Whether the job is still running on the node (1) or finished (0)")
+
+	for _, job := range m.Jobs {
+		running := 0.0
+
+		if job.Running {
+			running = 1
+		}
+
+		sample(sb, "njst_job_running", jobLabels(job), running)
+	}
+
+	family(sb, "njst_workers", "gauge", "Number of running workers")
+
+	for _, job := range m.Jobs {
+		sample(sb, "njst_workers", jobLabels(job), float64(job.Workers))
+	}
+
+	family(sb, "njst_messages_total", "counter", "Messages written (acked) or read per stream")
+
+	for _, job := range m.Jobs {
+		for _, stream := range job.Streams {
+			sample(sb, "njst_messages_total", jobLabels(job, "stream", stream.Stream), float64(stream.Messages))
+		}
+	}
+
+	family(sb, "njst_bytes_total", "counter", "Payload bytes written (acked) or read per stream")
+
+	for _, job := range m.Jobs {
+		for _, stream := range job.Streams {
+			sample(sb, "njst_bytes_total", jobLabels(job, "stream", stream.Stream), float64(stream.Bytes))
+		}
+	}
+
+	family(sb, "njst_errors_total", "counter", "Errors per stream by category")
+
+	for _, job := range m.Jobs {
+		for _, stream := range job.Streams {
+			categories := make([]string, 0, len(stream.Errors))
+
+			for category := range stream.Errors {
+				categories = append(categories, category)
+			}
+
+			sort.Strings(categories)
+
+			for _, category := range categories {
+				sample(sb, "njst_errors_total", jobLabels(job, "stream", stream.Stream, "category", category),
+					float64(stream.Errors[category]))
+			}
+		}
+	}
+
+	family(sb, "njst_batch_latency_seconds", "histogram",
+		"Time to publish a batch and receive all acks (write) or to fetch a batch (read)")
+
+	for _, job := range m.Jobs {
+		if job.Latency == nil || len(job.Latency.Buckets) != len(bench.LatencyBucketsMs)+1 {
+			continue
+		}
+
+		var cumulative uint64
+
+		for i, bound := range bench.LatencyBucketsMs {
+			cumulative += job.Latency.Buckets[i]
+
+			sample(sb, "njst_batch_latency_seconds_bucket",
+				jobLabels(job, "le", strconv.FormatFloat(bound/1000, 'g', -1, 64)), float64(cumulative))
+		}
+
+		sample(sb, "njst_batch_latency_seconds_bucket", jobLabels(job, "le", "+Inf"), float64(job.Latency.Count))
+		sample(sb, "njst_batch_latency_seconds_sum", jobLabels(job), job.Latency.SumMs/1000)
+		sample(sb, "njst_batch_latency_seconds_count", jobLabels(job), float64(job.Latency.Count))
+	}
+
+	return sb.String()
+}
+
+func family(sb *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func sample(sb *strings.Builder, name, labels string, value float64) {
+	fmt.Fprintf(sb, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labels formats name/value pairs as a Prometheus label set
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
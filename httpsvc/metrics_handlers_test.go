@@ -0,0 +1,94 @@
+package httpsvc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+)
+
+func TestRenderMetrics(t *testing.T) {
+	buckets := make([]uint64, len(bench.LatencyBucketsMs)+1)
+	buckets[0] = 1                           // <= 0.1ms
+	buckets[3] = 2                           // <= 1ms
+	buckets[len(bench.LatencyBucketsMs)] = 1 // > 10s
+
+	m := &types.NodeMetrics{
+		NodeID:              "node1",
+		RunningJobs:         1,
+		NATSConnectionState: "CONNECTED",
+		Jobs: []*types.JobMetrics{{
+			JobID:       "abc",
+			Description: "say \"hi\"\nto C:\\nats",
+			Type:        "write",
+			Running:     true,
+			Workers:     2,
+			Streams: []*types.StreamMetrics{{
+				Stream:   "njst-abc-0",
+				Messages: 100,
+				Bytes:    102400,
+				Errors:   map[string]uint64{"timeout": 2, "no_responders": 1},
+			}},
+			Latency: &types.LatencyHistogram{Buckets: buckets, Count: 4, SumMs: 1500},
+		}},
+	}
+
+	job := `job_id="abc",node_id="node1",description="say \"hi\"\nto C:\\nats",type="write"`
+
+	expected := []string{
+		`njst_node_info{node_id="node1",version="v1.0.0"} 1`,
+		`njst_running_jobs{node_id="node1"} 1`,
+		`njst_nats_connected{node_id="node1"} 1`,
+		`njst_job_running{` + job + `} 1`,
+		`njst_workers{` + job + `} 2`,
+		`njst_messages_total{` + job + `,stream="njst-abc-0"} 100`,
+		`njst_bytes_total{` + job + `,stream="njst-abc-0"} 102400`,
+
+		// Error categories in name order
+		`njst_errors_total{` + job + `,stream="njst-abc-0",category="no_responders"} 1` + "\n" +
+			`njst_errors_total{` + job + `,stream="njst-abc-0",category="timeout"} 2`,
+
+		// Buckets are cumulative and in seconds
+		`njst_batch_latency_seconds_bucket{` + job + `,le="0.0001"} 1` + "\n" +
+			`njst_batch_latency_seconds_bucket{` + job + `,le="0.00025"} 1`,
+		`njst_batch_latency_seconds_bucket{` + job + `,le="0.001"} 3`,
+		`njst_batch_latency_seconds_bucket{` + job + `,le="10"} 3` + "\n" +
+			`njst_batch_latency_seconds_bucket{` + job + `,le="+Inf"} 4`,
+		`njst_batch_latency_seconds_sum{` + job + `} 1.5`,
+		`njst_batch_latency_seconds_count{` + job + `} 4`,
+	}
+
+	out := renderMetrics(m, "v1.0.0")
+
+	for _, e := range expected {
+		if !strings.Contains(out, e+"\n") {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", e, out)
+		}
+	}
+
+	// An idle node only reports node metrics
+	out = renderMetrics(&types.NodeMetrics{NodeID: "node1", NATSConnectionState: "CLOSED"}, "v1.0.0")
+
+	if strings.Contains(out, "njst_job_running") || !strings.Contains(out, `njst_nats_connected{node_id="node1"} 0`) {
+		t.Errorf("unexpected output for an idle node:\n%s", out)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		pairs    []string
+		expected string
+	}{
+		{[]string{"node_id", "node1"}, `{node_id="node1"}`},
+		{[]string{"a", "1", "b", "2"}, `{a="1",b="2"}`},
+		{[]string{"description", `a "quoted" \ value` + "\n"}, `{description="a \"quoted\" \\ value\n"}`},
+		{[]string{"dangling"}, `{}`},
+	}
+
+	for _, tt := range tests {
+		if got := labels(tt.pairs...); got != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, got)
+		}
+	}
+}
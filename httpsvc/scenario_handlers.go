@@ -0,0 +1,211 @@
+package httpsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+func (h *HTTPService) createScenarioHandler(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.log.Errorf("could not read request body: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("could not read request body: %s", err), rw)
+		return
+	}
+	defer r.Body.Close()
+
+	scenario := &types.Scenario{}
+
+	if err := json.Unmarshal(body, scenario); err != nil {
+		h.log.Errorf("unable to unmarshal scenario: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to unmarshal scenario: %s", err), rw)
+		return
+	}
+
+	if err := validateScenario(scenario); err != nil {
+		h.log.Errorf("unable to validate scenario: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to validate scenario: %s", err), rw)
+		return
+	}
+
+	if err := h.bench.CreateScenario(scenario); err != nil {
+		h.log.Errorf("unable to create scenario: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to create scenario: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"id":      scenario.ID,
+		"message": fmt.Sprintf("scenario created successfully; running %d steps", len(scenario.Steps)),
+	}, rw)
+}
+
+func (h *HTTPService) getAllScenariosHandler(rw http.ResponseWriter, r *http.Request) {
+	scenarios, err := h.nats.GetAllScenarios()
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get scenarios: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, scenarios, rw)
+}
+
+func (h *HTTPService) getScenarioHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	scenario, err := h.nats.GetScenario(id)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get scenario: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, scenario, rw)
+}
+
+func (h *HTTPService) deleteScenarioHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	if err := h.nats.DeleteScenario(id); err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to delete scenario: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"message": "scenario deleted; a running scenario will stop after cancelling its current step",
+	}, rw)
+}
+
+func validateScenario(scenario *types.Scenario) error {
+	if scenario == nil {
+		return errors.New("scenario cannot be nil")
+	}
+
+	if len(scenario.Steps) == 0 {
+		return errors.New("scenario must have at least one step")
+	}
+
+	if scenario.Timeout != "" {
+		d, err := time.ParseDuration(scenario.Timeout)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse timeout")
+		}
+
+		if d <= 0 {
+			return errors.New("timeout must be positive")
+		}
+	}
+
+	// Steps that create jobs and can therefore be referenced by later steps
+	jobSteps := make(map[string]bool)
+	names := make(map[string]bool)
+
+	for i, step := range scenario.Steps {
+		if step == nil {
+			return errors.Errorf("step #%d cannot be nil", i+1)
+		}
+
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step-%d", i+1)
+		}
+
+		if names[step.Name] {
+			return errors.Errorf("duplicate step name '%s'", step.Name)
+		}
+
+		names[step.Name] = true
+
+		if err := validateScenarioStep(scenario, step, jobSteps); err != nil {
+			return errors.Wrapf(err, "invalid step '%s'", step.Name)
+		}
+
+		if step.Type == types.WriteStep || step.Type == types.ReadStep || step.Type == types.MixedStep {
+			jobSteps[step.Name] = true
+		}
+	}
+
+	return nil
+}
+
+func validateScenarioStep(scenario *types.Scenario, step *types.ScenarioStep, jobSteps map[string]bool) error {
+	switch step.Type {
+	case types.WriteStep, types.ReadStep, types.MixedStep:
+		if step.Settings == nil {
+			return errors.New("settings cannot be nil")
+		}
+
+		if step.Settings.NATS == nil {
+			step.Settings.NATS = scenario.NATS
+		}
+
+		if err := ValidateSettings(step.Settings); err != nil {
+			return err
+		}
+
+		if step.Type == types.WriteStep && (step.Settings.Write == nil || step.Settings.Read != nil) {
+			return errors.New("write step must only have write settings")
+		}
+
+		if step.Type == types.ReadStep && (step.Settings.Read == nil || step.Settings.Write != nil) {
+			return errors.New("read step must only have read settings")
+		}
+
+		if step.Type == types.MixedStep && (step.Settings.Read == nil || step.Settings.Write == nil) {
+			return errors.New("mixed step must have both read and write settings")
+		}
+
+		refs, err := bench.StepRefs(step.Settings)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range refs {
+			if !jobSteps[ref] {
+				return errors.Errorf("'%s' does not reference a previous write, read or mixed step", ref)
+			}
+		}
+	case types.WaitStep:
+		if _, err := time.ParseDuration(step.Duration); err != nil {
+			return errors.Wrap(err, "unable to parse duration")
+		}
+	case types.CleanupStep:
+		if step.Cleanup == nil {
+			step.Cleanup = &types.CleanupSettings{
+				Streams: true,
+			}
+		}
+
+		for _, ref := range step.Cleanup.Steps {
+			if !jobSteps[ref] {
+				return errors.Errorf("'%s' does not reference a previous write, read or mixed step", ref)
+			}
+		}
+	default:
+		return errors.Errorf("unknown step type '%s'", step.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package httpsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+func (h *HTTPService) createScheduleHandler(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.log.Errorf("could not read request body: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("could not read request body: %s", err), rw)
+		return
+	}
+	defer r.Body.Close()
+
+	schedule := &types.Schedule{}
+
+	if err := json.Unmarshal(body, schedule); err != nil {
+		h.log.Errorf("unable to unmarshal schedule: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to unmarshal schedule: %s", err), rw)
+		return
+	}
+
+	if err := validateSchedule(schedule); err != nil {
+		h.log.Errorf("unable to validate schedule: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to validate schedule: %s", err), rw)
+		return
+	}
+
+	if err := h.bench.CreateSchedule(schedule); err != nil {
+		h.log.Errorf("unable to create schedule: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to create schedule: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, schedule, rw)
+}
+
+func (h *HTTPService) getAllSchedulesHandler(rw http.ResponseWriter, r *http.Request) {
+	schedules, err := h.nats.GetAllSchedules()
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get schedules: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, schedules, rw)
+}
+
+func (h *HTTPService) getScheduleHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	schedule, err := h.nats.GetSchedule(id)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get schedule: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, schedule, rw)
+}
+
+func (h *HTTPService) deleteScheduleHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	// Deleting a key that does not exist is not an error in NATS
+	if _, err := h.nats.GetSchedule(id); err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get schedule: %s", err), rw)
+		return
+	}
+
+	if err := h.nats.DeleteSchedule(id); err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to delete schedule: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"message": "schedule deleted",
+	}, rw)
+}
+
+func validateSchedule(schedule *types.Schedule) error {
+	if schedule == nil {
+		return errors.New("schedule cannot be nil")
+	}
+
+	if schedule.Cron == "" {
+		return errors.New("cron cannot be empty")
+	}
+
+	if _, err := bench.ParseCron(schedule.Cron); err != nil {
+		return errors.Wrap(err, "invalid cron expression")
+	}
+
+	if err := ValidateSettings(schedule.Settings); err != nil {
+		return errors.Wrap(err, "invalid settings")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package httpsvc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/batchcorp/njst/natssvc/natssvcfakes"
+	"github.com/batchcorp/njst/types"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	settings := func() *types.Settings {
+		return &types.Settings{NATS: &types.NATS{Address: "localhost:4222"}, Write: &types.WriteSettings{}}
+	}
+
+	tests := []struct {
+		name     string
+		schedule *types.Schedule
+		err      string
+	}{
+		{"nil schedule", nil, "schedule cannot be nil"},
+		{"no cron", &types.Schedule{Settings: settings()}, "cron cannot be empty"},
+		{"invalid cron", &types.Schedule{Cron: "every day", Settings: settings()}, "invalid cron expression"},
+		{"invalid settings", &types.Schedule{Cron: "0 * * * *", Settings: &types.Settings{}}, "invalid settings"},
+		{"valid", &types.Schedule{Cron: "*/5 * * * *", Settings: settings()}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedule(tt.schedule)
+
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error '%s', got '%v'", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDeleteScheduleHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		deleted bool
+	}{
+		{"exists", nil, http.StatusOK, true},
+		{"not found", errors.Wrap(nats.ErrKeyNotFound, "unable to get schedule for id 'abc'"), http.StatusNotFound, false},
+		{"lookup fails", errors.New("nats: timeout"), http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &natssvcfakes.FakeIStore{}
+			fake.GetScheduleReturns(&types.Schedule{ID: "abc"}, tt.err)
+
+			h := &HTTPService{nats: fake}
+			rw := httptest.NewRecorder()
+
+			h.deleteScheduleHandler(rw, httptest.NewRequest(http.MethodDelete, "/schedules/abc", nil),
+				httprouter.Params{{Key: "id", Value: "abc"}})
+
+			if rw.Code != tt.code {
+				t.Errorf("expected status %d, got %d", tt.code, rw.Code)
+			}
+
+			if deleted := fake.DeleteScheduleCallCount() == 1; deleted != tt.deleted {
+				t.Errorf("expected schedule to be deleted: %v", tt.deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package httpsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/types"
+	"github.com/julienschmidt/httprouter"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+func (h *HTTPService) createSweepHandler(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.log.Errorf("could not read request body: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("could not read request body: %s", err), rw)
+		return
+	}
+	defer r.Body.Close()
+
+	sweep := &types.Sweep{}
+
+	if err := json.Unmarshal(body, sweep); err != nil {
+		h.log.Errorf("unable to unmarshal sweep: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to unmarshal sweep: %s", err), rw)
+		return
+	}
+
+	combinations, params, err := validateSweep(sweep)
+	if err != nil {
+		h.log.Errorf("unable to validate sweep: %s", err)
+		writeErrorJSON(http.StatusBadRequest, fmt.Sprintf("unable to validate sweep: %s", err), rw)
+		return
+	}
+
+	if err := h.bench.CreateSweep(sweep, combinations, params); err != nil {
+		h.log.Errorf("unable to create sweep: %s", err)
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to create sweep: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"id":      sweep.ID,
+		"message": fmt.Sprintf("sweep created successfully; running %d combinations", len(combinations)),
+	}, rw)
+}
+
+func (h *HTTPService) getAllSweepsHandler(rw http.ResponseWriter, r *http.Request) {
+	sweeps, err := h.nats.GetAllSweeps()
+	if err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get sweeps: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, sweeps, rw)
+}
+
+func (h *HTTPService) getSweepHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	sweep, err := h.nats.GetSweep(id)
+	if err != nil {
+		if errors.Cause(err) == nats.ErrKeyNotFound {
+			writeErrorJSON(http.StatusNotFound, err.Error(), rw)
+			return
+		}
+
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to get sweep: %s", err), rw)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(http.StatusOK, sweep, rw)
+	case "markdown", "md":
+		writeRaw(http.StatusOK, "text/markdown; charset=utf-8", []byte(sweepTable(sweep)), rw)
+	default:
+		writeErrorJSON(http.StatusBadRequest, "format must be one of 'json' or 'markdown'", rw)
+	}
+}
+
+func (h *HTTPService) deleteSweepHandler(rw http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id := ps.ByName("id")
+
+	if id == "" {
+		writeErrorJSON(http.StatusBadRequest, "id is required", rw)
+		return
+	}
+
+	if err := h.nats.DeleteSweep(id); err != nil {
+		writeErrorJSON(http.StatusInternalServerError, fmt.Sprintf("unable to delete sweep: %s", err), rw)
+		return
+	}
+
+	writeJSON(http.StatusOK, map[string]string{
+		"message": "sweep deleted; a running sweep will stop after cancelling its current job",
+	}, rw)
+}
+
+// validateSweep validates the base settings and every combination generated
+// from the axes; the (defaulted) combinations are returned so that they can
+// be run as-is.
+func validateSweep(sweep *types.Sweep) ([]*types.Settings, []map[string]interface{}, error) {
+	if sweep == nil {
+		return nil, nil, errors.New("sweep cannot be nil")
+	}
+
+	if sweep.Base == nil {
+		return nil, nil, errors.New("base cannot be nil")
+	}
+
+	if len(sweep.Axes) == 0 {
+		return nil, nil, errors.New("sweep must have at least one axis")
+	}
+
+	if err := ValidateSettings(sweep.Base); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid base settings")
+	}
+
+	combinations, params, err := bench.SweepCombinations(sweep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, settings := range combinations {
+		if err := ValidateSettings(settings); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid combination #%d", i+1)
+		}
+	}
+
+	return combinations, params, nil
+}
+
+// sweepTable renders the sweep results as a markdown comparison table
+func sweepTable(sweep *types.Sweep) string {
+	axes := make([]string, 0, len(sweep.Axes))
+
+	for name := range sweep.Axes {
+		axes = append(axes, name)
+	}
+
+	sort.Strings(axes)
+
+	headers := append(axes, "msg/sec (all nodes)", "avg msg/sec per node", "elapsed (s)",
+		"processed", "errors", "p50 (ms)", "p99 (ms)", "status")
+
+	rows := make([][]string, 0, len(sweep.Results))
+
+	for _, result := range sweep.Results {
+		row := make([]string, 0, len(headers))
+
+		for _, name := range axes {
+			row = append(row, fmt.Sprintf("%v", result.Params[name]))
+		}
+
+		p50, p99 := "-", "-"
+
+		if result.Latency != nil {
+			p50 = formatFloat(result.Latency.P50Ms)
+			p99 = formatFloat(result.Latency.P99Ms)
+		}
+
+		status := string(result.Status)
+
+		if result.Error != "" {
+			status = fmt.Sprintf("%s: %s", status, result.Error)
+		}
+
+		row = append(row,
+			formatFloat(result.TotalMsgPerSecAllNodes),
+			formatFloat(result.AvgMsgPerSecPerNode),
+			formatFloat(result.ElapsedSeconds),
+			fmt.Sprintf("%d", result.TotalProcessed),
+			fmt.Sprintf("%d", result.TotalErrors),
+			p50,
+			p99,
+			status,
+		)
+
+		rows = append(rows, row)
+	}
+
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "# Sweep %s\n\n", sweep.ID)
+
+	if sweep.Description != "" {
+		fmt.Fprintf(sb, "%s\n\n", sweep.Description)
+	}
+
+	sb.WriteString(markdownTable(headers, rows))
+
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package httpsvc
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+var (
+	//go:embed ui
+	uiFiles embed.FS
+)
+
+// uiHandler serves the embedded web dashboard; it only talks to the HTTP API
+func uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		// Only possible if the embed directive above is broken
+		panic(err)
+	}
+
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(files)))
+}
+
+func (h *HTTPService) rootHandler(rw http.ResponseWriter, r *http.Request) {
+	http.Redirect(rw, r, "/ui/", http.StatusFound)
+}
@@ -0,0 +1,688 @@
+// njst dashboard; a dependency-free single page app on top of the HTTP API.
+// Routes (hash based):
+//   #/              benchmarks (#/?status=..&q=.. to filter)
+//   #/cluster       cluster nodes
+//   #/new           create a read or write job
+//   #/bench/:id     job details with live throughput chart
+(function () {
+  "use strict";
+
+  const app = document.getElementById("app");
+  const colors = ["#0969da", "#1a7f37", "#cf222e", "#9a6700", "#8250df", "#bf3989", "#1b7c83", "#57606a"];
+
+  // Cleanup of the current view (event sources, timers)
+  let teardown = null;
+
+  // ---- helpers ----------------------------------------------------------
+
+  async function api(method, path, body) {
+    const opts = {method: method, headers: {}};
+
+    if (body !== undefined) {
+      opts.headers["Content-Type"] = "application/json";
+      opts.body = JSON.stringify(body);
+    }
+
+    const resp = await fetch(path, opts);
+    const data = await resp.json().catch(() => ({}));
+
+    if (!resp.ok) {
+      throw new Error(data.error || resp.status + " " + resp.statusText);
+    }
+
+    return data;
+  }
+
+  function h(tag, attrs, ...children) {
+    const el = document.createElement(tag);
+
+    for (const [k, v] of Object.entries(attrs || {})) {
+      if (k.startsWith("on")) {
+        el.addEventListener(k.substring(2), v);
+      } else if (v !== undefined && v !== null && v !== false) {
+        el.setAttribute(k, v);
+      }
+    }
+
+    for (const child of children.flat()) {
+      if (child === null || child === undefined) {
+        continue;
+      }
+
+      el.append(child instanceof Node ? child : String(child));
+    }
+
+    return el;
+  }
+
+  function num(v, digits) {
+    if (v === undefined || v === null || isNaN(v)) {
+      return "-";
+    }
+
+    return Number(v).toLocaleString(undefined, {maximumFractionDigits: digits === undefined ? 2 : digits});
+  }
+
+  function badge(text) {
+    return h("span", {class: "badge " + text}, text);
+  }
+
+  function table(headers, rows, rowClass) {
+    return h("table", {},
+      h("thead", {}, h("tr", {}, headers.map((hd) => h("th", {class: hd.num ? "num" : null}, hd.label)))),
+      h("tbody", {}, rows.length === 0
+        ? h("tr", {}, h("td", {colspan: headers.length, class: "muted"}, "nothing here yet"))
+        : rows.map((row, i) => h("tr", {class: rowClass ? rowClass(row, i) : null},
+          row.map((cell, j) => h("td", {class: headers[j].num ? "num" : null}, cell))))));
+  }
+
+  function card(label, value) {
+    return h("div", {class: "card"}, h("div", {class: "label"}, label), h("div", {class: "value"}, value));
+  }
+
+  function render(...children) {
+    app.replaceChildren(...children);
+  }
+
+  function showError(err) {
+    render(h("div", {class: "error"}, err.message || String(err)));
+  }
+
+  // "k1=v1, k2=v2" <-> {k1: "v1", k2: "v2"}; used for tags and annotations
+  function parsePairs(text) {
+    const pairs = {};
+
+    for (const part of (text || "").split(",")) {
+      const i = part.indexOf("=");
+
+      if (i > 0) {
+        pairs[part.substring(0, i).trim()] = part.substring(i + 1).trim();
+      }
+    }
+
+    return Object.keys(pairs).length > 0 ? pairs : undefined;
+  }
+
+  function formatPairs(pairs) {
+    return Object.entries(pairs || {}).sort().map(([k, v]) => k + "=" + v).join(", ");
+  }
+
+  function jobType(settings) {
+    return settings.write ? "write" : settings.read ? "read" : "-";
+  }
+
+  // ---- views ------------------------------------------------------------
+
+  // Filters are kept in the hash (ex: #/?status=completed&q=nightly) so that
+  // filtered lists can be bookmarked
+  async function benchmarksView(params) {
+    const list = await api("GET", "/bench?" + params.toString());
+
+    const rows = list.benchmarks.map((s) => [
+      h("a", {href: "#/bench/" + s.id}, s.id),
+      new Date(s.created_at).toLocaleString(),
+      s.description || "",
+      s.owner || "",
+      Object.entries(s.tags || {}).sort().map(([k, v]) =>
+        h("a", {class: "tag", href: "#/?tag=" + encodeURIComponent(k + "=" + v)}, k + "=" + v)),
+      s.type,
+      num(s.num_streams, 0),
+      s.num_nodes || "-",
+      s.profile || "",
+      [badge(s.status)].concat(
+        s.passed === false ? [" ", badge("failed")] : [],
+        s.archived ? [" ", badge("archived")] : []),
+      num(s.total_msg_per_sec_all_nodes, 0),
+    ]);
+
+    const filter = (name, el) => h("label", {}, name, " ", el);
+
+    const select = (name, options) => h("select", {name: name},
+      options.map((o) => h("option", {value: o, selected: params.get(name) === o ? "" : null}, o || "any")));
+
+    const form = h("form", {
+      class: "filters",
+      onsubmit: (e) => {
+        e.preventDefault();
+
+        const next = new URLSearchParams();
+
+        for (const [k, v] of new FormData(e.target)) {
+          if (v !== "") {
+            next.set(k, v);
+          }
+        }
+
+        location.hash = "#/?" + next.toString();
+      },
+    },
+    filter("Status", select("status", ["", "in-progress", "completed", "failed", "error", "cancelled", "unknown"])),
+    filter("Type", select("type", ["", "write", "read"])),
+    filter("Search", h("input", {name: "q", value: params.get("q") || "", placeholder: "description"})),
+    filter("Owner", h("input", {name: "owner", value: params.get("owner") || "", size: 10})),
+    filter("Tag", h("input", {name: "tag", value: params.get("tag") || "", placeholder: "key=value", size: 14})),
+    filter("Since", h("input", {name: "since", value: params.get("since") || "", placeholder: "ex: 24h", size: 8})),
+    filter("Sort", select("sort", ["", "created_at", "-throughput", "throughput", "-elapsed", "description"])),
+    h("button", {type: "submit"}, "Filter"));
+
+    const page = (offset) => {
+      const next = new URLSearchParams(params);
+      next.set("offset", offset);
+
+      return "#/?" + next.toString();
+    };
+
+    const pager = h("div", {class: "pager muted"},
+      list.offset > 0 ? h("a", {href: page(Math.max(0, list.offset - list.limit))}, "← newer") : null,
+      " " + (list.total === 0 ? "0" : (list.offset + 1) + "-" + (list.offset + list.benchmarks.length)) +
+        " of " + list.total + " ",
+      list.offset + list.benchmarks.length < list.total ? h("a", {href: page(list.offset + list.limit)}, "older →") : null);
+
+    render(
+      h("div", {class: "toolbar"},
+        h("h1", {}, "Benchmarks"),
+        h("button", {class: "primary", onclick: () => { location.hash = "#/new"; }}, "New job")),
+      form,
+      table([
+        {label: "ID"}, {label: "Created"}, {label: "Description"}, {label: "Owner"}, {label: "Tags"}, {label: "Type"},
+        {label: "Streams", num: true},
+        {label: "Nodes", num: true}, {label: "Profile"}, {label: "Status"}, {label: "Msgs/sec", num: true},
+      ], rows),
+      pager);
+  }
+
+  async function clusterView() {
+    const cluster = await api("GET", "/cluster");
+
+    cluster.nodes.sort((a, b) => a.id.localeCompare(b.id));
+
+    render(
+      h("h1", {}, "Cluster"),
+      h("div", {class: "cards"},
+        card("Nodes", cluster.count),
+        card("Idle nodes", cluster.num_idle),
+        card("Versions", Object.keys(cluster.versions || {}).join(", ")),
+        card("Lock", cluster.lock ? h("a", {href: "#/bench/" + cluster.lock.job_id}, cluster.lock.job_id) : "free")),
+      h("h2", {}, "Nodes"),
+      table([
+        {label: "ID"}, {label: "Version"}, {label: "Hostname"}, {label: "Labels"}, {label: "CPUs", num: true},
+        {label: "Goroutines", num: true}, {label: "Mem alloc (MB)", num: true}, {label: "Jobs"}, {label: "Queued"},
+        {label: "Last seen"},
+      ], cluster.nodes.map((n) => [
+        n.id,
+        n.version,
+        n.hostname || "",
+        Object.entries(n.labels || {}).map(([k, v]) => k + "=" + v).join(", "),
+        num(n.num_cpu, 0),
+        num(n.num_goroutines, 0),
+        num(n.mem_alloc_bytes / 1024 / 1024, 1),
+        (n.jobs || []).length === 0 ? h("span", {class: "muted"}, "idle")
+          : n.jobs.map((id) => h("a", {href: "#/bench/" + id}, id + " ")),
+        ((n.queue || {}).jobs || []).filter((j) => j.state === "queued")
+          .map((j) => h("a", {href: "#/bench/" + j.id, title: j.reason}, j.id + " ")),
+        new Date(n.last_seen).toLocaleTimeString(),
+      ])));
+  }
+
+  function newJobView() {
+    const field = (label, name, value, attrs) => [
+      h("label", {for: name}, label),
+      h("input", Object.assign({id: name, name: name, value: value}, attrs || {})),
+    ];
+
+    const numField = (label, name, value) => field(label, name, value, {type: "number", min: 0});
+
+    const writeFields = h("fieldset", {},
+      h("legend", {}, "Write settings"),
+      numField("Streams", "w_num_streams", 1),
+      numField("Nodes", "w_num_nodes", 1),
+      numField("Messages per stream", "w_num_messages_per_stream", 10000),
+      numField("Workers per stream", "w_num_workers_per_stream", 1),
+      numField("Batch size", "w_batch_size", 100),
+      numField("Message size (bytes)", "w_msg_size_bytes", 1024),
+      numField("Replicas", "w_num_replicas", 1),
+      h("label", {for: "w_storage"}, "Storage"),
+      h("select", {id: "w_storage", name: "w_storage"},
+        h("option", {value: "memory"}, "memory"),
+        h("option", {value: "disk"}, "disk")),
+      h("label", {for: "w_verify"}, "Verify"),
+      h("input", {id: "w_verify", name: "w_verify", type: "checkbox"}));
+
+    const readFields = h("fieldset", {},
+      h("legend", {}, "Read settings"),
+      field("Write job ID", "r_write_id", "", {placeholder: "ID of a completed write job"}),
+      numField("Streams", "r_num_streams", 1),
+      numField("Nodes", "r_num_nodes", 1),
+      numField("Messages per stream", "r_num_messages_per_stream", 10000),
+      numField("Workers per stream", "r_num_workers_per_stream", 1),
+      numField("Batch size", "r_batch_size", 100),
+      h("label", {for: "r_verify"}, "Verify"),
+      h("input", {id: "r_verify", name: "r_verify", type: "checkbox"}));
+
+    const errorEl = h("div", {class: "error"});
+
+    const typeSelect = h("select", {
+      id: "type",
+      name: "type",
+      onchange: () => {
+        writeFields.hidden = typeSelect.value !== "write";
+        readFields.hidden = typeSelect.value !== "read";
+      },
+    }, h("option", {value: "write"}, "write"), h("option", {value: "read"}, "read"));
+
+    readFields.hidden = true;
+
+    const form = h("form", {
+      onsubmit: async (e) => {
+        e.preventDefault();
+        errorEl.textContent = "";
+
+        const data = new FormData(form);
+        const ints = (prefix, names) => {
+          const out = {};
+
+          for (const name of names) {
+            const v = data.get(prefix + name);
+
+            if (v !== null && v !== "") {
+              out[name] = parseInt(v, 10);
+            }
+          }
+
+          return out;
+        };
+
+        const settings = {
+          description: data.get("description"),
+          profile: data.get("profile") || undefined,
+          owner: data.get("owner") || undefined,
+          tags: parsePairs(data.get("tags")),
+          annotations: parsePairs(data.get("annotations")),
+          max_duration: data.get("max_duration") || undefined,
+          reassign_on_failure: data.get("reassign_on_failure") === "on",
+          exclusive: data.get("exclusive") === "on",
+          on_conflict: data.get("queue_on_conflict") === "on" ? "queue" : undefined,
+          nats: {
+            address: data.get("nats_address"),
+            shared_connection: data.get("shared_connection") === "on",
+          },
+        };
+
+        if (data.get("type") === "write") {
+          settings.write = ints("w_", ["num_streams", "num_nodes", "num_messages_per_stream",
+            "num_workers_per_stream", "batch_size", "msg_size_bytes", "num_replicas"]);
+          settings.write.storage = data.get("w_storage");
+          settings.write.verify = data.get("w_verify") === "on";
+        } else {
+          settings.read = ints("r_", ["num_streams", "num_nodes", "num_messages_per_stream",
+            "num_workers_per_stream", "batch_size"]);
+          settings.read.write_id = data.get("r_write_id");
+          settings.read.verify = data.get("r_verify") === "on";
+        }
+
+        try {
+          const resp = await api("POST", "/bench", settings);
+          location.hash = "#/bench/" + resp.id;
+        } catch (err) {
+          errorEl.textContent = err.message;
+        }
+      },
+    },
+    h("fieldset", {},
+      h("legend", {}, "Job"),
+      h("label", {for: "type"}, "Type"), typeSelect,
+      field("Description", "description", ""),
+      field("Profile", "profile", "", {placeholder: "optional; used for baselines"}),
+      field("Owner", "owner", ""),
+      field("Tags", "tags", "", {placeholder: "optional; ex: eval=nats-2.10, env=staging"}),
+      field("Annotations", "annotations", "", {placeholder: "optional; ex: nats_version=2.10.1, ticket=OPS-1"}),
+      field("Max duration", "max_duration", "", {placeholder: "optional; ex: 30m"}),
+      h("label", {for: "reassign_on_failure"}, "Reassign on failure"),
+      h("input", {id: "reassign_on_failure", name: "reassign_on_failure", type: "checkbox"}),
+      h("label", {for: "exclusive"}, "Exclusive"),
+      h("input", {id: "exclusive", name: "exclusive", type: "checkbox"}),
+      h("label", {for: "queue_on_conflict"}, "Wait for cluster lock"),
+      h("input", {id: "queue_on_conflict", name: "queue_on_conflict", type: "checkbox"}),
+      field("NATS address", "nats_address", "localhost:4222", {required: true}),
+      h("label", {for: "shared_connection"}, "Shared connection"),
+      h("input", {id: "shared_connection", name: "shared_connection", type: "checkbox"})),
+    writeFields,
+    readFields,
+    errorEl,
+    h("button", {class: "primary", type: "submit"}, "Create job"));
+
+    render(h("h1", {}, "New job"), form);
+  }
+
+  async function benchView(id) {
+    const summary = h("div", {class: "cards"});
+    const verdict = h("div");
+    const canvas = h("canvas");
+    const legend = h("div", {class: "legend"});
+    const nodesEl = h("div");
+    const workersEl = h("div");
+    const nodeStates = {};
+    const title = h("h1", {}, "Job " + id);
+
+    render(
+      h("div", {class: "toolbar"},
+        title,
+        h("a", {href: "/bench/" + id + "/timeline", target: "_blank"}, "timeline"),
+        h("a", {href: "/bench/" + id + "?format=markdown", target: "_blank"}, "markdown"),
+        h("button", {
+          class: "danger",
+          onclick: async () => {
+            if (!confirm("Delete job " + id + " and its results?")) {
+              return;
+            }
+
+            try {
+              const resp = await api("DELETE", "/bench/" + id);
+
+              if (resp.unconfirmed && resp.unconfirmed.length) {
+                alert(resp.message + "\n\n" + (resp.errors || []).join("\n"));
+              }
+
+              location.hash = "#/";
+            } catch (err) {
+              alert(err.message);
+            }
+          },
+        }, "Delete")),
+      summary,
+      verdict,
+      h("h2", {}, "Throughput (msg/sec)"),
+      h("div", {class: "chart"}, canvas, legend),
+      h("h2", {}, "Nodes"),
+      nodesEl,
+      h("h2", {}, "Workers"),
+      workersEl);
+
+    const refresh = async () => {
+      const [resp, timeline] = await Promise.all([
+        api("GET", "/bench/" + id + "?full"),
+        api("GET", "/bench/" + id + "/timeline"),
+      ]);
+
+      renderStatus(resp);
+      renderNodes(resp.status, nodeStates, nodesEl, workersEl);
+      drawTimeline(canvas, legend, timeline);
+
+      return resp.status.status;
+    };
+
+    const renderStatus = (resp) => {
+      const s = resp.status;
+      const lat = s.latency || {};
+
+      title.replaceChildren("Job " + id + " ", badge(s.status));
+
+      if (resp.archived_at) {
+        title.append(" ", badge("archived"));
+      }
+
+      if (resp.settings && resp.settings.description) {
+        title.append(h("span", {class: "muted"}, " " + resp.settings.description));
+      }
+
+      const settings = resp.settings || {};
+
+      summary.replaceChildren(...[
+        card("Type", jobType(settings)),
+        settings.owner ? card("Owner", settings.owner) : null,
+        settings.tags ? card("Tags", formatPairs(settings.tags)) : null,
+        settings.annotations ? card("Annotations", formatPairs(settings.annotations)) : null,
+        resp.archived_at ? card("Archived", new Date(resp.archived_at).toLocaleString()) : null,
+        card("Total msg/sec", num(s.total_msg_per_sec_all_nodes)),
+        card("Avg msg/sec per node", num(s.avg_msg_per_sec_per_node)),
+        card("Processed", num(s.total_processed, 0) + (s.plan ? " / " + num(s.plan.planned, 0) : "")),
+        card("Errors", num(s.total_errors, 0)),
+        card("Elapsed (s)", num(s.elapsed_seconds)),
+        card("p50 / p99 (ms)", num(lat.p50_ms) + " / " + num(lat.p99_ms)),
+      ].filter((c) => c !== null));
+
+      verdict.replaceChildren();
+
+      if (s.verdict) {
+        verdict.append(h("h2", {}, "Verdict ", badge(s.verdict.passed ? "passed" : "failed")));
+
+        if (s.verdict.failures.length > 0) {
+          verdict.append(h("ul", {class: "failures"}, s.verdict.failures.map((f) => h("li", {}, f.message))));
+        }
+      }
+
+      if (s.verify) {
+        const failed = (r) => r.gaps > 0 || r.duplicates > 0 || r.reordered > 0 || r.corrupted > 0;
+
+        verdict.append(h("h2", {}, "Verification"), table([
+          {label: "Stream"}, {label: "Messages", num: true}, {label: "Producers", num: true},
+          {label: "Gaps", num: true}, {label: "Duplicates", num: true}, {label: "Reordered", num: true},
+          {label: "Corrupted", num: true},
+        ], s.verify.map((r) => [r.stream, num(r.messages, 0), num(r.producers, 0), num(r.gaps, 0),
+          num(r.duplicates, 0), num(r.reordered, 0), num(r.corrupted, 0)]),
+        (row, i) => failed(s.verify[i]) ? "failed" : null));
+      }
+    };
+
+    let timer = null;
+    let events = null;
+
+    teardown = () => {
+      clearTimeout(timer);
+
+      if (events) {
+        events.close();
+      }
+    };
+
+    const status = await refresh();
+
+    if (status !== "in-progress") {
+      return;
+    }
+
+    // Refresh the full status and timeline at most once a second while the
+    // job is running; node state changes come in via the event stream
+    let pending = false;
+
+    const scheduleRefresh = () => {
+      if (pending) {
+        return;
+      }
+
+      pending = true;
+      timer = setTimeout(() => {
+        pending = false;
+        refresh().catch(() => {});
+      }, 1000);
+    };
+
+    events = new EventSource("/bench/" + id + "/events");
+
+    events.addEventListener("node", (e) => {
+      const event = JSON.parse(e.data);
+
+      nodeStates[event.node_id] = event.state;
+      scheduleRefresh();
+    });
+
+    events.addEventListener("status", scheduleRefresh);
+
+    events.addEventListener("end", () => {
+      events.close();
+      clearTimeout(timer);
+      refresh().catch(() => {});
+    });
+  }
+
+  // renderNodes renders per-node and per-worker tables; nodes that are far
+  // behind the others (stragglers) are highlighted
+  function renderNodes(status, nodeStates, nodesEl, workersEl) {
+    const nodes = [];
+    const workers = [];
+
+    for (const report of status.node_reports || []) {
+      const node = {id: report.node_id || "-", processed: 0, errors: 0, elapsed: 0, msgPerSec: 0, workers: 0};
+
+      (report.streams || []).forEach((stream) => {
+        (stream.workers || []).forEach((w) => {
+          node.processed += w.processed;
+          node.errors += w.errors;
+          node.elapsed = Math.max(node.elapsed, w.elapsed_seconds || 0);
+          node.msgPerSec += w.avg_msg_per_sec || 0;
+          node.workers++;
+
+          workers.push([node.id, w.WorkerID, num(w.processed, 0), num(w.errors, 0), num(w.elapsed_seconds),
+            num(w.avg_msg_per_sec), num((w.latency || {}).p50_ms), num((w.latency || {}).p99_ms)]);
+        });
+      });
+
+      nodes.push(node);
+    }
+
+    // Nodes that reported a state but no report yet (just accepted)
+    for (const id of Object.keys(nodeStates)) {
+      if (!nodes.some((n) => n.id === id)) {
+        nodes.push({id: id, processed: 0, errors: 0, elapsed: 0, msgPerSec: 0, workers: 0});
+      }
+    }
+
+    nodes.sort((a, b) => a.id.localeCompare(b.id));
+
+    const maxProcessed = Math.max(0, ...nodes.map((n) => n.processed));
+
+    nodesEl.replaceChildren(table([
+      {label: "Node"}, {label: "State"}, {label: "Workers", num: true}, {label: "Processed", num: true},
+      {label: "Progress vs. fastest", num: true}, {label: "Errors", num: true}, {label: "Elapsed (s)", num: true},
+      {label: "msg/sec", num: true},
+    ], nodes.map((n) => [
+      n.id,
+      nodeStates[n.id] ? badge(nodeStates[n.id]) : "",
+      num(n.workers, 0),
+      num(n.processed, 0),
+      maxProcessed > 0 ? num(100 * n.processed / maxProcessed, 0) + "%" : "-",
+      num(n.errors, 0),
+      num(n.elapsed),
+      num(n.msgPerSec),
+    ]), (row, i) => maxProcessed > 0 && nodes[i].processed < 0.8 * maxProcessed ? "straggler" : null));
+
+    workersEl.replaceChildren(table([
+      {label: "Node"}, {label: "Worker"}, {label: "Processed", num: true}, {label: "Errors", num: true},
+      {label: "Elapsed (s)", num: true}, {label: "msg/sec", num: true}, {label: "p50 (ms)", num: true},
+      {label: "p99 (ms)", num: true},
+    ], workers));
+  }
+
+  // drawTimeline draws total and per-node msg/sec over time
+  function drawTimeline(canvas, legend, timeline) {
+    const series = [{name: "total", samples: timeline.totals || []}];
+
+    for (const nodeID of Object.keys(timeline.nodes || {}).sort()) {
+      series.push({name: nodeID, samples: timeline.nodes[nodeID]});
+    }
+
+    const ratio = window.devicePixelRatio || 1;
+    const width = canvas.clientWidth;
+    const height = canvas.clientHeight;
+
+    canvas.width = width * ratio;
+    canvas.height = height * ratio;
+
+    const ctx = canvas.getContext("2d");
+
+    ctx.scale(ratio, ratio);
+    ctx.clearRect(0, 0, width, height);
+
+    const points = series.flatMap((s) => s.samples);
+
+    if (points.length === 0) {
+      ctx.fillStyle = "#57606a";
+      ctx.fillText("no samples yet", 10, 20);
+      legend.replaceChildren();
+      return;
+    }
+
+    const pad = {left: 70, right: 10, top: 10, bottom: 24};
+    const times = points.map((p) => new Date(p.timestamp).getTime());
+    const minT = Math.min(...times);
+    const maxT = Math.max(...times, minT + 1000);
+    const maxY = Math.max(...points.map((p) => p.msg_per_sec), 1);
+
+    const x = (t) => pad.left + (t - minT) / (maxT - minT) * (width - pad.left - pad.right);
+    const y = (v) => height - pad.bottom - v / maxY * (height - pad.top - pad.bottom);
+
+    // Axes and labels
+    ctx.strokeStyle = "#d0d7de";
+    ctx.fillStyle = "#57606a";
+    ctx.font = "11px sans-serif";
+
+    for (let i = 0; i <= 4; i++) {
+      const v = maxY * i / 4;
+
+      ctx.beginPath();
+      ctx.moveTo(pad.left, y(v));
+      ctx.lineTo(width - pad.right, y(v));
+      ctx.stroke();
+      ctx.fillText(num(v, 0), 4, y(v) + 4);
+    }
+
+    ctx.fillText("0s", pad.left, height - 6);
+    ctx.fillText(num((maxT - minT) / 1000, 0) + "s", width - pad.right - 30, height - 6);
+
+    series.forEach((s, i) => {
+      ctx.strokeStyle = colors[i % colors.length];
+      ctx.lineWidth = i === 0 ? 2.5 : 1.5;
+      ctx.beginPath();
+
+      s.samples.forEach((p, j) => {
+        const px = x(new Date(p.timestamp).getTime());
+        const py = y(p.msg_per_sec);
+
+        if (j === 0) {
+          ctx.moveTo(px, py);
+        } else {
+          ctx.lineTo(px, py);
+        }
+      });
+
+      ctx.stroke();
+    });
+
+    legend.replaceChildren(...series.map((s, i) =>
+      h("span", {style: "--color: " + colors[i % colors.length]}, s.name)));
+  }
+
+  // ---- routing ----------------------------------------------------------
+
+  async function route() {
+    if (teardown) {
+      teardown();
+      teardown = null;
+    }
+
+    const [path, query] = (location.hash.replace(/^#/, "") || "/").split("?");
+    const bench = path.match(/^\/bench\/([^/]+)$/);
+
+    try {
+      if (bench) {
+        await benchView(decodeURIComponent(bench[1]));
+      } else if (path === "/cluster") {
+        await clusterView();
+      } else if (path === "/new") {
+        newJobView();
+      } else {
+        await benchmarksView(new URLSearchParams(query || ""));
+      }
+    } catch (err) {
+      showError(err);
+    }
+  }
+
+  window.addEventListener("hashchange", route);
+
+  api("GET", "/version")
+    .then((v) => { document.getElementById("version").textContent = v.version; })
+    .catch(() => {});
+
+  route();
+})();
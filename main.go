@@ -1,12 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/client"
 	"github.com/batchcorp/njst/httpsvc"
 	"github.com/batchcorp/njst/natssvc"
 	"github.com/nats-io/nats.go"
@@ -19,7 +21,12 @@ import (
 var (
 	VERSION = "UNSET"
 
-	params = &cli.Params{}
+	params = &cli.Params{
+		NodeLabels: make(map[string]string),
+	}
+
+	clientParams = &cli.ClientParams{}
+	command      string
 )
 
 func init() {
@@ -65,24 +72,187 @@ func init() {
 		Envar("NJST_NATS_TLS_CA").
 		ExistingFileVar(&params.NATSTLSCaCert)
 
+	kingpin.Flag("node-label", "Label to attach to this node; can be specified multiple times (ex: --node-label zone=us-west-2a)").
+		Envar("NJST_NODE_LABEL").
+		StringMapVar(&params.NodeLabels)
+
+	kingpin.Flag("max-concurrent-jobs", "Number of jobs this node runs at the same time; other jobs wait in a queue").
+		Default("1").
+		Envar("NJST_MAX_CONCURRENT_JOBS").
+		IntVar(&params.MaxConcurrentJobs)
+
+	kingpin.Flag("max-queued-jobs", "Number of jobs that may wait in this node's queue before it refuses new jobs (0 = no limit)").
+		Default("0").
+		Envar("NJST_MAX_QUEUED_JOBS").
+		IntVar(&params.MaxQueuedJobs)
+
+	kingpin.Flag("archive-after", "Archive finished jobs into the history stream and delete their results buckets and settings this long after they end (0 = never)").
+		Default("0").
+		Envar("NJST_ARCHIVE_AFTER").
+		DurationVar(&params.ArchiveAfter)
+
+	kingpin.Flag("retention-max-runs", "Number of archived jobs to keep; the oldest are dropped first (0 = no limit)").
+		Default("0").
+		Envar("NJST_RETENTION_MAX_RUNS").
+		IntVar(&params.RetentionMaxRuns)
+
+	kingpin.Flag("retention-max-age", "How long to keep archived jobs (0 = forever)").
+		Default("0").
+		Envar("NJST_RETENTION_MAX_AGE").
+		DurationVar(&params.RetentionMaxAge)
+
 	kingpin.Flag("enable-pprof", "Enable pprof (exposes /debug/pprof/*").
 		Envar("NJST_ENABLE_PPROF").
 		BoolVar(&params.EnablePprof)
 
+	// "server" is the default so that "njst --flags.." keeps working
+	kingpin.Command("server", "Run an njst node (default)").Default()
+
+	benchCmd := kingpin.Command("bench", "Manage benchmarks via a node's HTTP API")
+	addClientFlags(benchCmd)
+
+	benchCreateCmd := benchCmd.Command("create", "Create a benchmark")
+
+	benchCreateCmd.Flag("file", "Path to a JSON job spec, as accepted by POST /bench ('--file=-' for stdin)").
+		Short('f').
+		Required().
+		StringVar(&clientParams.SpecFile)
+
+	benchCreateCmd.Flag("wait", "Wait for the job to end; exit non-zero if it fails").
+		BoolVar(&clientParams.Wait)
+
+	benchStatusCmd := benchCmd.Command("status", "Show the status of a benchmark")
+
+	benchStatusCmd.Arg("id", "Job ID").
+		Required().
+		StringVar(&clientParams.JobID)
+
+	benchStatusCmd.Flag("watch", "Stream live updates until the job ends").
+		Short('w').
+		BoolVar(&clientParams.Watch)
+
+	benchStatusCmd.Flag("wait", "Wait for the job to end; exit non-zero if it fails").
+		BoolVar(&clientParams.Wait)
+
+	benchListCmd := benchCmd.Command("list", "List benchmarks, newest first")
+
+	benchListCmd.Flag("status", "Only list jobs with these statuses (comma separated)").
+		StringVar(&clientParams.ListStatus)
+
+	benchListCmd.Flag("type", "Only list jobs of this type (write or read)").
+		StringVar(&clientParams.ListType)
+
+	benchListCmd.Flag("search", "Only list jobs whose description contains this").
+		Short('q').
+		StringVar(&clientParams.ListQuery)
+
+	benchListCmd.Flag("profile", "Only list jobs with this profile").
+		StringVar(&clientParams.ListProfile)
+
+	benchListCmd.Flag("owner", "Only list jobs with this owner").
+		StringVar(&clientParams.ListOwner)
+
+	benchListCmd.Flag("tag", "Only list jobs with this tag, as key=value or key (repeatable)").
+		StringsVar(&clientParams.ListTags)
+
+	benchListCmd.Flag("since", "Only list jobs created since (RFC3339 time or duration ago, ex: 24h)").
+		StringVar(&clientParams.ListSince)
+
+	benchListCmd.Flag("until", "Only list jobs created before (RFC3339 time or duration ago)").
+		StringVar(&clientParams.ListUntil)
+
+	benchListCmd.Flag("sort", "Field to sort by, prefixed with '-' for descending order").
+		StringVar(&clientParams.ListSort)
+
+	benchListCmd.Flag("limit", "Max number of jobs to list").
+		Short('n').
+		IntVar(&clientParams.ListLimit)
+
+	benchListCmd.Flag("offset", "Number of jobs to skip").
+		IntVar(&clientParams.ListOffset)
+
+	benchDeleteCmd := benchCmd.Command("delete", "Stop a benchmark and optionally delete its data")
+
+	benchDeleteCmd.Arg("id", "Job ID").
+		Required().
+		StringVar(&clientParams.JobID)
+
+	benchDeleteCmd.Flag("streams", "Delete the job's streams").
+		BoolVar(&clientParams.DeleteStreams)
+
+	benchDeleteCmd.Flag("settings", "Delete the job's settings").
+		BoolVar(&clientParams.DeleteSettings)
+
+	benchDeleteCmd.Flag("results", "Delete the job's results").
+		BoolVar(&clientParams.DeleteResults)
+
+	benchCmd.Command("purge", "Delete all streams, k/v stores and results created by njst")
+
+	clusterCmd := kingpin.Command("cluster", "Show the nodes in the njst cluster")
+	addClientFlags(clusterCmd)
+
+	clusterCmd.Arg("node", "Show the jobs running and queued on this node instead").
+		StringVar(&clientParams.NodeID)
+
+	runCmd := kingpin.Command("run", "Run a read or write job in this process (no njst cluster required), "+
+		"print the report and exit; exits non-zero if the job fails")
+
+	runCmd.Flag("file", "Path to a JSON job spec, as accepted by POST /bench ('--file=-' for stdin); "+
+		"nats.address defaults to --nats-address").
+		Short('f').
+		Required().
+		StringVar(&clientParams.SpecFile)
+
 	kingpin.CommandLine.HelpFlag.Short('h')
-	kingpin.Parse()
+	command = kingpin.Parse()
 }
 
-func main() {
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
+func addClientFlags(cmd *kingpin.CmdClause) {
+	cmd.Flag("address", "HTTP address of any njst node").
+		Short('a').
+		Default(client.DefaultAddress).
+		Envar("NJST_ADDRESS").
+		StringVar(&clientParams.Address)
+
+	cmd.Flag("timeout", "Timeout for API requests").
+		Default(client.DefaultTimeout.String()).
+		Envar("NJST_TIMEOUT").
+		DurationVar(&clientParams.Timeout)
+}
 
+func main() {
 	if params.Debug {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
 
+	if command == "server" {
+		runServer()
+		return
+	}
+
+	if command == "run" {
+		if err := runStandalone(params, clientParams.SpecFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if err := runClient(command, clientParams); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func runServer() {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
 	logrus.Infof("njst is starting...")
 
+	params.Version = VERSION
+
 	// Create dependencies
 	n, err := natssvc.New(params)
 	if err != nil {
@@ -99,6 +269,9 @@ func main() {
 		logrus.Fatal("Unable to setup HTTP service: ", err)
 	}
 
+	n.SetJobsFunc(b.RunningJobs)
+	n.SetQueueFunc(b.NodeJobs)
+
 	msgHandlers := map[string]nats.MsgHandler{
 		"njst." + params.NodeID + ".create": b.CreateMsgHandler,
 		"njst." + params.NodeID + ".delete": b.DeleteMsgHandler,
@@ -109,6 +282,10 @@ func main() {
 		logrus.Fatal("Unable to start NATS service: ", err)
 	}
 
+	if err := b.Start(); err != nil {
+		logrus.Fatal("Unable to start benchmark service: ", err)
+	}
+
 	if err := h.Start(); err != nil {
 		logrus.Fatal("Unable to start HTTP service: ", err)
 	}
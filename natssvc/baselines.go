@@ -0,0 +1,52 @@
+package natssvc
+
+import (
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+func (n *NATSService) SaveBaseline(baseline *types.Baseline) error {
+	if _, err := n.putJSON(BaselinesBucket, baseline.Profile, baseline); err != nil {
+		return errors.Wrap(err, "unable to save baseline")
+	}
+
+	return nil
+}
+
+func (n *NATSService) GetBaseline(profile string) (*types.Baseline, error) {
+	baseline := &types.Baseline{}
+
+	if _, err := n.getJSON(BaselinesBucket, profile, baseline); err != nil {
+		return nil, errors.Wrapf(err, "unable to get baseline for profile '%s'", profile)
+	}
+
+	return baseline, nil
+}
+
+func (n *NATSService) GetAllBaselines() ([]*types.Baseline, error) {
+	keys, err := n.keys(BaselinesBucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get baseline keys")
+	}
+
+	baselines := make([]*types.Baseline, 0)
+
+	for _, key := range keys {
+		baseline, err := n.GetBaseline(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get baseline for key '%s'", key)
+		}
+
+		baselines = append(baselines, baseline)
+	}
+
+	return baselines, nil
+}
+
+func (n *NATSService) DeleteBaseline(profile string) error {
+	if err := n.buckets[BaselinesBucket].Delete(profile); err != nil {
+		return errors.Wrapf(err, "unable to delete baseline '%s'", profile)
+	}
+
+	return nil
+}
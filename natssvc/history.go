@@ -0,0 +1,203 @@
+package natssvc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/batchcorp/njst/cli"
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	HistoryStream = "njst-history"
+
+	// How long to wait for the next archived run when reading the history
+	HistoryReadTimeout = time.Second
+)
+
+func historySubject(jobID string) string {
+	return HistoryStream + "." + jobID
+}
+
+// historyStreamConfig returns the config of the stream that holds archived
+// runs; the retention params are the stream's limits
+func historyStreamConfig(params *cli.Params) *nats.StreamConfig {
+	maxMsgs := int64(-1)
+
+	if params.RetentionMaxRuns > 0 {
+		maxMsgs = int64(params.RetentionMaxRuns)
+	}
+
+	return &nats.StreamConfig{
+		Name:              HistoryStream,
+		Description:       "Archived job results",
+		Subjects:          []string{HistoryStream + ".>"},
+		MaxMsgsPerSubject: 1,
+		MaxMsgs:           maxMsgs,
+		MaxAge:            params.RetentionMaxAge,
+		Storage:           nats.FileStorage,
+	}
+}
+
+// ensureHistoryStream creates the history stream if it does not exist yet and
+// updates its limits if the retention params changed
+func ensureHistoryStream(js nats.JetStreamContext, params *cli.Params) error {
+	cfg := historyStreamConfig(params)
+
+	info, err := js.StreamInfo(HistoryStream)
+	if err != nil {
+		if err != nats.ErrStreamNotFound {
+			return errors.Wrapf(err, "unable to determine stream '%s' status", HistoryStream)
+		}
+
+		if _, err := js.AddStream(cfg); err != nil {
+			return errors.Wrapf(err, "unable to create stream '%s'", HistoryStream)
+		}
+
+		return nil
+	}
+
+	if info.Config.MaxMsgs == cfg.MaxMsgs && info.Config.MaxAge == cfg.MaxAge {
+		return nil
+	}
+
+	if _, err := js.UpdateStream(cfg); err != nil {
+		return errors.Wrapf(err, "unable to update retention of stream '%s'", HistoryStream)
+	}
+
+	return nil
+}
+
+// ArchiveRun publishes a run to the history stream, replacing any earlier
+// record of the same job
+func (n *NATSService) ArchiveRun(run *types.ArchivedRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal archived run")
+	}
+
+	// Nodes archiving the same job at the same time publish the same record
+	if _, err := n.js.Publish(historySubject(run.Settings.ID), data, nats.MsgId(run.Settings.ID)); err != nil {
+		return errors.Wrapf(err, "unable to archive job '%s'", run.Settings.ID)
+	}
+
+	return nil
+}
+
+// GetArchivedRun returns the archived run of a job or nil if the job was not
+// archived
+func (n *NATSService) GetArchivedRun(jobID string) (*types.ArchivedRun, error) {
+	// JetStreamContext does not expose getting the last message by subject
+	// yet; use the JS API directly
+	req, err := json.Marshal(map[string]string{
+		"last_by_subj": historySubject(jobID),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal get request")
+	}
+
+	resp, err := n.conn.Request("$JS.API.STREAM.MSG.GET."+HistoryStream, req, 5*time.Second)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get archived run for job '%s'", jobID)
+	}
+
+	getResp := &struct {
+		Message *struct {
+			Data []byte `json:"data"`
+		} `json:"message"`
+		Error *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(resp.Data, getResp); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal get response")
+	}
+
+	if getResp.Error != nil {
+		if getResp.Error.Code == 404 {
+			return nil, nil
+		}
+
+		return nil, errors.Errorf("unable to get archived run for job '%s': %s", jobID, getResp.Error.Description)
+	}
+
+	run := &types.ArchivedRun{}
+
+	if err := json.Unmarshal(getResp.Message.Data, run); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal archived run")
+	}
+
+	return run, nil
+}
+
+// GetArchivedRuns returns every archived run, oldest archived first
+func (n *NATSService) GetArchivedRuns() ([]*types.ArchivedRun, error) {
+	runs := make([]*types.ArchivedRun, 0)
+
+	info, err := n.js.StreamInfo(HistoryStream)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get stream '%s' info", HistoryStream)
+	}
+
+	// Avoids waiting for a message that will never come
+	if info.State.Msgs == 0 {
+		return runs, nil
+	}
+
+	sub, err := n.js.SubscribeSync(HistoryStream+".>", nats.OrderedConsumer(), nats.DeliverAll())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to subscribe to history")
+	}
+
+	defer sub.Unsubscribe()
+
+	for {
+		msg, err := sub.NextMsg(HistoryReadTimeout)
+		if err != nil {
+			if err == nats.ErrTimeout {
+				return runs, nil
+			}
+
+			return nil, errors.Wrap(err, "unable to read archived run")
+		}
+
+		run := &types.ArchivedRun{}
+
+		if err := json.Unmarshal(msg.Data, run); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal archived run")
+		}
+
+		runs = append(runs, run)
+
+		meta, err := msg.Metadata()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to get archived run metadata")
+		}
+
+		if meta.NumPending == 0 {
+			return runs, nil
+		}
+	}
+}
+
+// DeleteArchivedRun removes a job from the history stream
+func (n *NATSService) DeleteArchivedRun(jobID string) error {
+	if err := n.purgeSubject(HistoryStream, historySubject(jobID)); err != nil {
+		return errors.Wrapf(err, "unable to delete archived run for job '%s'", jobID)
+	}
+
+	return nil
+}
+
+// PurgeHistory removes every archived run
+func (n *NATSService) PurgeHistory() error {
+	if err := n.js.PurgeStream(HistoryStream); err != nil {
+		return errors.Wrapf(err, "unable to purge stream '%s'", HistoryStream)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package natssvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+)
+
+//go:generate counterfeiter -o natssvcfakes/fake_istore.go . IStore
+//go:generate counterfeiter -o natssvcfakes/fake_ijetstream_admin.go . IJetStreamAdmin
+//go:generate counterfeiter -o natssvcfakes/fake_inatsservice.go . INATSService
+
+// IStore is the coordination store shared by all njst nodes: job settings
+// and results, the node list, job emission and everything else that is kept
+// in the internal buckets and streams.
+type IStore interface {
+	// Nodes
+	GetNodeList() ([]string, error)
+	GetNodes() ([]*types.NodeInfo, error)
+	GetNode(id string) (*types.NodeInfo, error)
+
+	// Jobs
+	EmitJobs(jobType types.JobType, jobs []*types.Job) error
+	RequestJob(jobType types.JobType, job *types.Job, timeout time.Duration) ([]byte, error)
+
+	// Settings
+	SaveSettings(settings *types.Settings) error
+	UpdateSettings(settings *types.Settings) error
+	GetSettings(id string) (*types.Settings, error)
+	GetAllSettings() ([]*types.Settings, error)
+	DeleteSettings(id string) error
+
+	// Results
+	CreateResults(jobID string) error
+	WriteStatus(status *types.Status) error
+	GetStatus(jobID, nodeID string) (*types.Status, error)
+	UpdateStatus(status *types.Status) error
+	GetStatuses(jobID string) ([]*types.Status, error)
+	WatchResults(ctx context.Context, jobID string) (<-chan *types.Status, error)
+	DeleteResults(id string) error
+
+	// Timeline
+	WriteTimelineSample(sample *types.TimelineSample) error
+	GetTimelineSamples(jobID string) ([]*types.TimelineSample, error)
+	DeleteTimeline(jobID string) error
+
+	// History
+	ArchiveRun(run *types.ArchivedRun) error
+	GetArchivedRun(jobID string) (*types.ArchivedRun, error)
+	GetArchivedRuns() ([]*types.ArchivedRun, error)
+	DeleteArchivedRun(jobID string) error
+	PurgeHistory() error
+
+	// Schedules
+	SaveSchedule(schedule *types.Schedule) error
+	UpdateSchedule(schedule *types.Schedule) error
+	GetSchedule(id string) (*types.Schedule, error)
+	GetAllSchedules() ([]*types.Schedule, error)
+	DeleteSchedule(id string) error
+
+	// Scenarios
+	SaveScenario(scenario *types.Scenario) error
+	GetScenario(id string) (*types.Scenario, error)
+	GetAllScenarios() ([]*types.Scenario, error)
+	DeleteScenario(id string) error
+
+	// Sweeps
+	SaveSweep(sweep *types.Sweep) error
+	GetSweep(id string) (*types.Sweep, error)
+	GetAllSweeps() ([]*types.Sweep, error)
+	DeleteSweep(id string) error
+
+	// Cluster lock
+	AcquireLock(lock *types.ClusterLock) error
+	GetLock() (*types.ClusterLock, error)
+	ReleaseLock(lock *types.ClusterLock) error
+
+	// Baselines
+	SaveBaseline(baseline *types.Baseline) error
+	GetBaseline(profile string) (*types.Baseline, error)
+	GetAllBaselines() ([]*types.Baseline, error)
+	DeleteBaseline(profile string) error
+}
+
+// IJetStreamAdmin manages the connections, streams and consumers that
+// benchmarks run against
+type IJetStreamAdmin interface {
+	NewConn(settings *types.NATS) (*nats.Conn, error)
+	ConnectionState() string
+	AddStream(streamConfig *nats.StreamConfig) (*nats.StreamInfo, error)
+	GetStreams(filter ...string) []string
+	GetStreamInfo(stream string) (*nats.StreamInfo, error)
+	DeleteStreams(jobID string) error
+	AddDurableConsumer(stream string, cfg *nats.ConsumerConfig, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+	DeleteDurableConsumers(stream string) error
+}
+
+// INATSService is everything bench needs from natssvc
+type INATSService interface {
+	IStore
+	IJetStreamAdmin
+}
@@ -0,0 +1,73 @@
+package natssvc
+
+import (
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// Helpers for storing JSON documents in the internal buckets
+
+func (n *NATSService) putJSON(bucket, key string, v interface{}) (uint64, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to marshal to JSON")
+	}
+
+	return n.buckets[bucket].Put(key, data)
+}
+
+// updateJSON only writes the document if revision is still the latest
+// revision of the key
+func (n *NATSService) updateJSON(bucket, key string, v interface{}, revision uint64) (uint64, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to marshal to JSON")
+	}
+
+	return n.buckets[bucket].Update(key, data, revision)
+}
+
+func (n *NATSService) getJSON(bucket, key string, v interface{}) (uint64, error) {
+	entry, err := n.buckets[bucket].Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := json.Unmarshal(entry.Value(), v); err != nil {
+		return 0, errors.Wrap(err, "unable to unmarshal from JSON")
+	}
+
+	return entry.Revision(), nil
+}
+
+// keys returns all keys in the bucket; a bucket without keys is not an error
+func (n *NATSService) keys(bucket string) ([]string, error) {
+	keys, err := n.buckets[bucket].Keys()
+	if err != nil {
+		if err == nats.ErrNoKeysFound {
+			return make([]string, 0), nil
+		}
+
+		return nil, err
+	}
+
+	return uniqueKeys(keys), nil
+}
+
+// uniqueKeys drops duplicate keys: Keys() watches the bucket, so a key that is
+// updated while it runs (ex: a heartbeat) can be returned more than once
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, k)
+		}
+	}
+
+	return unique
+}
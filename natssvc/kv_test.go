@@ -0,0 +1,26 @@
+package natssvc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []string
+	}{
+		{"empty", []string{}, []string{}},
+		{"unique", []string{"node1", "node2"}, []string{"node1", "node2"}},
+		{"duplicates", []string{"node1", "node2", "node1", "node2", "node3"}, []string{"node1", "node2", "node3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keys := uniqueKeys(tt.keys); !reflect.DeepEqual(keys, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
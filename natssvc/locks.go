@@ -0,0 +1,60 @@
+package natssvc
+
+import (
+	"encoding/json"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Key of the cluster-wide lock held by exclusive jobs
+	ExclusiveLockKey = "exclusive"
+)
+
+// AcquireLock writes the cluster lock only if no job holds it; fails
+// otherwise (use GetLock to find out which job does)
+func (n *NATSService) AcquireLock(lock *types.ClusterLock) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal lock to JSON")
+	}
+
+	revision, err := n.buckets[LocksBucket].Create(ExclusiveLockKey, data)
+	if err != nil {
+		return errors.Wrapf(err, "unable to acquire lock for job '%s'", lock.JobID)
+	}
+
+	lock.Revision = revision
+
+	return nil
+}
+
+// GetLock returns the cluster lock or nil if no job holds it
+func (n *NATSService) GetLock() (*types.ClusterLock, error) {
+	lock := &types.ClusterLock{}
+
+	revision, err := n.getJSON(LocksBucket, ExclusiveLockKey, lock)
+	if err != nil {
+		if err == nats.ErrKeyNotFound {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "unable to get lock")
+	}
+
+	lock.Revision = revision
+
+	return lock, nil
+}
+
+// ReleaseLock deletes the cluster lock only if it has not been modified since
+// it was read (ie. it is still held by the same job)
+func (n *NATSService) ReleaseLock(lock *types.ClusterLock) error {
+	if err := n.buckets[LocksBucket].Delete(ExclusiveLockKey, nats.LastRevision(lock.Revision)); err != nil {
+		return errors.Wrapf(err, "unable to release lock held by job '%s'", lock.JobID)
+	}
+
+	return nil
+}
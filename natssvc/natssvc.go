@@ -9,6 +9,9 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"runtime"
+	"runtime/metrics"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +29,11 @@ const (
 
 	HeartbeatBucket    = "njst-heartbeats"
 	SettingsBucket     = "njst-settings"
+	SchedulesBucket    = "njst-schedules"
+	ScenariosBucket    = "njst-scenarios"
+	SweepsBucket       = "njst-sweeps"
+	BaselinesBucket    = "njst-baselines"
+	LocksBucket        = "njst-locks"
 	ResultBucketPrefix = "njst-results"
 )
 
@@ -37,6 +45,10 @@ type NATSService struct {
 	buckets      map[string]nats.KeyValue
 	subs         map[string]*nats.Subscription
 	subjectMap   map[string]nats.MsgHandler
+	jobsFunc     func() []string
+	queueFunc    func() *types.NodeJobs
+	startedAt    time.Time
+	hostname     string
 	log          *logrus.Entry
 }
 
@@ -57,6 +69,26 @@ var (
 			Name:        SettingsBucket,
 			Description: "Settings bucket",
 		},
+		{
+			Name:        SchedulesBucket,
+			Description: "Schedules bucket",
+		},
+		{
+			Name:        ScenariosBucket,
+			Description: "Scenarios bucket",
+		},
+		{
+			Name:        SweepsBucket,
+			Description: "Sweeps bucket",
+		},
+		{
+			Name:        BaselinesBucket,
+			Description: "Baselines bucket",
+		},
+		{
+			Name:        LocksBucket,
+			Description: "Locks bucket",
+		},
 	}
 )
 
@@ -79,6 +111,28 @@ func New(params *cli.Params) (*NATSService, error) {
 
 	internalBuckets := make(map[string]nats.KeyValue)
 
+	hostname, err := os.Hostname()
+	if err != nil {
+		logrus.Warningf("unable to determine hostname: %s", err)
+	}
+
+	n := &NATSService{
+		conn:         c,
+		js:           js,
+		params:       params,
+		buckets:      internalBuckets,
+		bucketsMutex: &sync.RWMutex{},
+		subs:         make(map[string]*nats.Subscription),
+		startedAt:    time.Now().UTC(),
+		hostname:     hostname,
+		log:          logrus.WithField("pkg", "natssvc"),
+	}
+
+	if params.Standalone {
+		// Only the target server is used; nothing to set up
+		return n, nil
+	}
+
 	// Create internal buckets
 	for _, b := range requiredBuckets {
 		kv, err := js.KeyValue(b.Name)
@@ -92,25 +146,37 @@ func New(params *cli.Params) (*NATSService, error) {
 				})
 
 				if err != nil {
-					return nil, errors.Wrap(err, "unable to create heartbeat bucket")
+					return nil, errors.Wrapf(err, "unable to create bucket '%s'", b.Name)
 				}
 			} else {
-				return nil, errors.Wrap(err, "unable to determine heartbeat bucket status")
+				return nil, errors.Wrapf(err, "unable to determine bucket '%s' status", b.Name)
 			}
 		}
 
 		internalBuckets[b.Name] = kv
 	}
 
-	return &NATSService{
-		conn:         c,
-		js:           js,
-		params:       params,
-		buckets:      internalBuckets,
-		bucketsMutex: &sync.RWMutex{},
-		subs:         make(map[string]*nats.Subscription),
-		log:          logrus.WithField("pkg", "natssvc"),
-	}, nil
+	if err := ensureTimelineStream(js); err != nil {
+		return nil, err
+	}
+
+	if err := ensureHistoryStream(js, params); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// SetJobsFunc sets the func used by the heartbeat to determine which jobs are
+// currently running on this node. Must be called before Start().
+func (n *NATSService) SetJobsFunc(f func() []string) {
+	n.jobsFunc = f
+}
+
+// SetQueueFunc sets the func used by the heartbeat to list the jobs this node
+// accepted. Must be called before Start().
+func (n *NATSService) SetQueueFunc(f func() *types.NodeJobs) {
+	n.queueFunc = f
 }
 
 func (n *NATSService) NewConn(settings *types.NATS) (*nats.Conn, error) {
@@ -125,6 +191,12 @@ func (n *NATSService) NewConn(settings *types.NATS) (*nats.Conn, error) {
 	})
 }
 
+// ConnectionState returns the state of the node's main NATS connection
+// (ex: "CONNECTED", "RECONNECTING")
+func (n *NATSService) ConnectionState() string {
+	return n.conn.Status().String()
+}
+
 func (n *NATSService) CacheBucket(name string, bucket nats.KeyValue) {
 	n.bucketsMutex.Lock()
 	defer n.bucketsMutex.Unlock()
@@ -132,11 +204,17 @@ func (n *NATSService) CacheBucket(name string, bucket nats.KeyValue) {
 	n.buckets[name] = bucket
 }
 
+// WriteStatus writes a node's status for a job into the job's results bucket;
+// it is a no-op in standalone mode
 func (n *NATSService) WriteStatus(status *types.Status) error {
 	if status == nil {
 		return errors.New("status cannot be nil")
 	}
 
+	if n.params.Standalone {
+		return nil
+	}
+
 	if status.JobID == "" {
 		return errors.New("job name cannot be empty")
 	}
@@ -243,21 +321,80 @@ func (n *NATSService) Start(msgHandlers map[string]nats.MsgHandler) error {
 }
 
 func (n *NATSService) runHeartbeat() error {
-	var err error
-
 	ticker := time.NewTicker(100 * time.Millisecond)
 
 	for {
 		<-ticker.C
 
+		data, err := json.Marshal(n.nodeInfo())
+		if err != nil {
+			n.log.Errorf("unable to marshal node info: %s", err)
+			continue
+		}
+
 		// Publish heartbeat
-		_, err = n.buckets[HeartbeatBucket].Put(n.params.NodeID, []byte("I'm alive!"))
+		_, err = n.buckets[HeartbeatBucket].Put(n.params.NodeID, data)
 		if err != nil {
 			n.log.Errorf("unable to write heartbeat kv: %s", err)
 		}
 	}
 }
 
+func (n *NATSService) nodeInfo() *types.NodeInfo {
+	memAlloc, memSys := readMemStats()
+
+	jobs := make([]string, 0)
+
+	if n.jobsFunc != nil {
+		jobs = append(jobs, n.jobsFunc()...)
+	}
+
+	var queue *types.NodeJobs
+
+	if n.queueFunc != nil {
+		queue = n.queueFunc()
+	}
+
+	return &types.NodeInfo{
+		ID:            n.params.NodeID,
+		Version:       n.params.Version,
+		Hostname:      n.hostname,
+		StartedAt:     n.startedAt,
+		LastSeen:      time.Now().UTC(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		MemAllocBytes: memAlloc,
+		MemSysBytes:   memSys,
+		NumGoroutines: runtime.NumGoroutine(),
+		Labels:        n.params.NodeLabels,
+		Jobs:          jobs,
+		Queue:         queue,
+	}
+}
+
+// readMemStats returns the bytes of live heap objects and the total memory
+// mapped by the runtime (the equivalent of MemStats.Alloc and MemStats.Sys).
+// Unlike runtime.ReadMemStats it does not stop the world, which would skew
+// the latency of running jobs since it runs on every heartbeat.
+func readMemStats() (uint64, uint64) {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/memory/classes/total:bytes"},
+	}
+
+	metrics.Read(samples)
+
+	values := make([]uint64, len(samples))
+
+	for i, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			values[i] = s.Value.Uint64()
+		}
+	}
+
+	return values[0], values[1]
+}
+
 // GetStreams will get all defined streams. If a filter is provided, GetStreams
 // will only return streams that match the filter.
 func (n *NATSService) GetStreams(filter ...string) []string {
@@ -294,18 +431,12 @@ func (n *NATSService) EmitJobs(jobType types.JobType, jobs []*types.Job) error {
 	}
 
 	for _, j := range jobs {
-		data, err := json.Marshal(j)
+		msg, err := jobMsg(jobType, j)
 		if err != nil {
-			return errors.Wrapf(err, "unable to marshal job '%s': %s", j.Settings.ID, err)
+			return err
 		}
 
-		if err := n.conn.PublishMsg(&nats.Msg{
-			Subject: fmt.Sprintf("njst.%s.%s", j.NodeID, jobType),
-			Header: map[string][]string{
-				HeaderJobID: {j.Settings.ID},
-			},
-			Data: data,
-		}); err != nil {
+		if err := n.conn.PublishMsg(msg); err != nil {
 			return errors.Wrapf(err, "unable to publish job '%s' for node '%s': %s", j.Settings.ID, j.NodeID, err)
 		}
 	}
@@ -313,6 +444,41 @@ func (n *NATSService) EmitJobs(jobType types.JobType, jobs []*types.Job) error {
 	return nil
 }
 
+// RequestJob sends a job to its node and waits up to timeout for the node to
+// reply
+func (n *NATSService) RequestJob(jobType types.JobType, job *types.Job, timeout time.Duration) ([]byte, error) {
+	if job == nil || job.Settings == nil {
+		return nil, errors.New("job or job settings cannot be nil")
+	}
+
+	msg, err := jobMsg(jobType, job)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := n.conn.RequestMsg(msg, timeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no reply to job '%s' from node '%s'", job.Settings.ID, job.NodeID)
+	}
+
+	return reply.Data, nil
+}
+
+func jobMsg(jobType types.JobType, j *types.Job) (*nats.Msg, error) {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to marshal job '%s': %s", j.Settings.ID, err)
+	}
+
+	return &nats.Msg{
+		Subject: fmt.Sprintf("njst.%s.%s", j.NodeID, jobType),
+		Header: map[string][]string{
+			HeaderJobID: {j.Settings.ID},
+		},
+		Data: data,
+	}, nil
+}
+
 func (n *NATSService) DeleteSettings(id string) error {
 	if err := n.buckets[SettingsBucket].Delete(id); err != nil {
 		return errors.Wrapf(err, "unable to delete settings for job id '%s'", id)
@@ -401,6 +567,14 @@ func validateParams(params *cli.Params) error {
 		return errors.New("nats address cannot be empty or nil")
 	}
 
+	if params.RetentionMaxRuns < 0 {
+		return errors.New("retention max runs cannot be negative")
+	}
+
+	if params.RetentionMaxAge < 0 {
+		return errors.New("retention max age cannot be negative")
+	}
+
 	return nil
 }
 
@@ -451,12 +625,69 @@ func generateTLSConfig(caCert, clientCert, clientKey string, skipVerify bool) (*
 }
 
 func (n *NATSService) GetNodeList() ([]string, error) {
+	if n.params.Standalone {
+		return []string{n.params.NodeID}, nil
+	}
+
 	keys, err := n.buckets[HeartbeatBucket].Keys()
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to get heartbeat keys")
 	}
 
-	return keys, nil
+	return uniqueKeys(keys), nil
+}
+
+// GetNodes returns the node info for every node that is currently heartbeating,
+// sorted by node ID.
+func (n *NATSService) GetNodes() ([]*types.NodeInfo, error) {
+	keys, err := n.GetNodeList()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*types.NodeInfo, 0)
+
+	for _, key := range keys {
+		node, err := n.GetNode(key)
+		if err != nil {
+			if err == nats.ErrKeyNotFound {
+				// Node went away between Keys() and Get()
+				continue
+			}
+
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes, nil
+}
+
+// GetNode returns the latest heartbeat node info for the given node ID
+func (n *NATSService) GetNode(id string) (*types.NodeInfo, error) {
+	entry, err := n.buckets[HeartbeatBucket].Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &types.NodeInfo{}
+
+	if err := json.Unmarshal(entry.Value(), node); err != nil {
+		// Pre-NodeInfo njst versions write a plain string heartbeat
+		return &types.NodeInfo{
+			ID:       id,
+			Version:  "unknown",
+			LastSeen: entry.Created(),
+			Jobs:     make([]string, 0),
+		}, nil
+	}
+
+	return node, nil
 }
 
 func (n *NATSService) SaveSettings(settings *types.Settings) error {
@@ -465,14 +696,35 @@ func (n *NATSService) SaveSettings(settings *types.Settings) error {
 		return errors.Wrap(err, "unable to marshal settings to JSON")
 	}
 
-	if _, err := n.buckets[SettingsBucket].Put(settings.ID, data); err != nil {
+	revision, err := n.buckets[SettingsBucket].Put(settings.ID, data)
+	if err != nil {
 		return errors.Wrap(err, "unable to save settings")
 	}
 
+	settings.Revision = revision
+
+	return nil
+}
+
+// UpdateSettings writes the settings only if they have not been modified
+// since they were read (ie. settings.Revision is still the latest revision);
+// used by the watchdog to decide which node reassigns a lost node's share.
+func (n *NATSService) UpdateSettings(settings *types.Settings) error {
+	revision, err := n.updateJSON(SettingsBucket, settings.ID, settings, settings.Revision)
+	if err != nil {
+		return errors.Wrapf(err, "unable to update settings for id '%s'", settings.ID)
+	}
+
+	settings.Revision = revision
+
 	return nil
 }
 
 func (n *NATSService) GetSettings(id string) (*types.Settings, error) {
+	if n.params.Standalone {
+		return nil, errors.Errorf("unable to get settings for id '%s': settings are not kept in standalone mode", id)
+	}
+
 	entry, err := n.buckets[SettingsBucket].Get(id)
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to get settings for id '%s'", id)
@@ -484,6 +736,8 @@ func (n *NATSService) GetSettings(id string) (*types.Settings, error) {
 		return nil, errors.Wrap(err, "unable to unmarshal settings from JSON")
 	}
 
+	settings.Revision = entry.Revision()
+
 	return settings, nil
 }
 
@@ -515,9 +769,14 @@ func (n *NATSService) GetAllSettings() ([]*types.Settings, error) {
 		return nil, errors.Wrap(err, "unable to get settings keys")
 	}
 
-	for _, key := range keys {
+	for _, key := range uniqueKeys(keys) {
 		settings, err := n.GetSettings(key)
 		if err != nil {
+			if errors.Cause(err) == nats.ErrKeyNotFound {
+				// Job was deleted between Keys() and Get()
+				continue
+			}
+
 			return nil, errors.Wrapf(err, "unable to get settings for key '%s'", key)
 		}
 
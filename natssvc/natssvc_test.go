@@ -0,0 +1,30 @@
+package natssvc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/batchcorp/njst/cli"
+	"github.com/nats-io/nats.go"
+)
+
+func TestReadMemStats(t *testing.T) {
+	alloc, sys := readMemStats()
+
+	if alloc == 0 || sys == 0 {
+		t.Fatalf("expected memory stats to be set, got alloc %d, sys %d", alloc, sys)
+	}
+
+	if alloc > sys {
+		t.Errorf("expected heap objects (%d) to fit in the memory mapped by the runtime (%d)", alloc, sys)
+	}
+}
+
+func TestGetSettingsStandalone(t *testing.T) {
+	// Standalone nodes have no buckets
+	n := &NATSService{params: &cli.Params{Standalone: true}, buckets: make(map[string]nats.KeyValue)}
+
+	if _, err := n.GetSettings("abc"); err == nil || !strings.Contains(err.Error(), "standalone mode") {
+		t.Errorf("expected standalone error, got %v", err)
+	}
+}
@@ -0,0 +1,646 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package natssvcfakes
+
+import (
+	"sync"
+
+	"github.com/batchcorp/njst/natssvc"
+	"github.com/batchcorp/njst/types"
+	nats "github.com/nats-io/nats.go"
+)
+
+type FakeIJetStreamAdmin struct {
+	AddDurableConsumerStub        func(string, *nats.ConsumerConfig, ...nats.JSOpt) (*nats.ConsumerInfo, error)
+	addDurableConsumerMutex       sync.RWMutex
+	addDurableConsumerArgsForCall []struct {
+		arg1 string
+		arg2 *nats.ConsumerConfig
+		arg3 []nats.JSOpt
+	}
+	addDurableConsumerReturns struct {
+		result1 *nats.ConsumerInfo
+		result2 error
+	}
+	addDurableConsumerReturnsOnCall map[int]struct {
+		result1 *nats.ConsumerInfo
+		result2 error
+	}
+	AddStreamStub        func(*nats.StreamConfig) (*nats.StreamInfo, error)
+	addStreamMutex       sync.RWMutex
+	addStreamArgsForCall []struct {
+		arg1 *nats.StreamConfig
+	}
+	addStreamReturns struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}
+	addStreamReturnsOnCall map[int]struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}
+	ConnectionStateStub        func() string
+	connectionStateMutex       sync.RWMutex
+	connectionStateArgsForCall []struct {
+	}
+	connectionStateReturns struct {
+		result1 string
+	}
+	connectionStateReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DeleteDurableConsumersStub        func(string) error
+	deleteDurableConsumersMutex       sync.RWMutex
+	deleteDurableConsumersArgsForCall []struct {
+		arg1 string
+	}
+	deleteDurableConsumersReturns struct {
+		result1 error
+	}
+	deleteDurableConsumersReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteStreamsStub        func(string) error
+	deleteStreamsMutex       sync.RWMutex
+	deleteStreamsArgsForCall []struct {
+		arg1 string
+	}
+	deleteStreamsReturns struct {
+		result1 error
+	}
+	deleteStreamsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetStreamInfoStub        func(string) (*nats.StreamInfo, error)
+	getStreamInfoMutex       sync.RWMutex
+	getStreamInfoArgsForCall []struct {
+		arg1 string
+	}
+	getStreamInfoReturns struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}
+	getStreamInfoReturnsOnCall map[int]struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}
+	GetStreamsStub        func(...string) []string
+	getStreamsMutex       sync.RWMutex
+	getStreamsArgsForCall []struct {
+		arg1 []string
+	}
+	getStreamsReturns struct {
+		result1 []string
+	}
+	getStreamsReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	NewConnStub        func(*types.NATS) (*nats.Conn, error)
+	newConnMutex       sync.RWMutex
+	newConnArgsForCall []struct {
+		arg1 *types.NATS
+	}
+	newConnReturns struct {
+		result1 *nats.Conn
+		result2 error
+	}
+	newConnReturnsOnCall map[int]struct {
+		result1 *nats.Conn
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumer(arg1 string, arg2 *nats.ConsumerConfig, arg3 ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	fake.addDurableConsumerMutex.Lock()
+	ret, specificReturn := fake.addDurableConsumerReturnsOnCall[len(fake.addDurableConsumerArgsForCall)]
+	fake.addDurableConsumerArgsForCall = append(fake.addDurableConsumerArgsForCall, struct {
+		arg1 string
+		arg2 *nats.ConsumerConfig
+		arg3 []nats.JSOpt
+	}{arg1, arg2, arg3})
+	stub := fake.AddDurableConsumerStub
+	fakeReturns := fake.addDurableConsumerReturns
+	fake.recordInvocation("AddDurableConsumer", []interface{}{arg1, arg2, arg3})
+	fake.addDurableConsumerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumerCallCount() int {
+	fake.addDurableConsumerMutex.RLock()
+	defer fake.addDurableConsumerMutex.RUnlock()
+	return len(fake.addDurableConsumerArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumerCalls(stub func(string, *nats.ConsumerConfig, ...nats.JSOpt) (*nats.ConsumerInfo, error)) {
+	fake.addDurableConsumerMutex.Lock()
+	defer fake.addDurableConsumerMutex.Unlock()
+	fake.AddDurableConsumerStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumerArgsForCall(i int) (string, *nats.ConsumerConfig, []nats.JSOpt) {
+	fake.addDurableConsumerMutex.RLock()
+	defer fake.addDurableConsumerMutex.RUnlock()
+	argsForCall := fake.addDurableConsumerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumerReturns(result1 *nats.ConsumerInfo, result2 error) {
+	fake.addDurableConsumerMutex.Lock()
+	defer fake.addDurableConsumerMutex.Unlock()
+	fake.AddDurableConsumerStub = nil
+	fake.addDurableConsumerReturns = struct {
+		result1 *nats.ConsumerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) AddDurableConsumerReturnsOnCall(i int, result1 *nats.ConsumerInfo, result2 error) {
+	fake.addDurableConsumerMutex.Lock()
+	defer fake.addDurableConsumerMutex.Unlock()
+	fake.AddDurableConsumerStub = nil
+	if fake.addDurableConsumerReturnsOnCall == nil {
+		fake.addDurableConsumerReturnsOnCall = make(map[int]struct {
+			result1 *nats.ConsumerInfo
+			result2 error
+		})
+	}
+	fake.addDurableConsumerReturnsOnCall[i] = struct {
+		result1 *nats.ConsumerInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) AddStream(arg1 *nats.StreamConfig) (*nats.StreamInfo, error) {
+	fake.addStreamMutex.Lock()
+	ret, specificReturn := fake.addStreamReturnsOnCall[len(fake.addStreamArgsForCall)]
+	fake.addStreamArgsForCall = append(fake.addStreamArgsForCall, struct {
+		arg1 *nats.StreamConfig
+	}{arg1})
+	stub := fake.AddStreamStub
+	fakeReturns := fake.addStreamReturns
+	fake.recordInvocation("AddStream", []interface{}{arg1})
+	fake.addStreamMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIJetStreamAdmin) AddStreamCallCount() int {
+	fake.addStreamMutex.RLock()
+	defer fake.addStreamMutex.RUnlock()
+	return len(fake.addStreamArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) AddStreamCalls(stub func(*nats.StreamConfig) (*nats.StreamInfo, error)) {
+	fake.addStreamMutex.Lock()
+	defer fake.addStreamMutex.Unlock()
+	fake.AddStreamStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) AddStreamArgsForCall(i int) *nats.StreamConfig {
+	fake.addStreamMutex.RLock()
+	defer fake.addStreamMutex.RUnlock()
+	argsForCall := fake.addStreamArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) AddStreamReturns(result1 *nats.StreamInfo, result2 error) {
+	fake.addStreamMutex.Lock()
+	defer fake.addStreamMutex.Unlock()
+	fake.AddStreamStub = nil
+	fake.addStreamReturns = struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) AddStreamReturnsOnCall(i int, result1 *nats.StreamInfo, result2 error) {
+	fake.addStreamMutex.Lock()
+	defer fake.addStreamMutex.Unlock()
+	fake.AddStreamStub = nil
+	if fake.addStreamReturnsOnCall == nil {
+		fake.addStreamReturnsOnCall = make(map[int]struct {
+			result1 *nats.StreamInfo
+			result2 error
+		})
+	}
+	fake.addStreamReturnsOnCall[i] = struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) ConnectionState() string {
+	fake.connectionStateMutex.Lock()
+	ret, specificReturn := fake.connectionStateReturnsOnCall[len(fake.connectionStateArgsForCall)]
+	fake.connectionStateArgsForCall = append(fake.connectionStateArgsForCall, struct {
+	}{})
+	stub := fake.ConnectionStateStub
+	fakeReturns := fake.connectionStateReturns
+	fake.recordInvocation("ConnectionState", []interface{}{})
+	fake.connectionStateMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIJetStreamAdmin) ConnectionStateCallCount() int {
+	fake.connectionStateMutex.RLock()
+	defer fake.connectionStateMutex.RUnlock()
+	return len(fake.connectionStateArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) ConnectionStateCalls(stub func() string) {
+	fake.connectionStateMutex.Lock()
+	defer fake.connectionStateMutex.Unlock()
+	fake.ConnectionStateStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) ConnectionStateReturns(result1 string) {
+	fake.connectionStateMutex.Lock()
+	defer fake.connectionStateMutex.Unlock()
+	fake.ConnectionStateStub = nil
+	fake.connectionStateReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) ConnectionStateReturnsOnCall(i int, result1 string) {
+	fake.connectionStateMutex.Lock()
+	defer fake.connectionStateMutex.Unlock()
+	fake.ConnectionStateStub = nil
+	if fake.connectionStateReturnsOnCall == nil {
+		fake.connectionStateReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.connectionStateReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumers(arg1 string) error {
+	fake.deleteDurableConsumersMutex.Lock()
+	ret, specificReturn := fake.deleteDurableConsumersReturnsOnCall[len(fake.deleteDurableConsumersArgsForCall)]
+	fake.deleteDurableConsumersArgsForCall = append(fake.deleteDurableConsumersArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteDurableConsumersStub
+	fakeReturns := fake.deleteDurableConsumersReturns
+	fake.recordInvocation("DeleteDurableConsumers", []interface{}{arg1})
+	fake.deleteDurableConsumersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumersCallCount() int {
+	fake.deleteDurableConsumersMutex.RLock()
+	defer fake.deleteDurableConsumersMutex.RUnlock()
+	return len(fake.deleteDurableConsumersArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumersCalls(stub func(string) error) {
+	fake.deleteDurableConsumersMutex.Lock()
+	defer fake.deleteDurableConsumersMutex.Unlock()
+	fake.DeleteDurableConsumersStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumersArgsForCall(i int) string {
+	fake.deleteDurableConsumersMutex.RLock()
+	defer fake.deleteDurableConsumersMutex.RUnlock()
+	argsForCall := fake.deleteDurableConsumersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumersReturns(result1 error) {
+	fake.deleteDurableConsumersMutex.Lock()
+	defer fake.deleteDurableConsumersMutex.Unlock()
+	fake.DeleteDurableConsumersStub = nil
+	fake.deleteDurableConsumersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteDurableConsumersReturnsOnCall(i int, result1 error) {
+	fake.deleteDurableConsumersMutex.Lock()
+	defer fake.deleteDurableConsumersMutex.Unlock()
+	fake.DeleteDurableConsumersStub = nil
+	if fake.deleteDurableConsumersReturnsOnCall == nil {
+		fake.deleteDurableConsumersReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDurableConsumersReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreams(arg1 string) error {
+	fake.deleteStreamsMutex.Lock()
+	ret, specificReturn := fake.deleteStreamsReturnsOnCall[len(fake.deleteStreamsArgsForCall)]
+	fake.deleteStreamsArgsForCall = append(fake.deleteStreamsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteStreamsStub
+	fakeReturns := fake.deleteStreamsReturns
+	fake.recordInvocation("DeleteStreams", []interface{}{arg1})
+	fake.deleteStreamsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreamsCallCount() int {
+	fake.deleteStreamsMutex.RLock()
+	defer fake.deleteStreamsMutex.RUnlock()
+	return len(fake.deleteStreamsArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreamsCalls(stub func(string) error) {
+	fake.deleteStreamsMutex.Lock()
+	defer fake.deleteStreamsMutex.Unlock()
+	fake.DeleteStreamsStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreamsArgsForCall(i int) string {
+	fake.deleteStreamsMutex.RLock()
+	defer fake.deleteStreamsMutex.RUnlock()
+	argsForCall := fake.deleteStreamsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreamsReturns(result1 error) {
+	fake.deleteStreamsMutex.Lock()
+	defer fake.deleteStreamsMutex.Unlock()
+	fake.DeleteStreamsStub = nil
+	fake.deleteStreamsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) DeleteStreamsReturnsOnCall(i int, result1 error) {
+	fake.deleteStreamsMutex.Lock()
+	defer fake.deleteStreamsMutex.Unlock()
+	fake.DeleteStreamsStub = nil
+	if fake.deleteStreamsReturnsOnCall == nil {
+		fake.deleteStreamsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteStreamsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfo(arg1 string) (*nats.StreamInfo, error) {
+	fake.getStreamInfoMutex.Lock()
+	ret, specificReturn := fake.getStreamInfoReturnsOnCall[len(fake.getStreamInfoArgsForCall)]
+	fake.getStreamInfoArgsForCall = append(fake.getStreamInfoArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStreamInfoStub
+	fakeReturns := fake.getStreamInfoReturns
+	fake.recordInvocation("GetStreamInfo", []interface{}{arg1})
+	fake.getStreamInfoMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfoCallCount() int {
+	fake.getStreamInfoMutex.RLock()
+	defer fake.getStreamInfoMutex.RUnlock()
+	return len(fake.getStreamInfoArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfoCalls(stub func(string) (*nats.StreamInfo, error)) {
+	fake.getStreamInfoMutex.Lock()
+	defer fake.getStreamInfoMutex.Unlock()
+	fake.GetStreamInfoStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfoArgsForCall(i int) string {
+	fake.getStreamInfoMutex.RLock()
+	defer fake.getStreamInfoMutex.RUnlock()
+	argsForCall := fake.getStreamInfoArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfoReturns(result1 *nats.StreamInfo, result2 error) {
+	fake.getStreamInfoMutex.Lock()
+	defer fake.getStreamInfoMutex.Unlock()
+	fake.GetStreamInfoStub = nil
+	fake.getStreamInfoReturns = struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamInfoReturnsOnCall(i int, result1 *nats.StreamInfo, result2 error) {
+	fake.getStreamInfoMutex.Lock()
+	defer fake.getStreamInfoMutex.Unlock()
+	fake.GetStreamInfoStub = nil
+	if fake.getStreamInfoReturnsOnCall == nil {
+		fake.getStreamInfoReturnsOnCall = make(map[int]struct {
+			result1 *nats.StreamInfo
+			result2 error
+		})
+	}
+	fake.getStreamInfoReturnsOnCall[i] = struct {
+		result1 *nats.StreamInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreams(arg1 ...string) []string {
+	fake.getStreamsMutex.Lock()
+	ret, specificReturn := fake.getStreamsReturnsOnCall[len(fake.getStreamsArgsForCall)]
+	fake.getStreamsArgsForCall = append(fake.getStreamsArgsForCall, struct {
+		arg1 []string
+	}{arg1})
+	stub := fake.GetStreamsStub
+	fakeReturns := fake.getStreamsReturns
+	fake.recordInvocation("GetStreams", []interface{}{arg1})
+	fake.getStreamsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamsCallCount() int {
+	fake.getStreamsMutex.RLock()
+	defer fake.getStreamsMutex.RUnlock()
+	return len(fake.getStreamsArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamsCalls(stub func(...string) []string) {
+	fake.getStreamsMutex.Lock()
+	defer fake.getStreamsMutex.Unlock()
+	fake.GetStreamsStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamsArgsForCall(i int) []string {
+	fake.getStreamsMutex.RLock()
+	defer fake.getStreamsMutex.RUnlock()
+	argsForCall := fake.getStreamsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamsReturns(result1 []string) {
+	fake.getStreamsMutex.Lock()
+	defer fake.getStreamsMutex.Unlock()
+	fake.GetStreamsStub = nil
+	fake.getStreamsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) GetStreamsReturnsOnCall(i int, result1 []string) {
+	fake.getStreamsMutex.Lock()
+	defer fake.getStreamsMutex.Unlock()
+	fake.GetStreamsStub = nil
+	if fake.getStreamsReturnsOnCall == nil {
+		fake.getStreamsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.getStreamsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeIJetStreamAdmin) NewConn(arg1 *types.NATS) (*nats.Conn, error) {
+	fake.newConnMutex.Lock()
+	ret, specificReturn := fake.newConnReturnsOnCall[len(fake.newConnArgsForCall)]
+	fake.newConnArgsForCall = append(fake.newConnArgsForCall, struct {
+		arg1 *types.NATS
+	}{arg1})
+	stub := fake.NewConnStub
+	fakeReturns := fake.newConnReturns
+	fake.recordInvocation("NewConn", []interface{}{arg1})
+	fake.newConnMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIJetStreamAdmin) NewConnCallCount() int {
+	fake.newConnMutex.RLock()
+	defer fake.newConnMutex.RUnlock()
+	return len(fake.newConnArgsForCall)
+}
+
+func (fake *FakeIJetStreamAdmin) NewConnCalls(stub func(*types.NATS) (*nats.Conn, error)) {
+	fake.newConnMutex.Lock()
+	defer fake.newConnMutex.Unlock()
+	fake.NewConnStub = stub
+}
+
+func (fake *FakeIJetStreamAdmin) NewConnArgsForCall(i int) *types.NATS {
+	fake.newConnMutex.RLock()
+	defer fake.newConnMutex.RUnlock()
+	argsForCall := fake.newConnArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIJetStreamAdmin) NewConnReturns(result1 *nats.Conn, result2 error) {
+	fake.newConnMutex.Lock()
+	defer fake.newConnMutex.Unlock()
+	fake.NewConnStub = nil
+	fake.newConnReturns = struct {
+		result1 *nats.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) NewConnReturnsOnCall(i int, result1 *nats.Conn, result2 error) {
+	fake.newConnMutex.Lock()
+	defer fake.newConnMutex.Unlock()
+	fake.NewConnStub = nil
+	if fake.newConnReturnsOnCall == nil {
+		fake.newConnReturnsOnCall = make(map[int]struct {
+			result1 *nats.Conn
+			result2 error
+		})
+	}
+	fake.newConnReturnsOnCall[i] = struct {
+		result1 *nats.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIJetStreamAdmin) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.addDurableConsumerMutex.RLock()
+	defer fake.addDurableConsumerMutex.RUnlock()
+	fake.addStreamMutex.RLock()
+	defer fake.addStreamMutex.RUnlock()
+	fake.connectionStateMutex.RLock()
+	defer fake.connectionStateMutex.RUnlock()
+	fake.deleteDurableConsumersMutex.RLock()
+	defer fake.deleteDurableConsumersMutex.RUnlock()
+	fake.deleteStreamsMutex.RLock()
+	defer fake.deleteStreamsMutex.RUnlock()
+	fake.getStreamInfoMutex.RLock()
+	defer fake.getStreamInfoMutex.RUnlock()
+	fake.getStreamsMutex.RLock()
+	defer fake.getStreamsMutex.RUnlock()
+	fake.newConnMutex.RLock()
+	defer fake.newConnMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeIJetStreamAdmin) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ natssvc.IJetStreamAdmin = new(FakeIJetStreamAdmin)
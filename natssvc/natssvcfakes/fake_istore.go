@@ -0,0 +1,3400 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package natssvcfakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/batchcorp/njst/natssvc"
+	"github.com/batchcorp/njst/types"
+)
+
+type FakeIStore struct {
+	AcquireLockStub        func(*types.ClusterLock) error
+	acquireLockMutex       sync.RWMutex
+	acquireLockArgsForCall []struct {
+		arg1 *types.ClusterLock
+	}
+	acquireLockReturns struct {
+		result1 error
+	}
+	acquireLockReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ArchiveRunStub        func(*types.ArchivedRun) error
+	archiveRunMutex       sync.RWMutex
+	archiveRunArgsForCall []struct {
+		arg1 *types.ArchivedRun
+	}
+	archiveRunReturns struct {
+		result1 error
+	}
+	archiveRunReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateResultsStub        func(string) error
+	createResultsMutex       sync.RWMutex
+	createResultsArgsForCall []struct {
+		arg1 string
+	}
+	createResultsReturns struct {
+		result1 error
+	}
+	createResultsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteArchivedRunStub        func(string) error
+	deleteArchivedRunMutex       sync.RWMutex
+	deleteArchivedRunArgsForCall []struct {
+		arg1 string
+	}
+	deleteArchivedRunReturns struct {
+		result1 error
+	}
+	deleteArchivedRunReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteBaselineStub        func(string) error
+	deleteBaselineMutex       sync.RWMutex
+	deleteBaselineArgsForCall []struct {
+		arg1 string
+	}
+	deleteBaselineReturns struct {
+		result1 error
+	}
+	deleteBaselineReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteResultsStub        func(string) error
+	deleteResultsMutex       sync.RWMutex
+	deleteResultsArgsForCall []struct {
+		arg1 string
+	}
+	deleteResultsReturns struct {
+		result1 error
+	}
+	deleteResultsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteScenarioStub        func(string) error
+	deleteScenarioMutex       sync.RWMutex
+	deleteScenarioArgsForCall []struct {
+		arg1 string
+	}
+	deleteScenarioReturns struct {
+		result1 error
+	}
+	deleteScenarioReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteScheduleStub        func(string) error
+	deleteScheduleMutex       sync.RWMutex
+	deleteScheduleArgsForCall []struct {
+		arg1 string
+	}
+	deleteScheduleReturns struct {
+		result1 error
+	}
+	deleteScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSettingsStub        func(string) error
+	deleteSettingsMutex       sync.RWMutex
+	deleteSettingsArgsForCall []struct {
+		arg1 string
+	}
+	deleteSettingsReturns struct {
+		result1 error
+	}
+	deleteSettingsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSweepStub        func(string) error
+	deleteSweepMutex       sync.RWMutex
+	deleteSweepArgsForCall []struct {
+		arg1 string
+	}
+	deleteSweepReturns struct {
+		result1 error
+	}
+	deleteSweepReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteTimelineStub        func(string) error
+	deleteTimelineMutex       sync.RWMutex
+	deleteTimelineArgsForCall []struct {
+		arg1 string
+	}
+	deleteTimelineReturns struct {
+		result1 error
+	}
+	deleteTimelineReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EmitJobsStub        func(types.JobType, []*types.Job) error
+	emitJobsMutex       sync.RWMutex
+	emitJobsArgsForCall []struct {
+		arg1 types.JobType
+		arg2 []*types.Job
+	}
+	emitJobsReturns struct {
+		result1 error
+	}
+	emitJobsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetAllBaselinesStub        func() ([]*types.Baseline, error)
+	getAllBaselinesMutex       sync.RWMutex
+	getAllBaselinesArgsForCall []struct {
+	}
+	getAllBaselinesReturns struct {
+		result1 []*types.Baseline
+		result2 error
+	}
+	getAllBaselinesReturnsOnCall map[int]struct {
+		result1 []*types.Baseline
+		result2 error
+	}
+	GetAllScenariosStub        func() ([]*types.Scenario, error)
+	getAllScenariosMutex       sync.RWMutex
+	getAllScenariosArgsForCall []struct {
+	}
+	getAllScenariosReturns struct {
+		result1 []*types.Scenario
+		result2 error
+	}
+	getAllScenariosReturnsOnCall map[int]struct {
+		result1 []*types.Scenario
+		result2 error
+	}
+	GetAllSchedulesStub        func() ([]*types.Schedule, error)
+	getAllSchedulesMutex       sync.RWMutex
+	getAllSchedulesArgsForCall []struct {
+	}
+	getAllSchedulesReturns struct {
+		result1 []*types.Schedule
+		result2 error
+	}
+	getAllSchedulesReturnsOnCall map[int]struct {
+		result1 []*types.Schedule
+		result2 error
+	}
+	GetAllSettingsStub        func() ([]*types.Settings, error)
+	getAllSettingsMutex       sync.RWMutex
+	getAllSettingsArgsForCall []struct {
+	}
+	getAllSettingsReturns struct {
+		result1 []*types.Settings
+		result2 error
+	}
+	getAllSettingsReturnsOnCall map[int]struct {
+		result1 []*types.Settings
+		result2 error
+	}
+	GetAllSweepsStub        func() ([]*types.Sweep, error)
+	getAllSweepsMutex       sync.RWMutex
+	getAllSweepsArgsForCall []struct {
+	}
+	getAllSweepsReturns struct {
+		result1 []*types.Sweep
+		result2 error
+	}
+	getAllSweepsReturnsOnCall map[int]struct {
+		result1 []*types.Sweep
+		result2 error
+	}
+	GetArchivedRunStub        func(string) (*types.ArchivedRun, error)
+	getArchivedRunMutex       sync.RWMutex
+	getArchivedRunArgsForCall []struct {
+		arg1 string
+	}
+	getArchivedRunReturns struct {
+		result1 *types.ArchivedRun
+		result2 error
+	}
+	getArchivedRunReturnsOnCall map[int]struct {
+		result1 *types.ArchivedRun
+		result2 error
+	}
+	GetArchivedRunsStub        func() ([]*types.ArchivedRun, error)
+	getArchivedRunsMutex       sync.RWMutex
+	getArchivedRunsArgsForCall []struct {
+	}
+	getArchivedRunsReturns struct {
+		result1 []*types.ArchivedRun
+		result2 error
+	}
+	getArchivedRunsReturnsOnCall map[int]struct {
+		result1 []*types.ArchivedRun
+		result2 error
+	}
+	GetBaselineStub        func(string) (*types.Baseline, error)
+	getBaselineMutex       sync.RWMutex
+	getBaselineArgsForCall []struct {
+		arg1 string
+	}
+	getBaselineReturns struct {
+		result1 *types.Baseline
+		result2 error
+	}
+	getBaselineReturnsOnCall map[int]struct {
+		result1 *types.Baseline
+		result2 error
+	}
+	GetLockStub        func() (*types.ClusterLock, error)
+	getLockMutex       sync.RWMutex
+	getLockArgsForCall []struct {
+	}
+	getLockReturns struct {
+		result1 *types.ClusterLock
+		result2 error
+	}
+	getLockReturnsOnCall map[int]struct {
+		result1 *types.ClusterLock
+		result2 error
+	}
+	GetNodeStub        func(string) (*types.NodeInfo, error)
+	getNodeMutex       sync.RWMutex
+	getNodeArgsForCall []struct {
+		arg1 string
+	}
+	getNodeReturns struct {
+		result1 *types.NodeInfo
+		result2 error
+	}
+	getNodeReturnsOnCall map[int]struct {
+		result1 *types.NodeInfo
+		result2 error
+	}
+	GetNodeListStub        func() ([]string, error)
+	getNodeListMutex       sync.RWMutex
+	getNodeListArgsForCall []struct {
+	}
+	getNodeListReturns struct {
+		result1 []string
+		result2 error
+	}
+	getNodeListReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	GetNodesStub        func() ([]*types.NodeInfo, error)
+	getNodesMutex       sync.RWMutex
+	getNodesArgsForCall []struct {
+	}
+	getNodesReturns struct {
+		result1 []*types.NodeInfo
+		result2 error
+	}
+	getNodesReturnsOnCall map[int]struct {
+		result1 []*types.NodeInfo
+		result2 error
+	}
+	GetScenarioStub        func(string) (*types.Scenario, error)
+	getScenarioMutex       sync.RWMutex
+	getScenarioArgsForCall []struct {
+		arg1 string
+	}
+	getScenarioReturns struct {
+		result1 *types.Scenario
+		result2 error
+	}
+	getScenarioReturnsOnCall map[int]struct {
+		result1 *types.Scenario
+		result2 error
+	}
+	GetScheduleStub        func(string) (*types.Schedule, error)
+	getScheduleMutex       sync.RWMutex
+	getScheduleArgsForCall []struct {
+		arg1 string
+	}
+	getScheduleReturns struct {
+		result1 *types.Schedule
+		result2 error
+	}
+	getScheduleReturnsOnCall map[int]struct {
+		result1 *types.Schedule
+		result2 error
+	}
+	GetSettingsStub        func(string) (*types.Settings, error)
+	getSettingsMutex       sync.RWMutex
+	getSettingsArgsForCall []struct {
+		arg1 string
+	}
+	getSettingsReturns struct {
+		result1 *types.Settings
+		result2 error
+	}
+	getSettingsReturnsOnCall map[int]struct {
+		result1 *types.Settings
+		result2 error
+	}
+	GetStatusStub        func(string, string) (*types.Status, error)
+	getStatusMutex       sync.RWMutex
+	getStatusArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getStatusReturns struct {
+		result1 *types.Status
+		result2 error
+	}
+	getStatusReturnsOnCall map[int]struct {
+		result1 *types.Status
+		result2 error
+	}
+	GetStatusesStub        func(string) ([]*types.Status, error)
+	getStatusesMutex       sync.RWMutex
+	getStatusesArgsForCall []struct {
+		arg1 string
+	}
+	getStatusesReturns struct {
+		result1 []*types.Status
+		result2 error
+	}
+	getStatusesReturnsOnCall map[int]struct {
+		result1 []*types.Status
+		result2 error
+	}
+	GetSweepStub        func(string) (*types.Sweep, error)
+	getSweepMutex       sync.RWMutex
+	getSweepArgsForCall []struct {
+		arg1 string
+	}
+	getSweepReturns struct {
+		result1 *types.Sweep
+		result2 error
+	}
+	getSweepReturnsOnCall map[int]struct {
+		result1 *types.Sweep
+		result2 error
+	}
+	GetTimelineSamplesStub        func(string) ([]*types.TimelineSample, error)
+	getTimelineSamplesMutex       sync.RWMutex
+	getTimelineSamplesArgsForCall []struct {
+		arg1 string
+	}
+	getTimelineSamplesReturns struct {
+		result1 []*types.TimelineSample
+		result2 error
+	}
+	getTimelineSamplesReturnsOnCall map[int]struct {
+		result1 []*types.TimelineSample
+		result2 error
+	}
+	PurgeHistoryStub        func() error
+	purgeHistoryMutex       sync.RWMutex
+	purgeHistoryArgsForCall []struct {
+	}
+	purgeHistoryReturns struct {
+		result1 error
+	}
+	purgeHistoryReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReleaseLockStub        func(*types.ClusterLock) error
+	releaseLockMutex       sync.RWMutex
+	releaseLockArgsForCall []struct {
+		arg1 *types.ClusterLock
+	}
+	releaseLockReturns struct {
+		result1 error
+	}
+	releaseLockReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RequestJobStub        func(types.JobType, *types.Job, time.Duration) ([]byte, error)
+	requestJobMutex       sync.RWMutex
+	requestJobArgsForCall []struct {
+		arg1 types.JobType
+		arg2 *types.Job
+		arg3 time.Duration
+	}
+	requestJobReturns struct {
+		result1 []byte
+		result2 error
+	}
+	requestJobReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	SaveBaselineStub        func(*types.Baseline) error
+	saveBaselineMutex       sync.RWMutex
+	saveBaselineArgsForCall []struct {
+		arg1 *types.Baseline
+	}
+	saveBaselineReturns struct {
+		result1 error
+	}
+	saveBaselineReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveScenarioStub        func(*types.Scenario) error
+	saveScenarioMutex       sync.RWMutex
+	saveScenarioArgsForCall []struct {
+		arg1 *types.Scenario
+	}
+	saveScenarioReturns struct {
+		result1 error
+	}
+	saveScenarioReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveScheduleStub        func(*types.Schedule) error
+	saveScheduleMutex       sync.RWMutex
+	saveScheduleArgsForCall []struct {
+		arg1 *types.Schedule
+	}
+	saveScheduleReturns struct {
+		result1 error
+	}
+	saveScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveSettingsStub        func(*types.Settings) error
+	saveSettingsMutex       sync.RWMutex
+	saveSettingsArgsForCall []struct {
+		arg1 *types.Settings
+	}
+	saveSettingsReturns struct {
+		result1 error
+	}
+	saveSettingsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveSweepStub        func(*types.Sweep) error
+	saveSweepMutex       sync.RWMutex
+	saveSweepArgsForCall []struct {
+		arg1 *types.Sweep
+	}
+	saveSweepReturns struct {
+		result1 error
+	}
+	saveSweepReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateScheduleStub        func(*types.Schedule) error
+	updateScheduleMutex       sync.RWMutex
+	updateScheduleArgsForCall []struct {
+		arg1 *types.Schedule
+	}
+	updateScheduleReturns struct {
+		result1 error
+	}
+	updateScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateSettingsStub        func(*types.Settings) error
+	updateSettingsMutex       sync.RWMutex
+	updateSettingsArgsForCall []struct {
+		arg1 *types.Settings
+	}
+	updateSettingsReturns struct {
+		result1 error
+	}
+	updateSettingsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UpdateStatusStub        func(*types.Status) error
+	updateStatusMutex       sync.RWMutex
+	updateStatusArgsForCall []struct {
+		arg1 *types.Status
+	}
+	updateStatusReturns struct {
+		result1 error
+	}
+	updateStatusReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WatchResultsStub        func(context.Context, string) (<-chan *types.Status, error)
+	watchResultsMutex       sync.RWMutex
+	watchResultsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	watchResultsReturns struct {
+		result1 <-chan *types.Status
+		result2 error
+	}
+	watchResultsReturnsOnCall map[int]struct {
+		result1 <-chan *types.Status
+		result2 error
+	}
+	WriteStatusStub        func(*types.Status) error
+	writeStatusMutex       sync.RWMutex
+	writeStatusArgsForCall []struct {
+		arg1 *types.Status
+	}
+	writeStatusReturns struct {
+		result1 error
+	}
+	writeStatusReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WriteTimelineSampleStub        func(*types.TimelineSample) error
+	writeTimelineSampleMutex       sync.RWMutex
+	writeTimelineSampleArgsForCall []struct {
+		arg1 *types.TimelineSample
+	}
+	writeTimelineSampleReturns struct {
+		result1 error
+	}
+	writeTimelineSampleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIStore) AcquireLock(arg1 *types.ClusterLock) error {
+	fake.acquireLockMutex.Lock()
+	ret, specificReturn := fake.acquireLockReturnsOnCall[len(fake.acquireLockArgsForCall)]
+	fake.acquireLockArgsForCall = append(fake.acquireLockArgsForCall, struct {
+		arg1 *types.ClusterLock
+	}{arg1})
+	stub := fake.AcquireLockStub
+	fakeReturns := fake.acquireLockReturns
+	fake.recordInvocation("AcquireLock", []interface{}{arg1})
+	fake.acquireLockMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) AcquireLockCallCount() int {
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	return len(fake.acquireLockArgsForCall)
+}
+
+func (fake *FakeIStore) AcquireLockCalls(stub func(*types.ClusterLock) error) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = stub
+}
+
+func (fake *FakeIStore) AcquireLockArgsForCall(i int) *types.ClusterLock {
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	argsForCall := fake.acquireLockArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) AcquireLockReturns(result1 error) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = nil
+	fake.acquireLockReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) AcquireLockReturnsOnCall(i int, result1 error) {
+	fake.acquireLockMutex.Lock()
+	defer fake.acquireLockMutex.Unlock()
+	fake.AcquireLockStub = nil
+	if fake.acquireLockReturnsOnCall == nil {
+		fake.acquireLockReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.acquireLockReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) ArchiveRun(arg1 *types.ArchivedRun) error {
+	fake.archiveRunMutex.Lock()
+	ret, specificReturn := fake.archiveRunReturnsOnCall[len(fake.archiveRunArgsForCall)]
+	fake.archiveRunArgsForCall = append(fake.archiveRunArgsForCall, struct {
+		arg1 *types.ArchivedRun
+	}{arg1})
+	stub := fake.ArchiveRunStub
+	fakeReturns := fake.archiveRunReturns
+	fake.recordInvocation("ArchiveRun", []interface{}{arg1})
+	fake.archiveRunMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) ArchiveRunCallCount() int {
+	fake.archiveRunMutex.RLock()
+	defer fake.archiveRunMutex.RUnlock()
+	return len(fake.archiveRunArgsForCall)
+}
+
+func (fake *FakeIStore) ArchiveRunCalls(stub func(*types.ArchivedRun) error) {
+	fake.archiveRunMutex.Lock()
+	defer fake.archiveRunMutex.Unlock()
+	fake.ArchiveRunStub = stub
+}
+
+func (fake *FakeIStore) ArchiveRunArgsForCall(i int) *types.ArchivedRun {
+	fake.archiveRunMutex.RLock()
+	defer fake.archiveRunMutex.RUnlock()
+	argsForCall := fake.archiveRunArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) ArchiveRunReturns(result1 error) {
+	fake.archiveRunMutex.Lock()
+	defer fake.archiveRunMutex.Unlock()
+	fake.ArchiveRunStub = nil
+	fake.archiveRunReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) ArchiveRunReturnsOnCall(i int, result1 error) {
+	fake.archiveRunMutex.Lock()
+	defer fake.archiveRunMutex.Unlock()
+	fake.ArchiveRunStub = nil
+	if fake.archiveRunReturnsOnCall == nil {
+		fake.archiveRunReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.archiveRunReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) CreateResults(arg1 string) error {
+	fake.createResultsMutex.Lock()
+	ret, specificReturn := fake.createResultsReturnsOnCall[len(fake.createResultsArgsForCall)]
+	fake.createResultsArgsForCall = append(fake.createResultsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CreateResultsStub
+	fakeReturns := fake.createResultsReturns
+	fake.recordInvocation("CreateResults", []interface{}{arg1})
+	fake.createResultsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) CreateResultsCallCount() int {
+	fake.createResultsMutex.RLock()
+	defer fake.createResultsMutex.RUnlock()
+	return len(fake.createResultsArgsForCall)
+}
+
+func (fake *FakeIStore) CreateResultsCalls(stub func(string) error) {
+	fake.createResultsMutex.Lock()
+	defer fake.createResultsMutex.Unlock()
+	fake.CreateResultsStub = stub
+}
+
+func (fake *FakeIStore) CreateResultsArgsForCall(i int) string {
+	fake.createResultsMutex.RLock()
+	defer fake.createResultsMutex.RUnlock()
+	argsForCall := fake.createResultsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) CreateResultsReturns(result1 error) {
+	fake.createResultsMutex.Lock()
+	defer fake.createResultsMutex.Unlock()
+	fake.CreateResultsStub = nil
+	fake.createResultsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) CreateResultsReturnsOnCall(i int, result1 error) {
+	fake.createResultsMutex.Lock()
+	defer fake.createResultsMutex.Unlock()
+	fake.CreateResultsStub = nil
+	if fake.createResultsReturnsOnCall == nil {
+		fake.createResultsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createResultsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteArchivedRun(arg1 string) error {
+	fake.deleteArchivedRunMutex.Lock()
+	ret, specificReturn := fake.deleteArchivedRunReturnsOnCall[len(fake.deleteArchivedRunArgsForCall)]
+	fake.deleteArchivedRunArgsForCall = append(fake.deleteArchivedRunArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteArchivedRunStub
+	fakeReturns := fake.deleteArchivedRunReturns
+	fake.recordInvocation("DeleteArchivedRun", []interface{}{arg1})
+	fake.deleteArchivedRunMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteArchivedRunCallCount() int {
+	fake.deleteArchivedRunMutex.RLock()
+	defer fake.deleteArchivedRunMutex.RUnlock()
+	return len(fake.deleteArchivedRunArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteArchivedRunCalls(stub func(string) error) {
+	fake.deleteArchivedRunMutex.Lock()
+	defer fake.deleteArchivedRunMutex.Unlock()
+	fake.DeleteArchivedRunStub = stub
+}
+
+func (fake *FakeIStore) DeleteArchivedRunArgsForCall(i int) string {
+	fake.deleteArchivedRunMutex.RLock()
+	defer fake.deleteArchivedRunMutex.RUnlock()
+	argsForCall := fake.deleteArchivedRunArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteArchivedRunReturns(result1 error) {
+	fake.deleteArchivedRunMutex.Lock()
+	defer fake.deleteArchivedRunMutex.Unlock()
+	fake.DeleteArchivedRunStub = nil
+	fake.deleteArchivedRunReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteArchivedRunReturnsOnCall(i int, result1 error) {
+	fake.deleteArchivedRunMutex.Lock()
+	defer fake.deleteArchivedRunMutex.Unlock()
+	fake.DeleteArchivedRunStub = nil
+	if fake.deleteArchivedRunReturnsOnCall == nil {
+		fake.deleteArchivedRunReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteArchivedRunReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteBaseline(arg1 string) error {
+	fake.deleteBaselineMutex.Lock()
+	ret, specificReturn := fake.deleteBaselineReturnsOnCall[len(fake.deleteBaselineArgsForCall)]
+	fake.deleteBaselineArgsForCall = append(fake.deleteBaselineArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteBaselineStub
+	fakeReturns := fake.deleteBaselineReturns
+	fake.recordInvocation("DeleteBaseline", []interface{}{arg1})
+	fake.deleteBaselineMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteBaselineCallCount() int {
+	fake.deleteBaselineMutex.RLock()
+	defer fake.deleteBaselineMutex.RUnlock()
+	return len(fake.deleteBaselineArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteBaselineCalls(stub func(string) error) {
+	fake.deleteBaselineMutex.Lock()
+	defer fake.deleteBaselineMutex.Unlock()
+	fake.DeleteBaselineStub = stub
+}
+
+func (fake *FakeIStore) DeleteBaselineArgsForCall(i int) string {
+	fake.deleteBaselineMutex.RLock()
+	defer fake.deleteBaselineMutex.RUnlock()
+	argsForCall := fake.deleteBaselineArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteBaselineReturns(result1 error) {
+	fake.deleteBaselineMutex.Lock()
+	defer fake.deleteBaselineMutex.Unlock()
+	fake.DeleteBaselineStub = nil
+	fake.deleteBaselineReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteBaselineReturnsOnCall(i int, result1 error) {
+	fake.deleteBaselineMutex.Lock()
+	defer fake.deleteBaselineMutex.Unlock()
+	fake.DeleteBaselineStub = nil
+	if fake.deleteBaselineReturnsOnCall == nil {
+		fake.deleteBaselineReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteBaselineReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteResults(arg1 string) error {
+	fake.deleteResultsMutex.Lock()
+	ret, specificReturn := fake.deleteResultsReturnsOnCall[len(fake.deleteResultsArgsForCall)]
+	fake.deleteResultsArgsForCall = append(fake.deleteResultsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteResultsStub
+	fakeReturns := fake.deleteResultsReturns
+	fake.recordInvocation("DeleteResults", []interface{}{arg1})
+	fake.deleteResultsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteResultsCallCount() int {
+	fake.deleteResultsMutex.RLock()
+	defer fake.deleteResultsMutex.RUnlock()
+	return len(fake.deleteResultsArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteResultsCalls(stub func(string) error) {
+	fake.deleteResultsMutex.Lock()
+	defer fake.deleteResultsMutex.Unlock()
+	fake.DeleteResultsStub = stub
+}
+
+func (fake *FakeIStore) DeleteResultsArgsForCall(i int) string {
+	fake.deleteResultsMutex.RLock()
+	defer fake.deleteResultsMutex.RUnlock()
+	argsForCall := fake.deleteResultsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteResultsReturns(result1 error) {
+	fake.deleteResultsMutex.Lock()
+	defer fake.deleteResultsMutex.Unlock()
+	fake.DeleteResultsStub = nil
+	fake.deleteResultsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteResultsReturnsOnCall(i int, result1 error) {
+	fake.deleteResultsMutex.Lock()
+	defer fake.deleteResultsMutex.Unlock()
+	fake.DeleteResultsStub = nil
+	if fake.deleteResultsReturnsOnCall == nil {
+		fake.deleteResultsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteResultsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteScenario(arg1 string) error {
+	fake.deleteScenarioMutex.Lock()
+	ret, specificReturn := fake.deleteScenarioReturnsOnCall[len(fake.deleteScenarioArgsForCall)]
+	fake.deleteScenarioArgsForCall = append(fake.deleteScenarioArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteScenarioStub
+	fakeReturns := fake.deleteScenarioReturns
+	fake.recordInvocation("DeleteScenario", []interface{}{arg1})
+	fake.deleteScenarioMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteScenarioCallCount() int {
+	fake.deleteScenarioMutex.RLock()
+	defer fake.deleteScenarioMutex.RUnlock()
+	return len(fake.deleteScenarioArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteScenarioCalls(stub func(string) error) {
+	fake.deleteScenarioMutex.Lock()
+	defer fake.deleteScenarioMutex.Unlock()
+	fake.DeleteScenarioStub = stub
+}
+
+func (fake *FakeIStore) DeleteScenarioArgsForCall(i int) string {
+	fake.deleteScenarioMutex.RLock()
+	defer fake.deleteScenarioMutex.RUnlock()
+	argsForCall := fake.deleteScenarioArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteScenarioReturns(result1 error) {
+	fake.deleteScenarioMutex.Lock()
+	defer fake.deleteScenarioMutex.Unlock()
+	fake.DeleteScenarioStub = nil
+	fake.deleteScenarioReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteScenarioReturnsOnCall(i int, result1 error) {
+	fake.deleteScenarioMutex.Lock()
+	defer fake.deleteScenarioMutex.Unlock()
+	fake.DeleteScenarioStub = nil
+	if fake.deleteScenarioReturnsOnCall == nil {
+		fake.deleteScenarioReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteScenarioReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteSchedule(arg1 string) error {
+	fake.deleteScheduleMutex.Lock()
+	ret, specificReturn := fake.deleteScheduleReturnsOnCall[len(fake.deleteScheduleArgsForCall)]
+	fake.deleteScheduleArgsForCall = append(fake.deleteScheduleArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteScheduleStub
+	fakeReturns := fake.deleteScheduleReturns
+	fake.recordInvocation("DeleteSchedule", []interface{}{arg1})
+	fake.deleteScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteScheduleCallCount() int {
+	fake.deleteScheduleMutex.RLock()
+	defer fake.deleteScheduleMutex.RUnlock()
+	return len(fake.deleteScheduleArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteScheduleCalls(stub func(string) error) {
+	fake.deleteScheduleMutex.Lock()
+	defer fake.deleteScheduleMutex.Unlock()
+	fake.DeleteScheduleStub = stub
+}
+
+func (fake *FakeIStore) DeleteScheduleArgsForCall(i int) string {
+	fake.deleteScheduleMutex.RLock()
+	defer fake.deleteScheduleMutex.RUnlock()
+	argsForCall := fake.deleteScheduleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteScheduleReturns(result1 error) {
+	fake.deleteScheduleMutex.Lock()
+	defer fake.deleteScheduleMutex.Unlock()
+	fake.DeleteScheduleStub = nil
+	fake.deleteScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteScheduleReturnsOnCall(i int, result1 error) {
+	fake.deleteScheduleMutex.Lock()
+	defer fake.deleteScheduleMutex.Unlock()
+	fake.DeleteScheduleStub = nil
+	if fake.deleteScheduleReturnsOnCall == nil {
+		fake.deleteScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteSettings(arg1 string) error {
+	fake.deleteSettingsMutex.Lock()
+	ret, specificReturn := fake.deleteSettingsReturnsOnCall[len(fake.deleteSettingsArgsForCall)]
+	fake.deleteSettingsArgsForCall = append(fake.deleteSettingsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteSettingsStub
+	fakeReturns := fake.deleteSettingsReturns
+	fake.recordInvocation("DeleteSettings", []interface{}{arg1})
+	fake.deleteSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteSettingsCallCount() int {
+	fake.deleteSettingsMutex.RLock()
+	defer fake.deleteSettingsMutex.RUnlock()
+	return len(fake.deleteSettingsArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteSettingsCalls(stub func(string) error) {
+	fake.deleteSettingsMutex.Lock()
+	defer fake.deleteSettingsMutex.Unlock()
+	fake.DeleteSettingsStub = stub
+}
+
+func (fake *FakeIStore) DeleteSettingsArgsForCall(i int) string {
+	fake.deleteSettingsMutex.RLock()
+	defer fake.deleteSettingsMutex.RUnlock()
+	argsForCall := fake.deleteSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteSettingsReturns(result1 error) {
+	fake.deleteSettingsMutex.Lock()
+	defer fake.deleteSettingsMutex.Unlock()
+	fake.DeleteSettingsStub = nil
+	fake.deleteSettingsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteSettingsReturnsOnCall(i int, result1 error) {
+	fake.deleteSettingsMutex.Lock()
+	defer fake.deleteSettingsMutex.Unlock()
+	fake.DeleteSettingsStub = nil
+	if fake.deleteSettingsReturnsOnCall == nil {
+		fake.deleteSettingsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSettingsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteSweep(arg1 string) error {
+	fake.deleteSweepMutex.Lock()
+	ret, specificReturn := fake.deleteSweepReturnsOnCall[len(fake.deleteSweepArgsForCall)]
+	fake.deleteSweepArgsForCall = append(fake.deleteSweepArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteSweepStub
+	fakeReturns := fake.deleteSweepReturns
+	fake.recordInvocation("DeleteSweep", []interface{}{arg1})
+	fake.deleteSweepMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteSweepCallCount() int {
+	fake.deleteSweepMutex.RLock()
+	defer fake.deleteSweepMutex.RUnlock()
+	return len(fake.deleteSweepArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteSweepCalls(stub func(string) error) {
+	fake.deleteSweepMutex.Lock()
+	defer fake.deleteSweepMutex.Unlock()
+	fake.DeleteSweepStub = stub
+}
+
+func (fake *FakeIStore) DeleteSweepArgsForCall(i int) string {
+	fake.deleteSweepMutex.RLock()
+	defer fake.deleteSweepMutex.RUnlock()
+	argsForCall := fake.deleteSweepArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteSweepReturns(result1 error) {
+	fake.deleteSweepMutex.Lock()
+	defer fake.deleteSweepMutex.Unlock()
+	fake.DeleteSweepStub = nil
+	fake.deleteSweepReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteSweepReturnsOnCall(i int, result1 error) {
+	fake.deleteSweepMutex.Lock()
+	defer fake.deleteSweepMutex.Unlock()
+	fake.DeleteSweepStub = nil
+	if fake.deleteSweepReturnsOnCall == nil {
+		fake.deleteSweepReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSweepReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteTimeline(arg1 string) error {
+	fake.deleteTimelineMutex.Lock()
+	ret, specificReturn := fake.deleteTimelineReturnsOnCall[len(fake.deleteTimelineArgsForCall)]
+	fake.deleteTimelineArgsForCall = append(fake.deleteTimelineArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteTimelineStub
+	fakeReturns := fake.deleteTimelineReturns
+	fake.recordInvocation("DeleteTimeline", []interface{}{arg1})
+	fake.deleteTimelineMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) DeleteTimelineCallCount() int {
+	fake.deleteTimelineMutex.RLock()
+	defer fake.deleteTimelineMutex.RUnlock()
+	return len(fake.deleteTimelineArgsForCall)
+}
+
+func (fake *FakeIStore) DeleteTimelineCalls(stub func(string) error) {
+	fake.deleteTimelineMutex.Lock()
+	defer fake.deleteTimelineMutex.Unlock()
+	fake.DeleteTimelineStub = stub
+}
+
+func (fake *FakeIStore) DeleteTimelineArgsForCall(i int) string {
+	fake.deleteTimelineMutex.RLock()
+	defer fake.deleteTimelineMutex.RUnlock()
+	argsForCall := fake.deleteTimelineArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) DeleteTimelineReturns(result1 error) {
+	fake.deleteTimelineMutex.Lock()
+	defer fake.deleteTimelineMutex.Unlock()
+	fake.DeleteTimelineStub = nil
+	fake.deleteTimelineReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) DeleteTimelineReturnsOnCall(i int, result1 error) {
+	fake.deleteTimelineMutex.Lock()
+	defer fake.deleteTimelineMutex.Unlock()
+	fake.DeleteTimelineStub = nil
+	if fake.deleteTimelineReturnsOnCall == nil {
+		fake.deleteTimelineReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteTimelineReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) EmitJobs(arg1 types.JobType, arg2 []*types.Job) error {
+	var arg2Copy []*types.Job
+	if arg2 != nil {
+		arg2Copy = make([]*types.Job, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.emitJobsMutex.Lock()
+	ret, specificReturn := fake.emitJobsReturnsOnCall[len(fake.emitJobsArgsForCall)]
+	fake.emitJobsArgsForCall = append(fake.emitJobsArgsForCall, struct {
+		arg1 types.JobType
+		arg2 []*types.Job
+	}{arg1, arg2Copy})
+	stub := fake.EmitJobsStub
+	fakeReturns := fake.emitJobsReturns
+	fake.recordInvocation("EmitJobs", []interface{}{arg1, arg2Copy})
+	fake.emitJobsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) EmitJobsCallCount() int {
+	fake.emitJobsMutex.RLock()
+	defer fake.emitJobsMutex.RUnlock()
+	return len(fake.emitJobsArgsForCall)
+}
+
+func (fake *FakeIStore) EmitJobsCalls(stub func(types.JobType, []*types.Job) error) {
+	fake.emitJobsMutex.Lock()
+	defer fake.emitJobsMutex.Unlock()
+	fake.EmitJobsStub = stub
+}
+
+func (fake *FakeIStore) EmitJobsArgsForCall(i int) (types.JobType, []*types.Job) {
+	fake.emitJobsMutex.RLock()
+	defer fake.emitJobsMutex.RUnlock()
+	argsForCall := fake.emitJobsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIStore) EmitJobsReturns(result1 error) {
+	fake.emitJobsMutex.Lock()
+	defer fake.emitJobsMutex.Unlock()
+	fake.EmitJobsStub = nil
+	fake.emitJobsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) EmitJobsReturnsOnCall(i int, result1 error) {
+	fake.emitJobsMutex.Lock()
+	defer fake.emitJobsMutex.Unlock()
+	fake.EmitJobsStub = nil
+	if fake.emitJobsReturnsOnCall == nil {
+		fake.emitJobsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.emitJobsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) GetAllBaselines() ([]*types.Baseline, error) {
+	fake.getAllBaselinesMutex.Lock()
+	ret, specificReturn := fake.getAllBaselinesReturnsOnCall[len(fake.getAllBaselinesArgsForCall)]
+	fake.getAllBaselinesArgsForCall = append(fake.getAllBaselinesArgsForCall, struct {
+	}{})
+	stub := fake.GetAllBaselinesStub
+	fakeReturns := fake.getAllBaselinesReturns
+	fake.recordInvocation("GetAllBaselines", []interface{}{})
+	fake.getAllBaselinesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetAllBaselinesCallCount() int {
+	fake.getAllBaselinesMutex.RLock()
+	defer fake.getAllBaselinesMutex.RUnlock()
+	return len(fake.getAllBaselinesArgsForCall)
+}
+
+func (fake *FakeIStore) GetAllBaselinesCalls(stub func() ([]*types.Baseline, error)) {
+	fake.getAllBaselinesMutex.Lock()
+	defer fake.getAllBaselinesMutex.Unlock()
+	fake.GetAllBaselinesStub = stub
+}
+
+func (fake *FakeIStore) GetAllBaselinesReturns(result1 []*types.Baseline, result2 error) {
+	fake.getAllBaselinesMutex.Lock()
+	defer fake.getAllBaselinesMutex.Unlock()
+	fake.GetAllBaselinesStub = nil
+	fake.getAllBaselinesReturns = struct {
+		result1 []*types.Baseline
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllBaselinesReturnsOnCall(i int, result1 []*types.Baseline, result2 error) {
+	fake.getAllBaselinesMutex.Lock()
+	defer fake.getAllBaselinesMutex.Unlock()
+	fake.GetAllBaselinesStub = nil
+	if fake.getAllBaselinesReturnsOnCall == nil {
+		fake.getAllBaselinesReturnsOnCall = make(map[int]struct {
+			result1 []*types.Baseline
+			result2 error
+		})
+	}
+	fake.getAllBaselinesReturnsOnCall[i] = struct {
+		result1 []*types.Baseline
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllScenarios() ([]*types.Scenario, error) {
+	fake.getAllScenariosMutex.Lock()
+	ret, specificReturn := fake.getAllScenariosReturnsOnCall[len(fake.getAllScenariosArgsForCall)]
+	fake.getAllScenariosArgsForCall = append(fake.getAllScenariosArgsForCall, struct {
+	}{})
+	stub := fake.GetAllScenariosStub
+	fakeReturns := fake.getAllScenariosReturns
+	fake.recordInvocation("GetAllScenarios", []interface{}{})
+	fake.getAllScenariosMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetAllScenariosCallCount() int {
+	fake.getAllScenariosMutex.RLock()
+	defer fake.getAllScenariosMutex.RUnlock()
+	return len(fake.getAllScenariosArgsForCall)
+}
+
+func (fake *FakeIStore) GetAllScenariosCalls(stub func() ([]*types.Scenario, error)) {
+	fake.getAllScenariosMutex.Lock()
+	defer fake.getAllScenariosMutex.Unlock()
+	fake.GetAllScenariosStub = stub
+}
+
+func (fake *FakeIStore) GetAllScenariosReturns(result1 []*types.Scenario, result2 error) {
+	fake.getAllScenariosMutex.Lock()
+	defer fake.getAllScenariosMutex.Unlock()
+	fake.GetAllScenariosStub = nil
+	fake.getAllScenariosReturns = struct {
+		result1 []*types.Scenario
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllScenariosReturnsOnCall(i int, result1 []*types.Scenario, result2 error) {
+	fake.getAllScenariosMutex.Lock()
+	defer fake.getAllScenariosMutex.Unlock()
+	fake.GetAllScenariosStub = nil
+	if fake.getAllScenariosReturnsOnCall == nil {
+		fake.getAllScenariosReturnsOnCall = make(map[int]struct {
+			result1 []*types.Scenario
+			result2 error
+		})
+	}
+	fake.getAllScenariosReturnsOnCall[i] = struct {
+		result1 []*types.Scenario
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSchedules() ([]*types.Schedule, error) {
+	fake.getAllSchedulesMutex.Lock()
+	ret, specificReturn := fake.getAllSchedulesReturnsOnCall[len(fake.getAllSchedulesArgsForCall)]
+	fake.getAllSchedulesArgsForCall = append(fake.getAllSchedulesArgsForCall, struct {
+	}{})
+	stub := fake.GetAllSchedulesStub
+	fakeReturns := fake.getAllSchedulesReturns
+	fake.recordInvocation("GetAllSchedules", []interface{}{})
+	fake.getAllSchedulesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetAllSchedulesCallCount() int {
+	fake.getAllSchedulesMutex.RLock()
+	defer fake.getAllSchedulesMutex.RUnlock()
+	return len(fake.getAllSchedulesArgsForCall)
+}
+
+func (fake *FakeIStore) GetAllSchedulesCalls(stub func() ([]*types.Schedule, error)) {
+	fake.getAllSchedulesMutex.Lock()
+	defer fake.getAllSchedulesMutex.Unlock()
+	fake.GetAllSchedulesStub = stub
+}
+
+func (fake *FakeIStore) GetAllSchedulesReturns(result1 []*types.Schedule, result2 error) {
+	fake.getAllSchedulesMutex.Lock()
+	defer fake.getAllSchedulesMutex.Unlock()
+	fake.GetAllSchedulesStub = nil
+	fake.getAllSchedulesReturns = struct {
+		result1 []*types.Schedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSchedulesReturnsOnCall(i int, result1 []*types.Schedule, result2 error) {
+	fake.getAllSchedulesMutex.Lock()
+	defer fake.getAllSchedulesMutex.Unlock()
+	fake.GetAllSchedulesStub = nil
+	if fake.getAllSchedulesReturnsOnCall == nil {
+		fake.getAllSchedulesReturnsOnCall = make(map[int]struct {
+			result1 []*types.Schedule
+			result2 error
+		})
+	}
+	fake.getAllSchedulesReturnsOnCall[i] = struct {
+		result1 []*types.Schedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSettings() ([]*types.Settings, error) {
+	fake.getAllSettingsMutex.Lock()
+	ret, specificReturn := fake.getAllSettingsReturnsOnCall[len(fake.getAllSettingsArgsForCall)]
+	fake.getAllSettingsArgsForCall = append(fake.getAllSettingsArgsForCall, struct {
+	}{})
+	stub := fake.GetAllSettingsStub
+	fakeReturns := fake.getAllSettingsReturns
+	fake.recordInvocation("GetAllSettings", []interface{}{})
+	fake.getAllSettingsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetAllSettingsCallCount() int {
+	fake.getAllSettingsMutex.RLock()
+	defer fake.getAllSettingsMutex.RUnlock()
+	return len(fake.getAllSettingsArgsForCall)
+}
+
+func (fake *FakeIStore) GetAllSettingsCalls(stub func() ([]*types.Settings, error)) {
+	fake.getAllSettingsMutex.Lock()
+	defer fake.getAllSettingsMutex.Unlock()
+	fake.GetAllSettingsStub = stub
+}
+
+func (fake *FakeIStore) GetAllSettingsReturns(result1 []*types.Settings, result2 error) {
+	fake.getAllSettingsMutex.Lock()
+	defer fake.getAllSettingsMutex.Unlock()
+	fake.GetAllSettingsStub = nil
+	fake.getAllSettingsReturns = struct {
+		result1 []*types.Settings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSettingsReturnsOnCall(i int, result1 []*types.Settings, result2 error) {
+	fake.getAllSettingsMutex.Lock()
+	defer fake.getAllSettingsMutex.Unlock()
+	fake.GetAllSettingsStub = nil
+	if fake.getAllSettingsReturnsOnCall == nil {
+		fake.getAllSettingsReturnsOnCall = make(map[int]struct {
+			result1 []*types.Settings
+			result2 error
+		})
+	}
+	fake.getAllSettingsReturnsOnCall[i] = struct {
+		result1 []*types.Settings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSweeps() ([]*types.Sweep, error) {
+	fake.getAllSweepsMutex.Lock()
+	ret, specificReturn := fake.getAllSweepsReturnsOnCall[len(fake.getAllSweepsArgsForCall)]
+	fake.getAllSweepsArgsForCall = append(fake.getAllSweepsArgsForCall, struct {
+	}{})
+	stub := fake.GetAllSweepsStub
+	fakeReturns := fake.getAllSweepsReturns
+	fake.recordInvocation("GetAllSweeps", []interface{}{})
+	fake.getAllSweepsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetAllSweepsCallCount() int {
+	fake.getAllSweepsMutex.RLock()
+	defer fake.getAllSweepsMutex.RUnlock()
+	return len(fake.getAllSweepsArgsForCall)
+}
+
+func (fake *FakeIStore) GetAllSweepsCalls(stub func() ([]*types.Sweep, error)) {
+	fake.getAllSweepsMutex.Lock()
+	defer fake.getAllSweepsMutex.Unlock()
+	fake.GetAllSweepsStub = stub
+}
+
+func (fake *FakeIStore) GetAllSweepsReturns(result1 []*types.Sweep, result2 error) {
+	fake.getAllSweepsMutex.Lock()
+	defer fake.getAllSweepsMutex.Unlock()
+	fake.GetAllSweepsStub = nil
+	fake.getAllSweepsReturns = struct {
+		result1 []*types.Sweep
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetAllSweepsReturnsOnCall(i int, result1 []*types.Sweep, result2 error) {
+	fake.getAllSweepsMutex.Lock()
+	defer fake.getAllSweepsMutex.Unlock()
+	fake.GetAllSweepsStub = nil
+	if fake.getAllSweepsReturnsOnCall == nil {
+		fake.getAllSweepsReturnsOnCall = make(map[int]struct {
+			result1 []*types.Sweep
+			result2 error
+		})
+	}
+	fake.getAllSweepsReturnsOnCall[i] = struct {
+		result1 []*types.Sweep
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetArchivedRun(arg1 string) (*types.ArchivedRun, error) {
+	fake.getArchivedRunMutex.Lock()
+	ret, specificReturn := fake.getArchivedRunReturnsOnCall[len(fake.getArchivedRunArgsForCall)]
+	fake.getArchivedRunArgsForCall = append(fake.getArchivedRunArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetArchivedRunStub
+	fakeReturns := fake.getArchivedRunReturns
+	fake.recordInvocation("GetArchivedRun", []interface{}{arg1})
+	fake.getArchivedRunMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetArchivedRunCallCount() int {
+	fake.getArchivedRunMutex.RLock()
+	defer fake.getArchivedRunMutex.RUnlock()
+	return len(fake.getArchivedRunArgsForCall)
+}
+
+func (fake *FakeIStore) GetArchivedRunCalls(stub func(string) (*types.ArchivedRun, error)) {
+	fake.getArchivedRunMutex.Lock()
+	defer fake.getArchivedRunMutex.Unlock()
+	fake.GetArchivedRunStub = stub
+}
+
+func (fake *FakeIStore) GetArchivedRunArgsForCall(i int) string {
+	fake.getArchivedRunMutex.RLock()
+	defer fake.getArchivedRunMutex.RUnlock()
+	argsForCall := fake.getArchivedRunArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetArchivedRunReturns(result1 *types.ArchivedRun, result2 error) {
+	fake.getArchivedRunMutex.Lock()
+	defer fake.getArchivedRunMutex.Unlock()
+	fake.GetArchivedRunStub = nil
+	fake.getArchivedRunReturns = struct {
+		result1 *types.ArchivedRun
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetArchivedRunReturnsOnCall(i int, result1 *types.ArchivedRun, result2 error) {
+	fake.getArchivedRunMutex.Lock()
+	defer fake.getArchivedRunMutex.Unlock()
+	fake.GetArchivedRunStub = nil
+	if fake.getArchivedRunReturnsOnCall == nil {
+		fake.getArchivedRunReturnsOnCall = make(map[int]struct {
+			result1 *types.ArchivedRun
+			result2 error
+		})
+	}
+	fake.getArchivedRunReturnsOnCall[i] = struct {
+		result1 *types.ArchivedRun
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetArchivedRuns() ([]*types.ArchivedRun, error) {
+	fake.getArchivedRunsMutex.Lock()
+	ret, specificReturn := fake.getArchivedRunsReturnsOnCall[len(fake.getArchivedRunsArgsForCall)]
+	fake.getArchivedRunsArgsForCall = append(fake.getArchivedRunsArgsForCall, struct {
+	}{})
+	stub := fake.GetArchivedRunsStub
+	fakeReturns := fake.getArchivedRunsReturns
+	fake.recordInvocation("GetArchivedRuns", []interface{}{})
+	fake.getArchivedRunsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetArchivedRunsCallCount() int {
+	fake.getArchivedRunsMutex.RLock()
+	defer fake.getArchivedRunsMutex.RUnlock()
+	return len(fake.getArchivedRunsArgsForCall)
+}
+
+func (fake *FakeIStore) GetArchivedRunsCalls(stub func() ([]*types.ArchivedRun, error)) {
+	fake.getArchivedRunsMutex.Lock()
+	defer fake.getArchivedRunsMutex.Unlock()
+	fake.GetArchivedRunsStub = stub
+}
+
+func (fake *FakeIStore) GetArchivedRunsReturns(result1 []*types.ArchivedRun, result2 error) {
+	fake.getArchivedRunsMutex.Lock()
+	defer fake.getArchivedRunsMutex.Unlock()
+	fake.GetArchivedRunsStub = nil
+	fake.getArchivedRunsReturns = struct {
+		result1 []*types.ArchivedRun
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetArchivedRunsReturnsOnCall(i int, result1 []*types.ArchivedRun, result2 error) {
+	fake.getArchivedRunsMutex.Lock()
+	defer fake.getArchivedRunsMutex.Unlock()
+	fake.GetArchivedRunsStub = nil
+	if fake.getArchivedRunsReturnsOnCall == nil {
+		fake.getArchivedRunsReturnsOnCall = make(map[int]struct {
+			result1 []*types.ArchivedRun
+			result2 error
+		})
+	}
+	fake.getArchivedRunsReturnsOnCall[i] = struct {
+		result1 []*types.ArchivedRun
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetBaseline(arg1 string) (*types.Baseline, error) {
+	fake.getBaselineMutex.Lock()
+	ret, specificReturn := fake.getBaselineReturnsOnCall[len(fake.getBaselineArgsForCall)]
+	fake.getBaselineArgsForCall = append(fake.getBaselineArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetBaselineStub
+	fakeReturns := fake.getBaselineReturns
+	fake.recordInvocation("GetBaseline", []interface{}{arg1})
+	fake.getBaselineMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetBaselineCallCount() int {
+	fake.getBaselineMutex.RLock()
+	defer fake.getBaselineMutex.RUnlock()
+	return len(fake.getBaselineArgsForCall)
+}
+
+func (fake *FakeIStore) GetBaselineCalls(stub func(string) (*types.Baseline, error)) {
+	fake.getBaselineMutex.Lock()
+	defer fake.getBaselineMutex.Unlock()
+	fake.GetBaselineStub = stub
+}
+
+func (fake *FakeIStore) GetBaselineArgsForCall(i int) string {
+	fake.getBaselineMutex.RLock()
+	defer fake.getBaselineMutex.RUnlock()
+	argsForCall := fake.getBaselineArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetBaselineReturns(result1 *types.Baseline, result2 error) {
+	fake.getBaselineMutex.Lock()
+	defer fake.getBaselineMutex.Unlock()
+	fake.GetBaselineStub = nil
+	fake.getBaselineReturns = struct {
+		result1 *types.Baseline
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetBaselineReturnsOnCall(i int, result1 *types.Baseline, result2 error) {
+	fake.getBaselineMutex.Lock()
+	defer fake.getBaselineMutex.Unlock()
+	fake.GetBaselineStub = nil
+	if fake.getBaselineReturnsOnCall == nil {
+		fake.getBaselineReturnsOnCall = make(map[int]struct {
+			result1 *types.Baseline
+			result2 error
+		})
+	}
+	fake.getBaselineReturnsOnCall[i] = struct {
+		result1 *types.Baseline
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetLock() (*types.ClusterLock, error) {
+	fake.getLockMutex.Lock()
+	ret, specificReturn := fake.getLockReturnsOnCall[len(fake.getLockArgsForCall)]
+	fake.getLockArgsForCall = append(fake.getLockArgsForCall, struct {
+	}{})
+	stub := fake.GetLockStub
+	fakeReturns := fake.getLockReturns
+	fake.recordInvocation("GetLock", []interface{}{})
+	fake.getLockMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetLockCallCount() int {
+	fake.getLockMutex.RLock()
+	defer fake.getLockMutex.RUnlock()
+	return len(fake.getLockArgsForCall)
+}
+
+func (fake *FakeIStore) GetLockCalls(stub func() (*types.ClusterLock, error)) {
+	fake.getLockMutex.Lock()
+	defer fake.getLockMutex.Unlock()
+	fake.GetLockStub = stub
+}
+
+func (fake *FakeIStore) GetLockReturns(result1 *types.ClusterLock, result2 error) {
+	fake.getLockMutex.Lock()
+	defer fake.getLockMutex.Unlock()
+	fake.GetLockStub = nil
+	fake.getLockReturns = struct {
+		result1 *types.ClusterLock
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetLockReturnsOnCall(i int, result1 *types.ClusterLock, result2 error) {
+	fake.getLockMutex.Lock()
+	defer fake.getLockMutex.Unlock()
+	fake.GetLockStub = nil
+	if fake.getLockReturnsOnCall == nil {
+		fake.getLockReturnsOnCall = make(map[int]struct {
+			result1 *types.ClusterLock
+			result2 error
+		})
+	}
+	fake.getLockReturnsOnCall[i] = struct {
+		result1 *types.ClusterLock
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNode(arg1 string) (*types.NodeInfo, error) {
+	fake.getNodeMutex.Lock()
+	ret, specificReturn := fake.getNodeReturnsOnCall[len(fake.getNodeArgsForCall)]
+	fake.getNodeArgsForCall = append(fake.getNodeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetNodeStub
+	fakeReturns := fake.getNodeReturns
+	fake.recordInvocation("GetNode", []interface{}{arg1})
+	fake.getNodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetNodeCallCount() int {
+	fake.getNodeMutex.RLock()
+	defer fake.getNodeMutex.RUnlock()
+	return len(fake.getNodeArgsForCall)
+}
+
+func (fake *FakeIStore) GetNodeCalls(stub func(string) (*types.NodeInfo, error)) {
+	fake.getNodeMutex.Lock()
+	defer fake.getNodeMutex.Unlock()
+	fake.GetNodeStub = stub
+}
+
+func (fake *FakeIStore) GetNodeArgsForCall(i int) string {
+	fake.getNodeMutex.RLock()
+	defer fake.getNodeMutex.RUnlock()
+	argsForCall := fake.getNodeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetNodeReturns(result1 *types.NodeInfo, result2 error) {
+	fake.getNodeMutex.Lock()
+	defer fake.getNodeMutex.Unlock()
+	fake.GetNodeStub = nil
+	fake.getNodeReturns = struct {
+		result1 *types.NodeInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNodeReturnsOnCall(i int, result1 *types.NodeInfo, result2 error) {
+	fake.getNodeMutex.Lock()
+	defer fake.getNodeMutex.Unlock()
+	fake.GetNodeStub = nil
+	if fake.getNodeReturnsOnCall == nil {
+		fake.getNodeReturnsOnCall = make(map[int]struct {
+			result1 *types.NodeInfo
+			result2 error
+		})
+	}
+	fake.getNodeReturnsOnCall[i] = struct {
+		result1 *types.NodeInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNodeList() ([]string, error) {
+	fake.getNodeListMutex.Lock()
+	ret, specificReturn := fake.getNodeListReturnsOnCall[len(fake.getNodeListArgsForCall)]
+	fake.getNodeListArgsForCall = append(fake.getNodeListArgsForCall, struct {
+	}{})
+	stub := fake.GetNodeListStub
+	fakeReturns := fake.getNodeListReturns
+	fake.recordInvocation("GetNodeList", []interface{}{})
+	fake.getNodeListMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetNodeListCallCount() int {
+	fake.getNodeListMutex.RLock()
+	defer fake.getNodeListMutex.RUnlock()
+	return len(fake.getNodeListArgsForCall)
+}
+
+func (fake *FakeIStore) GetNodeListCalls(stub func() ([]string, error)) {
+	fake.getNodeListMutex.Lock()
+	defer fake.getNodeListMutex.Unlock()
+	fake.GetNodeListStub = stub
+}
+
+func (fake *FakeIStore) GetNodeListReturns(result1 []string, result2 error) {
+	fake.getNodeListMutex.Lock()
+	defer fake.getNodeListMutex.Unlock()
+	fake.GetNodeListStub = nil
+	fake.getNodeListReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNodeListReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.getNodeListMutex.Lock()
+	defer fake.getNodeListMutex.Unlock()
+	fake.GetNodeListStub = nil
+	if fake.getNodeListReturnsOnCall == nil {
+		fake.getNodeListReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.getNodeListReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNodes() ([]*types.NodeInfo, error) {
+	fake.getNodesMutex.Lock()
+	ret, specificReturn := fake.getNodesReturnsOnCall[len(fake.getNodesArgsForCall)]
+	fake.getNodesArgsForCall = append(fake.getNodesArgsForCall, struct {
+	}{})
+	stub := fake.GetNodesStub
+	fakeReturns := fake.getNodesReturns
+	fake.recordInvocation("GetNodes", []interface{}{})
+	fake.getNodesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetNodesCallCount() int {
+	fake.getNodesMutex.RLock()
+	defer fake.getNodesMutex.RUnlock()
+	return len(fake.getNodesArgsForCall)
+}
+
+func (fake *FakeIStore) GetNodesCalls(stub func() ([]*types.NodeInfo, error)) {
+	fake.getNodesMutex.Lock()
+	defer fake.getNodesMutex.Unlock()
+	fake.GetNodesStub = stub
+}
+
+func (fake *FakeIStore) GetNodesReturns(result1 []*types.NodeInfo, result2 error) {
+	fake.getNodesMutex.Lock()
+	defer fake.getNodesMutex.Unlock()
+	fake.GetNodesStub = nil
+	fake.getNodesReturns = struct {
+		result1 []*types.NodeInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetNodesReturnsOnCall(i int, result1 []*types.NodeInfo, result2 error) {
+	fake.getNodesMutex.Lock()
+	defer fake.getNodesMutex.Unlock()
+	fake.GetNodesStub = nil
+	if fake.getNodesReturnsOnCall == nil {
+		fake.getNodesReturnsOnCall = make(map[int]struct {
+			result1 []*types.NodeInfo
+			result2 error
+		})
+	}
+	fake.getNodesReturnsOnCall[i] = struct {
+		result1 []*types.NodeInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetScenario(arg1 string) (*types.Scenario, error) {
+	fake.getScenarioMutex.Lock()
+	ret, specificReturn := fake.getScenarioReturnsOnCall[len(fake.getScenarioArgsForCall)]
+	fake.getScenarioArgsForCall = append(fake.getScenarioArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetScenarioStub
+	fakeReturns := fake.getScenarioReturns
+	fake.recordInvocation("GetScenario", []interface{}{arg1})
+	fake.getScenarioMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetScenarioCallCount() int {
+	fake.getScenarioMutex.RLock()
+	defer fake.getScenarioMutex.RUnlock()
+	return len(fake.getScenarioArgsForCall)
+}
+
+func (fake *FakeIStore) GetScenarioCalls(stub func(string) (*types.Scenario, error)) {
+	fake.getScenarioMutex.Lock()
+	defer fake.getScenarioMutex.Unlock()
+	fake.GetScenarioStub = stub
+}
+
+func (fake *FakeIStore) GetScenarioArgsForCall(i int) string {
+	fake.getScenarioMutex.RLock()
+	defer fake.getScenarioMutex.RUnlock()
+	argsForCall := fake.getScenarioArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetScenarioReturns(result1 *types.Scenario, result2 error) {
+	fake.getScenarioMutex.Lock()
+	defer fake.getScenarioMutex.Unlock()
+	fake.GetScenarioStub = nil
+	fake.getScenarioReturns = struct {
+		result1 *types.Scenario
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetScenarioReturnsOnCall(i int, result1 *types.Scenario, result2 error) {
+	fake.getScenarioMutex.Lock()
+	defer fake.getScenarioMutex.Unlock()
+	fake.GetScenarioStub = nil
+	if fake.getScenarioReturnsOnCall == nil {
+		fake.getScenarioReturnsOnCall = make(map[int]struct {
+			result1 *types.Scenario
+			result2 error
+		})
+	}
+	fake.getScenarioReturnsOnCall[i] = struct {
+		result1 *types.Scenario
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetSchedule(arg1 string) (*types.Schedule, error) {
+	fake.getScheduleMutex.Lock()
+	ret, specificReturn := fake.getScheduleReturnsOnCall[len(fake.getScheduleArgsForCall)]
+	fake.getScheduleArgsForCall = append(fake.getScheduleArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetScheduleStub
+	fakeReturns := fake.getScheduleReturns
+	fake.recordInvocation("GetSchedule", []interface{}{arg1})
+	fake.getScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetScheduleCallCount() int {
+	fake.getScheduleMutex.RLock()
+	defer fake.getScheduleMutex.RUnlock()
+	return len(fake.getScheduleArgsForCall)
+}
+
+func (fake *FakeIStore) GetScheduleCalls(stub func(string) (*types.Schedule, error)) {
+	fake.getScheduleMutex.Lock()
+	defer fake.getScheduleMutex.Unlock()
+	fake.GetScheduleStub = stub
+}
+
+func (fake *FakeIStore) GetScheduleArgsForCall(i int) string {
+	fake.getScheduleMutex.RLock()
+	defer fake.getScheduleMutex.RUnlock()
+	argsForCall := fake.getScheduleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetScheduleReturns(result1 *types.Schedule, result2 error) {
+	fake.getScheduleMutex.Lock()
+	defer fake.getScheduleMutex.Unlock()
+	fake.GetScheduleStub = nil
+	fake.getScheduleReturns = struct {
+		result1 *types.Schedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetScheduleReturnsOnCall(i int, result1 *types.Schedule, result2 error) {
+	fake.getScheduleMutex.Lock()
+	defer fake.getScheduleMutex.Unlock()
+	fake.GetScheduleStub = nil
+	if fake.getScheduleReturnsOnCall == nil {
+		fake.getScheduleReturnsOnCall = make(map[int]struct {
+			result1 *types.Schedule
+			result2 error
+		})
+	}
+	fake.getScheduleReturnsOnCall[i] = struct {
+		result1 *types.Schedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetSettings(arg1 string) (*types.Settings, error) {
+	fake.getSettingsMutex.Lock()
+	ret, specificReturn := fake.getSettingsReturnsOnCall[len(fake.getSettingsArgsForCall)]
+	fake.getSettingsArgsForCall = append(fake.getSettingsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetSettingsStub
+	fakeReturns := fake.getSettingsReturns
+	fake.recordInvocation("GetSettings", []interface{}{arg1})
+	fake.getSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetSettingsCallCount() int {
+	fake.getSettingsMutex.RLock()
+	defer fake.getSettingsMutex.RUnlock()
+	return len(fake.getSettingsArgsForCall)
+}
+
+func (fake *FakeIStore) GetSettingsCalls(stub func(string) (*types.Settings, error)) {
+	fake.getSettingsMutex.Lock()
+	defer fake.getSettingsMutex.Unlock()
+	fake.GetSettingsStub = stub
+}
+
+func (fake *FakeIStore) GetSettingsArgsForCall(i int) string {
+	fake.getSettingsMutex.RLock()
+	defer fake.getSettingsMutex.RUnlock()
+	argsForCall := fake.getSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetSettingsReturns(result1 *types.Settings, result2 error) {
+	fake.getSettingsMutex.Lock()
+	defer fake.getSettingsMutex.Unlock()
+	fake.GetSettingsStub = nil
+	fake.getSettingsReturns = struct {
+		result1 *types.Settings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetSettingsReturnsOnCall(i int, result1 *types.Settings, result2 error) {
+	fake.getSettingsMutex.Lock()
+	defer fake.getSettingsMutex.Unlock()
+	fake.GetSettingsStub = nil
+	if fake.getSettingsReturnsOnCall == nil {
+		fake.getSettingsReturnsOnCall = make(map[int]struct {
+			result1 *types.Settings
+			result2 error
+		})
+	}
+	fake.getSettingsReturnsOnCall[i] = struct {
+		result1 *types.Settings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetStatus(arg1 string, arg2 string) (*types.Status, error) {
+	fake.getStatusMutex.Lock()
+	ret, specificReturn := fake.getStatusReturnsOnCall[len(fake.getStatusArgsForCall)]
+	fake.getStatusArgsForCall = append(fake.getStatusArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetStatusStub
+	fakeReturns := fake.getStatusReturns
+	fake.recordInvocation("GetStatus", []interface{}{arg1, arg2})
+	fake.getStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetStatusCallCount() int {
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
+	return len(fake.getStatusArgsForCall)
+}
+
+func (fake *FakeIStore) GetStatusCalls(stub func(string, string) (*types.Status, error)) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = stub
+}
+
+func (fake *FakeIStore) GetStatusArgsForCall(i int) (string, string) {
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
+	argsForCall := fake.getStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIStore) GetStatusReturns(result1 *types.Status, result2 error) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = nil
+	fake.getStatusReturns = struct {
+		result1 *types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetStatusReturnsOnCall(i int, result1 *types.Status, result2 error) {
+	fake.getStatusMutex.Lock()
+	defer fake.getStatusMutex.Unlock()
+	fake.GetStatusStub = nil
+	if fake.getStatusReturnsOnCall == nil {
+		fake.getStatusReturnsOnCall = make(map[int]struct {
+			result1 *types.Status
+			result2 error
+		})
+	}
+	fake.getStatusReturnsOnCall[i] = struct {
+		result1 *types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetStatuses(arg1 string) ([]*types.Status, error) {
+	fake.getStatusesMutex.Lock()
+	ret, specificReturn := fake.getStatusesReturnsOnCall[len(fake.getStatusesArgsForCall)]
+	fake.getStatusesArgsForCall = append(fake.getStatusesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStatusesStub
+	fakeReturns := fake.getStatusesReturns
+	fake.recordInvocation("GetStatuses", []interface{}{arg1})
+	fake.getStatusesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetStatusesCallCount() int {
+	fake.getStatusesMutex.RLock()
+	defer fake.getStatusesMutex.RUnlock()
+	return len(fake.getStatusesArgsForCall)
+}
+
+func (fake *FakeIStore) GetStatusesCalls(stub func(string) ([]*types.Status, error)) {
+	fake.getStatusesMutex.Lock()
+	defer fake.getStatusesMutex.Unlock()
+	fake.GetStatusesStub = stub
+}
+
+func (fake *FakeIStore) GetStatusesArgsForCall(i int) string {
+	fake.getStatusesMutex.RLock()
+	defer fake.getStatusesMutex.RUnlock()
+	argsForCall := fake.getStatusesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetStatusesReturns(result1 []*types.Status, result2 error) {
+	fake.getStatusesMutex.Lock()
+	defer fake.getStatusesMutex.Unlock()
+	fake.GetStatusesStub = nil
+	fake.getStatusesReturns = struct {
+		result1 []*types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetStatusesReturnsOnCall(i int, result1 []*types.Status, result2 error) {
+	fake.getStatusesMutex.Lock()
+	defer fake.getStatusesMutex.Unlock()
+	fake.GetStatusesStub = nil
+	if fake.getStatusesReturnsOnCall == nil {
+		fake.getStatusesReturnsOnCall = make(map[int]struct {
+			result1 []*types.Status
+			result2 error
+		})
+	}
+	fake.getStatusesReturnsOnCall[i] = struct {
+		result1 []*types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetSweep(arg1 string) (*types.Sweep, error) {
+	fake.getSweepMutex.Lock()
+	ret, specificReturn := fake.getSweepReturnsOnCall[len(fake.getSweepArgsForCall)]
+	fake.getSweepArgsForCall = append(fake.getSweepArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetSweepStub
+	fakeReturns := fake.getSweepReturns
+	fake.recordInvocation("GetSweep", []interface{}{arg1})
+	fake.getSweepMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetSweepCallCount() int {
+	fake.getSweepMutex.RLock()
+	defer fake.getSweepMutex.RUnlock()
+	return len(fake.getSweepArgsForCall)
+}
+
+func (fake *FakeIStore) GetSweepCalls(stub func(string) (*types.Sweep, error)) {
+	fake.getSweepMutex.Lock()
+	defer fake.getSweepMutex.Unlock()
+	fake.GetSweepStub = stub
+}
+
+func (fake *FakeIStore) GetSweepArgsForCall(i int) string {
+	fake.getSweepMutex.RLock()
+	defer fake.getSweepMutex.RUnlock()
+	argsForCall := fake.getSweepArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetSweepReturns(result1 *types.Sweep, result2 error) {
+	fake.getSweepMutex.Lock()
+	defer fake.getSweepMutex.Unlock()
+	fake.GetSweepStub = nil
+	fake.getSweepReturns = struct {
+		result1 *types.Sweep
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetSweepReturnsOnCall(i int, result1 *types.Sweep, result2 error) {
+	fake.getSweepMutex.Lock()
+	defer fake.getSweepMutex.Unlock()
+	fake.GetSweepStub = nil
+	if fake.getSweepReturnsOnCall == nil {
+		fake.getSweepReturnsOnCall = make(map[int]struct {
+			result1 *types.Sweep
+			result2 error
+		})
+	}
+	fake.getSweepReturnsOnCall[i] = struct {
+		result1 *types.Sweep
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetTimelineSamples(arg1 string) ([]*types.TimelineSample, error) {
+	fake.getTimelineSamplesMutex.Lock()
+	ret, specificReturn := fake.getTimelineSamplesReturnsOnCall[len(fake.getTimelineSamplesArgsForCall)]
+	fake.getTimelineSamplesArgsForCall = append(fake.getTimelineSamplesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetTimelineSamplesStub
+	fakeReturns := fake.getTimelineSamplesReturns
+	fake.recordInvocation("GetTimelineSamples", []interface{}{arg1})
+	fake.getTimelineSamplesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) GetTimelineSamplesCallCount() int {
+	fake.getTimelineSamplesMutex.RLock()
+	defer fake.getTimelineSamplesMutex.RUnlock()
+	return len(fake.getTimelineSamplesArgsForCall)
+}
+
+func (fake *FakeIStore) GetTimelineSamplesCalls(stub func(string) ([]*types.TimelineSample, error)) {
+	fake.getTimelineSamplesMutex.Lock()
+	defer fake.getTimelineSamplesMutex.Unlock()
+	fake.GetTimelineSamplesStub = stub
+}
+
+func (fake *FakeIStore) GetTimelineSamplesArgsForCall(i int) string {
+	fake.getTimelineSamplesMutex.RLock()
+	defer fake.getTimelineSamplesMutex.RUnlock()
+	argsForCall := fake.getTimelineSamplesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) GetTimelineSamplesReturns(result1 []*types.TimelineSample, result2 error) {
+	fake.getTimelineSamplesMutex.Lock()
+	defer fake.getTimelineSamplesMutex.Unlock()
+	fake.GetTimelineSamplesStub = nil
+	fake.getTimelineSamplesReturns = struct {
+		result1 []*types.TimelineSample
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) GetTimelineSamplesReturnsOnCall(i int, result1 []*types.TimelineSample, result2 error) {
+	fake.getTimelineSamplesMutex.Lock()
+	defer fake.getTimelineSamplesMutex.Unlock()
+	fake.GetTimelineSamplesStub = nil
+	if fake.getTimelineSamplesReturnsOnCall == nil {
+		fake.getTimelineSamplesReturnsOnCall = make(map[int]struct {
+			result1 []*types.TimelineSample
+			result2 error
+		})
+	}
+	fake.getTimelineSamplesReturnsOnCall[i] = struct {
+		result1 []*types.TimelineSample
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) PurgeHistory() error {
+	fake.purgeHistoryMutex.Lock()
+	ret, specificReturn := fake.purgeHistoryReturnsOnCall[len(fake.purgeHistoryArgsForCall)]
+	fake.purgeHistoryArgsForCall = append(fake.purgeHistoryArgsForCall, struct {
+	}{})
+	stub := fake.PurgeHistoryStub
+	fakeReturns := fake.purgeHistoryReturns
+	fake.recordInvocation("PurgeHistory", []interface{}{})
+	fake.purgeHistoryMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) PurgeHistoryCallCount() int {
+	fake.purgeHistoryMutex.RLock()
+	defer fake.purgeHistoryMutex.RUnlock()
+	return len(fake.purgeHistoryArgsForCall)
+}
+
+func (fake *FakeIStore) PurgeHistoryCalls(stub func() error) {
+	fake.purgeHistoryMutex.Lock()
+	defer fake.purgeHistoryMutex.Unlock()
+	fake.PurgeHistoryStub = stub
+}
+
+func (fake *FakeIStore) PurgeHistoryReturns(result1 error) {
+	fake.purgeHistoryMutex.Lock()
+	defer fake.purgeHistoryMutex.Unlock()
+	fake.PurgeHistoryStub = nil
+	fake.purgeHistoryReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) PurgeHistoryReturnsOnCall(i int, result1 error) {
+	fake.purgeHistoryMutex.Lock()
+	defer fake.purgeHistoryMutex.Unlock()
+	fake.PurgeHistoryStub = nil
+	if fake.purgeHistoryReturnsOnCall == nil {
+		fake.purgeHistoryReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.purgeHistoryReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) ReleaseLock(arg1 *types.ClusterLock) error {
+	fake.releaseLockMutex.Lock()
+	ret, specificReturn := fake.releaseLockReturnsOnCall[len(fake.releaseLockArgsForCall)]
+	fake.releaseLockArgsForCall = append(fake.releaseLockArgsForCall, struct {
+		arg1 *types.ClusterLock
+	}{arg1})
+	stub := fake.ReleaseLockStub
+	fakeReturns := fake.releaseLockReturns
+	fake.recordInvocation("ReleaseLock", []interface{}{arg1})
+	fake.releaseLockMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) ReleaseLockCallCount() int {
+	fake.releaseLockMutex.RLock()
+	defer fake.releaseLockMutex.RUnlock()
+	return len(fake.releaseLockArgsForCall)
+}
+
+func (fake *FakeIStore) ReleaseLockCalls(stub func(*types.ClusterLock) error) {
+	fake.releaseLockMutex.Lock()
+	defer fake.releaseLockMutex.Unlock()
+	fake.ReleaseLockStub = stub
+}
+
+func (fake *FakeIStore) ReleaseLockArgsForCall(i int) *types.ClusterLock {
+	fake.releaseLockMutex.RLock()
+	defer fake.releaseLockMutex.RUnlock()
+	argsForCall := fake.releaseLockArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) ReleaseLockReturns(result1 error) {
+	fake.releaseLockMutex.Lock()
+	defer fake.releaseLockMutex.Unlock()
+	fake.ReleaseLockStub = nil
+	fake.releaseLockReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) ReleaseLockReturnsOnCall(i int, result1 error) {
+	fake.releaseLockMutex.Lock()
+	defer fake.releaseLockMutex.Unlock()
+	fake.ReleaseLockStub = nil
+	if fake.releaseLockReturnsOnCall == nil {
+		fake.releaseLockReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseLockReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) RequestJob(arg1 types.JobType, arg2 *types.Job, arg3 time.Duration) ([]byte, error) {
+	fake.requestJobMutex.Lock()
+	ret, specificReturn := fake.requestJobReturnsOnCall[len(fake.requestJobArgsForCall)]
+	fake.requestJobArgsForCall = append(fake.requestJobArgsForCall, struct {
+		arg1 types.JobType
+		arg2 *types.Job
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.RequestJobStub
+	fakeReturns := fake.requestJobReturns
+	fake.recordInvocation("RequestJob", []interface{}{arg1, arg2, arg3})
+	fake.requestJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) RequestJobCallCount() int {
+	fake.requestJobMutex.RLock()
+	defer fake.requestJobMutex.RUnlock()
+	return len(fake.requestJobArgsForCall)
+}
+
+func (fake *FakeIStore) RequestJobCalls(stub func(types.JobType, *types.Job, time.Duration) ([]byte, error)) {
+	fake.requestJobMutex.Lock()
+	defer fake.requestJobMutex.Unlock()
+	fake.RequestJobStub = stub
+}
+
+func (fake *FakeIStore) RequestJobArgsForCall(i int) (types.JobType, *types.Job, time.Duration) {
+	fake.requestJobMutex.RLock()
+	defer fake.requestJobMutex.RUnlock()
+	argsForCall := fake.requestJobArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeIStore) RequestJobReturns(result1 []byte, result2 error) {
+	fake.requestJobMutex.Lock()
+	defer fake.requestJobMutex.Unlock()
+	fake.RequestJobStub = nil
+	fake.requestJobReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) RequestJobReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.requestJobMutex.Lock()
+	defer fake.requestJobMutex.Unlock()
+	fake.RequestJobStub = nil
+	if fake.requestJobReturnsOnCall == nil {
+		fake.requestJobReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.requestJobReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) SaveBaseline(arg1 *types.Baseline) error {
+	fake.saveBaselineMutex.Lock()
+	ret, specificReturn := fake.saveBaselineReturnsOnCall[len(fake.saveBaselineArgsForCall)]
+	fake.saveBaselineArgsForCall = append(fake.saveBaselineArgsForCall, struct {
+		arg1 *types.Baseline
+	}{arg1})
+	stub := fake.SaveBaselineStub
+	fakeReturns := fake.saveBaselineReturns
+	fake.recordInvocation("SaveBaseline", []interface{}{arg1})
+	fake.saveBaselineMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) SaveBaselineCallCount() int {
+	fake.saveBaselineMutex.RLock()
+	defer fake.saveBaselineMutex.RUnlock()
+	return len(fake.saveBaselineArgsForCall)
+}
+
+func (fake *FakeIStore) SaveBaselineCalls(stub func(*types.Baseline) error) {
+	fake.saveBaselineMutex.Lock()
+	defer fake.saveBaselineMutex.Unlock()
+	fake.SaveBaselineStub = stub
+}
+
+func (fake *FakeIStore) SaveBaselineArgsForCall(i int) *types.Baseline {
+	fake.saveBaselineMutex.RLock()
+	defer fake.saveBaselineMutex.RUnlock()
+	argsForCall := fake.saveBaselineArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) SaveBaselineReturns(result1 error) {
+	fake.saveBaselineMutex.Lock()
+	defer fake.saveBaselineMutex.Unlock()
+	fake.SaveBaselineStub = nil
+	fake.saveBaselineReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveBaselineReturnsOnCall(i int, result1 error) {
+	fake.saveBaselineMutex.Lock()
+	defer fake.saveBaselineMutex.Unlock()
+	fake.SaveBaselineStub = nil
+	if fake.saveBaselineReturnsOnCall == nil {
+		fake.saveBaselineReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveBaselineReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveScenario(arg1 *types.Scenario) error {
+	fake.saveScenarioMutex.Lock()
+	ret, specificReturn := fake.saveScenarioReturnsOnCall[len(fake.saveScenarioArgsForCall)]
+	fake.saveScenarioArgsForCall = append(fake.saveScenarioArgsForCall, struct {
+		arg1 *types.Scenario
+	}{arg1})
+	stub := fake.SaveScenarioStub
+	fakeReturns := fake.saveScenarioReturns
+	fake.recordInvocation("SaveScenario", []interface{}{arg1})
+	fake.saveScenarioMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) SaveScenarioCallCount() int {
+	fake.saveScenarioMutex.RLock()
+	defer fake.saveScenarioMutex.RUnlock()
+	return len(fake.saveScenarioArgsForCall)
+}
+
+func (fake *FakeIStore) SaveScenarioCalls(stub func(*types.Scenario) error) {
+	fake.saveScenarioMutex.Lock()
+	defer fake.saveScenarioMutex.Unlock()
+	fake.SaveScenarioStub = stub
+}
+
+func (fake *FakeIStore) SaveScenarioArgsForCall(i int) *types.Scenario {
+	fake.saveScenarioMutex.RLock()
+	defer fake.saveScenarioMutex.RUnlock()
+	argsForCall := fake.saveScenarioArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) SaveScenarioReturns(result1 error) {
+	fake.saveScenarioMutex.Lock()
+	defer fake.saveScenarioMutex.Unlock()
+	fake.SaveScenarioStub = nil
+	fake.saveScenarioReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveScenarioReturnsOnCall(i int, result1 error) {
+	fake.saveScenarioMutex.Lock()
+	defer fake.saveScenarioMutex.Unlock()
+	fake.SaveScenarioStub = nil
+	if fake.saveScenarioReturnsOnCall == nil {
+		fake.saveScenarioReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveScenarioReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveSchedule(arg1 *types.Schedule) error {
+	fake.saveScheduleMutex.Lock()
+	ret, specificReturn := fake.saveScheduleReturnsOnCall[len(fake.saveScheduleArgsForCall)]
+	fake.saveScheduleArgsForCall = append(fake.saveScheduleArgsForCall, struct {
+		arg1 *types.Schedule
+	}{arg1})
+	stub := fake.SaveScheduleStub
+	fakeReturns := fake.saveScheduleReturns
+	fake.recordInvocation("SaveSchedule", []interface{}{arg1})
+	fake.saveScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) SaveScheduleCallCount() int {
+	fake.saveScheduleMutex.RLock()
+	defer fake.saveScheduleMutex.RUnlock()
+	return len(fake.saveScheduleArgsForCall)
+}
+
+func (fake *FakeIStore) SaveScheduleCalls(stub func(*types.Schedule) error) {
+	fake.saveScheduleMutex.Lock()
+	defer fake.saveScheduleMutex.Unlock()
+	fake.SaveScheduleStub = stub
+}
+
+func (fake *FakeIStore) SaveScheduleArgsForCall(i int) *types.Schedule {
+	fake.saveScheduleMutex.RLock()
+	defer fake.saveScheduleMutex.RUnlock()
+	argsForCall := fake.saveScheduleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) SaveScheduleReturns(result1 error) {
+	fake.saveScheduleMutex.Lock()
+	defer fake.saveScheduleMutex.Unlock()
+	fake.SaveScheduleStub = nil
+	fake.saveScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveScheduleReturnsOnCall(i int, result1 error) {
+	fake.saveScheduleMutex.Lock()
+	defer fake.saveScheduleMutex.Unlock()
+	fake.SaveScheduleStub = nil
+	if fake.saveScheduleReturnsOnCall == nil {
+		fake.saveScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveSettings(arg1 *types.Settings) error {
+	fake.saveSettingsMutex.Lock()
+	ret, specificReturn := fake.saveSettingsReturnsOnCall[len(fake.saveSettingsArgsForCall)]
+	fake.saveSettingsArgsForCall = append(fake.saveSettingsArgsForCall, struct {
+		arg1 *types.Settings
+	}{arg1})
+	stub := fake.SaveSettingsStub
+	fakeReturns := fake.saveSettingsReturns
+	fake.recordInvocation("SaveSettings", []interface{}{arg1})
+	fake.saveSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) SaveSettingsCallCount() int {
+	fake.saveSettingsMutex.RLock()
+	defer fake.saveSettingsMutex.RUnlock()
+	return len(fake.saveSettingsArgsForCall)
+}
+
+func (fake *FakeIStore) SaveSettingsCalls(stub func(*types.Settings) error) {
+	fake.saveSettingsMutex.Lock()
+	defer fake.saveSettingsMutex.Unlock()
+	fake.SaveSettingsStub = stub
+}
+
+func (fake *FakeIStore) SaveSettingsArgsForCall(i int) *types.Settings {
+	fake.saveSettingsMutex.RLock()
+	defer fake.saveSettingsMutex.RUnlock()
+	argsForCall := fake.saveSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) SaveSettingsReturns(result1 error) {
+	fake.saveSettingsMutex.Lock()
+	defer fake.saveSettingsMutex.Unlock()
+	fake.SaveSettingsStub = nil
+	fake.saveSettingsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveSettingsReturnsOnCall(i int, result1 error) {
+	fake.saveSettingsMutex.Lock()
+	defer fake.saveSettingsMutex.Unlock()
+	fake.SaveSettingsStub = nil
+	if fake.saveSettingsReturnsOnCall == nil {
+		fake.saveSettingsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveSettingsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveSweep(arg1 *types.Sweep) error {
+	fake.saveSweepMutex.Lock()
+	ret, specificReturn := fake.saveSweepReturnsOnCall[len(fake.saveSweepArgsForCall)]
+	fake.saveSweepArgsForCall = append(fake.saveSweepArgsForCall, struct {
+		arg1 *types.Sweep
+	}{arg1})
+	stub := fake.SaveSweepStub
+	fakeReturns := fake.saveSweepReturns
+	fake.recordInvocation("SaveSweep", []interface{}{arg1})
+	fake.saveSweepMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) SaveSweepCallCount() int {
+	fake.saveSweepMutex.RLock()
+	defer fake.saveSweepMutex.RUnlock()
+	return len(fake.saveSweepArgsForCall)
+}
+
+func (fake *FakeIStore) SaveSweepCalls(stub func(*types.Sweep) error) {
+	fake.saveSweepMutex.Lock()
+	defer fake.saveSweepMutex.Unlock()
+	fake.SaveSweepStub = stub
+}
+
+func (fake *FakeIStore) SaveSweepArgsForCall(i int) *types.Sweep {
+	fake.saveSweepMutex.RLock()
+	defer fake.saveSweepMutex.RUnlock()
+	argsForCall := fake.saveSweepArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) SaveSweepReturns(result1 error) {
+	fake.saveSweepMutex.Lock()
+	defer fake.saveSweepMutex.Unlock()
+	fake.SaveSweepStub = nil
+	fake.saveSweepReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) SaveSweepReturnsOnCall(i int, result1 error) {
+	fake.saveSweepMutex.Lock()
+	defer fake.saveSweepMutex.Unlock()
+	fake.SaveSweepStub = nil
+	if fake.saveSweepReturnsOnCall == nil {
+		fake.saveSweepReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveSweepReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateSchedule(arg1 *types.Schedule) error {
+	fake.updateScheduleMutex.Lock()
+	ret, specificReturn := fake.updateScheduleReturnsOnCall[len(fake.updateScheduleArgsForCall)]
+	fake.updateScheduleArgsForCall = append(fake.updateScheduleArgsForCall, struct {
+		arg1 *types.Schedule
+	}{arg1})
+	stub := fake.UpdateScheduleStub
+	fakeReturns := fake.updateScheduleReturns
+	fake.recordInvocation("UpdateSchedule", []interface{}{arg1})
+	fake.updateScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) UpdateScheduleCallCount() int {
+	fake.updateScheduleMutex.RLock()
+	defer fake.updateScheduleMutex.RUnlock()
+	return len(fake.updateScheduleArgsForCall)
+}
+
+func (fake *FakeIStore) UpdateScheduleCalls(stub func(*types.Schedule) error) {
+	fake.updateScheduleMutex.Lock()
+	defer fake.updateScheduleMutex.Unlock()
+	fake.UpdateScheduleStub = stub
+}
+
+func (fake *FakeIStore) UpdateScheduleArgsForCall(i int) *types.Schedule {
+	fake.updateScheduleMutex.RLock()
+	defer fake.updateScheduleMutex.RUnlock()
+	argsForCall := fake.updateScheduleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) UpdateScheduleReturns(result1 error) {
+	fake.updateScheduleMutex.Lock()
+	defer fake.updateScheduleMutex.Unlock()
+	fake.UpdateScheduleStub = nil
+	fake.updateScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateScheduleReturnsOnCall(i int, result1 error) {
+	fake.updateScheduleMutex.Lock()
+	defer fake.updateScheduleMutex.Unlock()
+	fake.UpdateScheduleStub = nil
+	if fake.updateScheduleReturnsOnCall == nil {
+		fake.updateScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateSettings(arg1 *types.Settings) error {
+	fake.updateSettingsMutex.Lock()
+	ret, specificReturn := fake.updateSettingsReturnsOnCall[len(fake.updateSettingsArgsForCall)]
+	fake.updateSettingsArgsForCall = append(fake.updateSettingsArgsForCall, struct {
+		arg1 *types.Settings
+	}{arg1})
+	stub := fake.UpdateSettingsStub
+	fakeReturns := fake.updateSettingsReturns
+	fake.recordInvocation("UpdateSettings", []interface{}{arg1})
+	fake.updateSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) UpdateSettingsCallCount() int {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	return len(fake.updateSettingsArgsForCall)
+}
+
+func (fake *FakeIStore) UpdateSettingsCalls(stub func(*types.Settings) error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = stub
+}
+
+func (fake *FakeIStore) UpdateSettingsArgsForCall(i int) *types.Settings {
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	argsForCall := fake.updateSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) UpdateSettingsReturns(result1 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	fake.updateSettingsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateSettingsReturnsOnCall(i int, result1 error) {
+	fake.updateSettingsMutex.Lock()
+	defer fake.updateSettingsMutex.Unlock()
+	fake.UpdateSettingsStub = nil
+	if fake.updateSettingsReturnsOnCall == nil {
+		fake.updateSettingsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateSettingsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateStatus(arg1 *types.Status) error {
+	fake.updateStatusMutex.Lock()
+	ret, specificReturn := fake.updateStatusReturnsOnCall[len(fake.updateStatusArgsForCall)]
+	fake.updateStatusArgsForCall = append(fake.updateStatusArgsForCall, struct {
+		arg1 *types.Status
+	}{arg1})
+	stub := fake.UpdateStatusStub
+	fakeReturns := fake.updateStatusReturns
+	fake.recordInvocation("UpdateStatus", []interface{}{arg1})
+	fake.updateStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) UpdateStatusCallCount() int {
+	fake.updateStatusMutex.RLock()
+	defer fake.updateStatusMutex.RUnlock()
+	return len(fake.updateStatusArgsForCall)
+}
+
+func (fake *FakeIStore) UpdateStatusCalls(stub func(*types.Status) error) {
+	fake.updateStatusMutex.Lock()
+	defer fake.updateStatusMutex.Unlock()
+	fake.UpdateStatusStub = stub
+}
+
+func (fake *FakeIStore) UpdateStatusArgsForCall(i int) *types.Status {
+	fake.updateStatusMutex.RLock()
+	defer fake.updateStatusMutex.RUnlock()
+	argsForCall := fake.updateStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) UpdateStatusReturns(result1 error) {
+	fake.updateStatusMutex.Lock()
+	defer fake.updateStatusMutex.Unlock()
+	fake.UpdateStatusStub = nil
+	fake.updateStatusReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) UpdateStatusReturnsOnCall(i int, result1 error) {
+	fake.updateStatusMutex.Lock()
+	defer fake.updateStatusMutex.Unlock()
+	fake.UpdateStatusStub = nil
+	if fake.updateStatusReturnsOnCall == nil {
+		fake.updateStatusReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateStatusReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) WatchResults(arg1 context.Context, arg2 string) (<-chan *types.Status, error) {
+	fake.watchResultsMutex.Lock()
+	ret, specificReturn := fake.watchResultsReturnsOnCall[len(fake.watchResultsArgsForCall)]
+	fake.watchResultsArgsForCall = append(fake.watchResultsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.WatchResultsStub
+	fakeReturns := fake.watchResultsReturns
+	fake.recordInvocation("WatchResults", []interface{}{arg1, arg2})
+	fake.watchResultsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeIStore) WatchResultsCallCount() int {
+	fake.watchResultsMutex.RLock()
+	defer fake.watchResultsMutex.RUnlock()
+	return len(fake.watchResultsArgsForCall)
+}
+
+func (fake *FakeIStore) WatchResultsCalls(stub func(context.Context, string) (<-chan *types.Status, error)) {
+	fake.watchResultsMutex.Lock()
+	defer fake.watchResultsMutex.Unlock()
+	fake.WatchResultsStub = stub
+}
+
+func (fake *FakeIStore) WatchResultsArgsForCall(i int) (context.Context, string) {
+	fake.watchResultsMutex.RLock()
+	defer fake.watchResultsMutex.RUnlock()
+	argsForCall := fake.watchResultsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeIStore) WatchResultsReturns(result1 <-chan *types.Status, result2 error) {
+	fake.watchResultsMutex.Lock()
+	defer fake.watchResultsMutex.Unlock()
+	fake.WatchResultsStub = nil
+	fake.watchResultsReturns = struct {
+		result1 <-chan *types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) WatchResultsReturnsOnCall(i int, result1 <-chan *types.Status, result2 error) {
+	fake.watchResultsMutex.Lock()
+	defer fake.watchResultsMutex.Unlock()
+	fake.WatchResultsStub = nil
+	if fake.watchResultsReturnsOnCall == nil {
+		fake.watchResultsReturnsOnCall = make(map[int]struct {
+			result1 <-chan *types.Status
+			result2 error
+		})
+	}
+	fake.watchResultsReturnsOnCall[i] = struct {
+		result1 <-chan *types.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIStore) WriteStatus(arg1 *types.Status) error {
+	fake.writeStatusMutex.Lock()
+	ret, specificReturn := fake.writeStatusReturnsOnCall[len(fake.writeStatusArgsForCall)]
+	fake.writeStatusArgsForCall = append(fake.writeStatusArgsForCall, struct {
+		arg1 *types.Status
+	}{arg1})
+	stub := fake.WriteStatusStub
+	fakeReturns := fake.writeStatusReturns
+	fake.recordInvocation("WriteStatus", []interface{}{arg1})
+	fake.writeStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) WriteStatusCallCount() int {
+	fake.writeStatusMutex.RLock()
+	defer fake.writeStatusMutex.RUnlock()
+	return len(fake.writeStatusArgsForCall)
+}
+
+func (fake *FakeIStore) WriteStatusCalls(stub func(*types.Status) error) {
+	fake.writeStatusMutex.Lock()
+	defer fake.writeStatusMutex.Unlock()
+	fake.WriteStatusStub = stub
+}
+
+func (fake *FakeIStore) WriteStatusArgsForCall(i int) *types.Status {
+	fake.writeStatusMutex.RLock()
+	defer fake.writeStatusMutex.RUnlock()
+	argsForCall := fake.writeStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) WriteStatusReturns(result1 error) {
+	fake.writeStatusMutex.Lock()
+	defer fake.writeStatusMutex.Unlock()
+	fake.WriteStatusStub = nil
+	fake.writeStatusReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) WriteStatusReturnsOnCall(i int, result1 error) {
+	fake.writeStatusMutex.Lock()
+	defer fake.writeStatusMutex.Unlock()
+	fake.WriteStatusStub = nil
+	if fake.writeStatusReturnsOnCall == nil {
+		fake.writeStatusReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeStatusReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) WriteTimelineSample(arg1 *types.TimelineSample) error {
+	fake.writeTimelineSampleMutex.Lock()
+	ret, specificReturn := fake.writeTimelineSampleReturnsOnCall[len(fake.writeTimelineSampleArgsForCall)]
+	fake.writeTimelineSampleArgsForCall = append(fake.writeTimelineSampleArgsForCall, struct {
+		arg1 *types.TimelineSample
+	}{arg1})
+	stub := fake.WriteTimelineSampleStub
+	fakeReturns := fake.writeTimelineSampleReturns
+	fake.recordInvocation("WriteTimelineSample", []interface{}{arg1})
+	fake.writeTimelineSampleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeIStore) WriteTimelineSampleCallCount() int {
+	fake.writeTimelineSampleMutex.RLock()
+	defer fake.writeTimelineSampleMutex.RUnlock()
+	return len(fake.writeTimelineSampleArgsForCall)
+}
+
+func (fake *FakeIStore) WriteTimelineSampleCalls(stub func(*types.TimelineSample) error) {
+	fake.writeTimelineSampleMutex.Lock()
+	defer fake.writeTimelineSampleMutex.Unlock()
+	fake.WriteTimelineSampleStub = stub
+}
+
+func (fake *FakeIStore) WriteTimelineSampleArgsForCall(i int) *types.TimelineSample {
+	fake.writeTimelineSampleMutex.RLock()
+	defer fake.writeTimelineSampleMutex.RUnlock()
+	argsForCall := fake.writeTimelineSampleArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeIStore) WriteTimelineSampleReturns(result1 error) {
+	fake.writeTimelineSampleMutex.Lock()
+	defer fake.writeTimelineSampleMutex.Unlock()
+	fake.WriteTimelineSampleStub = nil
+	fake.writeTimelineSampleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) WriteTimelineSampleReturnsOnCall(i int, result1 error) {
+	fake.writeTimelineSampleMutex.Lock()
+	defer fake.writeTimelineSampleMutex.Unlock()
+	fake.WriteTimelineSampleStub = nil
+	if fake.writeTimelineSampleReturnsOnCall == nil {
+		fake.writeTimelineSampleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeTimelineSampleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.acquireLockMutex.RLock()
+	defer fake.acquireLockMutex.RUnlock()
+	fake.archiveRunMutex.RLock()
+	defer fake.archiveRunMutex.RUnlock()
+	fake.createResultsMutex.RLock()
+	defer fake.createResultsMutex.RUnlock()
+	fake.deleteArchivedRunMutex.RLock()
+	defer fake.deleteArchivedRunMutex.RUnlock()
+	fake.deleteBaselineMutex.RLock()
+	defer fake.deleteBaselineMutex.RUnlock()
+	fake.deleteResultsMutex.RLock()
+	defer fake.deleteResultsMutex.RUnlock()
+	fake.deleteScenarioMutex.RLock()
+	defer fake.deleteScenarioMutex.RUnlock()
+	fake.deleteScheduleMutex.RLock()
+	defer fake.deleteScheduleMutex.RUnlock()
+	fake.deleteSettingsMutex.RLock()
+	defer fake.deleteSettingsMutex.RUnlock()
+	fake.deleteSweepMutex.RLock()
+	defer fake.deleteSweepMutex.RUnlock()
+	fake.deleteTimelineMutex.RLock()
+	defer fake.deleteTimelineMutex.RUnlock()
+	fake.emitJobsMutex.RLock()
+	defer fake.emitJobsMutex.RUnlock()
+	fake.getAllBaselinesMutex.RLock()
+	defer fake.getAllBaselinesMutex.RUnlock()
+	fake.getAllScenariosMutex.RLock()
+	defer fake.getAllScenariosMutex.RUnlock()
+	fake.getAllSchedulesMutex.RLock()
+	defer fake.getAllSchedulesMutex.RUnlock()
+	fake.getAllSettingsMutex.RLock()
+	defer fake.getAllSettingsMutex.RUnlock()
+	fake.getAllSweepsMutex.RLock()
+	defer fake.getAllSweepsMutex.RUnlock()
+	fake.getArchivedRunMutex.RLock()
+	defer fake.getArchivedRunMutex.RUnlock()
+	fake.getArchivedRunsMutex.RLock()
+	defer fake.getArchivedRunsMutex.RUnlock()
+	fake.getBaselineMutex.RLock()
+	defer fake.getBaselineMutex.RUnlock()
+	fake.getLockMutex.RLock()
+	defer fake.getLockMutex.RUnlock()
+	fake.getNodeMutex.RLock()
+	defer fake.getNodeMutex.RUnlock()
+	fake.getNodeListMutex.RLock()
+	defer fake.getNodeListMutex.RUnlock()
+	fake.getNodesMutex.RLock()
+	defer fake.getNodesMutex.RUnlock()
+	fake.getScenarioMutex.RLock()
+	defer fake.getScenarioMutex.RUnlock()
+	fake.getScheduleMutex.RLock()
+	defer fake.getScheduleMutex.RUnlock()
+	fake.getSettingsMutex.RLock()
+	defer fake.getSettingsMutex.RUnlock()
+	fake.getStatusMutex.RLock()
+	defer fake.getStatusMutex.RUnlock()
+	fake.getStatusesMutex.RLock()
+	defer fake.getStatusesMutex.RUnlock()
+	fake.getSweepMutex.RLock()
+	defer fake.getSweepMutex.RUnlock()
+	fake.getTimelineSamplesMutex.RLock()
+	defer fake.getTimelineSamplesMutex.RUnlock()
+	fake.purgeHistoryMutex.RLock()
+	defer fake.purgeHistoryMutex.RUnlock()
+	fake.releaseLockMutex.RLock()
+	defer fake.releaseLockMutex.RUnlock()
+	fake.requestJobMutex.RLock()
+	defer fake.requestJobMutex.RUnlock()
+	fake.saveBaselineMutex.RLock()
+	defer fake.saveBaselineMutex.RUnlock()
+	fake.saveScenarioMutex.RLock()
+	defer fake.saveScenarioMutex.RUnlock()
+	fake.saveScheduleMutex.RLock()
+	defer fake.saveScheduleMutex.RUnlock()
+	fake.saveSettingsMutex.RLock()
+	defer fake.saveSettingsMutex.RUnlock()
+	fake.saveSweepMutex.RLock()
+	defer fake.saveSweepMutex.RUnlock()
+	fake.updateScheduleMutex.RLock()
+	defer fake.updateScheduleMutex.RUnlock()
+	fake.updateSettingsMutex.RLock()
+	defer fake.updateSettingsMutex.RUnlock()
+	fake.updateStatusMutex.RLock()
+	defer fake.updateStatusMutex.RUnlock()
+	fake.watchResultsMutex.RLock()
+	defer fake.watchResultsMutex.RUnlock()
+	fake.writeStatusMutex.RLock()
+	defer fake.writeStatusMutex.RUnlock()
+	fake.writeTimelineSampleMutex.RLock()
+	defer fake.writeTimelineSampleMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeIStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ natssvc.IStore = new(FakeIStore)
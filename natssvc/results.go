@@ -0,0 +1,171 @@
+package natssvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// CreateResults creates the results bucket for a job
+func (n *NATSService) CreateResults(jobID string) error {
+	if _, err := n.GetOrCreateBucket(ResultBucketPrefix, jobID); err != nil {
+		return errors.Wrapf(err, "unable to create results bucket for job '%s'", jobID)
+	}
+
+	return nil
+}
+
+// GetStatuses returns the latest status reported by every node that
+// participates in the job
+func (n *NATSService) GetStatuses(jobID string) ([]*types.Status, error) {
+	bucketName := fmt.Sprintf("%s-%s", ResultBucketPrefix, jobID)
+
+	bucket, err := n.GetBucket(bucketName)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get bucket")
+	}
+
+	statuses := make([]*types.Status, 0)
+
+	keys, err := bucket.Keys()
+	if err != nil {
+		if err == nats.ErrNoKeysFound {
+			// No node has reported yet
+			return statuses, nil
+		}
+
+		return nil, errors.Wrap(err, "unable to get keys")
+	}
+
+	for _, key := range uniqueKeys(keys) {
+		n.log.Debugf("looking up results in bucket '%s', object '%s'", bucketName, key)
+
+		entry, err := bucket.Get(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to get k/v entry")
+		}
+
+		s := &types.Status{}
+
+		if err := json.Unmarshal(entry.Value(), s); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal status")
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	return statuses, nil
+}
+
+// GetStatus returns the latest status reported by a node; the error wraps
+// nats.ErrKeyNotFound if the node has not reported yet
+func (n *NATSService) GetStatus(jobID, nodeID string) (*types.Status, error) {
+	bucket, err := n.GetBucket(fmt.Sprintf("%s-%s", ResultBucketPrefix, jobID))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get bucket")
+	}
+
+	entry, err := bucket.Get(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get status of node '%s'", nodeID)
+	}
+
+	status := &types.Status{}
+
+	if err := json.Unmarshal(entry.Value(), status); err != nil {
+		return nil, errors.Wrap(err, "unable to unmarshal status")
+	}
+
+	status.Revision = entry.Revision()
+
+	return status, nil
+}
+
+// UpdateStatus writes a node's status only if the node's status in the
+// results bucket is still at status.Revision (0: the node has not reported
+// yet); status.Revision is set to the new revision
+func (n *NATSService) UpdateStatus(status *types.Status) error {
+	if status == nil {
+		return errors.New("status cannot be nil")
+	}
+
+	if n.params.Standalone {
+		return nil
+	}
+
+	bucket, err := n.GetOrCreateBucket(ResultBucketPrefix, status.JobID)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get bucket for job '%s'", status.JobID)
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal status for job '%s'", status.JobID)
+	}
+
+	revision, err := bucket.Update(status.NodeID, data, status.Revision)
+	if err != nil {
+		return errors.Wrapf(err, "unable to update status for job '%s'", status.JobID)
+	}
+
+	status.Revision = revision
+
+	return nil
+}
+
+// WatchResults streams every status written to the results bucket of a job,
+// starting with the latest status of each node. The returned channel is
+// closed once ctx is done.
+func (n *NATSService) WatchResults(ctx context.Context, jobID string) (<-chan *types.Status, error) {
+	bucket, err := n.GetBucket(fmt.Sprintf("%s-%s", ResultBucketPrefix, jobID))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get bucket")
+	}
+
+	watcher, err := bucket.WatchAll(nats.IgnoreDeletes())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to watch bucket")
+	}
+
+	statusCh := make(chan *types.Status)
+
+	go func() {
+		defer close(statusCh)
+		defer watcher.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+
+				// nil marks the end of the initial values
+				if entry == nil {
+					continue
+				}
+
+				status := &types.Status{}
+
+				if err := json.Unmarshal(entry.Value(), status); err != nil {
+					n.log.Errorf("unable to unmarshal status '%s' for job '%s': %s", entry.Key(), jobID, err)
+					continue
+				}
+
+				select {
+				case statusCh <- status:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return statusCh, nil
+}
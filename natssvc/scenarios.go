@@ -0,0 +1,52 @@
+package natssvc
+
+import (
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+func (n *NATSService) SaveScenario(scenario *types.Scenario) error {
+	if _, err := n.putJSON(ScenariosBucket, scenario.ID, scenario); err != nil {
+		return errors.Wrap(err, "unable to save scenario")
+	}
+
+	return nil
+}
+
+func (n *NATSService) GetScenario(id string) (*types.Scenario, error) {
+	scenario := &types.Scenario{}
+
+	if _, err := n.getJSON(ScenariosBucket, id, scenario); err != nil {
+		return nil, errors.Wrapf(err, "unable to get scenario for id '%s'", id)
+	}
+
+	return scenario, nil
+}
+
+func (n *NATSService) GetAllScenarios() ([]*types.Scenario, error) {
+	keys, err := n.keys(ScenariosBucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get scenario keys")
+	}
+
+	scenarios := make([]*types.Scenario, 0)
+
+	for _, key := range keys {
+		scenario, err := n.GetScenario(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get scenario for key '%s'", key)
+		}
+
+		scenarios = append(scenarios, scenario)
+	}
+
+	return scenarios, nil
+}
+
+func (n *NATSService) DeleteScenario(id string) error {
+	if err := n.buckets[ScenariosBucket].Delete(id); err != nil {
+		return errors.Wrapf(err, "unable to delete scenario '%s'", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package natssvc
+
+import (
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+// SaveSchedule unconditionally writes the schedule to the schedules bucket
+func (n *NATSService) SaveSchedule(schedule *types.Schedule) error {
+	revision, err := n.putJSON(SchedulesBucket, schedule.ID, schedule)
+	if err != nil {
+		return errors.Wrap(err, "unable to save schedule")
+	}
+
+	schedule.Revision = revision
+
+	return nil
+}
+
+// UpdateSchedule writes the schedule only if it has not been modified since
+// it was read (ie. schedule.Revision is still the latest revision). This is
+// how njst nodes decide which one of them gets to fire a schedule.
+func (n *NATSService) UpdateSchedule(schedule *types.Schedule) error {
+	revision, err := n.updateJSON(SchedulesBucket, schedule.ID, schedule, schedule.Revision)
+	if err != nil {
+		return errors.Wrapf(err, "unable to update schedule '%s'", schedule.ID)
+	}
+
+	schedule.Revision = revision
+
+	return nil
+}
+
+func (n *NATSService) GetSchedule(id string) (*types.Schedule, error) {
+	schedule := &types.Schedule{}
+
+	revision, err := n.getJSON(SchedulesBucket, id, schedule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get schedule for id '%s'", id)
+	}
+
+	schedule.Revision = revision
+
+	return schedule, nil
+}
+
+func (n *NATSService) GetAllSchedules() ([]*types.Schedule, error) {
+	keys, err := n.keys(SchedulesBucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get schedule keys")
+	}
+
+	schedules := make([]*types.Schedule, 0)
+
+	for _, key := range keys {
+		schedule, err := n.GetSchedule(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get schedule for key '%s'", key)
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+func (n *NATSService) DeleteSchedule(id string) error {
+	if err := n.buckets[SchedulesBucket].Delete(id); err != nil {
+		return errors.Wrapf(err, "unable to delete schedule '%s'", id)
+	}
+
+	return nil
+}
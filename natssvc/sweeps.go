@@ -0,0 +1,52 @@
+package natssvc
+
+import (
+	"github.com/batchcorp/njst/types"
+	"github.com/pkg/errors"
+)
+
+func (n *NATSService) SaveSweep(sweep *types.Sweep) error {
+	if _, err := n.putJSON(SweepsBucket, sweep.ID, sweep); err != nil {
+		return errors.Wrap(err, "unable to save sweep")
+	}
+
+	return nil
+}
+
+func (n *NATSService) GetSweep(id string) (*types.Sweep, error) {
+	sweep := &types.Sweep{}
+
+	if _, err := n.getJSON(SweepsBucket, id, sweep); err != nil {
+		return nil, errors.Wrapf(err, "unable to get sweep for id '%s'", id)
+	}
+
+	return sweep, nil
+}
+
+func (n *NATSService) GetAllSweeps() ([]*types.Sweep, error) {
+	keys, err := n.keys(SweepsBucket)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get sweep keys")
+	}
+
+	sweeps := make([]*types.Sweep, 0)
+
+	for _, key := range keys {
+		sweep, err := n.GetSweep(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to get sweep for key '%s'", key)
+		}
+
+		sweeps = append(sweeps, sweep)
+	}
+
+	return sweeps, nil
+}
+
+func (n *NATSService) DeleteSweep(id string) error {
+	if err := n.buckets[SweepsBucket].Delete(id); err != nil {
+		return errors.Wrapf(err, "unable to delete sweep '%s'", id)
+	}
+
+	return nil
+}
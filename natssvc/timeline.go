@@ -0,0 +1,147 @@
+package natssvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/batchcorp/njst/types"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	TimelineStream = "njst-timeline"
+	TimelineMaxAge = 7 * 24 * time.Hour
+
+	// How long to wait for the next timeline sample when reading a timeline
+	TimelineReadTimeout = time.Second
+)
+
+func timelineSubject(jobID, nodeID string) string {
+	return fmt.Sprintf("%s.%s.%s", TimelineStream, jobID, nodeID)
+}
+
+// ensureTimelineStream creates the stream that holds timeline samples for all
+// jobs if it does not exist yet
+func ensureTimelineStream(js nats.JetStreamContext) error {
+	if _, err := js.StreamInfo(TimelineStream); err == nil {
+		return nil
+	} else if err != nats.ErrStreamNotFound {
+		return errors.Wrapf(err, "unable to determine stream '%s' status", TimelineStream)
+	}
+
+	if _, err := js.AddStream(&nats.StreamConfig{
+		Name:        TimelineStream,
+		Description: "Per-interval job samples",
+		Subjects:    []string{TimelineStream + ".>"},
+		MaxAge:      TimelineMaxAge,
+		Storage:     nats.FileStorage,
+	}); err != nil {
+		return errors.Wrapf(err, "unable to create stream '%s'", TimelineStream)
+	}
+
+	return nil
+}
+
+// WriteTimelineSample publishes a sample to the timeline stream; it is a no-op
+// in standalone mode
+func (n *NATSService) WriteTimelineSample(sample *types.TimelineSample) error {
+	if n.params.Standalone {
+		return nil
+	}
+
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal timeline sample")
+	}
+
+	if _, err := n.js.Publish(timelineSubject(sample.JobID, sample.NodeID), data); err != nil {
+		return errors.Wrap(err, "unable to publish timeline sample")
+	}
+
+	return nil
+}
+
+// GetTimelineSamples returns the samples of all nodes for a job in the order
+// they were written
+func (n *NATSService) GetTimelineSamples(jobID string) ([]*types.TimelineSample, error) {
+	sub, err := n.js.SubscribeSync(timelineSubject(jobID, "*"), nats.OrderedConsumer(), nats.DeliverAll())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to subscribe to timeline")
+	}
+
+	defer sub.Unsubscribe()
+
+	samples := make([]*types.TimelineSample, 0)
+
+	for {
+		msg, err := sub.NextMsg(TimelineReadTimeout)
+		if err != nil {
+			if err == nats.ErrTimeout {
+				// No (more) samples
+				return samples, nil
+			}
+
+			return nil, errors.Wrap(err, "unable to read timeline sample")
+		}
+
+		sample := &types.TimelineSample{}
+
+		if err := json.Unmarshal(msg.Data, sample); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal timeline sample")
+		}
+
+		samples = append(samples, sample)
+
+		meta, err := msg.Metadata()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to get timeline sample metadata")
+		}
+
+		if meta.NumPending == 0 {
+			return samples, nil
+		}
+	}
+}
+
+// DeleteTimeline purges all samples of a job from the timeline stream
+func (n *NATSService) DeleteTimeline(jobID string) error {
+	if err := n.purgeSubject(TimelineStream, timelineSubject(jobID, ">")); err != nil {
+		return errors.Wrapf(err, "unable to purge timeline for job '%s'", jobID)
+	}
+
+	return nil
+}
+
+// purgeSubject removes the messages of a stream matching a subject
+func (n *NATSService) purgeSubject(stream, subject string) error {
+	// nats.go does not expose purging by subject yet; use the JS API directly
+	req, err := json.Marshal(map[string]string{
+		"filter": subject,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal purge request")
+	}
+
+	resp, err := n.conn.Request("$JS.API.STREAM.PURGE."+stream, req, 5*time.Second)
+	if err != nil {
+		return errors.Wrap(err, "unable to send purge request")
+	}
+
+	purgeResp := &struct {
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(resp.Data, purgeResp); err != nil {
+		return errors.Wrap(err, "unable to unmarshal purge response")
+	}
+
+	if purgeResp.Error != nil {
+		return errors.New(purgeResp.Error.Description)
+	}
+
+	return nil
+}
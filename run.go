@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/batchcorp/njst/bench"
+	"github.com/batchcorp/njst/cli"
+	"github.com/batchcorp/njst/httpsvc"
+	"github.com/batchcorp/njst/natssvc"
+	"github.com/batchcorp/njst/types"
+)
+
+// runStandalone runs a job spec in this process against the spec's NATS
+// server, without any of the cluster coordination (buckets, heartbeats,
+// subscriptions, HTTP server)
+func runStandalone(params *cli.Params, specFile string) error {
+	spec, err := readSpec(specFile)
+	if err != nil {
+		return err
+	}
+
+	settings := &types.Settings{}
+
+	if err := json.Unmarshal(spec, settings); err != nil {
+		return errors.Wrap(err, "unable to unmarshal spec")
+	}
+
+	if settings.NATS == nil {
+		settings.NATS = &types.NATS{}
+	}
+
+	if settings.NATS.Address == "" {
+		settings.NATS.Address = params.NATSAddress[0]
+	}
+
+	if err := httpsvc.ValidateSettings(settings); err != nil {
+		return errors.Wrap(err, "unable to validate spec")
+	}
+
+	// Streams and consumers are managed on the target server
+	params.NATSAddress = []string{settings.NATS.Address}
+	params.Standalone = true
+	params.Version = VERSION
+
+	n, err := natssvc.New(params)
+	if err != nil {
+		return errors.Wrap(err, "unable to setup NATS service")
+	}
+
+	b, err := bench.New(params, n)
+	if err != nil {
+		return errors.Wrap(err, "unable to setup benchmark service")
+	}
+
+	// ^C cancels the job; the (partial) report is still printed
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	logrus.Infof("Running job against %s", settings.NATS.Address)
+
+	status, err := b.RunStandalone(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	printStatus(os.Stdout, &types.StatusResponse{
+		Status:   status,
+		Settings: settings,
+	})
+
+	if settings.Write != nil && settings.Write.KeepStreams {
+		logrus.Infof("Streams were kept; read them with write_id '%s'", settings.ID)
+	}
+
+	return jobFailure(status)
+}
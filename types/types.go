@@ -11,6 +11,19 @@ const (
 	CompletedStatus  JobStatus = "completed"
 	CancelledStatus  JobStatus = "cancelled"
 
+	// Node statuses written by the watchdog (or by a node that ran out of
+	// time); a job with lost or timed out nodes ends up failed
+	LostStatus     JobStatus = "lost"
+	TimedOutStatus JobStatus = "timed-out"
+	FailedStatus   JobStatus = "failed"
+
+	// Node status written by the watchdog for a lost node whose remaining
+	// share was handed to another node; see Settings.ReassignOnFailure
+	ReassignedStatus JobStatus = "reassigned"
+
+	// Reported in job summaries for jobs that have no results
+	UnknownStatus JobStatus = "unknown"
+
 	CreateJob JobType = "create"
 	DeleteJob JobType = "delete"
 )
@@ -23,8 +36,106 @@ type Settings struct {
 	Write       *WriteSettings `json:"write,omitempty"`
 	Read        *ReadSettings  `json:"read,omitempty"`
 
+	// Jobs with the same profile are compared against the profile's baseline
+	Profile string `json:"profile,omitempty"`
+
+	// Who the job was run by or for; unlike Job.CreatedBy (the coordinating
+	// node), this is set by whoever creates the job
+	Owner string `json:"owner,omitempty"`
+
+	// Key/value pairs jobs can be listed by (ex: "eval": "nats-2.10")
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Free-form notes kept with the results (ex: "nats_version": "2.10.1",
+	// "ticket": "OPS-1234"); not used for filtering
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Assertions evaluated once the job is final; see Status.Verdict
+	Expect *Expect `json:"expect,omitempty"`
+
+	// Maximum time the job may run for (ex: "30m"); nodes stop their part
+	// of the job once it is exceeded
+	MaxDuration string `json:"max_duration,omitempty"`
+
+	// Hand the remaining share of a node that stops heartbeating to a node
+	// that is not participating in the job
+	ReassignOnFailure bool `json:"reassign_on_failure,omitempty"`
+
+	// Run the job alone in the njst cluster: it holds the cluster lock (see
+	// ClusterLock) and starts once no other job is running anywhere
+	Exclusive bool `json:"exclusive,omitempty"`
+
+	// What to do if the job conflicts with the cluster lock: "reject" (default)
+	// or "queue" to wait until the lock is free
+	OnConflict ConflictPolicy `json:"on_conflict,omitempty"`
+
+	// Set by the watchdog every time a lost node's share is reassigned
+	Reassignments []*Reassignment `json:"reassignments,omitempty"`
+
 	// Set by handler
 	ID string `json:"id,omitempty"`
+
+	// Set by the scheduler for jobs created by a schedule
+	ScheduleID string `json:"schedule_id,omitempty"`
+
+	// Set by bench.Create; IDs of the nodes the job was emitted to
+	Participants []string `json:"participants,omitempty"`
+
+	// Set by bench.Create
+	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// Revision of the settings in the settings bucket
+	Revision uint64 `json:"-"`
+}
+
+type ConflictPolicy string
+
+const (
+	RejectConflictPolicy ConflictPolicy = "reject"
+	QueueConflictPolicy  ConflictPolicy = "queue"
+)
+
+// ClusterLock is held by an exclusive job from the time it is created (or
+// claimed by one of its nodes) until it is final; no other job starts
+// anywhere in the njst cluster while it is held. Stored in the locks bucket.
+type ClusterLock struct {
+	JobID      string    `json:"job_id"`
+	NodeID     string    `json:"node_id"` // node that acquired the lock
+	AcquiredAt time.Time `json:"acquired_at"`
+
+	// Set by natssvc when reading the lock; used to release it
+	Revision uint64 `json:"-"`
+}
+
+// Reassignment records that the remaining share of a lost node was handed
+// to another node; Plan is the share the other node was given
+type Reassignment struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+	Plan *Plan     `json:"plan"`
+}
+
+// Expect holds SLO assertions for a job. Unset (nil) assertions are not
+// evaluated; pointers are used so that 0 is a valid limit.
+type Expect struct {
+	MinTotalMsgsPerSec *float64 `json:"min_total_msgs_per_sec,omitempty"`
+	MaxErrorRate       *float64 `json:"max_error_rate,omitempty"` // percent of failed operations
+	MaxP99LatencyMs    *float64 `json:"max_p99_latency_ms,omitempty"`
+	MaxElapsedSeconds  *float64 `json:"max_elapsed_seconds,omitempty"`
+}
+
+// Verdict is the outcome of evaluating Settings.Expect against a final status
+type Verdict struct {
+	Passed   bool                `json:"passed"`
+	Failures []*AssertionFailure `json:"failures,omitempty"`
+}
+
+type AssertionFailure struct {
+	Assertion string      `json:"assertion"`
+	Expected  interface{} `json:"expected"`
+	Actual    interface{} `json:"actual"`
+	Message   string      `json:"message"`
 }
 
 type NATS struct {
@@ -43,9 +154,11 @@ type WriteSettings struct {
 	MsgSizeBytes         int         `json:"msg_size_bytes"`
 	KeepStreams          bool        `json:"keep_streams"`
 	Storage              StorageType `json:"storage"`
+	Verify               bool        `json:"verify"` // stamp payloads so that read jobs can verify them
 
 	// Filled out by bench.GenerateCreateJobs
 	Streams []string `json:"streams,omitempty"`
+	Plan    *Plan    `json:"plan,omitempty"`
 }
 
 const (
@@ -67,9 +180,82 @@ type ReadSettings struct {
 	Subjects             []string `json:"subjects"`
 	BatchSize            int      `json:"batch_size"`
 	Strategy             string   `json:"strategy"`
+	Verify               bool     `json:"verify"` // check for gaps, duplicates, reordering and corruption
 
 	// Filled out by bench.GenerateCreateJobs
 	Streams []*StreamInfo `json:"streams,omitempty"`
+	Plan    *Plan         `json:"plan,omitempty"`
+	Written *Written      `json:"written,omitempty"` // only in verify mode, if the write job is known
+}
+
+// Written is how a write job split its messages; read jobs in verify mode
+// use it to tell the last sequence of every producer (see bench.newPlans)
+type Written struct {
+	NumNodes             int      `json:"num_nodes"`
+	NumMessagesPerStream int      `json:"num_messages_per_stream"`
+	NumWorkersPerStream  int      `json:"num_workers_per_stream"`
+	Subjects             []string `json:"subjects"`
+}
+
+// Plan is the exact share of a job's messages that a single node processes.
+// Every stream is split the same way and the shares of all nodes add up to
+// num_messages_per_stream for each stream; see bench.newPlans.
+type Plan struct {
+	NodeIndex int `json:"node_index"`
+
+	// Messages per worker and subject, for each stream: Workers[w][s] is the
+	// number of messages worker w processes on the s-th subject
+	Workers [][]int `json:"workers"`
+
+	NumMessagesPerStream int `json:"num_messages_per_stream"` // this node's share of each stream
+	NumMessages          int `json:"num_messages"`            // this node's share of the job
+
+	// Messages per worker and subject of individual streams; takes precedence
+	// over Workers. Only set for the remaining share of a reassigned node,
+	// which differs between streams (NumMessagesPerStream is 0 then).
+	Streams map[string][][]int `json:"streams,omitempty"`
+
+	// Messages per worker and subject of individual streams that a lost node
+	// wrote before its share was reassigned; the node that takes over
+	// continues the sequences of its producers from there
+	Offsets map[string][][]int `json:"offsets,omitempty"`
+}
+
+// VerifyReport is the outcome of verifying the messages read from a stream;
+// see ReadSettings.Verify
+type VerifyReport struct {
+	Stream     string `json:"stream"`
+	Messages   int    `json:"messages"`
+	Producers  int    `json:"producers"`
+	Gaps       int    `json:"gaps"` // messages missing; set once final
+	Duplicates int    `json:"duplicates"`
+	Reordered  int    `json:"reordered"`
+	Corrupted  int    `json:"corrupted"`
+
+	// Gaps and duplicates across nodes were estimated from the number of
+	// messages received from some producers; see SeqSummary
+	Estimated bool `json:"estimated,omitempty"`
+
+	// Subject -> producer -> received sequences; only set in final node
+	// statuses
+	Sequences map[string]map[string]*SeqSummary `json:"sequences,omitempty"`
+}
+
+// SeqSummary describes the sequences received from a producer. Ranges are
+// [first, last, ..] lists; they are left out once a status holds
+// bench.MaxVerifyRanges ranges, so that it fits in the results bucket.
+type SeqSummary struct {
+	First  uint64   `json:"first"`
+	Last   uint64   `json:"last"`
+	Count  uint64   `json:"count"`
+	Ranges []uint64 `json:"ranges,omitempty"`
+}
+
+// PlanReport compares the number of messages processed with the plan
+type PlanReport struct {
+	Planned   int  `json:"planned"`
+	Processed int  `json:"processed"`
+	Complete  bool `json:"complete"` // set once final; true if every planned message was processed
 }
 
 type StreamInfo struct {
@@ -79,16 +265,99 @@ type StreamInfo struct {
 }
 
 type StatusResponse struct {
-	Status   *Status   `json:"status"`
-	Settings *Settings `json:"settings"`
+	Status     *Status     `json:"status"`
+	Settings   *Settings   `json:"settings"`
+	Comparison *Comparison `json:"comparison,omitempty"`
+
+	// Set if the job was read from the history stream
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// ArchivedRun is a finished job as kept in the history stream: its settings
+// and aggregate status, without node reports or the raw latency histogram
+type ArchivedRun struct {
+	Settings   *Settings `json:"settings"`
+	Status     *Status   `json:"status"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// BenchSummary is a job as listed by GET /bench
+type BenchSummary struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description,omitempty"`
+	Type        string            `json:"type"` // "write" or "read"
+	Profile     string            `json:"profile,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	ScheduleID  string            `json:"schedule_id,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	NumStreams  int               `json:"num_streams"`
+	NumNodes    int               `json:"num_nodes"`
+	Archived    bool              `json:"archived,omitempty"`
+
+	// From the job's aggregate status; Status is "unknown" if the job has
+	// no results
+	Status                 JobStatus `json:"status"`
+	ElapsedSeconds         float64   `json:"elapsed_seconds,omitempty"`
+	TotalMsgPerSecAllNodes float64   `json:"total_msg_per_sec_all_nodes,omitempty"`
+	Passed                 *bool     `json:"passed,omitempty"` // set if the job has a verdict
+}
+
+// BenchList is a page of job summaries; Total is the number of jobs that
+// match the filter
+type BenchList struct {
+	Total      int             `json:"total"`
+	Offset     int             `json:"offset"`
+	Limit      int             `json:"limit"`
+	Benchmarks []*BenchSummary `json:"benchmarks"`
+}
+
+// ListFilter selects the jobs listed by GET /bench; zero values match every
+// job
+type ListFilter struct {
+	Statuses    []JobStatus
+	Type        string
+	Description string // case-insensitive substring
+	Profile     string
+	Owner       string
+
+	// Jobs must have every tag; an empty value matches any value
+	Tags  map[string]string
+	Since time.Time // created at or after
+	Until time.Time // created before
+
+	// Field to sort by, prefixed with "-" for descending order
+	Sort   string
+	Offset int
+	Limit  int
 }
 
 type WorkerReport struct {
 	WorkerID       string
-	Processed      int     `json:"processed"`
-	Errors         int     `json:"errors"`
-	ElapsedSeconds float64 `json:"elapsed_seconds,omitempty"`
-	AvgMsgPerSec   float64 `json:"avg_msg_per_sec,omitempty"` // Inf+ problem
+	Processed      int             `json:"processed"`
+	Errors         int             `json:"errors"`
+	ElapsedSeconds float64         `json:"elapsed_seconds,omitempty"`
+	AvgMsgPerSec   float64         `json:"avg_msg_per_sec,omitempty"` // Inf+ problem
+	Latency        *LatencySummary `json:"latency,omitempty"`
+}
+
+// LatencyHistogram holds batch latencies (time to publish a batch and receive
+// all of its acks, or time to fetch a batch). Buckets are not cumulative:
+// Buckets[i] is the number of observations in (LatencyBucketsMs[i-1],
+// LatencyBucketsMs[i]]; the last bucket is +Inf.
+type LatencyHistogram struct {
+	Buckets []uint64 `json:"buckets"`
+	Count   uint64   `json:"count"`
+	SumMs   float64  `json:"sum_ms"`
+	MaxMs   float64  `json:"max_ms"`
+}
+
+type LatencySummary struct {
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P90Ms  float64 `json:"p90_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
 }
 
 type StreamReport struct {
@@ -96,25 +365,34 @@ type StreamReport struct {
 }
 
 type NodeReport struct {
+	NodeID  string         `json:"node_id,omitempty"` // set in aggregated node_reports
 	Streams []StreamReport `json:"streams,omitempty"`
 }
 
 type Status struct {
-	Status                 JobStatus     `json:"status"`
-	Message                string        `json:"message"`
-	Errors                 []string      `json:"errors,omitempty"`
-	JobID                  string        `json:"job_id"`
-	NodeID                 string        `json:"node_id,omitempty"`
-	ElapsedSeconds         float64       `json:"elapsed_seconds,omitempty"`
-	AvgMsgPerSecPerNode    float64       `json:"avg_msg_per_sec_per_node,omitempty"` // Inf+ problem
-	TotalMsgPerSecAllNodes float64       `json:"total_msg_per_sec_all_nodes,omitempty"`
-	AvgMsgPerSecAllNodes   float64       `json:"avg_msg_per_sec_all_nodes,omitempty"`
-	TotalProcessed         int           `json:"total_processed"`
-	TotalErrors            int           `json:"total_errors"`
-	StartedAt              time.Time     `json:"started_at"`
-	EndedAt                time.Time     `json:"ended_at,omitempty"`     // omitempty because it's not set for in-progress jobs
-	NodeReport             *NodeReport   `json:"node_report,omitempty"`  // used per node
-	NodeReports            []*NodeReport `json:"node_reports,omitempty"` // used for aggregate display for status
+	Status                 JobStatus         `json:"status"`
+	Message                string            `json:"message"`
+	Errors                 []string          `json:"errors,omitempty"`
+	JobID                  string            `json:"job_id"`
+	NodeID                 string            `json:"node_id,omitempty"`
+	ElapsedSeconds         float64           `json:"elapsed_seconds,omitempty"`
+	AvgMsgPerSecPerNode    float64           `json:"avg_msg_per_sec_per_node,omitempty"` // Inf+ problem
+	TotalMsgPerSecAllNodes float64           `json:"total_msg_per_sec_all_nodes,omitempty"`
+	AvgMsgPerSecAllNodes   float64           `json:"avg_msg_per_sec_all_nodes,omitempty"`
+	TotalProcessed         int               `json:"total_processed"`
+	TotalErrors            int               `json:"total_errors"`
+	StartedAt              time.Time         `json:"started_at"`
+	EndedAt                time.Time         `json:"ended_at,omitempty"` // omitempty because it's not set for in-progress jobs
+	Latency                *LatencySummary   `json:"latency,omitempty"`
+	LatencyHistogram       *LatencyHistogram `json:"latency_histogram,omitempty"`
+	Verdict                *Verdict          `json:"verdict,omitempty"`      // set once the job is final if settings have an expect block
+	NodeReport             *NodeReport       `json:"node_report,omitempty"`  // used per node
+	NodeReports            []*NodeReport     `json:"node_reports,omitempty"` // used for aggregate display for status
+	Plan                   *PlanReport       `json:"plan,omitempty"`         // planned vs. processed messages
+	Verify                 []*VerifyReport   `json:"verify,omitempty"`       // per stream, for read jobs in verify mode
+
+	// Set by natssvc when reading a node's status; used for optimistic updates
+	Revision uint64 `json:"-"`
 }
 
 type PurgeRequest struct {
@@ -133,4 +411,347 @@ type Job struct {
 	// Set by bench.NewJob(jobID)
 	Context    context.Context    `json:"-"`
 	CancelFunc context.CancelFunc `json:"-"`
+
+	// Closed once the job has finished and its final status was written
+	Done chan struct{} `json:"-"`
+}
+
+// CreateReply is sent by a node in response to a create job, before the
+// job runs
+type CreateReply struct {
+	NodeID   string `json:"node_id"`
+	JobID    string `json:"job_id"`
+	Accepted bool   `json:"accepted"`
+
+	// Why the node rejected the job
+	Error string `json:"error,omitempty"`
+}
+
+// CreateResponse is returned by POST /bench. A job is only created if every
+// node accepted its share of it; otherwise Error is set and the nodes that
+// accepted are told to cancel.
+type CreateResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	Accepted []string `json:"accepted"`
+
+	// Nodes that rejected the job (or did not reply in time) and why
+	Rejected map[string]string `json:"rejected,omitempty"`
+
+	// Set if the job was not created because of the cluster lock
+	Conflict *Conflict `json:"conflict,omitempty"`
+}
+
+// Conflict is why a job with on_conflict "reject" was not created: another
+// exclusive job holds the cluster lock or, for exclusive jobs, other jobs are
+// running
+type Conflict struct {
+	Lock *ClusterLock `json:"lock,omitempty"`
+	Jobs []string     `json:"jobs,omitempty"`
+}
+
+// CancelReply is sent by a node in response to a delete job
+type CancelReply struct {
+	NodeID string `json:"node_id"`
+	JobID  string `json:"job_id"`
+
+	// Whether the job was running on the node when the delete job arrived
+	Running bool `json:"running"`
+
+	// Set if the job did not stop in time
+	Error string `json:"error,omitempty"`
+}
+
+// DeleteResponse is returned by DELETE /bench/:id
+type DeleteResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+
+	// Nodes that stopped the job (or were not running it)
+	Confirmed []string `json:"confirmed"`
+
+	// Confirmed nodes that were running the job when it was deleted
+	Cancelled []string `json:"cancelled"`
+
+	// Nodes that did not reply in time or failed to stop the job
+	Unconfirmed []string `json:"unconfirmed"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// NodeInfo is written by each njst node into the heartbeat bucket
+type NodeInfo struct {
+	ID            string            `json:"id"`
+	Version       string            `json:"version"`
+	Hostname      string            `json:"hostname,omitempty"`
+	StartedAt     time.Time         `json:"started_at"`
+	LastSeen      time.Time         `json:"last_seen"`
+	GOMAXPROCS    int               `json:"gomaxprocs"`
+	NumCPU        int               `json:"num_cpu"`
+	MemAllocBytes uint64            `json:"mem_alloc_bytes"`
+	MemSysBytes   uint64            `json:"mem_sys_bytes"`
+	NumGoroutines int               `json:"num_goroutines"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Jobs          []string          `json:"jobs"`
+	Queue         *NodeJobs         `json:"queue,omitempty"`
+}
+
+type NodeJobState string
+
+const (
+	RunningNodeJobState NodeJobState = "running"
+	QueuedNodeJobState  NodeJobState = "queued"
+)
+
+// NodeJobs lists the jobs a node accepted, running and queued; part of the
+// node's heartbeat and returned by GET /cluster/:node/jobs
+type NodeJobs struct {
+	NodeID            string     `json:"node_id"`
+	MaxConcurrentJobs int        `json:"max_concurrent_jobs"`
+	MaxQueuedJobs     int        `json:"max_queued_jobs"`
+	NumRunning        int        `json:"num_running"`
+	NumQueued         int        `json:"num_queued"`
+	Jobs              []*NodeJob `json:"jobs"`
+}
+
+type NodeJob struct {
+	ID         string       `json:"id"`
+	State      NodeJobState `json:"state"`
+	Position   int          `json:"position,omitempty"` // 1-based position in the queue
+	Reason     string       `json:"reason,omitempty"`   // why a queued job has not started yet
+	Exclusive  bool         `json:"exclusive,omitempty"`
+	AcceptedAt time.Time    `json:"accepted_at"`
+	StartedAt  time.Time    `json:"started_at,omitempty"`
+}
+
+// ClusterResponse is returned by GET /cluster
+type ClusterResponse struct {
+	Nodes    []*NodeInfo    `json:"nodes"`
+	Count    int            `json:"count"`
+	NumIdle  int            `json:"num_idle"`
+	Versions map[string]int `json:"versions"`
+	Lock     *ClusterLock   `json:"lock,omitempty"`
+}
+
+// Schedule is a recurring benchmark; stored in the schedules bucket
+type Schedule struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Cron        string    `json:"cron"`
+	Settings    *Settings `json:"settings"`
+	CreatedAt   time.Time `json:"created_at"`
+	NextRunAt   time.Time `json:"next_run_at"`
+	LastRunAt   time.Time `json:"last_run_at,omitempty"`
+	LastRunBy   string    `json:"last_run_by,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	JobIDs      []string  `json:"job_ids"`
+
+	// Set by natssvc when reading a schedule; used for optimistic updates
+	Revision uint64 `json:"-"`
+}
+
+const (
+	WriteStep   StepType = "write"
+	ReadStep    StepType = "read"
+	MixedStep   StepType = "mixed"
+	WaitStep    StepType = "wait"
+	CleanupStep StepType = "cleanup"
+)
+
+type StepType string
+
+// Scenario is an ordered list of steps that are executed one after another;
+// stored in the scenarios bucket
+type Scenario struct {
+	ID          string          `json:"id"`
+	Description string          `json:"description,omitempty"`
+	NATS        *NATS           `json:"nats,omitempty"` // default for steps that do not specify nats settings
+	Steps       []*ScenarioStep `json:"steps"`
+	Timeout     string          `json:"timeout,omitempty"` // ex: "2h"; the scenario fails if it runs longer
+
+	// Set by bench
+	Status    JobStatus     `json:"status"`
+	Message   string        `json:"message,omitempty"`
+	CreatedBy string        `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+	EndedAt   time.Time     `json:"ended_at,omitempty"`
+	Results   []*StepResult `json:"results"`
+}
+
+type ScenarioStep struct {
+	Name string   `json:"name"`
+	Type StepType `json:"type"`
+
+	// Used by write, read and mixed steps. String values may reference the job
+	// ID of a previous step via ${steps.<name>.id}
+	Settings *Settings `json:"settings,omitempty"`
+
+	// Used by wait steps (ex: "30s")
+	Duration string `json:"duration,omitempty"`
+
+	// Used by cleanup steps
+	Cleanup *CleanupSettings `json:"cleanup,omitempty"`
+}
+
+type CleanupSettings struct {
+	// Steps to clean up after; defaults to all previous steps
+	Steps    []string `json:"steps,omitempty"`
+	Streams  bool     `json:"streams"`
+	Results  bool     `json:"results"`
+	Settings bool     `json:"settings"`
+}
+
+type StepResult struct {
+	Name      string    `json:"name"`
+	Type      StepType  `json:"type"`
+	Status    JobStatus `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	JobIDs    []string  `json:"job_ids,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"`
+	Results   []*Status `json:"results,omitempty"`
+}
+
+// Sweep runs the base job once for every combination of axis values;
+// stored in the sweeps bucket
+type Sweep struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Base        *Settings `json:"base"`
+
+	// Setting name -> values to sweep over. Names are either qualified by
+	// section ("write.msg_size_bytes", "nats.shared_connection") or bare
+	// ("msg_size_bytes"), in which case they refer to the nats section or
+	// the read/write section of the base job.
+	Axes map[string][]interface{} `json:"axes"`
+
+	// Set by bench
+	Status    JobStatus      `json:"status"`
+	Message   string         `json:"message,omitempty"`
+	CreatedBy string         `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	EndedAt   time.Time      `json:"ended_at,omitempty"`
+	Results   []*SweepResult `json:"results"`
+}
+
+// SweepResult is a single row of the sweep comparison table
+type SweepResult struct {
+	Params                 map[string]interface{} `json:"params"`
+	JobID                  string                 `json:"job_id,omitempty"`
+	Status                 JobStatus              `json:"status"`
+	Error                  string                 `json:"error,omitempty"`
+	ElapsedSeconds         float64                `json:"elapsed_seconds"`
+	TotalProcessed         int                    `json:"total_processed"`
+	TotalErrors            int                    `json:"total_errors"`
+	TotalMsgPerSecAllNodes float64                `json:"total_msg_per_sec_all_nodes"`
+	AvgMsgPerSecPerNode    float64                `json:"avg_msg_per_sec_per_node"`
+	Latency                *LatencySummary        `json:"latency,omitempty"`
+}
+
+// Baseline is the reference job for a profile; stored in the baselines bucket
+type Baseline struct {
+	Profile    string      `json:"profile"`
+	JobID      string      `json:"job_id"`
+	Thresholds *Thresholds `json:"thresholds"`
+
+	// Set by bench; copy of the baseline job's aggregate status so that the
+	// baseline outlives the job's results
+	Status    *Status   `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Thresholds define how much worse than the baseline a job may be before it
+// is considered a regression
+// Thresholds that are not set (nil) fall back to their defaults; 0 means no
+// regression is tolerated
+type Thresholds struct {
+	// Max drop in total msg/sec, in percent of the baseline
+	ThroughputDropPct *float64 `json:"throughput_drop_pct,omitempty"`
+
+	// Max increase in error rate, in percentage points
+	ErrorRateIncreasePct *float64 `json:"error_rate_increase_pct,omitempty"`
+
+	// Max increase of p50/p90/p99 latency, in percent of the baseline
+	LatencyIncreasePct *float64 `json:"latency_increase_pct,omitempty"`
+}
+
+// Comparison of a completed job against the baseline of its profile
+type Comparison struct {
+	Profile       string              `json:"profile"`
+	BaselineJobID string              `json:"baseline_job_id"`
+	Regressed     bool                `json:"regressed"`
+	Metrics       []*MetricComparison `json:"metrics"`
+}
+
+type MetricComparison struct {
+	Name      string  `json:"name"`
+	Baseline  float64 `json:"baseline"`
+	Current   float64 `json:"current"`
+	Delta     float64 `json:"delta"`
+	DeltaPct  float64 `json:"delta_pct"`
+	Threshold float64 `json:"threshold"`
+	Regressed bool    `json:"regressed"`
+}
+
+// NodeMetrics is a point-in-time snapshot of a node's live job metrics; used
+// by the /metrics endpoint
+type NodeMetrics struct {
+	NodeID              string        `json:"node_id"`
+	RunningJobs         int           `json:"running_jobs"`
+	QueuedJobs          int           `json:"queued_jobs"`
+	NATSConnectionState string        `json:"nats_connection_state"`
+	Jobs                []*JobMetrics `json:"jobs"`
+}
+
+type JobMetrics struct {
+	JobID       string            `json:"job_id"`
+	Description string            `json:"description"`
+	Type        string            `json:"type"` // "write" or "read"
+	Running     bool              `json:"running"`
+	Workers     int               `json:"workers"` // running workers
+	Streams     []*StreamMetrics  `json:"streams"`
+	Latency     *LatencyHistogram `json:"latency"`
+}
+
+type StreamMetrics struct {
+	Stream   string            `json:"stream"`
+	Messages uint64            `json:"messages"`
+	Bytes    uint64            `json:"bytes"`
+	Errors   map[string]uint64 `json:"errors"` // by category
+}
+
+// TimelineSample holds the activity of a job on a node (or, in
+// Timeline.Totals, on all nodes) during a single reporter interval
+type TimelineSample struct {
+	JobID            string            `json:"job_id"`
+	NodeID           string            `json:"node_id,omitempty"`
+	Timestamp        time.Time         `json:"timestamp"` // end of the interval
+	IntervalSeconds  float64           `json:"interval_seconds"`
+	Messages         uint64            `json:"messages"`
+	Bytes            uint64            `json:"bytes"`
+	Errors           uint64            `json:"errors"`
+	MsgPerSec        float64           `json:"msg_per_sec"`
+	BytesPerSec      float64           `json:"bytes_per_sec"`
+	Latency          *LatencySummary   `json:"latency,omitempty"`
+	LatencyHistogram *LatencyHistogram `json:"latency_histogram,omitempty"`
+}
+
+type Timeline struct {
+	JobID string `json:"job_id"`
+
+	// Per-second sums of all nodes' samples
+	Totals []*TimelineSample `json:"totals"`
+
+	// Node ID -> samples of that node
+	Nodes map[string][]*TimelineSample `json:"nodes"`
+}
+
+// JobEvent is streamed by GET /bench/:id/events
+type JobEvent struct {
+	Type    string  `json:"type"`              // "node", "status" or "end"
+	NodeID  string  `json:"node_id,omitempty"` // set for "node" events
+	State   string  `json:"state,omitempty"`   // node state; set for "node" events
+	Message string  `json:"message,omitempty"`
+	Status  *Status `json:"status,omitempty"` // aggregated job status; set for "status" and "end" events
 }